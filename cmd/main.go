@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	docs "github.com/Leganyst/avitoTrainee/docs"
 	"github.com/Leganyst/avitoTrainee/internal/config"
 	"github.com/Leganyst/avitoTrainee/internal/controller/handlers"
 	"github.com/Leganyst/avitoTrainee/internal/db"
+	"github.com/Leganyst/avitoTrainee/internal/mapper"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
+	"github.com/Leganyst/avitoTrainee/internal/model"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
+	"github.com/Leganyst/avitoTrainee/internal/repository/memory"
 	"github.com/Leganyst/avitoTrainee/internal/service"
+	"github.com/Leganyst/avitoTrainee/internal/tracing"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -19,6 +34,14 @@ import (
 // @description     Service for assigning reviewers to pull requests.
 // @BasePath        /
 func main() {
+	// Код выхода выставляется в конце main, а применяется последним, после остальных defer: os.Exit их не выполняет.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	cfg := config.Load()
 	if err := config.InitLogger(cfg.LogLevel); err != nil {
 		panic("failed to initialize logger: " + err.Error())
@@ -26,30 +49,85 @@ func main() {
 	defer config.Logger().Sync()
 	config.Logger().Infow("config loaded", "port", cfg.Port, "logLevel", cfg.LogLevel)
 
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		config.Logger().Fatalw("tracing init failed", "error", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			config.Logger().Warnw("tracing shutdown failed", "error", err)
+		}
+	}()
+	config.Logger().Infow("tracing initialized", "exporter", cfg.TracingExporter)
+
 	docs.SwaggerInfo.BasePath = "/"
 
-	conn, err := db.Connect(cfg)
+	// Миграции управляются отдельно от остальных команд: откат и статус не должны начинаться с применения миграций.
+	if args := os.Args[1:]; len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(context.Background(), cfg, args[1:]); err != nil {
+			config.Logger().Fatalw("command failed", "command", strings.Join(args, " "), "error", err)
+		}
+		return
+	}
+
+	repos, err := openStorage(cfg)
 	if err != nil {
-		config.Logger().Fatalw("cannot connect to database", "error", err)
+		config.Logger().Fatalw("cannot open storage", "storage", cfg.Storage, "error", err)
+	}
+	config.Logger().Infow("storage opened", "storage", cfg.Storage)
+
+	teamSvc := service.NewTeamService(repos.uow, repos.team, repos.user)
+	prSvc := service.NewPrService(repos.uow, repos.pr, repos.user, repos.availability, repos.audit)
+	userSvc := service.NewUserService(repos.uow, repos.user, repos.pr, repos.team, repos.availability, repos.audit)
+	statsSvc := service.NewStatsService(repos.stats, repos.team)
+	availabilitySvc := service.NewAvailabilityService(repos.uow, repos.availability, repos.user, repos.team, repos.pr, repos.audit, prSvc)
+	auditSvc := service.NewAuditService(repos.audit)
+	consistencySvc := service.NewConsistencyService(repos.pr, prSvc)
+	jobSvc := service.NewJobService(repos.uow, repos.job, repos.team, userSvc)
+	// Резерв ключа живёт не меньше дедлайна запроса, иначе ретрай может начаться, пока первый запрос ещё выполняется.
+	idempotencySvc := service.NewIdempotencyService(repos.idempotency, cfg.IdempotencyTTL, max(cfg.RequestTimeout, time.Minute))
+
+	if err := metrics.RegisterOpenPRs(repos.stats); err != nil {
+		config.Logger().Fatalw("register open PRs metric failed", "error", err)
 	}
 
-	if err := db.Migrate(conn); err != nil {
-		config.Logger().Fatalw("auto-migrate failed", "error", err)
+	if args := os.Args[1:]; len(args) > 0 {
+		if err := runCommand(context.Background(), args, statsSvc, consistencySvc); err != nil {
+			config.Logger().Fatalw("command failed", "command", strings.Join(args, " "), "error", err)
+		}
+		return
 	}
 
-	teamRepo := repository.NewTeamRepository(conn)
-	userRepo := repository.NewUserRepository(conn)
-	prRepo := repository.NewPRRepository(conn)
-	statsRepo := repository.NewStatsRepository(conn)
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
-	userSvc := service.NewUserService(userRepo, prRepo, teamRepo)
-	statsSvc := service.NewStatsService(statsRepo)
+	// Фоновые задачи останавливаются закрытием stop, а сервер ждёт их в serve.
+	stop := make(chan struct{})
+	var background sync.WaitGroup
+	if cfg.AbsenceJobInterval > 0 {
+		background.Go(func() { service.RunAbsenceReassignJob(availabilitySvc, cfg.AbsenceJobInterval, stop) })
+		config.Logger().Infow("absence reassign job started", "interval", cfg.AbsenceJobInterval)
+	}
+	if cfg.SLAEscalationInterval > 0 {
+		background.Go(func() { service.RunSLAEscalationJob(statsSvc, prSvc, cfg.SLAEscalationInterval, stop) })
+		config.Logger().Infow("SLA escalation job started", "interval", cfg.SLAEscalationInterval)
+	}
+
+	if cfg.IdempotencyTTL > 0 {
+		background.Go(func() { service.RunIdempotencyCleanupJob(idempotencySvc, cfg.IdempotencyTTL, stop) })
+		config.Logger().Infow("idempotency cleanup job started", "interval", cfg.IdempotencyTTL)
+	}
+
+	if cfg.JobWorkers > 0 {
+		background.Go(func() { service.RunJobWorkers(jobSvc, cfg.JobWorkers, cfg.JobPollInterval, stop) })
+		config.Logger().Infow("job workers started", "workers", cfg.JobWorkers, "poll_interval", cfg.JobPollInterval)
+	}
 
 	r := gin.Default()
 
-	handlers.RegisterRoutes(r, teamSvc, userSvc, prSvc, statsSvc)
+	handlers.RegisterRoutes(r, cfg.RequestTimeout, teamSvc, userSvc, prSvc, statsSvc, availabilitySvc, auditSvc, idempotencySvc, consistencySvc, jobSvc)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -59,7 +137,151 @@ func main() {
 	}
 
 	config.Logger().Info("server running on :8080")
-	if err := srv.ListenAndServe(); err != nil {
-		config.Logger().Fatalw("server stopped", "error", err)
+	if err := serve(ctx, srv, cfg.RequestTimeout+shutdownGrace, stop, &background); err != nil {
+		config.Logger().Errorw("server stopped", "error", err)
+		exitCode = 1
+	}
+}
+
+// shutdownGrace — запас сверх дедлайна запроса, за который сервер дожидается текущих запросов при остановке.
+const shutdownGrace = 5 * time.Second
+
+// serve обслуживает HTTP до сигнала остановки в ctx или ошибки сервера. Затем за shutdownTimeout дожидается
+// текущих запросов, закрывает stop и ждёт фоновые задачи: обработчик задач отпускает недоделанную задачу в очередь,
+// а не бросает её до истечения аренды. Возвращает ошибку сервера; остановка по сигналу ошибкой не считается.
+func serve(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, stop chan<- struct{}, background *sync.WaitGroup) error {
+	served := make(chan error, 1)
+	go func() { served <- srv.ListenAndServe() }()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		config.Logger().Info("shutdown signal received")
+	case serveErr = <-served:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		config.Logger().Warnw("http server shutdown failed", "error", err)
+	}
+	close(stop)
+	background.Wait()
+	config.Logger().Info("shutdown complete")
+	return serveErr
+}
+
+// repositories — реализации репозиториев выбранного хранилища.
+type repositories struct {
+	team         repository.TeamRepository
+	user         repository.UserRepository
+	pr           repository.PRRepository
+	stats        repository.StatsRepository
+	availability repository.AvailabilityRepository
+	audit        repository.AuditRepository
+	idempotency  repository.IdempotencyRepository
+	job          repository.JobRepository
+	uow          repository.UnitOfWork
+}
+
+// openStorage подключает хранилище из cfg.Storage: БД (PostgreSQL или SQLite) с миграциями или память процесса.
+func openStorage(cfg *config.Config) (*repositories, error) {
+	switch cfg.Storage {
+	case config.StoragePostgres, config.StorageSQLite:
+		conn, err := db.Connect(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := db.MigrateUp(context.Background(), conn); err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+		return &repositories{
+			team:         repository.NewTeamRepository(conn),
+			user:         repository.NewUserRepository(conn),
+			pr:           repository.NewPRRepository(conn),
+			stats:        repository.NewStatsRepository(conn),
+			availability: repository.NewAvailabilityRepository(conn),
+			audit:        repository.NewAuditRepository(conn),
+			idempotency:  repository.NewIdempotencyRepository(conn),
+			job:          repository.NewJobRepository(conn),
+			uow:          repository.NewUnitOfWork(conn),
+		}, nil
+	case config.StorageMemory:
+		store := memory.NewStore()
+		return &repositories{
+			team:         memory.NewTeamRepository(store),
+			user:         memory.NewUserRepository(store),
+			pr:           memory.NewPRRepository(store),
+			stats:        memory.NewStatsRepository(store),
+			availability: memory.NewAvailabilityRepository(store),
+			audit:        memory.NewAuditRepository(store),
+			idempotency:  memory.NewIdempotencyRepository(store),
+			job:          memory.NewJobRepository(store),
+			uow:          memory.NewUnitOfWork(store),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, available: %s, %s, %s",
+			cfg.Storage, config.StoragePostgres, config.StorageSQLite, config.StorageMemory)
+	}
+}
+
+// runCommand выполняет административную команду вместо запуска HTTP-сервера, например `server stats rebuild`.
+// `consistency check` печатает отчёт о нарушениях в JSON, `consistency repair` ещё и исправляет их.
+func runCommand(ctx context.Context, args []string, statsSvc service.StatsService, consistencySvc service.ConsistencyService) error {
+	switch command := strings.Join(args, " "); command {
+	case "stats rebuild":
+		return statsSvc.RebuildAssignmentStats(ctx)
+	case "consistency check", "consistency repair":
+		report, err := consistencySvc.Check(ctx, service.SystemOrigin(model.ReasonConsistency), command == "consistency repair")
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(mapper.MapConsistencyReportToDTO(report))
+	default:
+		return fmt.Errorf("unknown command %q, available: stats rebuild, consistency check|repair, migrate up|down|status", command)
+	}
+}
+
+// runMigrate выполняет `server migrate up|down|status`: up применяет новые миграции,
+// down откатывает последнюю применённую, status печатает версии и время применения.
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if cfg.Storage == config.StorageMemory {
+		return fmt.Errorf("storage %q has no schema to migrate", cfg.Storage)
+	}
+	conn, err := db.Connect(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch command := strings.Join(args, " "); command {
+	case "up":
+		applied, err := db.MigrateUp(ctx, conn)
+		if err != nil {
+			return err
+		}
+		config.Logger().Infow("migrations up to date", "applied", len(applied))
+		return nil
+	case "down":
+		_, err := db.MigrateDown(ctx, conn)
+		return err
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range statuses {
+			appliedAt := "pending"
+			if st.AppliedAt != nil {
+				appliedAt = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", st.Version, st.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown command %q, available: migrate up|down|status", "migrate "+command)
 	}
 }
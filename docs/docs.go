@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/pullRequest/create": {
+        "/api/admin/consistency": {
             "post": {
-                "description": "Создаёт PR и автоматически назначает доступных ревьюверов.",
+                "description": "Ищет в открытых PR неактивных ревьюверов, автора среди ревьюверов, дубликаты и превышение лимита ревьюверов, ревьюверов не из команды автора и PR удалённых авторов. По умолчанию только отчёт (dry-run). С apply=true нарушения исправляются обычными операциями: Reassign (без кандидатов — снятие), RemoveReviewer или повторное добавление; в отчёт попадает результат каждого исправления.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,25 +25,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PullRequests"
+                    "Admin"
                 ],
-                "summary": "Создать PR",
+                "summary": "Проверка согласованности назначений",
                 "parameters": [
                     {
-                        "description": "Данные PR",
+                        "description": "Режим: dry-run или apply",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/CreatePRRequest"
+                            "$ref": "#/definitions/ConsistencyCheckRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CreatePRResponse"
+                            "$ref": "#/definitions/ConsistencyReportResponse"
                         }
                     },
                     "400": {
@@ -52,20 +57,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -73,9 +72,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/pullRequest/merge": {
-            "post": {
-                "description": "Переводит PR в состояние MERGED (идемпотентно).",
+        "/api/audit": {
+            "get": {
+                "description": "Возвращает события назначений (assigned, unassigned, replaced, merged, reviewed, activity_changed, а также изменения отсутствий, праздников и делегирований) от новых к старым. Фильтр по пользователю учитывает и нового, и заменённого ревьювера.",
                 "consumes": [
                     "application/json"
                 ],
@@ -83,25 +82,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PullRequests"
+                    "Audit"
                 ],
-                "summary": "Merge PR",
+                "summary": "Журнал назначений",
                 "parameters": [
                     {
+                        "type": "string",
                         "description": "Идентификатор PR",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/MergePRRequest"
-                        }
+                        "name": "pull_request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя команды",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339, включительно)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339, не включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум событий (по умолчанию 100, не больше 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/MergePRResponse"
+                            "$ref": "#/definitions/AuditResponse"
                         }
                     },
                     "400": {
@@ -110,14 +136,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -125,9 +151,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/pullRequest/reassign": {
+        "/api/jobs/bulkDeactivate": {
             "post": {
-                "description": "Заменяет ревьювера на другого активного участника его команды.",
+                "description": "Создаёт фоновую задачу деактивации пользователей команды с переназначением их открытых PR и сразу возвращает её. Каждый пользователь — отдельный элемент, который выполняется и фиксируется в своей транзакции; заменой не выбирается никто из пользователей задачи. Прогресс и результаты — в GET /api/jobs/{id}.",
                 "consumes": [
                     "application/json"
                 ],
@@ -135,25 +161,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PullRequests"
+                    "Jobs"
                 ],
-                "summary": "Переназначить ревьювера",
+                "summary": "Поставить массовую деактивацию в очередь",
                 "parameters": [
                     {
-                        "description": "Параметры переназначения",
+                        "description": "Команда и user_id",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ReassgnRequest"
+                            "$ref": "#/definitions/BulkDeactivateJobRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/ReassignResponse"
+                            "$ref": "#/definitions/JobResponse"
                         }
                     },
                     "400": {
@@ -168,14 +200,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -183,53 +215,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/stats/assignments/by-pr": {
+        "/api/jobs/{id}": {
             "get": {
-                "description": "Возвращает список PR с количеством назначенных ревьюверов. Список отсортирован по числу ревьюверов по убыванию.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает статус задачи, прогресс и результат каждого элемента.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stats"
+                    "Jobs"
+                ],
+                "summary": "Получить фоновую задачу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Статистика назначений по PR",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/AssignmentByPRResponse"
+                            "$ref": "#/definitions/JobResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/stats/assignments/by-user": {
-            "get": {
-                "description": "Возвращает список пользователей с количеством назначений на ревью. Пользователи сортируются по количеству назначений по убыванию.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Stats"
-                ],
-                "summary": "Статистика назначений по пользователям",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/AssignmentByUserResponse"
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
@@ -237,39 +258,46 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/team/add": {
+        "/api/jobs/{id}/cancel": {
             "post": {
-                "description": "Создаёт команду и пользователей, если их ещё нет.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Задача из очереди отменяется сразу. Выполняемая задача останавливается перед следующим элементом: уже выполненные элементы остаются в силе, остальные помечаются canceled. Завершённую или уже отменённую задачу отменить нельзя.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Jobs"
                 ],
-                "summary": "Создать команду",
+                "summary": "Отменить фоновую задачу",
                 "parameters": [
                     {
-                        "description": "Данные команды",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/CreateTeamRequest"
-                        }
+                        "type": "integer",
+                        "description": "Идентификатор задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/TeamResponse"
+                            "$ref": "#/definitions/JobResponse"
                         }
                     },
                     "400": {
@@ -278,18 +306,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/team/get": {
-            "get": {
-                "description": "Возвращает команду и участников по имени.",
+        "/api/pullRequest/create": {
+            "post": {
+                "description": "Создаёт PR и автоматически назначает доступных ревьюверов.",
                 "consumes": [
                     "application/json"
                 ],
@@ -297,23 +343,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "PullRequests"
                 ],
-                "summary": "Получить команду",
+                "summary": "Создать PR",
                 "parameters": [
+                    {
+                        "description": "Данные PR",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreatePRRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Имя команды",
-                        "name": "team_name",
-                        "in": "query",
-                        "required": true
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/Team"
+                            "$ref": "#/definitions/CreatePRResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
                         }
                     },
                     "400": {
@@ -328,18 +388,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/bulkDeactivate": {
+        "/api/pullRequest/decline": {
             "post": {
-                "description": "Деактивирует переданный список user_id внутри команды и безопасно переназначает их в открытых PR (если найдены кандидаты).",
+                "description": "Снимает ревьювера по его отказу и подбирает замену по правилам команды. Если кандидатов нет, ревьювер просто снимается с PR. В журнале и статистике замен отмечается причиной decline.",
                 "consumes": [
                     "application/json"
                 ],
@@ -347,25 +419,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "PullRequests"
                 ],
-                "summary": "Массовая деактивация пользователей команды",
+                "summary": "Отказаться от ревью",
                 "parameters": [
                     {
-                        "description": "Команда и user_id",
+                        "description": "PR и ревьювер",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/BulkDeactivateRequest"
+                            "$ref": "#/definitions/DeclineRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/BulkDeactivateResponse"
+                            "$ref": "#/definitions/DeclineResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
                         }
                     },
                     "400": {
@@ -380,18 +470,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/getReview": {
+        "/api/pullRequest/get": {
             "get": {
-                "description": "Возвращает PR, где пользователь выступает ревьювером.",
+                "description": "Возвращает PR с ревьюверами. С as_of — статус и состав ревьюверов на указанный момент.",
                 "consumes": [
                     "application/json"
                 ],
@@ -399,23 +507,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "PullRequests"
                 ],
-                "summary": "Получить PR пользователя",
+                "summary": "Получить PR",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Идентификатор пользователя",
-                        "name": "user_id",
+                        "description": "Идентификатор PR",
+                        "name": "pull_request_id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени (RFC3339)",
+                        "name": "as_of",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserReviewResponse"
+                            "$ref": "#/definitions/GetPRResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR, без as_of"
+                            }
                         }
                     },
                     "400": {
@@ -435,13 +555,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/setIsActive": {
+        "/api/pullRequest/merge": {
             "post": {
-                "description": "Ставит или снимает флаг активности пользователя.",
+                "description": "Переводит PR в состояние MERGED (идемпотентно).",
                 "consumes": [
                     "application/json"
                 ],
@@ -449,25 +575,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "PullRequests"
                 ],
-                "summary": "Обновить активность пользователя",
+                "summary": "Merge PR",
                 "parameters": [
                     {
-                        "description": "Параметры активности",
+                        "description": "Идентификатор PR",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/UserRequest"
+                            "$ref": "#/definitions/MergePRRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/MergePRResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
                         }
                     },
                     "400": {
@@ -482,214 +626,2996 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/healthcheck": {
-            "get": {
-                "description": "Returns service health status.",
+        "/api/pullRequest/reassign": {
+            "post": {
+                "description": "Заменяет ревьювера на другого активного участника команды автора PR. Если передан new_user_id, назначается именно он.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "PullRequests"
+                ],
+                "summary": "Переназначить ревьювера",
+                "parameters": [
+                    {
+                        "description": "Параметры переназначения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ReassgnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Health Check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/ReassignResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "AssignmentByPR": {
-            "description": "Количество назначений по PR.",
-            "type": "object",
-            "properties": {
-                "pull_request_id": {
-                    "description": "Идентификатор PR.",
-                    "type": "string",
-                    "example": "pr-1001"
-                },
-                "pull_request_name": {
-                    "description": "Название PR.",
-                    "type": "string",
-                    "example": "Add search endpoint"
-                },
-                "reviewer_count": {
-                    "description": "Число назначенных ревьюверов для PR.",
-                    "type": "integer",
-                    "example": 2
+        },
+        "/api/pullRequest/review": {
+            "post": {
+                "description": "Фиксирует ревью-действие назначенного ревьювера в открытом PR. В метриках учитывается только первое действие в назначении, повторные вызовы ничего не меняют.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PullRequests"
+                ],
+                "summary": "Отметить ревью-действие",
+                "parameters": [
+                    {
+                        "description": "PR и ревьювер",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SubmitReviewRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SubmitReviewResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pullRequest/reviewers/add": {
+            "post": {
+                "description": "Вручную добавляет ревьювера в открытый PR. Ревьювер должен быть активным участником команды автора, не автором и не превышать лимит команды.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PullRequests"
+                ],
+                "summary": "Добавить ревьювера",
+                "parameters": [
+                    {
+                        "description": "PR и добавляемый ревьювер",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddReviewerRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AddReviewerResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pullRequest/reviewers/remove": {
+            "post": {
+                "description": "Убирает ревьювера из открытого PR без назначения замены.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PullRequests"
+                ],
+                "summary": "Снять ревьювера",
+                "parameters": [
+                    {
+                        "description": "PR и снимаемый ревьювер",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RemoveReviewerRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag PR, полученный ранее",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RemoveReviewerResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия PR"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/assignments/by-pr": {
+            "get": {
+                "description": "Возвращает страницу PR с количеством назначенных ревьюверов. По умолчанию сортировка по числу ревьюверов по убыванию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Статистика назначений по PR",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Команда автора PR",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус PR: OPEN или MERGED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id автора PR",
+                        "name": "author_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные не раньше (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные раньше (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50, не больше 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "reviewers_desc (по умолчанию), reviewers_asc, pr_id_asc, pr_id_desc, created_at_desc, created_at_asc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AssignmentByPRResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/assignments/by-user": {
+            "get": {
+                "description": "Возвращает страницу пользователей с количеством текущих назначений на ревью. Фильтры относятся к PR, на которые назначены ревьюверы. По умолчанию сортировка по количеству назначений по убыванию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Статистика назначений по пользователям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Команда автора PR",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус PR: OPEN или MERGED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id автора PR",
+                        "name": "author_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные не раньше (RFC3339, начало суток UTC)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные раньше (RFC3339, начало суток UTC)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50, не больше 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "assignments_desc (по умолчанию), assignments_asc, user_id_asc, user_id_desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AssignmentByUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/durations": {
+            "get": {
+                "description": "Время от создания PR до merge, до первого ревью-действия и время до замены ревьювера, с разбивкой по неделям (понедельник, UTC). group_by=team группирует по команде автора PR, group_by=user — по автору (merge) и ревьюверу (ревью, замены). Без from/to берутся последние 12 недель.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Метрики длительности ревью по неделям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "team (по умолчанию) или user",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339, включительно)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339, не включительно)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ReviewDurationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/fairness": {
+            "get": {
+                "description": "Для каждого участника — открытые и все назначения за историю и отклонение от среднего; коэффициенты Джини и список активных участников, которые ни разу не назначались. Среднее и Джини считаются по активным участникам.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Равномерность нагрузки в команде",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя команды",
+                        "name": "team_name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/FairnessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/reassignments/by-pr": {
+            "get": {
+                "description": "Возвращает страницу PR, с которых хотя бы раз снимали ревьювера: сколько разных ревьюверов было у PR и сколько было снятий. Фильтры такие же, как у статистики назначений. По умолчанию сортировка по числу снятий по убыванию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "PR с наибольшей сменой ревьюверов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Команда автора PR",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус PR: OPEN или MERGED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user_id автора PR",
+                        "name": "author_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные не раньше (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PR, созданные раньше (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50, не больше 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "removals_desc (по умолчанию), removals_asc, pr_id_asc, pr_id_desc, created_at_desc, created_at_asc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/PRChurnResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/reassignments/by-user": {
+            "get": {
+                "description": "По журналу назначений считает, сколько раз каждого пользователя снимали с ревью, всего и по причинам (reassign, manual, delegation, absence, bulk_deactivate, decline, sla_escalation, consistency_repair). Сортировка по общему числу снятий по убыванию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Частота снятий ревьюверов по пользователям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Команда автора PR",
+                        "name": "team_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Снятия не раньше (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Снятия раньше (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserReplacementsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/sla": {
+            "get": {
+                "description": "Для каждого ревьювера открытых PR возвращает, сколько его рабочих часов (с учётом часового пояса, рабочего окна и выходных) прошло с назначения, и нарушен ли SLA команды автора. Сортировка по времени ожидания по убыванию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "SLA ревью в рабочих часах",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только нарушения SLA",
+                        "name": "breached_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ReviewSLAResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team/add": {
+            "post": {
+                "description": "Создаёт команду и пользователей, если их ещё нет.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Создать команду",
+                "parameters": [
+                    {
+                        "description": "Данные команды",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateTeamRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/TeamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team/get": {
+            "get": {
+                "description": "Возвращает команду и участников по имени.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Получить команду",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя команды",
+                        "name": "team_name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team/holidays": {
+            "get": {
+                "description": "Возвращает праздники команды.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Календарь праздников команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя команды",
+                        "name": "team_name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TeamHolidayListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team/holidays/add": {
+            "post": {
+                "description": "Добавляет период в календарь команды. Пока период идёт, участники команды не выбираются ревьюверами.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Добавить праздник команды",
+                "parameters": [
+                    {
+                        "description": "Праздник команды",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddTeamHolidayRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/TeamHolidayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/team/setMaxReviewers": {
+            "post": {
+                "description": "Задаёт максимум ревьюверов на PR для команды. PR, в которых ревьюверов больше нового лимита, не меняются, но новых ревьюверов в них не назначить, пока их не станет меньше лимита.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Изменить лимит ревьюверов команды",
+                "parameters": [
+                    {
+                        "description": "Команда и новый лимит",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MaxReviewersRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TeamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/absences": {
+            "get": {
+                "description": "Возвращает все периоды отсутствия пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Отсутствия пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AbsenceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/absences/add": {
+            "post": {
+                "description": "Сохраняет период отсутствия пользователя. Пока период идёт, пользователь не выбирается ревьювером; флаг is_active при этом не меняется. Период, пересекающийся с другим отсутствием пользователя, отклоняется с 409 ABSENCE_OVERLAP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Добавить отсутствие",
+                "parameters": [
+                    {
+                        "description": "Период отсутствия",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddAbsenceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/AbsenceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/absences/delete": {
+            "post": {
+                "description": "Удаляет период отсутствия по идентификатору.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Удалить отсутствие",
+                "parameters": [
+                    {
+                        "description": "Идентификатор отсутствия",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DeleteAbsenceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/bulkDeactivate": {
+            "post": {
+                "description": "Деактивирует переданный список user_id внутри команды и безопасно переназначает их в открытых PR (если найдены кандидаты). С dry_run только возвращает план (снятые ревьюверы, замены или причины пропуска) и plan_token, ничего не записывая. С plan_token выполняет ровно этот план, а если данные с тех пор изменились, отвечает 409 PLAN_STALE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Массовая деактивация пользователей команды",
+                "parameters": [
+                    {
+                        "description": "Команда и user_id",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/BulkDeactivateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/BulkDeactivateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/delegations": {
+            "get": {
+                "description": "Возвращает все делегирования ревью пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Делегирования пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DelegationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/delegations/add": {
+            "post": {
+                "description": "На период ревью пользователя сначала предлагаются делегату из той же команды: при автоматическом выборе, переназначении и массовой деактивации. Если делегат недоступен, выбор идёт как обычно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Делегировать ревью",
+                "parameters": [
+                    {
+                        "description": "Делегирование",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddDelegationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/DelegationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/delegations/delete": {
+            "post": {
+                "description": "Удаляет делегирование по идентификатору. Уже назначенные делегатам ревью не меняются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Availability"
+                ],
+                "summary": "Удалить делегирование",
+                "parameters": [
+                    {
+                        "description": "Идентификатор делегирования",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DeleteDelegationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/getReview": {
+            "get": {
+                "description": "Возвращает PR, где пользователь выступает ревьювером. С as_of — PR, где он был ревьювером в указанный момент, со статусом на тот момент.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Получить PR пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени (RFC3339): вернуть PR, где пользователь был ревьювером тогда",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserReviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/setIsActive": {
+            "post": {
+                "description": "Ставит или снимает флаг активности пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Обновить активность пользователя",
+                "parameters": [
+                    {
+                        "description": "Параметры активности",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UserRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/setWorkingHours": {
+            "post": {
+                "description": "Задаёт часовой пояс и рабочее окно (пн–пт) пользователя. Используется режимом выбора working_hours и расчётом SLA.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Обновить рабочее время пользователя",
+                "parameters": [
+                    {
+                        "description": "Часовой пояс и рабочее окно",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/WorkingHoursRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthcheck": {
+            "get": {
+                "description": "Returns service health status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "Absence": {
+            "description": "Период отсутствия пользователя.",
+            "type": "object",
+            "required": [
+                "absence_id",
+                "ends_at",
+                "starts_at",
+                "user_id"
+            ],
+            "properties": {
+                "absence_id": {
+                    "description": "Идентификатор отсутствия.",
+                    "type": "integer",
+                    "example": 7
+                },
+                "ends_at": {
+                    "description": "Конец отсутствия.",
+                    "type": "string",
+                    "example": "2025-11-15T00:00:00Z"
+                },
+                "reason": {
+                    "description": "Причина отсутствия.",
+                    "type": "string",
+                    "example": "vacation"
+                },
+                "starts_at": {
+                    "description": "Начало отсутствия.",
+                    "type": "string",
+                    "example": "2025-11-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "AbsenceListResponse": {
+            "description": "Отсутствия пользователя.",
+            "type": "object",
+            "required": [
+                "absences",
+                "user_id"
+            ],
+            "properties": {
+                "absences": {
+                    "description": "Периоды отсутствия по возрастанию начала.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Absence"
+                    }
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "AbsenceResponse": {
+            "description": "Ответ с периодом отсутствия.",
+            "type": "object",
+            "required": [
+                "absence"
+            ],
+            "properties": {
+                "absence": {
+                    "description": "Период отсутствия.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Absence"
+                        }
+                    ]
+                }
+            }
+        },
+        "AddAbsenceRequest": {
+            "description": "Запрос на добавление периода отсутствия пользователя.",
+            "type": "object",
+            "required": [
+                "ends_at",
+                "starts_at",
+                "user_id"
+            ],
+            "properties": {
+                "ends_at": {
+                    "description": "Конец отсутствия (RFC3339, не включительно).",
+                    "type": "string",
+                    "example": "2025-11-15T00:00:00Z"
+                },
+                "reason": {
+                    "description": "Причина отсутствия.",
+                    "type": "string",
+                    "example": "vacation"
+                },
+                "starts_at": {
+                    "description": "Начало отсутствия (RFC3339, включительно).",
+                    "type": "string",
+                    "example": "2025-11-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "AddDelegationRequest": {
+            "description": "Запрос на делегирование ревью пользователя на период.",
+            "type": "object",
+            "required": [
+                "delegate_id",
+                "ends_at",
+                "starts_at",
+                "user_id"
+            ],
+            "properties": {
+                "delegate_id": {
+                    "description": "Делегат из той же команды.",
+                    "type": "string",
+                    "example": "u4"
+                },
+                "ends_at": {
+                    "description": "Конец делегирования (RFC3339, не включительно).",
+                    "type": "string",
+                    "example": "2025-11-15T00:00:00Z"
+                },
+                "starts_at": {
+                    "description": "Начало делегирования (RFC3339, включительно).",
+                    "type": "string",
+                    "example": "2025-11-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "Пользователь, который делегирует свои ревью.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "AddReviewerRequest": {
+            "description": "Запрос на ручное добавление ревьювера.",
+            "type": "object",
+            "required": [
+                "pull_request_id",
+                "user_id"
+            ],
+            "properties": {
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "user_id": {
+                    "description": "user_id добавляемого ревьювера.",
+                    "type": "string",
+                    "example": "u3"
+                }
+            }
+        },
+        "AddReviewerResponse": {
+            "description": "Ответ на ручное добавление ревьювера.",
+            "type": "object",
+            "required": [
+                "pr"
+            ],
+            "properties": {
+                "pr": {
+                    "description": "PR после добавления ревьювера.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "AddTeamHolidayRequest": {
+            "description": "Запрос на добавление праздника команды.",
+            "type": "object",
+            "required": [
+                "ends_at",
+                "starts_at",
+                "team_name"
+            ],
+            "properties": {
+                "ends_at": {
+                    "description": "Конец праздника (RFC3339, не включительно).",
+                    "type": "string",
+                    "example": "2026-01-09T00:00:00Z"
+                },
+                "name": {
+                    "description": "Название праздника.",
+                    "type": "string",
+                    "example": "New Year"
+                },
+                "starts_at": {
+                    "description": "Начало праздника (RFC3339, включительно).",
+                    "type": "string",
+                    "example": "2025-12-31T00:00:00Z"
+                },
+                "team_name": {
+                    "description": "Имя команды.",
+                    "type": "string",
+                    "example": "backend"
+                }
+            }
+        },
+        "AssignmentByPR": {
+            "description": "Количество назначений по PR.",
+            "type": "object",
+            "properties": {
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "pull_request_name": {
+                    "description": "Название PR.",
+                    "type": "string",
+                    "example": "Add search endpoint"
+                },
+                "reviewer_count": {
+                    "description": "Число назначенных ревьюверов для PR.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "AssignmentByPRResponse": {
+            "description": "Ответ со списком назначений по PR.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AssignmentByPR"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Курсор следующей страницы; отсутствует на последней странице.",
+                    "type": "string",
+                    "example": "NTA"
+                }
+            }
+        },
+        "AssignmentByUser": {
+            "description": "Количество назначений по пользователям.",
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "description": "Сколько раз этот пользователь назначался ревьювером.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "user_id": {
+                    "description": "user_id ревьювера.",
+                    "type": "string",
+                    "example": "u1"
+                },
+                "username": {
+                    "description": "username ревьювера.",
+                    "type": "string",
+                    "example": "Alice"
+                }
+            }
+        },
+        "AssignmentByUserResponse": {
+            "description": "Ответ со списком назначений по пользователям.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AssignmentByUser"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Курсор следующей страницы; отсутствует на последней странице.",
+                    "type": "string",
+                    "example": "NTA"
+                }
+            }
+        },
+        "AuditEvent": {
+            "description": "Событие журнала назначений.",
+            "type": "object",
+            "required": [
+                "actor",
+                "event_id",
+                "occurred_at",
+                "reason",
+                "type"
+            ],
+            "properties": {
+                "actor": {
+                    "description": "Кто инициировал изменение (заголовок X-Actor) или system для фоновых задач.",
+                    "type": "string",
+                    "example": "u1"
+                },
+                "details": {
+                    "description": "Дополнительные сведения.",
+                    "type": "string",
+                    "example": "on behalf of u2"
+                },
+                "event_id": {
+                    "description": "Идентификатор события.",
+                    "type": "integer",
+                    "example": 15
+                },
+                "occurred_at": {
+                    "description": "Время события.",
+                    "type": "string",
+                    "example": "2025-10-24T12:34:56Z"
+                },
+                "previous_user_id": {
+                    "description": "user_id заменённого ревьювера.",
+                    "type": "string",
+                    "example": "u2"
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "reason": {
+                    "description": "Код причины.",
+                    "type": "string",
+                    "example": "reassign"
+                },
+                "team_name": {
+                    "description": "Команда, в которой произошло событие.",
+                    "type": "string",
+                    "example": "backend"
+                },
+                "type": {
+                    "description": "Тип события: assigned, unassigned, replaced, merged, reviewed, activity_changed,\nabsence_added, absence_deleted, holiday_added, delegation_added, delegation_deleted.",
+                    "type": "string",
+                    "example": "replaced"
+                },
+                "user_id": {
+                    "description": "user_id затронутого пользователя (для replaced — новый ревьювер).",
+                    "type": "string",
+                    "example": "u3"
+                }
+            }
+        },
+        "AuditResponse": {
+            "description": "События журнала назначений от новых к старым.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AuditEvent"
+                    }
+                }
+            }
+        },
+        "BulkDeactivateJobRequest": {
+            "description": "Запрос на фоновую массовую деактивацию пользователей команды.",
+            "type": "object",
+            "required": [
+                "team_name",
+                "user_ids"
+            ],
+            "properties": {
+                "team_name": {
+                    "description": "Имя команды, в которой отключаются пользователи.",
+                    "type": "string",
+                    "example": "backend"
+                },
+                "user_ids": {
+                    "description": "user_id пользователей для деактивации; каждый становится отдельным элементом задачи.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "u1",
+                        "u2",
+                        "u3"
+                    ]
+                }
+            }
+        },
+        "BulkDeactivatePlanItem": {
+            "description": "Снятие ревьювера в плане массовой деактивации.",
+            "type": "object",
+            "properties": {
+                "delegated": {
+                    "description": "Замена выбрана как делегат снимаемого ревьювера.",
+                    "type": "boolean"
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "removed_user_id": {
+                    "description": "Снимаемый ревьювер.",
+                    "type": "string",
+                    "example": "u2"
+                },
+                "replacement_user_id": {
+                    "description": "Предлагаемая замена; пусто, если замену не нашли.",
+                    "type": "string",
+                    "example": "u5"
+                },
+                "skip_reason": {
+                    "description": "Почему замены нет.",
+                    "type": "string",
+                    "example": "no replacement candidate"
+                }
+            }
+        },
+        "BulkDeactivateRequest": {
+            "description": "Запрос на массовую деактивацию пользователей команды.",
+            "type": "object",
+            "required": [
+                "team_name",
+                "user_ids"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "Только рассчитать план и вернуть его вместе с plan_token, ничего не записывая.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "plan_token": {
+                    "description": "Токен плана из ответа dry_run: выполнить ровно этот план, если данные не изменились.",
+                    "type": "string",
+                    "example": "1f3a9c.5d41402abc4b2a76b9719d911017c592"
+                },
+                "team_name": {
+                    "description": "Имя команды, в которой отключаются пользователи.",
+                    "type": "string",
+                    "example": "backend"
+                },
+                "user_ids": {
+                    "description": "user_id пользователей для деактивации.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "u1",
+                        "u2",
+                        "u3"
+                    ]
+                }
+            }
+        },
+        "BulkDeactivateResponse": {
+            "description": "Ответ на массовую деактивацию.",
+            "type": "object",
+            "properties": {
+                "affected_prs": {
+                    "description": "Сколько открытых PR затронуто.",
+                    "type": "integer"
+                },
+                "deactivated": {
+                    "description": "Сколько пользователей деактивировано.",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "План только рассчитан, изменения не записаны.",
+                    "type": "boolean"
+                },
+                "plan": {
+                    "description": "Снятые ревьюверы по PR с заменой или причиной пропуска.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BulkDeactivatePlanItem"
+                    }
+                },
+                "plan_token": {
+                    "description": "Токен для выполнения этого плана; возвращается только при dry_run.",
+                    "type": "string"
+                },
+                "policy_violations": {
+                    "description": "Сколько PR остались без соблюдения правил состава команды (senior/junior).",
+                    "type": "integer"
+                },
+                "reassigned": {
+                    "description": "Сколько замен ревьюверов выполнено.",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Сколько замен пропущено из-за отсутствия кандидатов.",
+                    "type": "integer"
+                },
+                "team": {
+                    "description": "Имя команды.",
+                    "type": "string"
+                }
+            }
+        },
+        "ConsistencyCheckRequest": {
+            "description": "Запрос на проверку согласованности назначений.",
+            "type": "object",
+            "properties": {
+                "apply": {
+                    "description": "true — исправить найденные нарушения, false — только отчёт (dry-run).",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "ConsistencyFinding": {
+            "description": "Нарушение инварианта назначений в открытом PR.",
+            "type": "object",
+            "required": [
+                "kind",
+                "pull_request_id",
+                "repair"
+            ],
+            "properties": {
+                "error": {
+                    "description": "Почему исправление не удалось или выполнено частично.",
+                    "type": "string",
+                    "example": "pull request already merged"
+                },
+                "kind": {
+                    "description": "Вид нарушения: inactive_reviewer, author_reviewer, duplicate_reviewer, over_quota, wrong_team, deleted_author.\nИсправление выбирается по нему; приоритет: author_reviewer, wrong_team, inactive_reviewer.",
+                    "type": "string",
+                    "example": "inactive_reviewer"
+                },
+                "other_kinds": {
+                    "description": "Остальные нарушения того же ревьювера; исправление по kind закрывает и их.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "duplicate_reviewer"
+                    ]
+                },
+                "outcome": {
+                    "description": "Результат исправления при apply: reassigned, removed, readded, skipped, failed.",
+                    "type": "string",
+                    "example": "reassigned"
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "repair": {
+                    "description": "Исправление: reassign, remove, readd или none, если исправить автоматически нельзя.",
+                    "type": "string",
+                    "example": "reassign"
+                },
+                "replaced_by": {
+                    "description": "user_id назначенной замены.",
+                    "type": "string",
+                    "example": "u5"
+                },
+                "user_id": {
+                    "description": "user_id ревьювера, которого касается нарушение.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "ConsistencyReportResponse": {
+            "description": "Отчёт проверки согласованности назначений.",
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Выполнялись ли исправления.",
+                    "type": "boolean"
+                },
+                "findings": {
+                    "description": "Найденные нарушения; пустой список — данные согласованы.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ConsistencyFinding"
+                    }
+                }
+            }
+        },
+        "CreatePRRequest": {
+            "description": "Запрос на создание PR.",
+            "type": "object",
+            "required": [
+                "author_id",
+                "pull_request_id",
+                "pull_request_name"
+            ],
+            "properties": {
+                "author_id": {
+                    "description": "Автор PR.",
+                    "type": "string",
+                    "example": "u1"
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "pull_request_name": {
+                    "description": "Название PR.",
+                    "type": "string",
+                    "example": "Add search endpoint"
+                }
+            }
+        },
+        "CreatePRResponse": {
+            "description": "Ответ на создание PR.",
+            "type": "object",
+            "required": [
+                "pr"
+            ],
+            "properties": {
+                "pr": {
+                    "description": "Созданный PR.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "CreateTeamRequest": {
+            "description": "Запрос на создание команды.",
+            "type": "object",
+            "required": [
+                "members",
+                "team_name"
+            ],
+            "properties": {
+                "assignment_mode": {
+                    "description": "Режим выбора ревьюверов: random (по умолчанию) или working_hours — сначала те, у кого сейчас рабочее время.",
+                    "type": "string",
+                    "enum": [
+                        "random",
+                        "working_hours"
+                    ],
+                    "example": "working_hours"
+                },
+                "forbid_sole_junior": {
+                    "description": "Запретить junior быть единственным ревьювером PR.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "max_reviewers": {
+                    "description": "Максимум ревьюверов на PR (по умолчанию 2).",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                },
+                "members": {
+                    "description": "Массив участников команды.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/TeamMember"
+                    }
+                },
+                "require_senior": {
+                    "description": "Требовать хотя бы одного senior или lead среди ревьюверов PR.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "review_sla_hours": {
+                    "description": "SLA на ревью в рабочих часах ревьювера; 0 — не отслеживается.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 8
+                },
+                "team_name": {
+                    "description": "Имя команды.",
+                    "type": "string",
+                    "example": "backend"
+                }
+            }
+        },
+        "DeclineRequest": {
+            "description": "Отказ назначенного ревьювера от ревью.",
+            "type": "object",
+            "required": [
+                "pull_request_id",
+                "user_id"
+            ],
+            "properties": {
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "user_id": {
+                    "description": "user_id отказавшегося ревьювера.",
+                    "type": "string",
+                    "example": "u3"
+                }
+            }
+        },
+        "DeclineResponse": {
+            "description": "Ответ на отказ от ревью.",
+            "type": "object",
+            "required": [
+                "pr"
+            ],
+            "properties": {
+                "pr": {
+                    "description": "Текущий PR.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                },
+                "replaced_by": {
+                    "description": "user_id замены; пусто, если подходящего кандидата не нашлось и ревьювер просто снят.",
+                    "type": "string",
+                    "example": "u5"
+                }
+            }
+        },
+        "DelegatedReviewer": {
+            "description": "Ревьювер, назначенный вместо участника, который делегировал свои ревью.",
+            "type": "object",
+            "required": [
+                "on_behalf_of",
+                "reviewer_id"
+            ],
+            "properties": {
+                "on_behalf_of": {
+                    "description": "Участник, за которого выполняется ревью.",
+                    "type": "string",
+                    "example": "u2"
+                },
+                "reviewer_id": {
+                    "description": "Назначенный ревьювер (делегат).",
+                    "type": "string",
+                    "example": "u4"
+                }
+            }
+        },
+        "Delegation": {
+            "description": "Делегирование ревью пользователя.",
+            "type": "object",
+            "required": [
+                "delegate_id",
+                "delegation_id",
+                "ends_at",
+                "starts_at",
+                "user_id"
+            ],
+            "properties": {
+                "delegate_id": {
+                    "description": "Делегат.",
+                    "type": "string",
+                    "example": "u4"
+                },
+                "delegation_id": {
+                    "description": "Идентификатор делегирования.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "ends_at": {
+                    "description": "Конец делегирования.",
+                    "type": "string",
+                    "example": "2025-11-15T00:00:00Z"
+                },
+                "starts_at": {
+                    "description": "Начало делегирования.",
+                    "type": "string",
+                    "example": "2025-11-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "Пользователь, который делегирует свои ревью.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "DelegationListResponse": {
+            "description": "Делегирования пользователя.",
+            "type": "object",
+            "required": [
+                "delegations",
+                "user_id"
+            ],
+            "properties": {
+                "delegations": {
+                    "description": "Делегирования по возрастанию начала.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Delegation"
+                    }
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "DelegationResponse": {
+            "description": "Ответ с делегированием.",
+            "type": "object",
+            "required": [
+                "delegation"
+            ],
+            "properties": {
+                "delegation": {
+                    "description": "Делегирование.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Delegation"
+                        }
+                    ]
+                }
+            }
+        },
+        "DeleteAbsenceRequest": {
+            "description": "Запрос на удаление периода отсутствия.",
+            "type": "object",
+            "required": [
+                "absence_id"
+            ],
+            "properties": {
+                "absence_id": {
+                    "description": "Идентификатор отсутствия.",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "DeleteDelegationRequest": {
+            "description": "Запрос на удаление делегирования.",
+            "type": "object",
+            "required": [
+                "delegation_id"
+            ],
+            "properties": {
+                "delegation_id": {
+                    "description": "Идентификатор делегирования.",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "DurationBucket": {
+            "description": "Метрики длительности команды или пользователя за неделю.",
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "Имя команды или user_id.",
+                    "type": "string",
+                    "example": "backend"
+                },
+                "reassignment_latency": {
+                    "description": "От назначения ревьювера до его замены.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DurationSummary"
+                        }
+                    ]
+                },
+                "time_to_first_review": {
+                    "description": "Для команды — от создания PR до первого ревью-действия; для пользователя — от его назначения до его ревью-действия.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DurationSummary"
+                        }
+                    ]
+                },
+                "time_to_merge": {
+                    "description": "От создания PR до merge.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DurationSummary"
+                        }
+                    ]
+                },
+                "week_start": {
+                    "description": "Понедельник недели (UTC).",
+                    "type": "string",
+                    "example": "2025-10-20"
                 }
             }
         },
-        "AssignmentByPRResponse": {
-            "description": "Ответ со списком назначений по PR.",
+        "DurationSummary": {
+            "description": "Сводка по длительностям в часах.",
             "type": "object",
             "properties": {
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/AssignmentByPR"
-                    }
+                "avg_hours": {
+                    "description": "Среднее, часы.",
+                    "type": "number",
+                    "example": 20.5
+                },
+                "count": {
+                    "description": "Количество наблюдений.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "max_hours": {
+                    "description": "Максимум, часы.",
+                    "type": "number",
+                    "example": 40.25
+                },
+                "median_hours": {
+                    "description": "Медиана, часы.",
+                    "type": "number",
+                    "example": 18
                 }
             }
         },
-        "AssignmentByUser": {
-            "description": "Количество назначений по пользователям.",
+        "ErrorBody": {
+            "description": "Содержит код и сообщение ошибки.",
             "type": "object",
+            "required": [
+                "code",
+                "message"
+            ],
             "properties": {
-                "assignments": {
-                    "description": "Сколько раз этот пользователь назначался ревьювером.",
-                    "type": "integer",
-                    "example": 3
-                },
-                "user_id": {
-                    "description": "user_id ревьювера.",
+                "code": {
+                    "description": "Код ошибки.",
                     "type": "string",
-                    "example": "u1"
+                    "example": "NOT_FOUND"
                 },
-                "username": {
-                    "description": "username ревьювера.",
+                "message": {
+                    "description": "Сообщение ошибки.",
                     "type": "string",
-                    "example": "Alice"
+                    "example": "resource not found"
                 }
             }
         },
-        "AssignmentByUserResponse": {
-            "description": "Ответ со списком назначений по пользователям.",
+        "ErrorResponse": {
+            "description": "Стандартный ответ с ошибкой.",
             "type": "object",
+            "required": [
+                "error"
+            ],
             "properties": {
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/AssignmentByUser"
-                    }
+                "error": {
+                    "description": "Детали ошибки.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ErrorBody"
+                        }
+                    ]
                 }
             }
         },
-        "BulkDeactivateRequest": {
-            "description": "Запрос на массовую деактивацию пользователей команды.",
+        "FairnessResponse": {
+            "description": "Отчёт о равномерности нагрузки в команде.",
             "type": "object",
-            "required": [
-                "team_name",
-                "user_ids"
-            ],
             "properties": {
-                "team_name": {
-                    "description": "Имя команды, в которой отключаются пользователи.",
-                    "type": "string",
-                    "example": "backend"
+                "gini_open": {
+                    "description": "Коэффициент Джини по открытым назначениям.",
+                    "type": "number",
+                    "example": 0.3
                 },
-                "user_ids": {
-                    "description": "user_id пользователей для деактивации.",
+                "gini_total": {
+                    "description": "Коэффициент Джини по всем назначениям (0 — поровну, ближе к 1 — неравномерно).",
+                    "type": "number",
+                    "example": 0.12
+                },
+                "mean_open": {
+                    "description": "Среднее число открытых назначений на активного участника.",
+                    "type": "number",
+                    "example": 1.75
+                },
+                "mean_total": {
+                    "description": "Среднее число назначений за историю на активного участника.",
+                    "type": "number",
+                    "example": 15.5
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/MemberFairness"
+                    }
+                },
+                "never_assigned": {
+                    "description": "Активные участники, ни разу не назначавшиеся ревьюверами.",
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "u1",
-                        "u2",
-                        "u3"
+                        "u5"
                     ]
+                },
+                "team_name": {
+                    "description": "Имя команды.",
+                    "type": "string",
+                    "example": "backend"
                 }
             }
         },
-        "BulkDeactivateResponse": {
-            "description": "Ответ на массовую деактивацию.",
+        "GetPRResponse": {
+            "description": "PR, в том числе в состоянии на момент as_of.",
             "type": "object",
+            "required": [
+                "pr"
+            ],
             "properties": {
-                "affected_prs": {
-                    "description": "Сколько открытых PR затронуто.",
-                    "type": "integer"
+                "pr": {
+                    "description": "PR с ревьюверами.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "Job": {
+            "description": "Фоновая задача с прогрессом и результатами элементов.",
+            "type": "object",
+            "properties": {
+                "cancel_requested": {
+                    "description": "Запрошена отмена; выполняемая задача остановится перед следующим элементом.",
+                    "type": "boolean"
                 },
-                "deactivated": {
-                    "description": "Сколько пользователей деактивировано.",
-                    "type": "integer"
+                "created_at": {
+                    "description": "Когда задача поставлена в очередь.",
+                    "type": "string"
                 },
-                "reassigned": {
-                    "description": "Сколько замен ревьюверов выполнено.",
-                    "type": "integer"
+                "finished_at": {
+                    "description": "Когда задача завершилась или была отменена.",
+                    "type": "string"
                 },
-                "skipped": {
-                    "description": "Сколько замен пропущено из-за отсутствия кандидатов.",
-                    "type": "integer"
+                "items": {
+                    "description": "Элементы задачи в порядке выполнения.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/JobItem"
+                    }
                 },
-                "team": {
-                    "description": "Имя команды.",
+                "job_id": {
+                    "description": "Идентификатор задачи.",
+                    "type": "integer",
+                    "example": 42
+                },
+                "kind": {
+                    "description": "Вид операции.",
+                    "type": "string",
+                    "example": "bulk_deactivate"
+                },
+                "processed": {
+                    "description": "Сколько элементов обработано.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "started_at": {
+                    "description": "Когда задачу впервые взял обработчик.",
                     "type": "string"
+                },
+                "status": {
+                    "description": "Статус: queued, running, completed, canceled или failed.",
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "description": "Сколько элементов всего.",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "CreatePRRequest": {
-            "description": "Запрос на создание PR.",
+        "JobItem": {
+            "description": "Элемент фоновой задачи и его результат.",
             "type": "object",
-            "required": [
-                "author_id",
-                "pull_request_id",
-                "pull_request_name"
-            ],
             "properties": {
-                "author_id": {
-                    "description": "Автор PR.",
+                "error": {
+                    "description": "Ошибка, если элемент не выполнен.",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "Когда элемент завершён.",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Над чем выполняется элемент, для bulk_deactivate — user_id.",
                     "type": "string",
                     "example": "u1"
                 },
-                "pull_request_id": {
-                    "description": "Идентификатор PR.",
-                    "type": "string",
-                    "example": "pr-1001"
+                "result": {
+                    "description": "Итог элемента; для bulk_deactivate — счётчики и снятые ревьюверы с заменами.",
+                    "type": "object"
                 },
-                "pull_request_name": {
-                    "description": "Название PR.",
+                "status": {
+                    "description": "Статус: pending, succeeded, failed или canceled.",
                     "type": "string",
-                    "example": "Add search endpoint"
+                    "example": "succeeded"
                 }
             }
         },
-        "CreatePRResponse": {
-            "description": "Ответ на создание PR.",
+        "JobResponse": {
+            "description": "Ответ с фоновой задачей.",
             "type": "object",
-            "required": [
-                "pr"
-            ],
             "properties": {
-                "pr": {
-                    "description": "Созданный PR.",
+                "job": {
+                    "description": "Задача.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/PullRequest"
+                            "$ref": "#/definitions/Job"
                         }
                     ]
                 }
             }
         },
-        "CreateTeamRequest": {
-            "description": "Запрос на создание команды.",
+        "MaxReviewersRequest": {
+            "description": "Запрос на смену лимита ревьюверов команды.",
             "type": "object",
             "required": [
-                "members",
+                "max_reviewers",
                 "team_name"
             ],
             "properties": {
-                "members": {
-                    "description": "Массив участников команды.",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/TeamMember"
-                    }
+                "max_reviewers": {
+                    "description": "Максимум ревьюверов на PR.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
                 },
                 "team_name": {
                     "description": "Имя команды.",
@@ -698,40 +3624,39 @@ const docTemplate = `{
                 }
             }
         },
-        "ErrorBody": {
-            "description": "Содержит код и сообщение ошибки.",
+        "MemberFairness": {
+            "description": "Нагрузка участника команды.",
             "type": "object",
-            "required": [
-                "code",
-                "message"
-            ],
             "properties": {
-                "code": {
-                    "description": "Код ошибки.",
+                "deviation": {
+                    "description": "Отклонение total_assignments от среднего по активным участникам.",
+                    "type": "number",
+                    "example": -1.5
+                },
+                "is_active": {
+                    "description": "Активен ли участник.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "open_assignments": {
+                    "description": "Текущие назначения на открытые PR.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_assignments": {
+                    "description": "Все назначения за историю, включая замены.",
+                    "type": "integer",
+                    "example": 14
+                },
+                "user_id": {
+                    "description": "user_id участника.",
                     "type": "string",
-                    "example": "NOT_FOUND"
+                    "example": "u2"
                 },
-                "message": {
-                    "description": "Сообщение ошибки.",
+                "username": {
+                    "description": "username участника.",
                     "type": "string",
-                    "example": "resource not found"
-                }
-            }
-        },
-        "ErrorResponse": {
-            "description": "Стандартный ответ с ошибкой.",
-            "type": "object",
-            "required": [
-                "error"
-            ],
-            "properties": {
-                "error": {
-                    "description": "Детали ошибки.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/ErrorBody"
-                        }
-                    ]
+                    "example": "Bob"
                 }
             }
         },
@@ -756,13 +3681,61 @@ const docTemplate = `{
                 "pr"
             ],
             "properties": {
-                "pr": {
-                    "description": "PR после merge.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/PullRequest"
-                        }
-                    ]
+                "pr": {
+                    "description": "PR после merge.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "PRChurn": {
+            "description": "Смена ревьюверов в PR.",
+            "type": "object",
+            "properties": {
+                "distinct_reviewers": {
+                    "description": "Сколько разных пользователей было ревьюверами PR за историю.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "pull_request_name": {
+                    "description": "Название PR.",
+                    "type": "string",
+                    "example": "Add search endpoint"
+                },
+                "removals": {
+                    "description": "Сколько раз ревьювера снимали с PR.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "description": "Статус PR.",
+                    "type": "string",
+                    "example": "OPEN"
+                }
+            }
+        },
+        "PRChurnResponse": {
+            "description": "Ответ со списком PR, с которых снимали ревьюверов.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/PRChurn"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Курсор следующей страницы; отсутствует на последней странице.",
+                    "type": "string",
+                    "example": "NTA"
                 }
             }
         },
@@ -778,7 +3751,7 @@ const docTemplate = `{
             ],
             "properties": {
                 "assigned_reviewers": {
-                    "description": "Назначенные ревьюверы (до двух user_id).",
+                    "description": "Назначенные ревьюверы (не больше лимита команды автора).",
                     "type": "array",
                     "items": {
                         "type": "string"
@@ -788,6 +3761,16 @@ const docTemplate = `{
                         "u3"
                     ]
                 },
+                "assignment_warnings": {
+                    "description": "Почему набор ревьюверов не удовлетворяет правилам команды (если так вышло).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "no senior reviewer available: team requires at least one senior or lead"
+                    ]
+                },
                 "author_id": {
                     "description": "Автор PR.",
                     "type": "string",
@@ -798,6 +3781,13 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-10-25T12:00:00Z"
                 },
+                "delegated_reviewers": {
+                    "description": "Ревьюверы, назначенные по делегированию вместо другого участника.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DelegatedReviewer"
+                    }
+                },
                 "merged_at": {
                     "description": "Время merge (если есть).",
                     "type": "string",
@@ -860,6 +3850,11 @@ const docTemplate = `{
                 "pull_request_id"
             ],
             "properties": {
+                "new_user_id": {
+                    "description": "user_id конкретной замены; если не задан, замена выбирается случайно.",
+                    "type": "string",
+                    "example": "u5"
+                },
                 "old_user_id": {
                     "description": "user_id ревьювера, которого заменяем.",
                     "type": "string",
@@ -895,6 +3890,160 @@ const docTemplate = `{
                 }
             }
         },
+        "RemoveReviewerRequest": {
+            "description": "Запрос на снятие ревьювера без замены.",
+            "type": "object",
+            "required": [
+                "pull_request_id",
+                "user_id"
+            ],
+            "properties": {
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "user_id": {
+                    "description": "user_id снимаемого ревьювера.",
+                    "type": "string",
+                    "example": "u3"
+                }
+            }
+        },
+        "RemoveReviewerResponse": {
+            "description": "Ответ на снятие ревьювера.",
+            "type": "object",
+            "required": [
+                "pr"
+            ],
+            "properties": {
+                "pr": {
+                    "description": "PR после снятия ревьювера.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "ReviewDurationsResponse": {
+            "description": "Метрики длительности по неделям.",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "Начало периода.",
+                    "type": "string",
+                    "example": "2025-08-01T00:00:00Z"
+                },
+                "group_by": {
+                    "description": "Разрез: team или user.",
+                    "type": "string",
+                    "example": "team"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DurationBucket"
+                    }
+                },
+                "to": {
+                    "description": "Конец периода.",
+                    "type": "string",
+                    "example": "2025-10-24T00:00:00Z"
+                }
+            }
+        },
+        "ReviewSLAItem": {
+            "description": "Ожидание ревью в рабочих часах ревьювера.",
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "description": "Когда ревьювер назначен.",
+                    "type": "string",
+                    "example": "2025-10-24T16:00:00Z"
+                },
+                "breached": {
+                    "description": "SLA нарушен.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "pull_request_name": {
+                    "description": "Название PR.",
+                    "type": "string",
+                    "example": "Add search endpoint"
+                },
+                "reviewer_id": {
+                    "description": "user_id ревьювера.",
+                    "type": "string",
+                    "example": "u2"
+                },
+                "sla_hours": {
+                    "description": "SLA команды автора в рабочих часах; 0 — не отслеживается.",
+                    "type": "integer",
+                    "example": 8
+                },
+                "working_hours": {
+                    "description": "Сколько рабочих часов ревьювера прошло с назначения.",
+                    "type": "number",
+                    "example": 5.5
+                }
+            }
+        },
+        "ReviewSLAResponse": {
+            "description": "Ожидание ревью по открытым PR.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ReviewSLAItem"
+                    }
+                }
+            }
+        },
+        "SubmitReviewRequest": {
+            "description": "Ревью-действие назначенного ревьювера (одобрение, комментарий и т.п.).",
+            "type": "object",
+            "required": [
+                "pull_request_id",
+                "user_id"
+            ],
+            "properties": {
+                "pull_request_id": {
+                    "description": "Идентификатор PR.",
+                    "type": "string",
+                    "example": "pr-1001"
+                },
+                "user_id": {
+                    "description": "user_id ревьювера.",
+                    "type": "string",
+                    "example": "u3"
+                }
+            }
+        },
+        "SubmitReviewResponse": {
+            "description": "Ответ на ревью-действие.",
+            "type": "object",
+            "required": [
+                "pr"
+            ],
+            "properties": {
+                "pr": {
+                    "description": "Текущий PR.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PullRequest"
+                        }
+                    ]
+                }
+            }
+        },
         "Team": {
             "description": "Команда с участниками.",
             "type": "object",
@@ -903,6 +4052,21 @@ const docTemplate = `{
                 "team_name"
             ],
             "properties": {
+                "assignment_mode": {
+                    "description": "Режим выбора ревьюверов.",
+                    "type": "string",
+                    "example": "random"
+                },
+                "forbid_sole_junior": {
+                    "description": "Запрещено ли junior быть единственным ревьювером.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "max_reviewers": {
+                    "description": "Максимум ревьюверов на PR.",
+                    "type": "integer",
+                    "example": 2
+                },
                 "members": {
                     "description": "Участники команды.",
                     "type": "array",
@@ -910,6 +4074,75 @@ const docTemplate = `{
                         "$ref": "#/definitions/TeamMember"
                     }
                 },
+                "require_senior": {
+                    "description": "Требуется ли хотя бы один senior или lead среди ревьюверов.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "review_sla_hours": {
+                    "description": "SLA на ревью в рабочих часах ревьювера; 0 — не отслеживается.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "team_name": {
+                    "description": "Имя команды.",
+                    "type": "string",
+                    "example": "backend"
+                }
+            }
+        },
+        "TeamHoliday": {
+            "description": "Праздник команды.",
+            "type": "object",
+            "required": [
+                "ends_at",
+                "holiday_id",
+                "starts_at",
+                "team_name"
+            ],
+            "properties": {
+                "ends_at": {
+                    "description": "Конец праздника.",
+                    "type": "string",
+                    "example": "2026-01-09T00:00:00Z"
+                },
+                "holiday_id": {
+                    "description": "Идентификатор праздника.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "description": "Название праздника.",
+                    "type": "string",
+                    "example": "New Year"
+                },
+                "starts_at": {
+                    "description": "Начало праздника.",
+                    "type": "string",
+                    "example": "2025-12-31T00:00:00Z"
+                },
+                "team_name": {
+                    "description": "Имя команды.",
+                    "type": "string",
+                    "example": "backend"
+                }
+            }
+        },
+        "TeamHolidayListResponse": {
+            "description": "Календарь праздников команды.",
+            "type": "object",
+            "required": [
+                "holidays",
+                "team_name"
+            ],
+            "properties": {
+                "holidays": {
+                    "description": "Праздники по возрастанию начала.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/TeamHoliday"
+                    }
+                },
                 "team_name": {
                     "description": "Имя команды.",
                     "type": "string",
@@ -917,6 +4150,23 @@ const docTemplate = `{
                 }
             }
         },
+        "TeamHolidayResponse": {
+            "description": "Ответ с праздником команды.",
+            "type": "object",
+            "required": [
+                "holiday"
+            ],
+            "properties": {
+                "holiday": {
+                    "description": "Праздник команды.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/TeamHoliday"
+                        }
+                    ]
+                }
+            }
+        },
         "TeamMember": {
             "description": "Участник команды.",
             "type": "object",
@@ -931,6 +4181,22 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": true
                 },
+                "seniority": {
+                    "description": "Уровень: junior, middle, senior или lead (по умолчанию middle).",
+                    "type": "string",
+                    "enum": [
+                        "junior",
+                        "middle",
+                        "senior",
+                        "lead"
+                    ],
+                    "example": "senior"
+                },
+                "time_zone": {
+                    "description": "IANA-зона участника (по умолчанию UTC).",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
                 "user_id": {
                     "description": "user_id участника.",
                     "type": "string",
@@ -940,6 +4206,16 @@ const docTemplate = `{
                     "description": "username участника.",
                     "type": "string",
                     "example": "Alice"
+                },
+                "work_end": {
+                    "description": "Конец рабочего дня в локальном времени, HH:MM (по умолчанию 18:00).",
+                    "type": "string",
+                    "example": "19:00"
+                },
+                "work_start": {
+                    "description": "Начало рабочего дня в локальном времени, HH:MM (по умолчанию 09:00).",
+                    "type": "string",
+                    "example": "10:00"
                 }
             }
         },
@@ -975,11 +4251,21 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": true
                 },
+                "seniority": {
+                    "description": "Уровень пользователя.",
+                    "type": "string",
+                    "example": "middle"
+                },
                 "team_name": {
                     "description": "Название команды.",
                     "type": "string",
                     "example": "backend"
                 },
+                "time_zone": {
+                    "description": "Часовой пояс пользователя.",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
                 "user_id": {
                     "description": "Идентификатор пользователя.",
                     "type": "string",
@@ -989,6 +4275,52 @@ const docTemplate = `{
                     "description": "Имя пользователя.",
                     "type": "string",
                     "example": "Bob"
+                },
+                "work_end": {
+                    "description": "Конец рабочего дня в локальном времени.",
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "work_start": {
+                    "description": "Начало рабочего дня в локальном времени.",
+                    "type": "string",
+                    "example": "09:00"
+                }
+            }
+        },
+        "UserReplacements": {
+            "description": "Сколько раз ревьювера снимали с PR, всего и по причинам.",
+            "type": "object",
+            "properties": {
+                "by_reason": {
+                    "description": "Снятия по причинам: reassign, manual, bulk_deactivate, decline, sla_escalation, consistency_repair и т.д.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "total": {
+                    "description": "Всего снятий.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "user_id": {
+                    "description": "user_id снятого ревьювера.",
+                    "type": "string",
+                    "example": "u2"
+                }
+            }
+        },
+        "UserReplacementsResponse": {
+            "description": "Ответ со статистикой снятий ревьюверов по пользователям.",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/UserReplacements"
+                    }
                 }
             }
         },
@@ -1050,6 +4382,35 @@ const docTemplate = `{
                     "example": "u2"
                 }
             }
+        },
+        "WorkingHoursRequest": {
+            "description": "Запрос на смену часового пояса и рабочего окна пользователя.",
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "time_zone": {
+                    "description": "IANA-зона (пусто — UTC).",
+                    "type": "string",
+                    "example": "Asia/Novosibirsk"
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя.",
+                    "type": "string",
+                    "example": "u2"
+                },
+                "work_end": {
+                    "description": "Конец рабочего дня, HH:MM (пусто — 18:00).",
+                    "type": "string",
+                    "example": "17:00"
+                },
+                "work_start": {
+                    "description": "Начало рабочего дня, HH:MM (пусто — 09:00).",
+                    "type": "string",
+                    "example": "08:00"
+                }
+            }
         }
     }
 }`
@@ -2,21 +2,61 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Хранилища данных сервиса.
+const (
+	StoragePostgres = "postgres"
+	// StorageSQLite — файл SQLite для развёртывания на одном узле.
+	StorageSQLite = "sqlite"
+	// StorageMemory держит данные в памяти процесса: для демо и тестов без БД, данные теряются при остановке.
+	StorageMemory = "memory"
+)
+
 type Config struct {
-	Port   string
+	Port string
+	// Одно из Storage*.
+	Storage string
+
 	DBHost string
 	DBPort string
 	DBUser string
 	DBPass string
 	DBName string
+	// Файл БД для Storage=sqlite.
+	SQLitePath string
 
 	LogLevel string
+
+	// Дедлайн обработки одного HTTP-запроса; 0 — без ограничения.
+	RequestTimeout time.Duration
+	// Сколько хранится ответ на запрос с Idempotency-Key.
+	IdempotencyTTL time.Duration
+
+	// Период фоновой задачи переназначения ревью отсутствующих; 0 — задача выключена.
+	AbsenceJobInterval time.Duration
+	// Период фоновой задачи замены ревьюверов с нарушенным SLA; 0 — задача выключена.
+	SLAEscalationInterval time.Duration
+
+	// Сколько обработчиков выполняют фоновые задачи; 0 — задачи принимаются, но этот экземпляр их не выполняет.
+	JobWorkers int
+	// Как часто обработчики проверяют очередь задач, кроме сигнала о новой задаче этого экземпляра.
+	JobPollInterval time.Duration
+
+	// Куда отправлять трейсы: none, otlp, stdout или file.
+	TracingExporter string
+	// Адрес OTLP/HTTP-коллектора (host:port) для TracingExporter=otlp.
+	TracingOTLPEndpoint string
+	// Файл для TracingExporter=file.
+	TracingFile string
+	// Доля запросов, которые попадают в трейсы, от 0 до 1.
+	TracingSampleRatio float64
 }
 
 var (
@@ -26,13 +66,29 @@ var (
 
 func Load() *Config {
 	return &Config{
-		Port:     getEnv("APP_PORT", "8080"),
-		DBHost:   getEnv("DB_HOST", "localhost"),
-		DBPort:   getEnv("DB_PORT", "5432"),
-		DBUser:   getEnv("DB_USER", "app"),
-		DBPass:   getEnv("DB_PASS", "app"),
-		DBName:   getEnv("DB_NAME", "app"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
+		Port:       getEnv("APP_PORT", "8080"),
+		Storage:    getEnv("STORAGE", StoragePostgres),
+		DBHost:     getEnv("DB_HOST", "localhost"),
+		DBPort:     getEnv("DB_PORT", "5432"),
+		DBUser:     getEnv("DB_USER", "app"),
+		DBPass:     getEnv("DB_PASS", "app"),
+		DBName:     getEnv("DB_NAME", "app"),
+		SQLitePath: getEnv("SQLITE_PATH", "pr_service.db"),
+		LogLevel:   getEnv("LOG_LEVEL", "info"),
+
+		RequestTimeout: getDurationEnv("REQUEST_TIMEOUT", 10*time.Second),
+		IdempotencyTTL: getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		AbsenceJobInterval:    getDurationEnv("ABSENCE_JOB_INTERVAL", 0),
+		SLAEscalationInterval: getDurationEnv("SLA_ESCALATION_INTERVAL", 0),
+
+		JobWorkers:      getIntEnv("JOB_WORKERS", 2),
+		JobPollInterval: getDurationEnv("JOB_POLL_INTERVAL", 5*time.Second),
+
+		TracingExporter:     getEnv("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+		TracingFile:         getEnv("TRACING_FILE", "traces.json"),
+		TracingSampleRatio:  getFloatEnv("TRACING_SAMPLE_RATIO", 1),
 	}
 }
 
@@ -43,6 +99,42 @@ func getEnv(key, def string) string {
 	return def
 }
 
+func getIntEnv(key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func getFloatEnv(key string, def float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
 func InitLogger(level string) error {
 	var cfg zap.Config
 	lvl := strings.ToLower(level)
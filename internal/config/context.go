@@ -0,0 +1,36 @@
+package config
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет X-Request-ID запроса в контексте, чтобы он попадал в логи сервисов и репозиториев.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID возвращает X-Request-ID из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// LoggerFrom возвращает логгер с request_id и идентификаторами текущего трейса и спана из контекста.
+func LoggerFrom(ctx context.Context) *zap.SugaredLogger {
+	logger := Logger()
+	if ctx == nil {
+		return logger
+	}
+	if id := RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	return logger
+}
@@ -0,0 +1,33 @@
+package dto
+
+// @Description Событие журнала назначений.
+// swagger:model AuditEvent
+type AuditEvent struct {
+	// Идентификатор события.
+	EventID uint `json:"event_id" validate:"required" example:"15"`
+	// Тип события: assigned, unassigned, replaced, merged, reviewed, activity_changed,
+	// absence_added, absence_deleted, holiday_added, delegation_added, delegation_deleted.
+	Type string `json:"type" validate:"required" example:"replaced"`
+	// Код причины.
+	Reason string `json:"reason" validate:"required" example:"reassign"`
+	// Кто инициировал изменение (заголовок X-Actor) или system для фоновых задач.
+	Actor string `json:"actor" validate:"required" example:"u1"`
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id,omitempty" example:"pr-1001"`
+	// user_id затронутого пользователя (для replaced — новый ревьювер).
+	UserID string `json:"user_id,omitempty" example:"u3"`
+	// user_id заменённого ревьювера.
+	PreviousUserID string `json:"previous_user_id,omitempty" example:"u2"`
+	// Команда, в которой произошло событие.
+	TeamName string `json:"team_name,omitempty" example:"backend"`
+	// Дополнительные сведения.
+	Details string `json:"details,omitempty" example:"on behalf of u2"`
+	// Время события.
+	OccurredAt string `json:"occurred_at" validate:"required" example:"2025-10-24T12:34:56Z"`
+} // @name AuditEvent
+
+// @Description События журнала назначений от новых к старым.
+// swagger:model AuditResponse
+type AuditResponse struct {
+	Items []AuditEvent `json:"items"`
+} // @name AuditResponse
@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// @Description Запрос на добавление периода отсутствия пользователя.
+// swagger:model AddAbsenceRequest
+type AddAbsenceRequest struct {
+	// Идентификатор пользователя.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u2"`
+	// Начало отсутствия (RFC3339, включительно).
+	StartsAt time.Time `json:"starts_at" binding:"required" validate:"required" example:"2025-11-01T00:00:00Z"`
+	// Конец отсутствия (RFC3339, не включительно).
+	EndsAt time.Time `json:"ends_at" binding:"required" validate:"required" example:"2025-11-15T00:00:00Z"`
+	// Причина отсутствия.
+	Reason string `json:"reason,omitempty" example:"vacation"`
+} // @name AddAbsenceRequest
+
+// @Description Запрос на удаление периода отсутствия.
+// swagger:model DeleteAbsenceRequest
+type DeleteAbsenceRequest struct {
+	// Идентификатор отсутствия.
+	AbsenceID uint `json:"absence_id" binding:"required" validate:"required" example:"7"`
+} // @name DeleteAbsenceRequest
+
+// @Description Запрос на добавление праздника команды.
+// swagger:model AddTeamHolidayRequest
+type AddTeamHolidayRequest struct {
+	// Имя команды.
+	TeamName string `json:"team_name" binding:"required" validate:"required" example:"backend"`
+	// Начало праздника (RFC3339, включительно).
+	StartsAt time.Time `json:"starts_at" binding:"required" validate:"required" example:"2025-12-31T00:00:00Z"`
+	// Конец праздника (RFC3339, не включительно).
+	EndsAt time.Time `json:"ends_at" binding:"required" validate:"required" example:"2026-01-09T00:00:00Z"`
+	// Название праздника.
+	Name string `json:"name,omitempty" example:"New Year"`
+} // @name AddTeamHolidayRequest
+
+// @Description Запрос на делегирование ревью пользователя на период.
+// swagger:model AddDelegationRequest
+type AddDelegationRequest struct {
+	// Пользователь, который делегирует свои ревью.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u2"`
+	// Делегат из той же команды.
+	DelegateID string `json:"delegate_id" binding:"required" validate:"required" example:"u4"`
+	// Начало делегирования (RFC3339, включительно).
+	StartsAt time.Time `json:"starts_at" binding:"required" validate:"required" example:"2025-11-01T00:00:00Z"`
+	// Конец делегирования (RFC3339, не включительно).
+	EndsAt time.Time `json:"ends_at" binding:"required" validate:"required" example:"2025-11-15T00:00:00Z"`
+} // @name AddDelegationRequest
+
+// @Description Запрос на удаление делегирования.
+// swagger:model DeleteDelegationRequest
+type DeleteDelegationRequest struct {
+	// Идентификатор делегирования.
+	DelegationID uint `json:"delegation_id" binding:"required" validate:"required" example:"4"`
+} // @name DeleteDelegationRequest
@@ -0,0 +1,94 @@
+package dto
+
+// @Description Период отсутствия пользователя.
+// swagger:model Absence
+type Absence struct {
+	// Идентификатор отсутствия.
+	AbsenceID uint `json:"absence_id" validate:"required" example:"7"`
+	// Идентификатор пользователя.
+	UserID string `json:"user_id" validate:"required" example:"u2"`
+	// Начало отсутствия.
+	StartsAt string `json:"starts_at" validate:"required" example:"2025-11-01T00:00:00Z"`
+	// Конец отсутствия.
+	EndsAt string `json:"ends_at" validate:"required" example:"2025-11-15T00:00:00Z"`
+	// Причина отсутствия.
+	Reason string `json:"reason,omitempty" example:"vacation"`
+} // @name Absence
+
+// @Description Ответ с периодом отсутствия.
+// swagger:model AbsenceResponse
+type AbsenceResponse struct {
+	// Период отсутствия.
+	Absence Absence `json:"absence" validate:"required"`
+} // @name AbsenceResponse
+
+// @Description Отсутствия пользователя.
+// swagger:model AbsenceListResponse
+type AbsenceListResponse struct {
+	// Идентификатор пользователя.
+	UserID string `json:"user_id" validate:"required" example:"u2"`
+	// Периоды отсутствия по возрастанию начала.
+	Absences []Absence `json:"absences" validate:"required"`
+} // @name AbsenceListResponse
+
+// @Description Праздник команды.
+// swagger:model TeamHoliday
+type TeamHoliday struct {
+	// Идентификатор праздника.
+	HolidayID uint `json:"holiday_id" validate:"required" example:"3"`
+	// Имя команды.
+	TeamName string `json:"team_name" validate:"required" example:"backend"`
+	// Начало праздника.
+	StartsAt string `json:"starts_at" validate:"required" example:"2025-12-31T00:00:00Z"`
+	// Конец праздника.
+	EndsAt string `json:"ends_at" validate:"required" example:"2026-01-09T00:00:00Z"`
+	// Название праздника.
+	Name string `json:"name,omitempty" example:"New Year"`
+} // @name TeamHoliday
+
+// @Description Ответ с праздником команды.
+// swagger:model TeamHolidayResponse
+type TeamHolidayResponse struct {
+	// Праздник команды.
+	Holiday TeamHoliday `json:"holiday" validate:"required"`
+} // @name TeamHolidayResponse
+
+// @Description Календарь праздников команды.
+// swagger:model TeamHolidayListResponse
+type TeamHolidayListResponse struct {
+	// Имя команды.
+	TeamName string `json:"team_name" validate:"required" example:"backend"`
+	// Праздники по возрастанию начала.
+	Holidays []TeamHoliday `json:"holidays" validate:"required"`
+} // @name TeamHolidayListResponse
+
+// @Description Делегирование ревью пользователя.
+// swagger:model Delegation
+type Delegation struct {
+	// Идентификатор делегирования.
+	DelegationID uint `json:"delegation_id" validate:"required" example:"4"`
+	// Пользователь, который делегирует свои ревью.
+	UserID string `json:"user_id" validate:"required" example:"u2"`
+	// Делегат.
+	DelegateID string `json:"delegate_id" validate:"required" example:"u4"`
+	// Начало делегирования.
+	StartsAt string `json:"starts_at" validate:"required" example:"2025-11-01T00:00:00Z"`
+	// Конец делегирования.
+	EndsAt string `json:"ends_at" validate:"required" example:"2025-11-15T00:00:00Z"`
+} // @name Delegation
+
+// @Description Ответ с делегированием.
+// swagger:model DelegationResponse
+type DelegationResponse struct {
+	// Делегирование.
+	Delegation Delegation `json:"delegation" validate:"required"`
+} // @name DelegationResponse
+
+// @Description Делегирования пользователя.
+// swagger:model DelegationListResponse
+type DelegationListResponse struct {
+	// Идентификатор пользователя.
+	UserID string `json:"user_id" validate:"required" example:"u2"`
+	// Делегирования по возрастанию начала.
+	Delegations []Delegation `json:"delegations" validate:"required"`
+} // @name DelegationListResponse
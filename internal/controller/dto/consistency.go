@@ -0,0 +1,39 @@
+package dto
+
+// @Description Запрос на проверку согласованности назначений.
+// swagger:model ConsistencyCheckRequest
+type ConsistencyCheckRequest struct {
+	// true — исправить найденные нарушения, false — только отчёт (dry-run).
+	Apply bool `json:"apply" example:"false"`
+} // @name ConsistencyCheckRequest
+
+// @Description Нарушение инварианта назначений в открытом PR.
+// swagger:model ConsistencyFinding
+type ConsistencyFinding struct {
+	// Вид нарушения: inactive_reviewer, author_reviewer, duplicate_reviewer, over_quota, wrong_team, deleted_author.
+	// Исправление выбирается по нему; приоритет: author_reviewer, wrong_team, inactive_reviewer.
+	Kind string `json:"kind" validate:"required" example:"inactive_reviewer"`
+	// Остальные нарушения того же ревьювера; исправление по kind закрывает и их.
+	OtherKinds []string `json:"other_kinds,omitempty" example:"duplicate_reviewer"`
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" validate:"required" example:"pr-1001"`
+	// user_id ревьювера, которого касается нарушение.
+	UserID string `json:"user_id,omitempty" example:"u2"`
+	// Исправление: reassign, remove, readd или none, если исправить автоматически нельзя.
+	Repair string `json:"repair" validate:"required" example:"reassign"`
+	// Результат исправления при apply: reassigned, removed, readded, skipped, failed.
+	Outcome string `json:"outcome,omitempty" example:"reassigned"`
+	// user_id назначенной замены.
+	ReplacedBy string `json:"replaced_by,omitempty" example:"u5"`
+	// Почему исправление не удалось или выполнено частично.
+	Error string `json:"error,omitempty" example:"pull request already merged"`
+} // @name ConsistencyFinding
+
+// @Description Отчёт проверки согласованности назначений.
+// swagger:model ConsistencyReportResponse
+type ConsistencyReportResponse struct {
+	// Выполнялись ли исправления.
+	Applied bool `json:"applied"`
+	// Найденные нарушения; пустой список — данные согласованы.
+	Findings []ConsistencyFinding `json:"findings"`
+} // @name ConsistencyReportResponse
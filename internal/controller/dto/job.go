@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// @Description Запрос на фоновую массовую деактивацию пользователей команды.
+// swagger:model BulkDeactivateJobRequest
+type BulkDeactivateJobRequest struct {
+	// Имя команды, в которой отключаются пользователи.
+	TeamName string `json:"team_name" binding:"required" example:"backend"`
+	// user_id пользователей для деактивации; каждый становится отдельным элементом задачи.
+	UserIDs []string `json:"user_ids" binding:"required" example:"u1,u2,u3"`
+} // @name BulkDeactivateJobRequest
+
+// @Description Фоновая задача с прогрессом и результатами элементов.
+// swagger:model Job
+type Job struct {
+	// Идентификатор задачи.
+	JobID uint `json:"job_id" example:"42"`
+	// Вид операции.
+	Kind string `json:"kind" example:"bulk_deactivate"`
+	// Статус: queued, running, completed, canceled или failed.
+	Status string `json:"status" example:"running"`
+	// Сколько элементов всего.
+	Total int `json:"total" example:"3"`
+	// Сколько элементов обработано.
+	Processed int `json:"processed" example:"1"`
+	// Запрошена отмена; выполняемая задача остановится перед следующим элементом.
+	CancelRequested bool `json:"cancel_requested"`
+	// Когда задача поставлена в очередь.
+	CreatedAt time.Time `json:"created_at"`
+	// Когда задачу впервые взял обработчик.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// Когда задача завершилась или была отменена.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Элементы задачи в порядке выполнения.
+	Items []JobItem `json:"items"`
+} // @name Job
+
+// @Description Элемент фоновой задачи и его результат.
+// swagger:model JobItem
+type JobItem struct {
+	// Над чем выполняется элемент, для bulk_deactivate — user_id.
+	Key string `json:"key" example:"u1"`
+	// Статус: pending, succeeded, failed или canceled.
+	Status string `json:"status" example:"succeeded"`
+	// Итог элемента; для bulk_deactivate — счётчики и снятые ревьюверы с заменами.
+	Result json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	// Ошибка, если элемент не выполнен.
+	Error string `json:"error,omitempty"`
+	// Когда элемент завершён.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+} // @name JobItem
+
+// @Description Ответ с фоновой задачей.
+// swagger:model JobResponse
+type JobResponse struct {
+	// Задача.
+	Job Job `json:"job"`
+} // @name JobResponse
@@ -25,4 +25,42 @@ type ReassgnRequest struct {
 	PRID string `json:"pull_request_id" binding:"required" validate:"required" example:"pr-1001"`
 	// user_id ревьювера, которого заменяем.
 	OldUserID string `json:"old_user_id" binding:"required" validate:"required" example:"u2"`
+	// user_id конкретной замены; если не задан, замена выбирается случайно.
+	NewUserID string `json:"new_user_id,omitempty" example:"u5"`
 } // @name ReassgnRequest
+
+// @Description Запрос на ручное добавление ревьювера.
+// swagger:model AddReviewerRequest
+type AddReviewerRequest struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" binding:"required" validate:"required" example:"pr-1001"`
+	// user_id добавляемого ревьювера.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u3"`
+} // @name AddReviewerRequest
+
+// @Description Запрос на снятие ревьювера без замены.
+// swagger:model RemoveReviewerRequest
+type RemoveReviewerRequest struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" binding:"required" validate:"required" example:"pr-1001"`
+	// user_id снимаемого ревьювера.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u3"`
+} // @name RemoveReviewerRequest
+
+// @Description Ревью-действие назначенного ревьювера (одобрение, комментарий и т.п.).
+// swagger:model SubmitReviewRequest
+type SubmitReviewRequest struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" binding:"required" validate:"required" example:"pr-1001"`
+	// user_id ревьювера.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u3"`
+} // @name SubmitReviewRequest
+
+// @Description Отказ назначенного ревьювера от ревью.
+// swagger:model DeclineRequest
+type DeclineRequest struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" binding:"required" validate:"required" example:"pr-1001"`
+	// user_id отказавшегося ревьювера.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u3"`
+} // @name DeclineRequest
@@ -11,14 +11,34 @@ type PullRequest struct {
 	AuthorID string `json:"author_id" validate:"required" example:"u1"`
 	// Статус PR.
 	Status string `json:"status" validate:"required" example:"OPEN"`
-	// Назначенные ревьюверы (до двух user_id).
+	// Назначенные ревьюверы (не больше лимита команды автора).
 	AssignedReviewers []string `json:"assigned_reviewers" validate:"required" example:"u2,u3"`
 	// Время создания.
 	CreatedAT *string `json:"created_at,omitempty" example:"2025-10-25T12:00:00Z"`
 	// Время merge (если есть).
 	MergedAt *string `json:"merged_at,omitempty" example:"2025-10-26T09:30:00Z"`
+	// Почему набор ревьюверов не удовлетворяет правилам команды (если так вышло).
+	AssignmentWarnings []string `json:"assignment_warnings,omitempty" example:"no senior reviewer available: team requires at least one senior or lead"`
+	// Ревьюверы, назначенные по делегированию вместо другого участника.
+	DelegatedReviewers []DelegatedReviewer `json:"delegated_reviewers,omitempty"`
 } // @name PullRequest
 
+// @Description Ревьювер, назначенный вместо участника, который делегировал свои ревью.
+// swagger:model DelegatedReviewer
+type DelegatedReviewer struct {
+	// Назначенный ревьювер (делегат).
+	ReviewerID string `json:"reviewer_id" validate:"required" example:"u4"`
+	// Участник, за которого выполняется ревью.
+	OnBehalfOf string `json:"on_behalf_of" validate:"required" example:"u2"`
+} // @name DelegatedReviewer
+
+// @Description PR, в том числе в состоянии на момент as_of.
+// swagger:model GetPRResponse
+type GetPRResponse struct {
+	// PR с ревьюверами.
+	PR PullRequest `json:"pr" validate:"required"`
+} // @name GetPRResponse
+
 // @Description Ответ на создание PR.
 // swagger:model CreatePRResponse
 type CreatePRResponse struct {
@@ -41,3 +61,33 @@ type ReassignResponse struct {
 	// user_id, который заменил предыдущего ревьювера.
 	ReplacedBy string `json:"replaced_by" validate:"required" example:"u5"`
 } // @name ReassignResponse
+
+// @Description Ответ на ручное добавление ревьювера.
+// swagger:model AddReviewerResponse
+type AddReviewerResponse struct {
+	// PR после добавления ревьювера.
+	PR PullRequest `json:"pr" validate:"required"`
+} // @name AddReviewerResponse
+
+// @Description Ответ на снятие ревьювера.
+// swagger:model RemoveReviewerResponse
+type RemoveReviewerResponse struct {
+	// PR после снятия ревьювера.
+	PR PullRequest `json:"pr" validate:"required"`
+} // @name RemoveReviewerResponse
+
+// @Description Ответ на ревью-действие.
+// swagger:model SubmitReviewResponse
+type SubmitReviewResponse struct {
+	// Текущий PR.
+	PR PullRequest `json:"pr" validate:"required"`
+} // @name SubmitReviewResponse
+
+// @Description Ответ на отказ от ревью.
+// swagger:model DeclineResponse
+type DeclineResponse struct {
+	// Текущий PR.
+	PR PullRequest `json:"pr" validate:"required"`
+	// user_id замены; пусто, если подходящего кандидата не нашлось и ревьювер просто снят.
+	ReplacedBy string `json:"replaced_by,omitempty" example:"u5"`
+} // @name DeclineResponse
@@ -26,10 +26,154 @@ type AssignmentByPR struct {
 // swagger:model AssignmentByUserResponse
 type AssignmentByUserResponse struct {
 	Items []AssignmentByUser `json:"items"`
+	// Курсор следующей страницы; отсутствует на последней странице.
+	NextCursor string `json:"next_cursor,omitempty" example:"NTA"`
 } // @name AssignmentByUserResponse
 
 // @Description Ответ со списком назначений по PR.
 // swagger:model AssignmentByPRResponse
 type AssignmentByPRResponse struct {
 	Items []AssignmentByPR `json:"items"`
+	// Курсор следующей страницы; отсутствует на последней странице.
+	NextCursor string `json:"next_cursor,omitempty" example:"NTA"`
 } // @name AssignmentByPRResponse
+
+// @Description Ожидание ревью в рабочих часах ревьювера.
+// swagger:model ReviewSLAItem
+type ReviewSLAItem struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" example:"pr-1001"`
+	// Название PR.
+	Name string `json:"pull_request_name" example:"Add search endpoint"`
+	// user_id ревьювера.
+	ReviewerID string `json:"reviewer_id" example:"u2"`
+	// Когда ревьювер назначен.
+	AssignedAt string `json:"assigned_at" example:"2025-10-24T16:00:00Z"`
+	// Сколько рабочих часов ревьювера прошло с назначения.
+	WorkingHours float64 `json:"working_hours" example:"5.5"`
+	// SLA команды автора в рабочих часах; 0 — не отслеживается.
+	SLAHours int `json:"sla_hours" example:"8"`
+	// SLA нарушен.
+	Breached bool `json:"breached" example:"false"`
+} // @name ReviewSLAItem
+
+// @Description Ожидание ревью по открытым PR.
+// swagger:model ReviewSLAResponse
+type ReviewSLAResponse struct {
+	Items []ReviewSLAItem `json:"items"`
+} // @name ReviewSLAResponse
+
+// @Description Сводка по длительностям в часах.
+// swagger:model DurationSummary
+type DurationSummary struct {
+	// Количество наблюдений.
+	Count int `json:"count" example:"4"`
+	// Среднее, часы.
+	AvgHours float64 `json:"avg_hours" example:"20.5"`
+	// Медиана, часы.
+	MedianHours float64 `json:"median_hours" example:"18"`
+	// Максимум, часы.
+	MaxHours float64 `json:"max_hours" example:"40.25"`
+} // @name DurationSummary
+
+// @Description Метрики длительности команды или пользователя за неделю.
+// swagger:model DurationBucket
+type DurationBucket struct {
+	// Имя команды или user_id.
+	Group string `json:"group" example:"backend"`
+	// Понедельник недели (UTC).
+	WeekStart string `json:"week_start" example:"2025-10-20"`
+	// От создания PR до merge.
+	TimeToMerge DurationSummary `json:"time_to_merge"`
+	// Для команды — от создания PR до первого ревью-действия; для пользователя — от его назначения до его ревью-действия.
+	TimeToFirstReview DurationSummary `json:"time_to_first_review"`
+	// От назначения ревьювера до его замены.
+	ReassignmentLatency DurationSummary `json:"reassignment_latency"`
+} // @name DurationBucket
+
+// @Description Метрики длительности по неделям.
+// swagger:model ReviewDurationsResponse
+type ReviewDurationsResponse struct {
+	// Разрез: team или user.
+	GroupBy string `json:"group_by" example:"team"`
+	// Начало периода.
+	From string `json:"from" example:"2025-08-01T00:00:00Z"`
+	// Конец периода.
+	To    string           `json:"to" example:"2025-10-24T00:00:00Z"`
+	Items []DurationBucket `json:"items"`
+} // @name ReviewDurationsResponse
+
+// @Description Нагрузка участника команды.
+// swagger:model MemberFairness
+type MemberFairness struct {
+	// user_id участника.
+	UserID string `json:"user_id" example:"u2"`
+	// username участника.
+	Username string `json:"username" example:"Bob"`
+	// Активен ли участник.
+	IsActive bool `json:"is_active" example:"true"`
+	// Текущие назначения на открытые PR.
+	OpenAssignments int64 `json:"open_assignments" example:"2"`
+	// Все назначения за историю, включая замены.
+	TotalAssignments int64 `json:"total_assignments" example:"14"`
+	// Отклонение total_assignments от среднего по активным участникам.
+	Deviation float64 `json:"deviation" example:"-1.5"`
+} // @name MemberFairness
+
+// @Description Отчёт о равномерности нагрузки в команде.
+// swagger:model FairnessResponse
+type FairnessResponse struct {
+	// Имя команды.
+	TeamName string `json:"team_name" example:"backend"`
+	// Среднее число назначений за историю на активного участника.
+	MeanTotal float64 `json:"mean_total" example:"15.5"`
+	// Среднее число открытых назначений на активного участника.
+	MeanOpen float64 `json:"mean_open" example:"1.75"`
+	// Коэффициент Джини по всем назначениям (0 — поровну, ближе к 1 — неравномерно).
+	GiniTotal float64 `json:"gini_total" example:"0.12"`
+	// Коэффициент Джини по открытым назначениям.
+	GiniOpen float64          `json:"gini_open" example:"0.3"`
+	Members  []MemberFairness `json:"members"`
+	// Активные участники, ни разу не назначавшиеся ревьюверами.
+	NeverAssigned []string `json:"never_assigned" example:"u5"`
+} // @name FairnessResponse
+
+// @Description Сколько раз ревьювера снимали с PR, всего и по причинам.
+// swagger:model UserReplacements
+type UserReplacements struct {
+	// user_id снятого ревьювера.
+	UserID string `json:"user_id" example:"u2"`
+	// Всего снятий.
+	Total int64 `json:"total" example:"4"`
+	// Снятия по причинам: reassign, manual, bulk_deactivate, decline, sla_escalation, consistency_repair и т.д.
+	ByReason map[string]int64 `json:"by_reason"`
+} // @name UserReplacements
+
+// @Description Ответ со статистикой снятий ревьюверов по пользователям.
+// swagger:model UserReplacementsResponse
+type UserReplacementsResponse struct {
+	Items []UserReplacements `json:"items"`
+} // @name UserReplacementsResponse
+
+// @Description Смена ревьюверов в PR.
+// swagger:model PRChurn
+type PRChurn struct {
+	// Идентификатор PR.
+	PRID string `json:"pull_request_id" example:"pr-1001"`
+	// Название PR.
+	Name string `json:"pull_request_name" example:"Add search endpoint"`
+	// Статус PR.
+	Status string `json:"status" example:"OPEN"`
+	// Сколько разных пользователей было ревьюверами PR за историю.
+	DistinctReviewers int64 `json:"distinct_reviewers" example:"5"`
+	// Сколько раз ревьювера снимали с PR.
+	Removals int64 `json:"removals" example:"3"`
+} // @name PRChurn
+
+// @Description Ответ со списком PR, с которых снимали ревьюверов.
+// swagger:model PRChurnResponse
+type PRChurnResponse struct {
+	Items []PRChurn `json:"items"`
+	// Курсор следующей страницы; отсутствует на последней странице.
+	NextCursor string `json:"next_cursor,omitempty" example:"NTA"`
+} // @name PRChurnResponse
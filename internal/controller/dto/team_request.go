@@ -9,6 +9,14 @@ type TeamMember struct {
 	Username string `json:"username" binding:"required" validate:"required" example:"Alice"`
 	// Признак активности.
 	IsActive bool `json:"is_active" binding:"required" validate:"required" example:"true"`
+	// Уровень: junior, middle, senior или lead (по умолчанию middle).
+	Seniority string `json:"seniority,omitempty" binding:"omitempty,oneof=junior middle senior lead" example:"senior"`
+	// IANA-зона участника (по умолчанию UTC).
+	TimeZone string `json:"time_zone,omitempty" example:"Europe/Moscow"`
+	// Начало рабочего дня в локальном времени, HH:MM (по умолчанию 09:00).
+	WorkStart string `json:"work_start,omitempty" example:"10:00"`
+	// Конец рабочего дня в локальном времени, HH:MM (по умолчанию 18:00).
+	WorkEnd string `json:"work_end,omitempty" example:"19:00"`
 } // @name TeamMember
 
 // @Description Запрос на создание команды.
@@ -18,4 +26,23 @@ type CreateTeamRequest struct {
 	TeamName string `json:"team_name" binding:"required" validate:"required" example:"backend"`
 	// Массив участников команды.
 	Members []TeamMember `json:"members" binding:"required,dive" validate:"required,dive"`
+	// Максимум ревьюверов на PR (по умолчанию 2).
+	MaxReviewers int `json:"max_reviewers,omitempty" binding:"omitempty,min=1" example:"2"`
+	// Требовать хотя бы одного senior или lead среди ревьюверов PR.
+	RequireSenior bool `json:"require_senior,omitempty" example:"true"`
+	// Запретить junior быть единственным ревьювером PR.
+	ForbidSoleJunior bool `json:"forbid_sole_junior,omitempty" example:"true"`
+	// Режим выбора ревьюверов: random (по умолчанию) или working_hours — сначала те, у кого сейчас рабочее время.
+	AssignmentMode string `json:"assignment_mode,omitempty" binding:"omitempty,oneof=random working_hours" example:"working_hours"`
+	// SLA на ревью в рабочих часах ревьювера; 0 — не отслеживается.
+	ReviewSLAHours int `json:"review_sla_hours,omitempty" binding:"omitempty,min=0" example:"8"`
 } // @name CreateTeamRequest
+
+// @Description Запрос на смену лимита ревьюверов команды.
+// swagger:model MaxReviewersRequest
+type MaxReviewersRequest struct {
+	// Имя команды.
+	TeamName string `json:"team_name" binding:"required" validate:"required" example:"backend"`
+	// Максимум ревьюверов на PR.
+	MaxReviewers int `json:"max_reviewers" binding:"required,min=1" validate:"required" example:"3"`
+} // @name MaxReviewersRequest
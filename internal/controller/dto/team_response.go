@@ -7,6 +7,16 @@ type Team struct {
 	TeamName string `json:"team_name" validate:"required" example:"backend"`
 	// Участники команды.
 	Members []TeamMember `json:"members" validate:"required"`
+	// Максимум ревьюверов на PR.
+	MaxReviewers int `json:"max_reviewers" example:"2"`
+	// Требуется ли хотя бы один senior или lead среди ревьюверов.
+	RequireSenior bool `json:"require_senior" example:"false"`
+	// Запрещено ли junior быть единственным ревьювером.
+	ForbidSoleJunior bool `json:"forbid_sole_junior" example:"false"`
+	// Режим выбора ревьюверов.
+	AssignmentMode string `json:"assignment_mode" example:"random"`
+	// SLA на ревью в рабочих часах ревьювера; 0 — не отслеживается.
+	ReviewSLAHours int `json:"review_sla_hours" example:"0"`
 } // @name Team
 
 // @Description Ответ, содержащий объект team.
@@ -7,6 +7,10 @@ type BulkDeactivateRequest struct {
 	TeamName string `json:"team_name" binding:"required" example:"backend"`
 	// user_id пользователей для деактивации.
 	UserIDs []string `json:"user_ids" binding:"required" example:"u1,u2,u3"`
+	// Только рассчитать план и вернуть его вместе с plan_token, ничего не записывая.
+	DryRun bool `json:"dry_run" example:"false"`
+	// Токен плана из ответа dry_run: выполнить ровно этот план, если данные не изменились.
+	PlanToken string `json:"plan_token,omitempty" example:"1f3a9c.5d41402abc4b2a76b9719d911017c592"`
 } // @name BulkDeactivateRequest
 
 // @Description Ответ на массовую деактивацию.
@@ -22,4 +26,27 @@ type BulkDeactivateResponse struct {
 	Skipped int `json:"skipped"`
 	// Сколько открытых PR затронуто.
 	AffectedPRs int `json:"affected_prs"`
+	// Сколько PR остались без соблюдения правил состава команды (senior/junior).
+	PolicyViolations int `json:"policy_violations"`
+	// Снятые ревьюверы по PR с заменой или причиной пропуска.
+	Plan []BulkDeactivatePlanItem `json:"plan"`
+	// План только рассчитан, изменения не записаны.
+	DryRun bool `json:"dry_run"`
+	// Токен для выполнения этого плана; возвращается только при dry_run.
+	PlanToken string `json:"plan_token,omitempty"`
 } // @name BulkDeactivateResponse
+
+// @Description Снятие ревьювера в плане массовой деактивации.
+// swagger:model BulkDeactivatePlanItem
+type BulkDeactivatePlanItem struct {
+	// Идентификатор PR.
+	PullRequestID string `json:"pull_request_id" example:"pr-1001"`
+	// Снимаемый ревьювер.
+	RemovedUserID string `json:"removed_user_id" example:"u2"`
+	// Предлагаемая замена; пусто, если замену не нашли.
+	ReplacementUserID string `json:"replacement_user_id,omitempty" example:"u5"`
+	// Замена выбрана как делегат снимаемого ревьювера.
+	Delegated bool `json:"delegated,omitempty"`
+	// Почему замены нет.
+	SkipReason string `json:"skip_reason,omitempty" example:"no replacement candidate"`
+} // @name BulkDeactivatePlanItem
@@ -8,3 +8,16 @@ type UserRequest struct {
 	// Значение флага активности.
 	IsActive *bool `json:"is_active" binding:"required" validate:"required" example:"false"`
 } // @name UserRequest
+
+// @Description Запрос на смену часового пояса и рабочего окна пользователя.
+// swagger:model WorkingHoursRequest
+type WorkingHoursRequest struct {
+	// Идентификатор пользователя.
+	UserID string `json:"user_id" binding:"required" validate:"required" example:"u2"`
+	// IANA-зона (пусто — UTC).
+	TimeZone string `json:"time_zone,omitempty" example:"Asia/Novosibirsk"`
+	// Начало рабочего дня, HH:MM (пусто — 09:00).
+	WorkStart string `json:"work_start,omitempty" example:"08:00"`
+	// Конец рабочего дня, HH:MM (пусто — 18:00).
+	WorkEnd string `json:"work_end,omitempty" example:"17:00"`
+} // @name WorkingHoursRequest
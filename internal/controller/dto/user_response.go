@@ -11,6 +11,14 @@ type User struct {
 	TeamName string `json:"team_name" validate:"required" example:"backend"`
 	// Флаг активности.
 	IsActive bool `json:"is_active" validate:"required" example:"true"`
+	// Уровень пользователя.
+	Seniority string `json:"seniority" example:"middle"`
+	// Часовой пояс пользователя.
+	TimeZone string `json:"time_zone" example:"Europe/Moscow"`
+	// Начало рабочего дня в локальном времени.
+	WorkStart string `json:"work_start" example:"09:00"`
+	// Конец рабочего дня в локальном времени.
+	WorkEnd string `json:"work_end" example:"18:00"`
 } // @name User
 
 // @Description Ответ с пользователем.
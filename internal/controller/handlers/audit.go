@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/mapper"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// actorHeader — заголовок, которым клиент представляется в журнале назначений.
+const actorHeader = "X-Actor"
+
+type AuditHandler struct {
+	auditSvc service.AuditService
+}
+
+func NewAuditHandler(auditSvc service.AuditService) *AuditHandler {
+	return &AuditHandler{auditSvc: auditSvc}
+}
+
+func registerAuditRoutes(r gin.IRouter, auditSvc service.AuditService) {
+	handler := NewAuditHandler(auditSvc)
+	r.GET("/audit", handler.Events)
+}
+
+// requestOrigin собирает инициатора изменения и ожидаемую версию PR из запроса.
+func requestOrigin(c *gin.Context) service.Origin {
+	return service.Origin{Actor: c.GetHeader(actorHeader), ExpectedVersion: ifMatchVersion(c)}
+}
+
+// Events godoc
+// @Summary      Журнал назначений
+// @Description  Возвращает события назначений (assigned, unassigned, replaced, merged, reviewed, activity_changed, а также изменения отсутствий, праздников и делегирований) от новых к старым. Фильтр по пользователю учитывает и нового, и заменённого ревьювера.
+// @Tags         Audit
+// @Accept       json
+// @Produce      json
+// @Param        pull_request_id  query     string  false  "Идентификатор PR"
+// @Param        user_id          query     string  false  "Идентификатор пользователя"
+// @Param        team_name        query     string  false  "Имя команды"
+// @Param        from             query     string  false  "Начало периода (RFC3339, включительно)"
+// @Param        to               query     string  false  "Конец периода (RFC3339, не включительно)"
+// @Param        limit            query     int     false  "Максимум событий (по умолчанию 100, не больше 1000)"
+// @Success      200              {object}  dto.AuditResponse
+// @Failure      400              {object}  dto.ErrorResponse
+// @Failure      500              {object}  dto.ErrorResponse
+// @Failure      504              {object}  dto.ErrorResponse
+// @Router       /api/audit [get]
+func (h *AuditHandler) Events(c *gin.Context) {
+	log := logger(c)
+	filter := repository.AuditFilter{
+		PRID:     c.Query("pull_request_id"),
+		UserID:   c.Query("user_id"),
+		TeamName: c.Query("team_name"),
+	}
+
+	var err error
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "from must be RFC3339")
+		return
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "to must be RFC3339")
+		return
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		filter.Limit = limit
+	}
+	log.Debugw("audit request", "filter", filter)
+
+	events, err := h.auditSvc.Events(c.Request.Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, serviceerrs.ErrInvalidPeriod):
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		default:
+			writeInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuditResponse{
+		Items: mapper.MapAuditEventsToDTO(events),
+	})
+	log.Infow("audit events fetched", "count", len(events))
+}
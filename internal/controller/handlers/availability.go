@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/mapper"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	availabilitySvc service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilitySvc service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilitySvc: availabilitySvc}
+}
+
+func registerAvailabilityRoutes(r gin.IRouter, availabilitySvc service.AvailabilityService) {
+	handler := NewAvailabilityHandler(availabilitySvc)
+
+	users := r.Group("/users/absences")
+	users.POST("/add", handler.AddAbsence)
+	users.GET("", handler.GetAbsences)
+	users.POST("/delete", handler.DeleteAbsence)
+
+	delegations := r.Group("/users/delegations")
+	delegations.POST("/add", handler.AddDelegation)
+	delegations.GET("", handler.GetDelegations)
+	delegations.POST("/delete", handler.DeleteDelegation)
+
+	teams := r.Group("/team/holidays")
+	teams.POST("/add", handler.AddTeamHoliday)
+	teams.GET("", handler.GetTeamHolidays)
+}
+
+// AddAbsence godoc
+// @Summary      Добавить отсутствие
+// @Description  Сохраняет период отсутствия пользователя. Пока период идёт, пользователь не выбирается ревьювером; флаг is_active при этом не меняется. Период, пересекающийся с другим отсутствием пользователя, отклоняется с 409 ABSENCE_OVERLAP.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.AddAbsenceRequest  true  "Период отсутствия"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      201      {object}  dto.AbsenceResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/users/absences/add [post]
+func (h *AvailabilityHandler) AddAbsence(c *gin.Context) {
+	log := logger(c)
+	var req dto.AddAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid add absence payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("add absence request", "payload", req)
+
+	absence, err := h.availabilitySvc.AddAbsence(c.Request.Context(), requestOrigin(c), req.UserID, req.StartsAt, req.EndsAt, req.Reason)
+	if err != nil {
+		log.Errorw("failed to add absence", "user_id", req.UserID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.AbsenceResponse{
+		Absence: mapper.MapAbsenceToDTO(*absence),
+	})
+	log.Infow("absence added", "user_id", req.UserID, "absence_id", absence.ID)
+}
+
+// GetAbsences godoc
+// @Summary      Отсутствия пользователя
+// @Description  Возвращает все периоды отсутствия пользователя.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        user_id  query     string  true  "Идентификатор пользователя"
+// @Success      200      {object}  dto.AbsenceListResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/users/absences [get]
+func (h *AvailabilityHandler) GetAbsences(c *gin.Context) {
+	log := logger(c)
+	userID := c.Query("user_id")
+	if userID == "" {
+		log.Warnw("user_id query parameter missing")
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "user_id is required")
+		return
+	}
+
+	absences, err := h.availabilitySvc.GetAbsences(c.Request.Context(), userID)
+	if err != nil {
+		log.Errorw("failed to get absences", "user_id", userID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AbsenceListResponse{
+		UserID:   userID,
+		Absences: mapper.MapAbsencesToDTO(absences),
+	})
+	log.Infow("absences fetched", "user_id", userID, "count", len(absences))
+}
+
+// DeleteAbsence godoc
+// @Summary      Удалить отсутствие
+// @Description  Удаляет период отсутствия по идентификатору.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.DeleteAbsenceRequest  true  "Идентификатор отсутствия"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      204
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Failure      504  {object}  dto.ErrorResponse
+// @Router       /api/users/absences/delete [post]
+func (h *AvailabilityHandler) DeleteAbsence(c *gin.Context) {
+	log := logger(c)
+	var req dto.DeleteAbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid delete absence payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+
+	if err := h.availabilitySvc.DeleteAbsence(c.Request.Context(), requestOrigin(c), req.AbsenceID); err != nil {
+		log.Errorw("failed to delete absence", "absence_id", req.AbsenceID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	log.Infow("absence deleted", "absence_id", req.AbsenceID)
+}
+
+// AddTeamHoliday godoc
+// @Summary      Добавить праздник команды
+// @Description  Добавляет период в календарь команды. Пока период идёт, участники команды не выбираются ревьюверами.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.AddTeamHolidayRequest  true  "Праздник команды"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      201      {object}  dto.TeamHolidayResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/team/holidays/add [post]
+func (h *AvailabilityHandler) AddTeamHoliday(c *gin.Context) {
+	log := logger(c)
+	var req dto.AddTeamHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid add holiday payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("add team holiday request", "payload", req)
+
+	holiday, err := h.availabilitySvc.AddTeamHoliday(c.Request.Context(), requestOrigin(c), req.TeamName, req.StartsAt, req.EndsAt, req.Name)
+	if err != nil {
+		log.Errorw("failed to add team holiday", "team_name", req.TeamName, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.TeamHolidayResponse{
+		Holiday: mapper.MapTeamHolidayToDTO(*holiday),
+	})
+	log.Infow("team holiday added", "team_name", req.TeamName, "holiday_id", holiday.ID)
+}
+
+// GetTeamHolidays godoc
+// @Summary      Календарь праздников команды
+// @Description  Возвращает праздники команды.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        team_name  query     string  true  "Имя команды"
+// @Success      200        {object}  dto.TeamHolidayListResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      404        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
+// @Router       /api/team/holidays [get]
+func (h *AvailabilityHandler) GetTeamHolidays(c *gin.Context) {
+	log := logger(c)
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		log.Warnw("team_name query parameter missing")
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "team_name is required")
+		return
+	}
+
+	holidays, err := h.availabilitySvc.GetTeamHolidays(c.Request.Context(), teamName)
+	if err != nil {
+		log.Errorw("failed to get team holidays", "team_name", teamName, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TeamHolidayListResponse{
+		TeamName: teamName,
+		Holidays: mapper.MapTeamHolidaysToDTO(holidays),
+	})
+	log.Infow("team holidays fetched", "team_name", teamName, "count", len(holidays))
+}
+
+// AddDelegation godoc
+// @Summary      Делегировать ревью
+// @Description  На период ревью пользователя сначала предлагаются делегату из той же команды: при автоматическом выборе, переназначении и массовой деактивации. Если делегат недоступен, выбор идёт как обычно.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.AddDelegationRequest  true  "Делегирование"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      201      {object}  dto.DelegationResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/users/delegations/add [post]
+func (h *AvailabilityHandler) AddDelegation(c *gin.Context) {
+	log := logger(c)
+	var req dto.AddDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid add delegation payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("add delegation request", "payload", req)
+
+	delegation, err := h.availabilitySvc.AddDelegation(c.Request.Context(), requestOrigin(c), req.UserID, req.DelegateID, req.StartsAt, req.EndsAt)
+	if err != nil {
+		log.Errorw("failed to add delegation", "user_id", req.UserID, "delegate_id", req.DelegateID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DelegationResponse{
+		Delegation: mapper.MapDelegationToDTO(*delegation),
+	})
+	log.Infow("delegation added", "user_id", req.UserID, "delegate_id", req.DelegateID, "delegation_id", delegation.ID)
+}
+
+// GetDelegations godoc
+// @Summary      Делегирования пользователя
+// @Description  Возвращает все делегирования ревью пользователя.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        user_id  query     string  true  "Идентификатор пользователя"
+// @Success      200      {object}  dto.DelegationListResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/users/delegations [get]
+func (h *AvailabilityHandler) GetDelegations(c *gin.Context) {
+	log := logger(c)
+	userID := c.Query("user_id")
+	if userID == "" {
+		log.Warnw("user_id query parameter missing")
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "user_id is required")
+		return
+	}
+
+	delegations, err := h.availabilitySvc.GetDelegations(c.Request.Context(), userID)
+	if err != nil {
+		log.Errorw("failed to get delegations", "user_id", userID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DelegationListResponse{
+		UserID:      userID,
+		Delegations: mapper.MapDelegationsToDTO(delegations),
+	})
+	log.Infow("delegations fetched", "user_id", userID, "count", len(delegations))
+}
+
+// DeleteDelegation godoc
+// @Summary      Удалить делегирование
+// @Description  Удаляет делегирование по идентификатору. Уже назначенные делегатам ревью не меняются.
+// @Tags         Availability
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.DeleteDelegationRequest  true  "Идентификатор делегирования"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      204
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Failure      504  {object}  dto.ErrorResponse
+// @Router       /api/users/delegations/delete [post]
+func (h *AvailabilityHandler) DeleteDelegation(c *gin.Context) {
+	log := logger(c)
+	var req dto.DeleteDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid delete delegation payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+
+	if err := h.availabilitySvc.DeleteDelegation(c.Request.Context(), requestOrigin(c), req.DelegationID); err != nil {
+		log.Errorw("failed to delete delegation", "delegation_id", req.DelegationID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	log.Infow("delegation deleted", "delegation_id", req.DelegationID)
+}
+
+func (h *AvailabilityHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, serviceerrs.ErrInvalidPeriod),
+		errors.Is(err, serviceerrs.ErrDelegateSelf):
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+	case errors.Is(err, serviceerrs.ErrDelegateWrongTeam):
+		writeError(c, http.StatusBadRequest, errorCodeDelegateWrongTeam, err.Error())
+	case errors.Is(err, serviceerrs.ErrDelegationOverlap):
+		writeError(c, http.StatusConflict, errorCodeDelegationOverlap, err.Error())
+	case errors.Is(err, serviceerrs.ErrAbsenceOverlap):
+		writeError(c, http.StatusConflict, errorCodeAbsenceOverlap, err.Error())
+	case errors.Is(err, serviceerrs.ErrUserNotFound),
+		errors.Is(err, serviceerrs.ErrTeamNotFound),
+		errors.Is(err, serviceerrs.ErrAbsenceNotFound),
+		errors.Is(err, serviceerrs.ErrDelegationNotFound):
+		writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
+	default:
+		writeInternalError(c, err)
+	}
+}
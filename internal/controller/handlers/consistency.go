@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/mapper"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ConsistencyHandler struct {
+	consistencySvc service.ConsistencyService
+}
+
+func NewConsistencyHandler(consistencySvc service.ConsistencyService) *ConsistencyHandler {
+	return &ConsistencyHandler{consistencySvc: consistencySvc}
+}
+
+func registerConsistencyRoutes(r gin.IRouter, consistencySvc service.ConsistencyService) {
+	handler := NewConsistencyHandler(consistencySvc)
+	group := r.Group("/admin")
+
+	group.POST("/consistency", handler.Check)
+}
+
+// Check godoc
+// @Summary      Проверка согласованности назначений
+// @Description  Ищет в открытых PR неактивных ревьюверов, автора среди ревьюверов, дубликаты и превышение лимита ревьюверов, ревьюверов не из команды автора и PR удалённых авторов. По умолчанию только отчёт (dry-run). С apply=true нарушения исправляются обычными операциями: Reassign (без кандидатов — снятие), RemoveReviewer или повторное добавление; в отчёт попадает результат каждого исправления.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.ConsistencyCheckRequest  false  "Режим: dry-run или apply"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.ConsistencyReportResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/admin/consistency [post]
+func (h *ConsistencyHandler) Check(c *gin.Context) {
+	log := logger(c)
+	var req dto.ConsistencyCheckRequest
+	// Пустое тело — dry-run.
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Warnw("invalid consistency check payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+
+	report, err := h.consistencySvc.Check(c.Request.Context(), requestOrigin(c), req.Apply)
+	if err != nil {
+		log.Errorw("consistency check failed", "apply", req.Apply, "error", err)
+		writeInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.MapConsistencyReportToDTO(report))
+	log.Infow("consistency check completed", "apply", req.Apply, "findings", len(report.Findings))
+}
@@ -1,9 +1,16 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
 
 const (
 	errorCodeBadRequest  = "BAD_REQUEST"
+	errorCodeValidation  = "VALIDATION"
 	errorCodeInternal    = "INTERNAL"
 	errorCodeNotFound    = "NOT_FOUND"
 	errorCodeTeamExists  = "TEAM_EXISTS"
@@ -11,8 +18,46 @@ const (
 	errorCodePRMerged    = "PR_MERGED"
 	errorCodeNotAssigned = "NOT_ASSIGNED"
 	errorCodeNoCandidate = "NO_CANDIDATE"
+	errorCodeTimeout     = "TIMEOUT"
+	errorCodeCanceled    = "CANCELED"
+
+	errorCodePreconditionFailed = "PRECONDITION_FAILED"
+	errorCodeConcurrentUpdate   = "CONCURRENT_UPDATE"
+	errorCodePlanStale          = "PLAN_STALE"
+	errorCodeJobFinished        = "JOB_FINISHED"
+
+	errorCodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	errorCodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+
+	errorCodeReviewerIsAuthor  = "REVIEWER_IS_AUTHOR"
+	errorCodeReviewerInactive  = "REVIEWER_INACTIVE"
+	errorCodeAlreadyAssigned   = "ALREADY_ASSIGNED"
+	errorCodeReviewerLimit     = "REVIEWER_LIMIT"
+	errorCodeReviewerWrongTeam = "REVIEWER_WRONG_TEAM"
+	errorCodeReviewerAbsent    = "REVIEWER_ABSENT"
+
+	errorCodeDelegateWrongTeam = "DELEGATE_WRONG_TEAM"
+	errorCodeDelegationOverlap = "DELEGATION_OVERLAP"
+	errorCodeAbsenceOverlap    = "ABSENCE_OVERLAP"
 )
 
+// statusClientClosedRequest — нестандартный статус nginx для запросов, которые клиент оборвал сам.
+const statusClientClosedRequest = 499
+
+// writeInternalError отвечает 500, если только ошибка не вызвана истёкшим дедлайном запроса
+// (504 TIMEOUT) или отключением клиента (499 CANCELED).
+func writeInternalError(c *gin.Context, err error) {
+	ctxErr := c.Request.Context().Err()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctxErr, context.DeadlineExceeded):
+		writeError(c, http.StatusGatewayTimeout, errorCodeTimeout, "request timed out")
+	case errors.Is(err, context.Canceled) || errors.Is(ctxErr, context.Canceled):
+		writeError(c, statusClientClosedRequest, errorCodeCanceled, "request canceled")
+	default:
+		writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+	}
+}
+
 func writeError(c *gin.Context, status int, code, message string) {
 	log := logger(c)
 	switch {
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// setPRETag отдаёт версию PR сильным ETag, чтобы клиент мог прислать её в If-Match следующего изменения.
+func setPRETag(c *gin.Context, pr *model.PullRequest) {
+	c.Header(etagHeader, `"`+strconv.FormatUint(uint64(pr.Version), 10)+`"`)
+}
+
+// ifMatchVersion возвращает версию PR из If-Match; без заголовка и для "*" проверки нет.
+// Слабые, составные и нераспознанные значения дают версию 0, которая не совпадает ни с одной (версии начинаются с 1).
+func ifMatchVersion(c *gin.Context) *uint {
+	value := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if value == "" || value == "*" {
+		return nil
+	}
+	var version uint
+	if tag, ok := strings.CutPrefix(value, `"`); ok {
+		if tag, ok = strings.CutSuffix(tag, `"`); ok {
+			if v, err := strconv.ParseUint(tag, 10, 0); err == nil {
+				version = uint(v)
+			}
+		}
+	}
+	return &version
+}
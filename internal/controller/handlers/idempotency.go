@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Leganyst/avitoTrainee/internal/service"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// replayedHeaders — заголовки ответа, которые сохраняются вместе с телом и отдаются при повторе.
+var replayedHeaders = []string{"Content-Type", etagHeader}
+
+// idempotencyMiddleware отдаёт сохранённый ответ на повтор изменяющего запроса с тем же Idempotency-Key.
+// Ответы 5xx и оборванные запросы не сохраняются: их повтор выполняется заново.
+func idempotencyMiddleware(idempotencySvc service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, "Idempotency-Key is too long")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, "failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		log := logger(c)
+		route := c.Request.Method + " " + c.FullPath()
+		fingerprint := requestFingerprint(c.Request, body)
+		record, err := idempotencySvc.Begin(c.Request.Context(), key, route, fingerprint)
+		switch {
+		case errors.Is(err, serviceerrs.ErrIdempotencyKeyReused):
+			writeError(c, http.StatusConflict, errorCodeIdempotencyKeyReused, err.Error())
+			c.Abort()
+			return
+		case errors.Is(err, serviceerrs.ErrIdempotencyInProgress):
+			writeError(c, http.StatusConflict, errorCodeIdempotencyInProgress, err.Error())
+			c.Abort()
+			return
+		case err != nil:
+			log.Errorw("idempotency key lookup failed", "key", key, "error", err)
+			writeInternalError(c, err)
+			c.Abort()
+			return
+		case record != nil:
+			var headers map[string]string
+			if err := json.Unmarshal([]byte(record.Headers), &headers); err != nil {
+				log.Warnw("stored idempotent headers are invalid", "key", key, "error", err)
+			}
+			for name, value := range headers {
+				c.Header(name, value)
+			}
+			c.Header(idempotencyReplayedHeader, "true")
+			c.Data(record.StatusCode, headers["Content-Type"], record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Контекст запроса к этому моменту может быть отменён, а сохранить результат всё равно нужно.
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == statusClientClosedRequest {
+			if err := idempotencySvc.Abort(ctx, key, route); err != nil {
+				log.Errorw("idempotency key release failed", "key", key, "error", err)
+			}
+			return
+		}
+
+		headers := make(map[string]string, len(replayedHeaders))
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encoded, _ := json.Marshal(headers)
+		// Ответ сохраняется уже после фиксации операции, отдельной записью: в транзакцию операции он не входит.
+		// Если запись не удалась или процесс упал до неё, резерв ключа истекает через lockTTL, и повтор выполнит
+		// операцию заново; от повторного изменения PR защищает If-Match.
+		if err := idempotencySvc.Complete(ctx, key, route, fingerprint, status, string(encoded), recorder.body.Bytes()); err != nil {
+			log.Errorw("idempotent response save failed", "key", key, "error", err)
+		}
+	}
+}
+
+// requestFingerprint отличает разные запросы под одним ключом: метод, путь, query, If-Match и тело.
+// If-Match входит в отпечаток, потому что от ожидаемой версии зависит результат изменения PR.
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery + "\n"))
+	h.Write([]byte(ifMatchHeader + ": " + req.Header.Get(ifMatchHeader) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder копирует тело ответа, продолжая писать его клиенту.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/mapper"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobSvc service.JobService
+}
+
+func NewJobHandler(jobSvc service.JobService) *JobHandler {
+	return &JobHandler{jobSvc: jobSvc}
+}
+
+func registerJobRoutes(r gin.IRouter, jobSvc service.JobService) {
+	handler := NewJobHandler(jobSvc)
+
+	group := r.Group("/jobs")
+	group.POST("/bulkDeactivate", handler.SubmitBulkDeactivate)
+	group.GET("/:id", handler.GetJob)
+	group.POST("/:id/cancel", handler.CancelJob)
+}
+
+// SubmitBulkDeactivate godoc
+// @Summary      Поставить массовую деактивацию в очередь
+// @Description  Создаёт фоновую задачу деактивации пользователей команды с переназначением их открытых PR и сразу возвращает её. Каждый пользователь — отдельный элемент, который выполняется и фиксируется в своей транзакции; заменой не выбирается никто из пользователей задачи. Прогресс и результаты — в GET /api/jobs/{id}.
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.BulkDeactivateJobRequest  true  "Команда и user_id"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      202      {object}  dto.JobResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/jobs/bulkDeactivate [post]
+func (h *JobHandler) SubmitBulkDeactivate(c *gin.Context) {
+	log := logger(c)
+	var req dto.BulkDeactivateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid bulk deactivate job payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	if req.TeamName == "" || len(req.UserIDs) == 0 {
+		log.Warnw("missing fields in bulk deactivate job", "payload", req)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "team_name and user_ids are required")
+		return
+	}
+
+	job, err := h.jobSvc.SubmitBulkDeactivate(c.Request.Context(), requestOrigin(c), req.TeamName, req.UserIDs)
+	if err != nil {
+		log.Errorw("submit bulk deactivate job failed", "team", req.TeamName, "error", err)
+		h.handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.JobResponse{Job: mapper.MapJobToDTO(job)})
+	log.Infow("bulk deactivate job submitted", "job_id", job.ID, "team", req.TeamName, "items", job.Total)
+}
+
+// GetJob godoc
+// @Summary      Получить фоновую задачу
+// @Description  Возвращает статус задачи, прогресс и результат каждого элемента.
+// @Tags         Jobs
+// @Produce      json
+// @Param        id   path      int  true  "Идентификатор задачи"
+// @Success      200  {object}  dto.JobResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Failure      504  {object}  dto.ErrorResponse
+// @Router       /api/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	log := logger(c)
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobSvc.GetJob(c.Request.Context(), id)
+	if err != nil {
+		log.Errorw("get job failed", "job_id", id, "error", err)
+		h.handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JobResponse{Job: mapper.MapJobToDTO(job)})
+	log.Infow("job fetched", "job_id", id, "status", job.Status, "processed", job.Processed, "total", job.Total)
+}
+
+// CancelJob godoc
+// @Summary      Отменить фоновую задачу
+// @Description  Задача из очереди отменяется сразу. Выполняемая задача останавливается перед следующим элементом: уже выполненные элементы остаются в силе, остальные помечаются canceled. Завершённую или уже отменённую задачу отменить нельзя.
+// @Tags         Jobs
+// @Produce      json
+// @Param        id   path      int  true  "Идентификатор задачи"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200  {object}  dto.JobResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Failure      504  {object}  dto.ErrorResponse
+// @Router       /api/jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	log := logger(c)
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobSvc.CancelJob(c.Request.Context(), id)
+	if err != nil {
+		log.Errorw("cancel job failed", "job_id", id, "error", err)
+		h.handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JobResponse{Job: mapper.MapJobToDTO(job)})
+	log.Infow("job cancel requested", "job_id", id, "status", job.Status)
+}
+
+// jobID читает идентификатор задачи из пути; при ошибке ответ уже записан.
+func jobID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		logger(c).Warnw("invalid job id", "id", c.Param("id"))
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "job id must be a positive integer")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *JobHandler) handleDomainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, serviceerrs.ErrJobNotFound),
+		errors.Is(err, serviceerrs.ErrTeamNotFound):
+		writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
+	case errors.Is(err, serviceerrs.ErrUserIDsRequired):
+		writeError(c, http.StatusBadRequest, errorCodeValidation, err.Error())
+	case errors.Is(err, serviceerrs.ErrJobFinished):
+		writeError(c, http.StatusConflict, errorCodeJobFinished, err.Error())
+	default:
+		writeInternalError(c, err)
+	}
+}
@@ -5,22 +5,34 @@ import (
 	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-const traceIDKey = "trace_id"
+const requestIDHeader = "X-Request-ID"
 
+// requestLoggerMiddleware кладёт X-Request-ID в контекст запроса, чтобы он вместе с trace_id
+// попадал в логи хэндлеров, сервисов и репозиториев. Без заголовка используется trace_id запроса.
 func requestLoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID := c.GetHeader("X-Request-ID")
-		if traceID == "" {
-			traceID = strconv.FormatInt(time.Now().UnixNano(), 10)
+		ctx := c.Request.Context()
+		span := trace.SpanFromContext(ctx)
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				requestID = sc.TraceID().String()
+			} else {
+				requestID = strconv.FormatInt(time.Now().UnixNano(), 10)
+			}
 		}
-		c.Set(traceIDKey, traceID)
-		c.Writer.Header().Set("X-Request-ID", traceID)
+		span.SetAttributes(attribute.String("http.request_id", requestID))
+		c.Request = c.Request.WithContext(config.WithRequestID(ctx, requestID))
+		c.Writer.Header().Set(requestIDHeader, requestID)
 
-		log := baseLogger().With("traceID", traceID)
+		log := logger(c)
 		start := time.Now()
 		log.Infow("request started",
 			"method", c.Request.Method,
@@ -29,8 +41,15 @@ func requestLoggerMiddleware() gin.HandlerFunc {
 
 		c.Next()
 
-		duration := time.Since(start).Milliseconds()
+		elapsed := time.Since(start)
+		duration := elapsed.Milliseconds()
 		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, status, elapsed)
+
 		switch {
 		case status >= 500:
 			log.Errorw("request finished", "status", status, "duration_ms", duration)
@@ -43,16 +62,5 @@ func requestLoggerMiddleware() gin.HandlerFunc {
 }
 
 func logger(c *gin.Context) *zap.SugaredLogger {
-	traceID, _ := c.Get(traceIDKey)
-	if traceID == nil {
-		return baseLogger()
-	}
-	return baseLogger().With("traceID", traceID)
-}
-
-func baseLogger() *zap.SugaredLogger {
-	if l := config.Logger(); l != nil {
-		return l
-	}
-	return zap.NewNop().Sugar()
+	return config.LoggerFrom(c.Request.Context())
 }
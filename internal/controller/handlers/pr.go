@@ -23,9 +23,59 @@ func registerPRRoutes(r gin.IRouter, prSvc service.PRService) {
 	handler := NewPRHandler(prSvc)
 
 	group := r.Group("/pullRequest")
+	group.GET("/get", handler.GetPR)
 	group.POST("/create", handler.CreatePR)
 	group.POST("/merge", handler.MergePR)
 	group.POST("/reassign", handler.ReassignReviewer)
+	group.POST("/reviewers/add", handler.AddReviewer)
+	group.POST("/reviewers/remove", handler.RemoveReviewer)
+	group.POST("/review", handler.SubmitReview)
+	group.POST("/decline", handler.Decline)
+}
+
+// GetPR godoc
+// @Summary      Получить PR
+// @Description  Возвращает PR с ревьюверами. С as_of — статус и состав ревьюверов на указанный момент.
+// @Tags         PullRequests
+// @Accept       json
+// @Produce      json
+// @Param        pull_request_id  query     string  true   "Идентификатор PR"
+// @Param        as_of            query     string  false  "Момент времени (RFC3339)"
+// @Success      200              {object}  dto.GetPRResponse
+// @Header       200              {string}  ETag  "Версия PR, без as_of"
+// @Failure      400              {object}  dto.ErrorResponse
+// @Failure      404              {object}  dto.ErrorResponse
+// @Failure      500              {object}  dto.ErrorResponse
+// @Failure      504              {object}  dto.ErrorResponse
+// @Router       /api/pullRequest/get [get]
+func (h *PRHandler) GetPR(c *gin.Context) {
+	log := logger(c)
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		log.Warnw("pull_request_id query parameter missing")
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "pull_request_id is required")
+		return
+	}
+	asOf, err := queryTime(c, "as_of")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "as_of must be RFC3339")
+		return
+	}
+	log.Debugw("get PR request", "pr_id", prID, "as_of", asOf)
+
+	pr, err := h.prSvc.GetPR(c.Request.Context(), prID, asOf)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if asOf == nil {
+		setPRETag(c, pr)
+	}
+	c.JSON(http.StatusOK, dto.GetPRResponse{
+		PR: mapper.MapPullRequestToDTO(*pr),
+	})
+	log.Infow("PR fetched", "pr_id", pr.PRID, "as_of", asOf, "reviewers", len(pr.AssignedReviewers))
 }
 
 // CreatePR godoc
@@ -35,11 +85,14 @@ func registerPRRoutes(r gin.IRouter, prSvc service.PRService) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.CreatePRRequest  true  "Данные PR"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      201      {object}  dto.CreatePRResponse
+// @Header       201      {string}  ETag  "Версия PR"
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
 // @Failure      409      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/pullRequest/create [post]
 func (h *PRHandler) CreatePR(c *gin.Context) {
 	log := logger(c)
@@ -57,13 +110,14 @@ func (h *PRHandler) CreatePR(c *gin.Context) {
 	}
 	log.Debugw("create PR request", "payload", req)
 
-	pr, err := h.prSvc.CreatePR(req.PRID, req.Name, req.Author)
+	pr, err := h.prSvc.CreatePR(c.Request.Context(), requestOrigin(c), req.PRID, req.Name, req.Author)
 	if err != nil {
 		log.Errorw("failed to create PR", "pr_id", req.PRID, "author", req.Author, "error", err)
 		h.handleError(c, err)
 		return
 	}
 
+	setPRETag(c, pr)
 	c.JSON(http.StatusCreated, dto.CreatePRResponse{
 		PR: mapper.MapPullRequestToDTO(*pr),
 	})
@@ -77,10 +131,16 @@ func (h *PRHandler) CreatePR(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.MergePRRequest  true  "Идентификатор PR"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      200      {object}  dto.MergePRResponse
+// @Header       200      {string}  ETag  "Версия PR"
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/pullRequest/merge [post]
 func (h *PRHandler) MergePR(c *gin.Context) {
 	log := logger(c)
@@ -97,13 +157,14 @@ func (h *PRHandler) MergePR(c *gin.Context) {
 	}
 	log.Debugw("merge PR request", "payload", req)
 
-	pr, err := h.prSvc.Merge(req.PRID)
+	pr, err := h.prSvc.Merge(c.Request.Context(), requestOrigin(c), req.PRID)
 	if err != nil {
 		log.Errorw("failed to merge PR", "pr_id", req.PRID, "error", err)
 		h.handleError(c, err)
 		return
 	}
 
+	setPRETag(c, pr)
 	c.JSON(http.StatusOK, dto.MergePRResponse{
 		PR: mapper.MapPullRequestToDTO(*pr),
 	})
@@ -112,16 +173,21 @@ func (h *PRHandler) MergePR(c *gin.Context) {
 
 // ReassignReviewer godoc
 // @Summary      Переназначить ревьювера
-// @Description  Заменяет ревьювера на другого активного участника его команды.
+// @Description  Заменяет ревьювера на другого активного участника команды автора PR. Если передан new_user_id, назначается именно он.
 // @Tags         PullRequests
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.ReassgnRequest  true  "Параметры переназначения"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      200      {object}  dto.ReassignResponse
+// @Header       200      {string}  ETag  "Версия PR"
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
 // @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/pullRequest/reassign [post]
 func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 	log := logger(c)
@@ -138,13 +204,14 @@ func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 	}
 	log.Debugw("reassign request", "payload", req)
 
-	pr, replacedBy, err := h.prSvc.Reassign(req.PRID, req.OldUserID)
+	pr, replacedBy, err := h.prSvc.Reassign(c.Request.Context(), requestOrigin(c), req.PRID, req.OldUserID, req.NewUserID)
 	if err != nil {
 		log.Errorw("failed to reassign reviewer", "pr_id", req.PRID, "old_user", req.OldUserID, "error", err)
 		h.handleError(c, err)
 		return
 	}
 
+	setPRETag(c, pr)
 	c.JSON(http.StatusOK, dto.ReassignResponse{
 		PR:         mapper.MapPullRequestToDTO(*pr),
 		ReplacedBy: replacedBy,
@@ -152,6 +219,175 @@ func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 	log.Infow("reviewer reassigned", "pr_id", pr.PRID, "old_user", req.OldUserID, "new_user", replacedBy)
 }
 
+// AddReviewer godoc
+// @Summary      Добавить ревьювера
+// @Description  Вручную добавляет ревьювера в открытый PR. Ревьювер должен быть активным участником команды автора, не автором и не превышать лимит команды.
+// @Tags         PullRequests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.AddReviewerRequest  true  "PR и добавляемый ревьювер"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.AddReviewerResponse
+// @Header       200      {string}  ETag  "Версия PR"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/pullRequest/reviewers/add [post]
+func (h *PRHandler) AddReviewer(c *gin.Context) {
+	log := logger(c)
+	var req dto.AddReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid add reviewer payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("add reviewer request", "payload", req)
+
+	pr, err := h.prSvc.AddReviewer(c.Request.Context(), requestOrigin(c), req.PRID, req.UserID)
+	if err != nil {
+		log.Errorw("failed to add reviewer", "pr_id", req.PRID, "user_id", req.UserID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	setPRETag(c, pr)
+	c.JSON(http.StatusOK, dto.AddReviewerResponse{
+		PR: mapper.MapPullRequestToDTO(*pr),
+	})
+	log.Infow("reviewer added", "pr_id", pr.PRID, "user_id", req.UserID)
+}
+
+// RemoveReviewer godoc
+// @Summary      Снять ревьювера
+// @Description  Убирает ревьювера из открытого PR без назначения замены.
+// @Tags         PullRequests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.RemoveReviewerRequest  true  "PR и снимаемый ревьювер"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.RemoveReviewerResponse
+// @Header       200      {string}  ETag  "Версия PR"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/pullRequest/reviewers/remove [post]
+func (h *PRHandler) RemoveReviewer(c *gin.Context) {
+	log := logger(c)
+	var req dto.RemoveReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid remove reviewer payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("remove reviewer request", "payload", req)
+
+	pr, err := h.prSvc.RemoveReviewer(c.Request.Context(), requestOrigin(c), req.PRID, req.UserID)
+	if err != nil {
+		log.Errorw("failed to remove reviewer", "pr_id", req.PRID, "user_id", req.UserID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	setPRETag(c, pr)
+	c.JSON(http.StatusOK, dto.RemoveReviewerResponse{
+		PR: mapper.MapPullRequestToDTO(*pr),
+	})
+	log.Infow("reviewer removed", "pr_id", pr.PRID, "user_id", req.UserID)
+}
+
+// Decline godoc
+// @Summary      Отказаться от ревью
+// @Description  Снимает ревьювера по его отказу и подбирает замену по правилам команды. Если кандидатов нет, ревьювер просто снимается с PR. В журнале и статистике замен отмечается причиной decline.
+// @Tags         PullRequests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.DeclineRequest  true  "PR и ревьювер"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.DeclineResponse
+// @Header       200      {string}  ETag  "Версия PR"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/pullRequest/decline [post]
+func (h *PRHandler) Decline(c *gin.Context) {
+	log := logger(c)
+	var req dto.DeclineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid decline payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("decline request", "payload", req)
+
+	pr, replacedBy, err := h.prSvc.Decline(c.Request.Context(), requestOrigin(c), req.PRID, req.UserID)
+	if err != nil {
+		log.Errorw("failed to decline review", "pr_id", req.PRID, "user_id", req.UserID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	setPRETag(c, pr)
+	c.JSON(http.StatusOK, dto.DeclineResponse{
+		PR:         mapper.MapPullRequestToDTO(*pr),
+		ReplacedBy: replacedBy,
+	})
+	log.Infow("review declined", "pr_id", pr.PRID, "user_id", req.UserID, "replaced_by", replacedBy)
+}
+
+// SubmitReview godoc
+// @Summary      Отметить ревью-действие
+// @Description  Фиксирует ревью-действие назначенного ревьювера в открытом PR. В метриках учитывается только первое действие в назначении, повторные вызовы ничего не меняют.
+// @Tags         PullRequests
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.SubmitReviewRequest  true  "PR и ревьювер"
+// @Param        If-Match  header    string  false  "ETag PR, полученный ранее"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.SubmitReviewResponse
+// @Header       200      {string}  ETag  "Версия PR"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      412      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/pullRequest/review [post]
+func (h *PRHandler) SubmitReview(c *gin.Context) {
+	log := logger(c)
+	var req dto.SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid submit review payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("submit review request", "payload", req)
+
+	pr, err := h.prSvc.SubmitReview(c.Request.Context(), requestOrigin(c), req.PRID, req.UserID)
+	if err != nil {
+		log.Errorw("failed to submit review", "pr_id", req.PRID, "user_id", req.UserID, "error", err)
+		h.handleError(c, err)
+		return
+	}
+
+	setPRETag(c, pr)
+	c.JSON(http.StatusOK, dto.SubmitReviewResponse{
+		PR: mapper.MapPullRequestToDTO(*pr),
+	})
+	log.Infow("review submitted", "pr_id", pr.PRID, "user_id", req.UserID)
+}
+
 func (h *PRHandler) handleError(c *gin.Context, err error) {
 	log := logger(c)
 	switch {
@@ -162,6 +398,12 @@ func (h *PRHandler) handleError(c *gin.Context, err error) {
 	case errors.Is(err, serviceerrs.ErrPRExists):
 		log.Warnw("PR already exists", "error", err)
 		writeError(c, http.StatusConflict, errorCodePRExists, err.Error())
+	case errors.Is(err, serviceerrs.ErrVersionMismatch):
+		log.Warnw("PR version precondition failed", "error", err)
+		writeError(c, http.StatusPreconditionFailed, errorCodePreconditionFailed, err.Error())
+	case errors.Is(err, serviceerrs.ErrConcurrentUpdate):
+		log.Warnw("PR modified concurrently", "error", err)
+		writeError(c, http.StatusConflict, errorCodeConcurrentUpdate, err.Error())
 	case errors.Is(err, serviceerrs.ErrPRMerged):
 		log.Warnw("operation on merged PR", "error", err)
 		writeError(c, http.StatusConflict, errorCodePRMerged, err.Error())
@@ -171,8 +413,26 @@ func (h *PRHandler) handleError(c *gin.Context, err error) {
 	case errors.Is(err, serviceerrs.ErrNoCandidates):
 		log.Warnw("no candidates for reassignment", "error", err)
 		writeError(c, http.StatusConflict, errorCodeNoCandidate, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerIsAuthor):
+		log.Warnw("author proposed as reviewer", "error", err)
+		writeError(c, http.StatusConflict, errorCodeReviewerIsAuthor, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerInactive):
+		log.Warnw("inactive user proposed as reviewer", "error", err)
+		writeError(c, http.StatusConflict, errorCodeReviewerInactive, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerAssigned):
+		log.Warnw("reviewer already assigned", "error", err)
+		writeError(c, http.StatusConflict, errorCodeAlreadyAssigned, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerLimit):
+		log.Warnw("reviewer limit reached", "error", err)
+		writeError(c, http.StatusConflict, errorCodeReviewerLimit, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerWrongTeam):
+		log.Warnw("reviewer from ineligible team", "error", err)
+		writeError(c, http.StatusConflict, errorCodeReviewerWrongTeam, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerAbsent):
+		log.Warnw("absent user proposed as reviewer", "error", err)
+		writeError(c, http.StatusConflict, errorCodeReviewerAbsent, err.Error())
 	default:
 		log.Errorw("internal PR handler error", "error", err)
-		writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+		writeInternalError(c, err)
 	}
 }
@@ -1,25 +1,49 @@
 package handlers
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
 	"github.com/Leganyst/avitoTrainee/internal/service"
+	"github.com/Leganyst/avitoTrainee/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // RegisterRoutes прокидывает зависимости в хэндлеры и вешает эндпоинты.
+// requestTimeout задаёт дедлайн каждого запроса, 0 отключает его.
 func RegisterRoutes(r *gin.Engine,
+	requestTimeout time.Duration,
 	teamSvc service.TeamService,
 	userSvc service.UserService,
 	prSvc service.PRService,
 	statsSvc service.StatsService,
+	availabilitySvc service.AvailabilityService,
+	auditSvc service.AuditService,
+	idempotencySvc service.IdempotencyService,
+	consistencySvc service.ConsistencyService,
+	jobSvc service.JobService,
 ) {
-	r.Use(requestLoggerMiddleware())
+	r.Use(
+		otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(req *http.Request) bool {
+			return req.URL.Path != "/metrics" && req.URL.Path != "/healthcheck"
+		})),
+		requestLoggerMiddleware(),
+		requestTimeoutMiddleware(requestTimeout),
+	)
 
 	r.GET("/healthcheck", healthCheckHandler)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	api := r.Group("/api")
+	api := r.Group("/api", idempotencyMiddleware(idempotencySvc))
 
 	registerTeamRoutes(api, teamSvc)
 	registerUserRoutes(api, userSvc)
 	registerPRRoutes(api, prSvc)
 	registerStatsRoutes(api, statsSvc)
+	registerAvailabilityRoutes(api, availabilitySvc)
+	registerAuditRoutes(api, auditSvc)
+	registerConsistencyRoutes(api, consistencySvc)
+	registerJobRoutes(api, jobSvc)
 }
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
 	"github.com/Leganyst/avitoTrainee/internal/mapper"
 	"github.com/Leganyst/avitoTrainee/internal/service"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,46 +26,299 @@ func registerStatsRoutes(r gin.IRouter, statsSvc service.StatsService) {
 
 	group.GET("/assignments/by-user", handler.AssignmentsByUser)
 	group.GET("/assignments/by-pr", handler.AssignmentsByPR)
+	group.GET("/sla", handler.ReviewSLA)
+	group.GET("/durations", handler.ReviewDurations)
+	group.GET("/fairness", handler.Fairness)
+	group.GET("/reassignments/by-user", handler.ReplacementsByUser)
+	group.GET("/reassignments/by-pr", handler.PRChurn)
 }
 
 // AssignmentsByUser godoc
 // @Summary      Статистика назначений по пользователям
-// @Description  Возвращает список пользователей с количеством назначений на ревью. Пользователи сортируются по количеству назначений по убыванию.
+// @Description  Возвращает страницу пользователей с количеством текущих назначений на ревью. Фильтры относятся к PR, на которые назначены ревьюверы. По умолчанию сортировка по количеству назначений по убыванию.
 // @Tags         Stats
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  dto.AssignmentByUserResponse
-// @Failure      500  {object}  dto.ErrorResponse
+// @Param        team_name  query     string  false  "Команда автора PR"
+// @Param        status     query     string  false  "Статус PR: OPEN или MERGED"
+// @Param        author_id  query     string  false  "user_id автора PR"
+// @Param        from       query     string  false  "PR, созданные не раньше (RFC3339, начало суток UTC)"
+// @Param        to         query     string  false  "PR, созданные раньше (RFC3339, начало суток UTC)"
+// @Param        limit      query     int     false  "Размер страницы (по умолчанию 50, не больше 500)"
+// @Param        cursor     query     string  false  "Курсор следующей страницы из next_cursor"
+// @Param        sort       query     string  false  "assignments_desc (по умолчанию), assignments_asc, user_id_asc, user_id_desc"
+// @Success      200        {object}  dto.AssignmentByUserResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
 // @Router       /api/stats/assignments/by-user [get]
 func (h *StatsHandler) AssignmentsByUser(c *gin.Context) {
-	stats, err := h.statsSvc.AssignmentsByUser()
+	query, ok := assignmentQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, next, err := h.statsSvc.AssignmentsByUser(c.Request.Context(), query)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+		handleStatsError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.AssignmentByUserResponse{
-		Items: mapper.MapAssignmentsByUser(stats),
+		Items:      mapper.MapAssignmentsByUser(stats),
+		NextCursor: next,
 	})
 }
 
 // AssignmentsByPR godoc
 // @Summary      Статистика назначений по PR
-// @Description  Возвращает список PR с количеством назначенных ревьюверов. Список отсортирован по числу ревьюверов по убыванию.
+// @Description  Возвращает страницу PR с количеством назначенных ревьюверов. По умолчанию сортировка по числу ревьюверов по убыванию.
 // @Tags         Stats
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  dto.AssignmentByPRResponse
-// @Failure      500  {object}  dto.ErrorResponse
+// @Param        team_name  query     string  false  "Команда автора PR"
+// @Param        status     query     string  false  "Статус PR: OPEN или MERGED"
+// @Param        author_id  query     string  false  "user_id автора PR"
+// @Param        from       query     string  false  "PR, созданные не раньше (RFC3339)"
+// @Param        to         query     string  false  "PR, созданные раньше (RFC3339)"
+// @Param        limit      query     int     false  "Размер страницы (по умолчанию 50, не больше 500)"
+// @Param        cursor     query     string  false  "Курсор следующей страницы из next_cursor"
+// @Param        sort       query     string  false  "reviewers_desc (по умолчанию), reviewers_asc, pr_id_asc, pr_id_desc, created_at_desc, created_at_asc"
+// @Success      200        {object}  dto.AssignmentByPRResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
 // @Router       /api/stats/assignments/by-pr [get]
 func (h *StatsHandler) AssignmentsByPR(c *gin.Context) {
-	stats, err := h.statsSvc.AssignmentsByPR()
+	query, ok := assignmentQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, next, err := h.statsSvc.AssignmentsByPR(c.Request.Context(), query)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+		handleStatsError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.AssignmentByPRResponse{
-		Items: mapper.MapAssignmentsByPR(stats),
+		Items:      mapper.MapAssignmentsByPR(stats),
+		NextCursor: next,
+	})
+}
+
+// assignmentQuery разбирает фильтры статистики назначений; при ошибке ответ уже записан.
+func assignmentQuery(c *gin.Context) (service.AssignmentQuery, bool) {
+	query := service.AssignmentQuery{
+		TeamName: c.Query("team_name"),
+		Status:   c.Query("status"),
+		AuthorID: c.Query("author_id"),
+		Sort:     c.Query("sort"),
+		Cursor:   c.Query("cursor"),
+	}
+
+	var err error
+	if query.From, err = queryTime(c, "from"); err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "from must be RFC3339")
+		return query, false
+	}
+	if query.To, err = queryTime(c, "to"); err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "to must be RFC3339")
+		return query, false
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, "limit must be a non-negative integer")
+			return query, false
+		}
+		query.Limit = limit
+	}
+	return query, true
+}
+
+func handleStatsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, serviceerrs.ErrInvalidStatus),
+		errors.Is(err, serviceerrs.ErrInvalidSort),
+		errors.Is(err, serviceerrs.ErrInvalidCursor),
+		errors.Is(err, serviceerrs.ErrInvalidGroupBy),
+		errors.Is(err, serviceerrs.ErrInvalidPeriod),
+		errors.Is(err, serviceerrs.ErrPeriodNotDays):
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+	case errors.Is(err, serviceerrs.ErrTeamNotFound):
+		writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
+	default:
+		writeInternalError(c, err)
+	}
+}
+
+// ReviewSLA godoc
+// @Summary      SLA ревью в рабочих часах
+// @Description  Для каждого ревьювера открытых PR возвращает, сколько его рабочих часов (с учётом часового пояса, рабочего окна и выходных) прошло с назначения, и нарушен ли SLA команды автора. Сортировка по времени ожидания по убыванию.
+// @Tags         Stats
+// @Accept       json
+// @Produce      json
+// @Param        breached_only  query     bool  false  "Только нарушения SLA"
+// @Success      200            {object}  dto.ReviewSLAResponse
+// @Failure      400            {object}  dto.ErrorResponse
+// @Failure      500            {object}  dto.ErrorResponse
+// @Failure      504            {object}  dto.ErrorResponse
+// @Router       /api/stats/sla [get]
+func (h *StatsHandler) ReviewSLA(c *gin.Context) {
+	breachedOnly := false
+	if raw := c.Query("breached_only"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, "breached_only must be a boolean")
+			return
+		}
+		breachedOnly = parsed
+	}
+
+	stats, err := h.statsSvc.ReviewSLA(c.Request.Context(), breachedOnly)
+	if err != nil {
+		writeInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ReviewSLAResponse{
+		Items: mapper.MapReviewSLA(stats),
+	})
+}
+
+// ReviewDurations godoc
+// @Summary      Метрики длительности ревью по неделям
+// @Description  Время от создания PR до merge, до первого ревью-действия и время до замены ревьювера, с разбивкой по неделям (понедельник, UTC). group_by=team группирует по команде автора PR, group_by=user — по автору (merge) и ревьюверу (ревью, замены). Без from/to берутся последние 12 недель.
+// @Tags         Stats
+// @Accept       json
+// @Produce      json
+// @Param        group_by  query     string  false  "team (по умолчанию) или user"
+// @Param        from      query     string  false  "Начало периода (RFC3339, включительно)"
+// @Param        to        query     string  false  "Конец периода (RFC3339, не включительно)"
+// @Success      200       {object}  dto.ReviewDurationsResponse
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Failure      504       {object}  dto.ErrorResponse
+// @Router       /api/stats/durations [get]
+func (h *StatsHandler) ReviewDurations(c *gin.Context) {
+	from, err := queryTime(c, "from")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "from must be RFC3339")
+		return
+	}
+	to, err := queryTime(c, "to")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "to must be RFC3339")
+		return
+	}
+
+	report, err := h.statsSvc.ReviewDurations(c.Request.Context(), c.Query("group_by"), from, to)
+	if err != nil {
+		handleStatsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.MapReviewDurations(*report))
+}
+
+// Fairness godoc
+// @Summary      Равномерность нагрузки в команде
+// @Description  Для каждого участника — открытые и все назначения за историю и отклонение от среднего; коэффициенты Джини и список активных участников, которые ни разу не назначались. Среднее и Джини считаются по активным участникам.
+// @Tags         Stats
+// @Accept       json
+// @Produce      json
+// @Param        team_name  query     string  true  "Имя команды"
+// @Success      200        {object}  dto.FairnessResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      404        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
+// @Router       /api/stats/fairness [get]
+func (h *StatsHandler) Fairness(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "team_name is required")
+		return
+	}
+
+	report, err := h.statsSvc.Fairness(c.Request.Context(), teamName)
+	if err != nil {
+		handleStatsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.MapFairness(*report))
+}
+
+// ReplacementsByUser godoc
+// @Summary      Частота снятий ревьюверов по пользователям
+// @Description  По журналу назначений считает, сколько раз каждого пользователя снимали с ревью, всего и по причинам (reassign, manual, delegation, absence, bulk_deactivate, decline, sla_escalation, consistency_repair). Сортировка по общему числу снятий по убыванию.
+// @Tags         Stats
+// @Accept       json
+// @Produce      json
+// @Param        team_name  query     string  false  "Команда автора PR"
+// @Param        from       query     string  false  "Снятия не раньше (RFC3339)"
+// @Param        to         query     string  false  "Снятия раньше (RFC3339)"
+// @Success      200        {object}  dto.UserReplacementsResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
+// @Router       /api/stats/reassignments/by-user [get]
+func (h *StatsHandler) ReplacementsByUser(c *gin.Context) {
+	from, err := queryTime(c, "from")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "from must be RFC3339")
+		return
+	}
+	to, err := queryTime(c, "to")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "to must be RFC3339")
+		return
+	}
+
+	stats, err := h.statsSvc.ReplacementsByUser(c.Request.Context(), c.Query("team_name"), from, to)
+	if err != nil {
+		handleStatsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UserReplacementsResponse{Items: mapper.MapUserReplacements(stats)})
+}
+
+// PRChurn godoc
+// @Summary      PR с наибольшей сменой ревьюверов
+// @Description  Возвращает страницу PR, с которых хотя бы раз снимали ревьювера: сколько разных ревьюверов было у PR и сколько было снятий. Фильтры такие же, как у статистики назначений. По умолчанию сортировка по числу снятий по убыванию.
+// @Tags         Stats
+// @Accept       json
+// @Produce      json
+// @Param        team_name  query     string  false  "Команда автора PR"
+// @Param        status     query     string  false  "Статус PR: OPEN или MERGED"
+// @Param        author_id  query     string  false  "user_id автора PR"
+// @Param        from       query     string  false  "PR, созданные не раньше (RFC3339)"
+// @Param        to         query     string  false  "PR, созданные раньше (RFC3339)"
+// @Param        limit      query     int     false  "Размер страницы (по умолчанию 50, не больше 500)"
+// @Param        cursor     query     string  false  "Курсор следующей страницы из next_cursor"
+// @Param        sort       query     string  false  "removals_desc (по умолчанию), removals_asc, pr_id_asc, pr_id_desc, created_at_desc, created_at_asc"
+// @Success      200        {object}  dto.PRChurnResponse
+// @Failure      400        {object}  dto.ErrorResponse
+// @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
+// @Router       /api/stats/reassignments/by-pr [get]
+func (h *StatsHandler) PRChurn(c *gin.Context) {
+	query, ok := assignmentQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, next, err := h.statsSvc.PRChurn(c.Request.Context(), query)
+	if err != nil {
+		handleStatsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PRChurnResponse{
+		Items:      mapper.MapPRChurn(stats),
+		NextCursor: next,
 	})
 }
@@ -25,6 +25,7 @@ func registerTeamRoutes(r gin.IRouter, teamSvc service.TeamService) {
 	group := r.Group("/team")
 	group.POST("/add", handler.CreateTeam)
 	group.GET("/get", handler.GetTeam)
+	group.POST("/setMaxReviewers", handler.SetMaxReviewers)
 }
 
 // CreateTeam godoc
@@ -34,9 +35,12 @@ func registerTeamRoutes(r gin.IRouter, teamSvc service.TeamService) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.CreateTeamRequest  true  "Данные команды"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      201      {object}  dto.TeamResponse
 // @Failure      400      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/team/add [post]
 func (h *TeamHandler) CreateTeam(c *gin.Context) {
 	log := logger(c)
@@ -53,27 +57,26 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 	}
 	log.Debugw("create team payload", "request", req)
 
-	members := mapper.MapTeamMemberDTOsToUsers(req.Members)
-	team, err := h.teamSvc.CreateTeam(req.TeamName, members)
+	team, err := h.teamSvc.CreateTeam(c.Request.Context(), mapper.MapCreateTeamRequestToModel(req))
 	if err != nil {
 		switch {
 		case errors.Is(err, serviceerrs.ErrTeamExists):
 			log.Warnw("team already exists", "team_name", req.TeamName)
 			writeError(c, http.StatusBadRequest, errorCodeTeamExists, err.Error())
+		case errors.Is(err, serviceerrs.ErrInvalidAssignmentMode),
+			errors.Is(err, serviceerrs.ErrInvalidTimeZone),
+			errors.Is(err, serviceerrs.ErrInvalidWorkingHours):
+			log.Warnw("invalid team settings", "team_name", req.TeamName, "error", err)
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
 		default:
 			log.Errorw("failed to create team", "team_name", req.TeamName, "error", err)
-			writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+			writeInternalError(c, err)
 		}
 		return
 	}
 
-	teamDTO := dto.Team{
-		TeamName: team.Name,
-		Members:  mapper.MapUsersToTeamMemberDTO(team.Users),
-	}
-
 	c.JSON(http.StatusCreated, dto.TeamResponse{
-		Team: teamDTO,
+		Team: mapper.MapTeamToDTO(*team),
 	})
 	log.Infow("team created", "team_name", team.Name, "members", len(team.Users))
 }
@@ -89,6 +92,7 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 // @Failure      400        {object}  dto.ErrorResponse
 // @Failure      404        {object}  dto.ErrorResponse
 // @Failure      500        {object}  dto.ErrorResponse
+// @Failure      504        {object}  dto.ErrorResponse
 // @Router       /api/team/get [get]
 func (h *TeamHandler) GetTeam(c *gin.Context) {
 	log := logger(c)
@@ -100,7 +104,7 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 	}
 	log.Debugw("get team request", "team_name", teamName)
 
-	team, err := h.teamSvc.GetTeam(teamName)
+	team, err := h.teamSvc.GetTeam(c.Request.Context(), teamName)
 	if err != nil {
 		switch {
 		case errors.Is(err, serviceerrs.ErrTeamNotFound):
@@ -108,14 +112,57 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 			writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
 		default:
 			log.Errorw("failed to get team", "team_name", teamName, "error", err)
-			writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+			writeInternalError(c, err)
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.Team{
-		TeamName: team.Name,
-		Members:  mapper.MapUsersToTeamMemberDTO(team.Users),
-	})
+	c.JSON(http.StatusOK, mapper.MapTeamToDTO(*team))
 	log.Infow("team fetched", "team_name", team.Name, "members", len(team.Users))
 }
+
+// SetMaxReviewers godoc
+// @Summary      Изменить лимит ревьюверов команды
+// @Description  Задаёт максимум ревьюверов на PR для команды. PR, в которых ревьюверов больше нового лимита, не меняются, но новых ревьюверов в них не назначить, пока их не станет меньше лимита.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.MaxReviewersRequest  true  "Команда и новый лимит"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.TeamResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/team/setMaxReviewers [post]
+func (h *TeamHandler) SetMaxReviewers(c *gin.Context) {
+	log := logger(c)
+	var req dto.MaxReviewersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid SetMaxReviewers payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("set max reviewers request", "payload", req)
+
+	team, err := h.teamSvc.SetMaxReviewers(c.Request.Context(), req.TeamName, req.MaxReviewers)
+	if err != nil {
+		switch {
+		case errors.Is(err, serviceerrs.ErrTeamNotFound):
+			log.Warnw("team not found", "team_name", req.TeamName)
+			writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
+		case errors.Is(err, serviceerrs.ErrInvalidMaxReviewers):
+			log.Warnw("invalid max reviewers", "team_name", req.TeamName, "max_reviewers", req.MaxReviewers)
+			writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		default:
+			log.Errorw("failed to set max reviewers", "team_name", req.TeamName, "error", err)
+			writeInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TeamResponse{
+		Team: mapper.MapTeamToDTO(*team),
+	})
+	log.Infow("team max reviewers updated", "team_name", team.Name, "max_reviewers", team.MaxReviewers)
+}
@@ -24,6 +24,7 @@ func registerUserRoutes(r gin.IRouter, userSvc service.UserService) {
 
 	group := r.Group("/users")
 	group.POST("/setIsActive", handler.SetActive)
+	group.POST("/setWorkingHours", handler.SetWorkingHours)
 	group.GET("/getReview", handler.GetUserReviews)
 	group.POST("/bulkDeactivate", handler.BulkDeactivate)
 }
@@ -35,10 +36,13 @@ func registerUserRoutes(r gin.IRouter, userSvc service.UserService) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.UserRequest  true  "Параметры активности"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      200      {object}  dto.UserResponse
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/users/setIsActive [post]
 func (h *UserHandler) SetActive(c *gin.Context) {
 	log := logger(c)
@@ -50,7 +54,7 @@ func (h *UserHandler) SetActive(c *gin.Context) {
 	}
 	log.Debugw("set active request", "payload", req)
 
-	user, err := h.userSvc.SetActive(req.UserID, *req.IsActive)
+	user, err := h.userSvc.SetActive(c.Request.Context(), requestOrigin(c), req.UserID, *req.IsActive)
 	if err != nil {
 		log.Errorw("failed to update user activity", "user_id", req.UserID, "is_active", req.IsActive, "error", err)
 		h.handleDomainError(c, err)
@@ -63,17 +67,57 @@ func (h *UserHandler) SetActive(c *gin.Context) {
 	log.Infow("user activity updated", "user_id", req.UserID, "is_active", req.IsActive)
 }
 
+// SetWorkingHours godoc
+// @Summary      Обновить рабочее время пользователя
+// @Description  Задаёт часовой пояс и рабочее окно (пн–пт) пользователя. Используется режимом выбора working_hours и расчётом SLA.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.WorkingHoursRequest  true  "Часовой пояс и рабочее окно"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
+// @Success      200      {object}  dto.UserResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
+// @Router       /api/users/setWorkingHours [post]
+func (h *UserHandler) SetWorkingHours(c *gin.Context) {
+	log := logger(c)
+	var req dto.WorkingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnw("invalid SetWorkingHours payload", "error", err)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "invalid request payload")
+		return
+	}
+	log.Debugw("set working hours request", "payload", req)
+
+	user, err := h.userSvc.SetWorkingHours(c.Request.Context(), req.UserID, req.TimeZone, req.WorkStart, req.WorkEnd)
+	if err != nil {
+		log.Errorw("failed to update working hours", "user_id", req.UserID, "error", err)
+		h.handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UserResponse{
+		User: mapper.MapUserToDTO(*user),
+	})
+	log.Infow("user working hours updated", "user_id", req.UserID, "time_zone", user.TimeZone)
+}
+
 // GetUserReviews godoc
 // @Summary      Получить PR пользователя
-// @Description  Возвращает PR, где пользователь выступает ревьювером.
+// @Description  Возвращает PR, где пользователь выступает ревьювером. С as_of — PR, где он был ревьювером в указанный момент, со статусом на тот момент.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
-// @Param        user_id  query     string  true  "Идентификатор пользователя"
+// @Param        user_id  query     string  true   "Идентификатор пользователя"
+// @Param        as_of    query     string  false  "Момент времени (RFC3339): вернуть PR, где пользователь был ревьювером тогда"
 // @Success      200      {object}  dto.UserReviewResponse
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/users/getReview [get]
 func (h *UserHandler) GetUserReviews(c *gin.Context) {
 	log := logger(c)
@@ -83,9 +127,14 @@ func (h *UserHandler) GetUserReviews(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "user_id is required")
 		return
 	}
-	log.Debugw("get user reviews request", "user_id", userID)
+	asOf, err := queryTime(c, "as_of")
+	if err != nil {
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "as_of must be RFC3339")
+		return
+	}
+	log.Debugw("get user reviews request", "user_id", userID, "as_of", asOf)
 
-	prs, err := h.userSvc.GetUserReviews(userID)
+	prs, err := h.userSvc.GetUserReviews(c.Request.Context(), userID, asOf)
 	if err != nil {
 		log.Errorw("failed to get user reviews", "user_id", userID, "error", err)
 		h.handleDomainError(c, err)
@@ -103,22 +152,43 @@ func (h *UserHandler) handleDomainError(c *gin.Context, err error) {
 		writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
 	case errors.Is(err, serviceerrs.ErrTeamNotFound):
 		writeError(c, http.StatusNotFound, errorCodeNotFound, err.Error())
+	case errors.Is(err, serviceerrs.ErrInvalidTimeZone),
+		errors.Is(err, serviceerrs.ErrInvalidWorkingHours):
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+	case errors.Is(err, serviceerrs.ErrUserIDsRequired):
+		writeError(c, http.StatusBadRequest, errorCodeValidation, err.Error())
+	case errors.Is(err, serviceerrs.ErrInvalidPlanToken):
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+	case errors.Is(err, serviceerrs.ErrPlanStale):
+		writeError(c, http.StatusConflict, errorCodePlanStale, err.Error())
+	case errors.Is(err, serviceerrs.ErrConcurrentUpdate):
+		writeError(c, http.StatusConflict, errorCodeConcurrentUpdate, err.Error())
+	// Замены при массовой деактивации подбирает сервис, но БД может отклонить их по инвариантам ревьюверов.
+	case errors.Is(err, serviceerrs.ErrReviewerIsAuthor):
+		writeError(c, http.StatusConflict, errorCodeReviewerIsAuthor, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerLimit):
+		writeError(c, http.StatusConflict, errorCodeReviewerLimit, err.Error())
+	case errors.Is(err, serviceerrs.ErrReviewerWrongTeam):
+		writeError(c, http.StatusConflict, errorCodeReviewerWrongTeam, err.Error())
 	default:
-		writeError(c, http.StatusInternalServerError, errorCodeInternal, "internal error")
+		writeInternalError(c, err)
 	}
 }
 
 // BulkDeactivate godoc
 // @Summary      Массовая деактивация пользователей команды
-// @Description  Деактивирует переданный список user_id внутри команды и безопасно переназначает их в открытых PR (если найдены кандидаты).
+// @Description  Деактивирует переданный список user_id внутри команды и безопасно переназначает их в открытых PR (если найдены кандидаты). С dry_run только возвращает план (снятые ревьюверы, замены или причины пропуска) и plan_token, ничего не записывая. С plan_token выполняет ровно этот план, а если данные с тех пор изменились, отвечает 409 PLAN_STALE.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.BulkDeactivateRequest  true  "Команда и user_id"
+// @Param        Idempotency-Key  header  string  false  "Ключ для безопасного повтора запроса"
 // @Success      200      {object}  dto.BulkDeactivateResponse
 // @Failure      400      {object}  dto.ErrorResponse
 // @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
 // @Failure      500      {object}  dto.ErrorResponse
+// @Failure      504      {object}  dto.ErrorResponse
 // @Router       /api/users/bulkDeactivate [post]
 func (h *UserHandler) BulkDeactivate(c *gin.Context) {
 	log := logger(c)
@@ -133,20 +203,20 @@ func (h *UserHandler) BulkDeactivate(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "team_name and user_ids are required")
 		return
 	}
+	if req.DryRun && req.PlanToken != "" {
+		log.Warnw("dry_run with plan_token in bulk deactivate", "payload", req)
+		writeError(c, http.StatusBadRequest, errorCodeBadRequest, "dry_run and plan_token are mutually exclusive")
+		return
+	}
 
-	result, err := h.userSvc.BulkDeactivate(req.TeamName, req.UserIDs)
+	opts := service.BulkDeactivateOptions{DryRun: req.DryRun, PlanToken: req.PlanToken}
+	result, err := h.userSvc.BulkDeactivate(c.Request.Context(), requestOrigin(c), req.TeamName, req.UserIDs, opts)
 	if err != nil {
 		log.Errorw("bulk deactivate failed", "team", req.TeamName, "error", err)
 		h.handleDomainError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.BulkDeactivateResponse{
-		Team:        result.TeamName,
-		Deactivated: result.DeactivatedUsers,
-		Reassigned:  result.ReassignmentsDone,
-		Skipped:     result.ReassignmentsSkipped,
-		AffectedPRs: result.AffectedPullRequests,
-	})
-	log.Infow("bulk deactivate completed", "team", req.TeamName, "deactivated", result.DeactivatedUsers, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "prs", result.AffectedPullRequests)
+	c.JSON(http.StatusOK, mapper.MapBulkDeactivateResultToDTO(result))
+	log.Infow("bulk deactivate completed", "team", req.TeamName, "dry_run", result.DryRun, "deactivated", result.DeactivatedUsers, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "prs", result.AffectedPullRequests)
 }
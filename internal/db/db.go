@@ -4,22 +4,56 @@ import (
 	"fmt"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
+// dialectors — драйверы БД по значению STORAGE. Репозитории не зависят от драйвера:
+// различия SQL решаются по tx.Dialector.Name(), ошибки приводятся к общим через TranslateError.
+var dialectors = map[string]func(cfg *config.Config) gorm.Dialector{
+	config.StoragePostgres: postgresDialector,
+	config.StorageSQLite:   sqliteDialector,
+}
+
 func Connect(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName,
-	)
+	dialector, ok := dialectors[cfg.Storage]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database storage %q", cfg.Storage)
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector(cfg), &gorm.Config{
 		TranslateError: true,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	// Спаны запросов становятся дочерними к спану сервиса, если репозиторий передал контекст через WithContext.
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics())); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
+
+func postgresDialector(cfg *config.Config) gorm.Dialector {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName,
+	)
+	return postgres.Open(dsn)
+}
+
+// sqliteDialector включает внешние ключи (каскадное удаление, как в PostgreSQL) и WAL.
+// Транзакции сразу берут блокировку записи: иначе две транзакции, начавшие с чтения,
+// не смогут обе перейти к записи и одна из них упадёт с SQLITE_BUSY вместо ожидания.
+func sqliteDialector(cfg *config.Config) gorm.Dialector {
+	dsn := cfg.SQLitePath +
+		"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	return sqlite.Open(dsn)
+}
@@ -1,13 +1,282 @@
 package db
 
 import (
-	"github.com/Leganyst/avitoTrainee/internal/model"
+	"cmp"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
 	"gorm.io/gorm"
 )
 
+// Миграции лежат в migrations/<диалект>/<версия>_<имя>.up.sql и .down.sql и встраиваются в бинарник.
+// Применённые версии записываются в schema_migrations в той же транзакции, что и сама миграция.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// ErrNoMigrations возвращается откатом, когда в базе нет применённых миграций.
+var ErrNoMigrations = errors.New("no applied migrations")
+
+// migrationLockKey — ключ advisory lock PostgreSQL, под которым экземпляры сервиса применяют миграции по очереди.
+const migrationLockKey int64 = 4_608_201_746
+
+type (
+	// Migration — одна версия схемы.
+	Migration struct {
+		Version int64
+		Name    string
+		up      string
+		down    string
+	}
+
+	// MigrationStatus — миграция и время её применения; AppliedAt == nil — миграция ещё не применена.
+	MigrationStatus struct {
+		Version   int64
+		Name      string
+		AppliedAt *time.Time
+	}
+
+	// migrationDialect — то, чем диалекты различаются при миграциях.
+	// SQLite не знает advisory lock: там экземпляры разводит блокировка записи, которую берёт каждая транзакция.
+	migrationDialect struct {
+		dir          string
+		versionTable string
+		lock         string
+		unlock       string
+	}
+
+	// schemaMigration — строка schema_migrations.
+	schemaMigration struct {
+		Version   int64
+		Name      string
+		AppliedAt time.Time
+	}
+)
+
+var migrationDialects = map[string]migrationDialect{
+	"postgres": {
+		dir: "migrations/postgres",
+		versionTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL
+		)`,
+		lock:   "SELECT pg_advisory_lock(?)",
+		unlock: "SELECT pg_advisory_unlock(?)",
+	},
+	"sqlite": {
+		dir: "migrations/sqlite",
+		versionTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version integer PRIMARY KEY,
+			name text NOT NULL,
+			applied_at datetime NOT NULL
+		)`,
+	},
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate применяет все ещё не применённые миграции; используется при старте сервиса.
 func Migrate(conn *gorm.DB) error {
-	// if err := conn.SetupJoinTable(&model.PullRequest{}, "AssignedReviewers", &model.User{}); err != nil {
-	// 	return err
-	// }
-	return conn.AutoMigrate(&model.Team{}, &model.User{}, &model.PullRequest{}, &model.PRReviewer{})
+	_, err := MigrateUp(context.Background(), conn)
+	return err
+}
+
+// MigrateUp применяет ещё не применённые миграции по возрастанию версии и возвращает применённые.
+// Миграции выполняются под блокировкой, поэтому одновременно стартующие экземпляры не применят одну версию дважды.
+func MigrateUp(ctx context.Context, conn *gorm.DB) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(ctx, conn, func(tx *gorm.DB, migrations []Migration) error {
+		for _, m := range migrations {
+			ok, err := applyMigration(tx, m)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+			}
+			if ok {
+				config.LoggerFrom(ctx).Infow("migration applied", "version", m.Version, "name", m.Name)
+				applied = append(applied, m)
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown откатывает последнюю применённую миграцию и возвращает её.
+func MigrateDown(ctx context.Context, conn *gorm.DB) (*Migration, error) {
+	var rolledBack *Migration
+	err := withMigrationLock(ctx, conn, func(tx *gorm.DB, migrations []Migration) error {
+		var last schemaMigration
+		if err := tx.Order("version DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		if last.Version == 0 {
+			return ErrNoMigrations
+		}
+		i := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == last.Version })
+		if i < 0 {
+			return fmt.Errorf("migration %d_%s is applied but unknown to this build", last.Version, last.Name)
+		}
+		m := migrations[i]
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+		}
+		config.LoggerFrom(ctx).Infow("migration rolled back", "version", m.Version, "name", m.Name)
+		rolledBack = &m
+		return nil
+	})
+	return rolledBack, err
+}
+
+// MigrationStatuses возвращает все известные миграции по возрастанию версии с отметкой о применении.
+func MigrationStatuses(ctx context.Context, conn *gorm.DB) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := withMigrationLock(ctx, conn, func(tx *gorm.DB, migrations []Migration) error {
+		var rows []schemaMigration
+		if err := tx.Order("version").Find(&rows).Error; err != nil {
+			return err
+		}
+		appliedAt := make(map[int64]time.Time, len(rows))
+		for _, row := range rows {
+			appliedAt[row.Version] = row.AppliedAt
+		}
+		for _, m := range migrations {
+			status := MigrationStatus{Version: m.Version, Name: m.Name}
+			if at, ok := appliedAt[m.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withMigrationLock держит одно соединение на всё время работы fn: advisory lock PostgreSQL
+// принадлежит сессии, и снять его можно только из неё же.
+func withMigrationLock(ctx context.Context, conn *gorm.DB, fn func(tx *gorm.DB, migrations []Migration) error) error {
+	dialect, ok := migrationDialects[conn.Dialector.Name()]
+	if !ok {
+		return fmt.Errorf("migrations are not available for %q", conn.Dialector.Name())
+	}
+	migrations, err := loadMigrations(dialect.dir)
+	if err != nil {
+		return err
+	}
+
+	return conn.WithContext(ctx).Connection(func(tx *gorm.DB) error {
+		if dialect.lock != "" {
+			if err := tx.Exec(dialect.lock, migrationLockKey).Error; err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			defer func() {
+				// Контекст мог уже закончиться, а соединение вернётся в пул: блокировку снимаем в любом случае.
+				if err := tx.WithContext(context.WithoutCancel(ctx)).Exec(dialect.unlock, migrationLockKey).Error; err != nil {
+					config.LoggerFrom(ctx).Errorw("release migration lock failed", "error", err)
+				}
+			}()
+		}
+		if err := tx.Exec(dialect.versionTable).Error; err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+		return fn(tx, migrations)
+	})
+}
+
+// applyMigration применяет миграцию, если её версия ещё не записана. Проверка идёт внутри транзакции:
+// в SQLite, где нет advisory lock, параллельный экземпляр увидит запись, дождавшись блокировки записи.
+func applyMigration(tx *gorm.DB, m Migration) (bool, error) {
+	applied := false
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", m.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Exec(m.up).Error; err != nil {
+			return err
+		}
+		applied = true
+		return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	return applied, err
+}
+
+// loadMigrations читает пары up/down из dir и сортирует их по версии.
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := cutDirection(file)
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>.up.sql or .down.sql", file)
+		}
+		rawVersion, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(rawVersion, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, rawVersion)
+		}
+		body, err := fs.ReadFile(migrationFiles, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d has different names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+func cutDirection(file string) (base, direction string, ok bool) {
+	if base, ok = strings.CutSuffix(file, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok = strings.CutSuffix(file, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
 }
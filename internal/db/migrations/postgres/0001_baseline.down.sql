@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS idempotency_records;
+DROP TABLE IF EXISTS pr_assignment_stats;
+DROP TABLE IF EXISTS reviewer_assignment_stats;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS delegations;
+DROP TABLE IF EXISTS team_holidays;
+DROP TABLE IF EXISTS absences;
+DROP TABLE IF EXISTS pr_reviewer_periods;
+DROP TABLE IF EXISTS pr_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
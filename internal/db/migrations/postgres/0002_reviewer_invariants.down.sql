@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS pr_reviewers_invariants ON pr_reviewers;
+DROP FUNCTION IF EXISTS check_reviewer_invariants();
@@ -0,0 +1,28 @@
+-- Фоновые задачи и их элементы: прогресс переживает перезапуск, а брошенную задачу подхватывает другой экземпляр.
+CREATE TABLE jobs (
+    id bigserial PRIMARY KEY,
+    kind text NOT NULL,
+    status text NOT NULL,
+    actor text,
+    payload text NOT NULL,
+    total bigint NOT NULL DEFAULT 0,
+    processed bigint NOT NULL DEFAULT 0,
+    cancel_requested boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    started_at timestamptz,
+    finished_at timestamptz,
+    heartbeat_at timestamptz
+);
+CREATE INDEX idx_jobs_status ON jobs (status);
+
+CREATE TABLE job_items (
+    job_id bigint,
+    seq bigint,
+    key text NOT NULL,
+    status text NOT NULL,
+    result text,
+    error text,
+    finished_at timestamptz,
+    PRIMARY KEY (job_id, seq),
+    CONSTRAINT fk_jobs_items FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
@@ -0,0 +1,22 @@
+DROP TRIGGER IF EXISTS users_assignment_stats_delete ON users;
+DROP FUNCTION IF EXISTS drop_user_assignments();
+DROP TRIGGER IF EXISTS pull_requests_assignment_stats_delete ON pull_requests;
+DROP FUNCTION IF EXISTS drop_pr_assignments();
+DROP TRIGGER IF EXISTS users_assignment_stats_team ON users;
+DROP FUNCTION IF EXISTS move_author_assignment_stats();
+
+DROP TABLE IF EXISTS reviewer_assignment_stats;
+CREATE TABLE reviewer_assignment_stats (
+    reviewer_id bigint,
+    author_id bigint,
+    status text,
+    assignments bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewer_id, author_id, status)
+);
+CREATE INDEX idx_reviewer_assignment_stats_author_id ON reviewer_assignment_stats (author_id);
+
+INSERT INTO reviewer_assignment_stats (reviewer_id, author_id, status, assignments)
+SELECT prr.user_id, p.author_id, p.status, COUNT(*)
+FROM pr_reviewers prr
+JOIN pull_requests p ON p.id = prr.pull_request_id
+GROUP BY 1, 2, 3;
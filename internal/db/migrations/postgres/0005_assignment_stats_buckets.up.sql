@@ -0,0 +1,69 @@
+-- Счётчики назначений по пользователям получают команду автора и день создания PR (UTC, YYYY-MM-DD):
+-- статистика команды и периода читается из счётчиков, а не пересчитывается по pr_reviewers.
+-- Записи PR и ревьюверов ведёт репозиторий PR; остальные изменения, которые сдвигают счётчики, ведут триггеры ниже.
+DROP TABLE IF EXISTS reviewer_assignment_stats;
+CREATE TABLE reviewer_assignment_stats (
+    reviewer_id bigint,
+    author_id bigint,
+    status text,
+    created_on text,
+    team_id bigint,
+    assignments bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewer_id, author_id, status, created_on)
+);
+CREATE INDEX idx_reviewer_assignment_stats_author_id ON reviewer_assignment_stats (author_id);
+CREATE INDEX idx_reviewer_assignment_stats_team ON reviewer_assignment_stats (team_id);
+
+INSERT INTO reviewer_assignment_stats (reviewer_id, author_id, status, created_on, team_id, assignments)
+SELECT prr.user_id, p.author_id, p.status, to_char(p.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD'), a.team_id, COUNT(*)
+FROM pr_reviewers prr
+JOIN pull_requests p ON p.id = prr.pull_request_id
+JOIN users a ON a.id = p.author_id
+GROUP BY 1, 2, 3, 4, 5;
+
+-- Автор перешёл в другую команду: его счётчики переезжают вместе с ним.
+CREATE OR REPLACE FUNCTION move_author_assignment_stats() RETURNS trigger AS $$
+BEGIN
+    UPDATE reviewer_assignment_stats SET team_id = NEW.team_id WHERE author_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_assignment_stats_team
+    AFTER UPDATE OF team_id ON users
+    FOR EACH ROW WHEN (OLD.team_id IS DISTINCT FROM NEW.team_id)
+    EXECUTE FUNCTION move_author_assignment_stats();
+
+-- Удаление PR, в том числе каскадом вместе с автором, снимает его ревьюверов и вычитает их из счётчиков.
+-- pr_reviewers каскадом не удаляется, поэтому без триггера PR с ревьюверами удалить нельзя.
+CREATE OR REPLACE FUNCTION drop_pr_assignments() RETURNS trigger AS $$
+BEGIN
+    UPDATE reviewer_assignment_stats s SET assignments = s.assignments - 1
+    FROM pr_reviewers r
+    WHERE r.pull_request_id = OLD.id AND s.reviewer_id = r.user_id
+      AND s.author_id = OLD.author_id AND s.status = OLD.status
+      AND s.created_on = to_char(OLD.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD');
+    DELETE FROM pr_reviewers WHERE pull_request_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER pull_requests_assignment_stats_delete
+    BEFORE DELETE ON pull_requests
+    FOR EACH ROW EXECUTE FUNCTION drop_pr_assignments();
+
+-- Удалённый пользователь перестаёт быть ревьювером; его счётчики как ревьювера и как автора уходят.
+CREATE OR REPLACE FUNCTION drop_user_assignments() RETURNS trigger AS $$
+BEGIN
+    UPDATE pr_assignment_stats s SET reviewers = s.reviewers - 1
+    FROM pr_reviewers r
+    WHERE r.user_id = OLD.id AND s.pull_request_id = r.pull_request_id;
+    DELETE FROM pr_reviewers WHERE user_id = OLD.id;
+    DELETE FROM reviewer_assignment_stats WHERE reviewer_id = OLD.id OR author_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_assignment_stats_delete
+    BEFORE DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION drop_user_assignments();
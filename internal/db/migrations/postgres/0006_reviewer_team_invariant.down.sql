@@ -0,0 +1,56 @@
+-- Возвращает проверку команды из 0002: допустимы и команды прежних ревьюверов PR.
+
+CREATE OR REPLACE FUNCTION check_reviewer_invariants() RETURNS trigger AS $$
+DECLARE
+    pr_author_id bigint;
+    pr_team_id bigint;
+    reviewer_limit bigint;
+    reviewer_count bigint;
+BEGIN
+    -- Повторная вставка существующей строки (ON CONFLICT DO NOTHING) состав не меняет.
+    IF TG_OP = 'INSERT' AND EXISTS (
+        SELECT 1 FROM pr_reviewers r WHERE r.pull_request_id = NEW.pull_request_id AND r.user_id = NEW.user_id
+    ) THEN
+        RETURN NEW;
+    END IF;
+
+    -- Блокировка PR выстраивает параллельные назначения в очередь, иначе обе вставки уложатся в лимит по отдельности.
+    SELECT p.author_id, u.team_id, CASE WHEN t.max_reviewers > 0 THEN t.max_reviewers ELSE 2 END
+    INTO pr_author_id, pr_team_id, reviewer_limit
+    FROM pull_requests p
+    JOIN users u ON u.id = p.author_id
+    LEFT JOIN teams t ON t.id = u.team_id
+    WHERE p.id = NEW.pull_request_id
+    FOR UPDATE OF p;
+    IF NOT FOUND THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.user_id = pr_author_id THEN
+        RAISE EXCEPTION 'reviewer_invariant:author';
+    END IF;
+
+    SELECT count(*) INTO reviewer_count
+    FROM pr_reviewers r
+    WHERE r.pull_request_id = NEW.pull_request_id AND r.user_id <> NEW.user_id
+      AND (TG_OP = 'INSERT' OR r.pull_request_id <> OLD.pull_request_id OR r.user_id <> OLD.user_id);
+    IF reviewer_count >= reviewer_limit THEN
+        RAISE EXCEPTION 'reviewer_invariant:limit';
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM users u
+        WHERE u.id = NEW.user_id AND (
+            u.team_id = pr_team_id OR u.team_id IN (
+                SELECT past.team_id FROM pr_reviewer_periods rp
+                JOIN users past ON past.id = rp.user_id
+                WHERE rp.pull_request_id = NEW.pull_request_id
+            )
+        )
+    ) THEN
+        RAISE EXCEPTION 'reviewer_invariant:team';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
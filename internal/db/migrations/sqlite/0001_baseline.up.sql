@@ -0,0 +1,150 @@
+-- Схема, которую создавал AutoMigrate до перехода на версионные миграции.
+-- IF NOT EXISTS позволяет принять базу, созданную AutoMigrate, без изменений.
+
+CREATE TABLE IF NOT EXISTS teams (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    max_reviewers integer NOT NULL DEFAULT 2,
+    require_senior numeric NOT NULL DEFAULT false,
+    forbid_sole_junior numeric NOT NULL DEFAULT false,
+    assignment_mode text NOT NULL DEFAULT 'random',
+    review_sla_hours integer NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_teams_name ON teams (name);
+
+CREATE TABLE IF NOT EXISTS users (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id text NOT NULL,
+    username text NOT NULL,
+    is_active numeric DEFAULT true,
+    seniority text NOT NULL DEFAULT 'middle',
+    time_zone text NOT NULL DEFAULT 'UTC',
+    work_start text NOT NULL DEFAULT '09:00',
+    work_end text NOT NULL DEFAULT '18:00',
+    team_id integer,
+    CONSTRAINT fk_teams_users FOREIGN KEY (team_id) REFERENCES teams (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_user_id ON users (user_id);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    pr_id text NOT NULL,
+    name text NOT NULL,
+    status text NOT NULL,
+    author_id integer NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime,
+    CONSTRAINT fk_pull_requests_author FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author_id ON pull_requests (author_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pull_requests_pr_id ON pull_requests (pr_id);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id integer,
+    user_id integer,
+    assigned_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delegated_for_id integer,
+    PRIMARY KEY (pull_request_id, user_id),
+    CONSTRAINT fk_pr_reviewers_delegated_for FOREIGN KEY (delegated_for_id) REFERENCES users (id),
+    CONSTRAINT fk_pull_requests_reviewer_links FOREIGN KEY (pull_request_id) REFERENCES pull_requests (id)
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewer_periods (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    pull_request_id integer NOT NULL,
+    user_id integer NOT NULL,
+    valid_from datetime NOT NULL,
+    valid_to datetime,
+    delegated_for_id integer,
+    reviewed_at datetime,
+    CONSTRAINT fk_pr_reviewer_periods_pull_request FOREIGN KEY (pull_request_id) REFERENCES pull_requests (id) ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviewer_periods_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviewer_periods_delegated_for FOREIGN KEY (delegated_for_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_reviewer_periods_user ON pr_reviewer_periods (user_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_reviewer_periods_pr ON pr_reviewer_periods (pull_request_id, valid_from);
+
+CREATE TABLE IF NOT EXISTS absences (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL,
+    starts_at datetime NOT NULL,
+    ends_at datetime NOT NULL,
+    reason text,
+    reassigned_at datetime,
+    created_at datetime,
+    CONSTRAINT fk_absences_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_absences_user_period ON absences (user_id, starts_at, ends_at);
+
+CREATE TABLE IF NOT EXISTS team_holidays (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    team_id integer NOT NULL,
+    starts_at datetime NOT NULL,
+    ends_at datetime NOT NULL,
+    name text,
+    created_at datetime,
+    CONSTRAINT fk_team_holidays_team FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_team_holidays_period ON team_holidays (team_id, starts_at, ends_at);
+
+CREATE TABLE IF NOT EXISTS delegations (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL,
+    delegate_id integer NOT NULL,
+    starts_at datetime NOT NULL,
+    ends_at datetime NOT NULL,
+    created_at datetime,
+    CONSTRAINT fk_delegations_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_delegations_delegate FOREIGN KEY (delegate_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_delegations_delegate_id ON delegations (delegate_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_user_period ON delegations (user_id, starts_at, ends_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    type text NOT NULL,
+    reason text NOT NULL,
+    actor text NOT NULL,
+    pr_id text,
+    user_id text,
+    previous_user_id text,
+    team_name text,
+    details text,
+    occurred_at datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_team_name ON audit_events (team_name);
+CREATE INDEX IF NOT EXISTS idx_audit_events_previous_user_id ON audit_events (previous_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_pr_id ON audit_events (pr_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_type ON audit_events (type);
+
+CREATE TABLE IF NOT EXISTS reviewer_assignment_stats (
+    reviewer_id integer,
+    author_id integer,
+    status text,
+    assignments integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewer_id, author_id, status)
+);
+CREATE INDEX IF NOT EXISTS idx_reviewer_assignment_stats_author_id ON reviewer_assignment_stats (author_id);
+
+CREATE TABLE IF NOT EXISTS pr_assignment_stats (
+    pull_request_id integer PRIMARY KEY,
+    reviewers integer NOT NULL DEFAULT 0,
+    CONSTRAINT fk_pr_assignment_stats_pull_request FOREIGN KEY (pull_request_id) REFERENCES pull_requests (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_pr_assignment_stats_reviewers ON pr_assignment_stats (reviewers);
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    key text,
+    route text,
+    fingerprint text NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    headers text,
+    body blob,
+    created_at datetime,
+    expires_at datetime NOT NULL,
+    PRIMARY KEY (key, route)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records (expires_at);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS pr_reviewers_invariants_update;
+DROP TRIGGER IF EXISTS pr_reviewers_invariants_insert;
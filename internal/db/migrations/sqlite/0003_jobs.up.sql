@@ -0,0 +1,28 @@
+-- Фоновые задачи и их элементы: прогресс переживает перезапуск, а брошенную задачу подхватывает другой экземпляр.
+CREATE TABLE jobs (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    kind text NOT NULL,
+    status text NOT NULL,
+    actor text,
+    payload text NOT NULL,
+    total integer NOT NULL DEFAULT 0,
+    processed integer NOT NULL DEFAULT 0,
+    cancel_requested numeric NOT NULL DEFAULT false,
+    created_at datetime,
+    started_at datetime,
+    finished_at datetime,
+    heartbeat_at datetime
+);
+CREATE INDEX idx_jobs_status ON jobs (status);
+
+CREATE TABLE job_items (
+    job_id integer,
+    seq integer,
+    key text NOT NULL,
+    status text NOT NULL,
+    result text,
+    error text,
+    finished_at datetime,
+    PRIMARY KEY (job_id, seq),
+    CONSTRAINT fk_jobs_items FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Время merge отдельно от updated_at, который GORM сдвигает при любом сохранении PR.
+-- Для уже смёрженных PR лучшего источника нет, поэтому берётся updated_at.
+ALTER TABLE pull_requests ADD COLUMN merged_at datetime;
+UPDATE pull_requests SET merged_at = updated_at WHERE status = 'MERGED';
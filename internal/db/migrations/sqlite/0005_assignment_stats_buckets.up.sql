@@ -0,0 +1,47 @@
+-- Счётчики назначений по пользователям получают команду автора и день создания PR (UTC, YYYY-MM-DD),
+-- те же триггеры, что у PostgreSQL: перевод автора в другую команду и удаление пользователей и PR.
+DROP TABLE IF EXISTS reviewer_assignment_stats;
+CREATE TABLE reviewer_assignment_stats (
+    reviewer_id integer,
+    author_id integer,
+    status text,
+    created_on text,
+    team_id integer,
+    assignments integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewer_id, author_id, status, created_on)
+);
+CREATE INDEX idx_reviewer_assignment_stats_author_id ON reviewer_assignment_stats (author_id);
+CREATE INDEX idx_reviewer_assignment_stats_team ON reviewer_assignment_stats (team_id);
+
+INSERT INTO reviewer_assignment_stats (reviewer_id, author_id, status, created_on, team_id, assignments)
+SELECT prr.user_id, p.author_id, p.status, strftime('%Y-%m-%d', p.created_at), a.team_id, COUNT(*)
+FROM pr_reviewers prr
+JOIN pull_requests p ON p.id = prr.pull_request_id
+JOIN users a ON a.id = p.author_id
+GROUP BY 1, 2, 3, 4, 5;
+
+CREATE TRIGGER users_assignment_stats_team
+AFTER UPDATE OF team_id ON users
+WHEN OLD.team_id IS NOT NEW.team_id
+BEGIN
+    UPDATE reviewer_assignment_stats SET team_id = NEW.team_id WHERE author_id = NEW.id;
+END;
+
+CREATE TRIGGER pull_requests_assignment_stats_delete
+BEFORE DELETE ON pull_requests
+BEGIN
+    UPDATE reviewer_assignment_stats SET assignments = assignments - 1
+    WHERE author_id = OLD.author_id AND status = OLD.status
+      AND created_on = strftime('%Y-%m-%d', OLD.created_at)
+      AND reviewer_id IN (SELECT user_id FROM pr_reviewers WHERE pull_request_id = OLD.id);
+    DELETE FROM pr_reviewers WHERE pull_request_id = OLD.id;
+END;
+
+CREATE TRIGGER users_assignment_stats_delete
+BEFORE DELETE ON users
+BEGIN
+    UPDATE pr_assignment_stats SET reviewers = reviewers - 1
+    WHERE pull_request_id IN (SELECT pull_request_id FROM pr_reviewers WHERE user_id = OLD.id);
+    DELETE FROM pr_reviewers WHERE user_id = OLD.id;
+    DELETE FROM reviewer_assignment_stats WHERE reviewer_id = OLD.id OR author_id = OLD.id;
+END;
@@ -0,0 +1,72 @@
+-- Возвращает проверку команды из 0002: допустимы и команды прежних ревьюверов PR.
+
+DROP TRIGGER IF EXISTS pr_reviewers_invariants_update;
+DROP TRIGGER IF EXISTS pr_reviewers_invariants_insert;
+
+CREATE TRIGGER pr_reviewers_invariants_insert
+BEFORE INSERT ON pr_reviewers
+WHEN NOT EXISTS (
+    SELECT 1 FROM pr_reviewers r WHERE r.pull_request_id = NEW.pull_request_id AND r.user_id = NEW.user_id
+)
+BEGIN
+    SELECT RAISE(ABORT, 'reviewer_invariant:author')
+    FROM pull_requests p
+    WHERE p.id = NEW.pull_request_id AND p.author_id = NEW.user_id;
+
+    SELECT RAISE(ABORT, 'reviewer_invariant:limit')
+    FROM pull_requests p
+    JOIN users a ON a.id = p.author_id
+    LEFT JOIN teams t ON t.id = a.team_id
+    WHERE p.id = NEW.pull_request_id
+      AND (SELECT count(*) FROM pr_reviewers r WHERE r.pull_request_id = NEW.pull_request_id)
+          >= CASE WHEN t.max_reviewers > 0 THEN t.max_reviewers ELSE 2 END;
+
+    SELECT RAISE(ABORT, 'reviewer_invariant:team')
+    FROM pull_requests p
+    JOIN users a ON a.id = p.author_id
+    WHERE p.id = NEW.pull_request_id
+      AND NOT EXISTS (
+          SELECT 1 FROM users u
+          WHERE u.id = NEW.user_id AND (
+              u.team_id = a.team_id OR u.team_id IN (
+                  SELECT past.team_id FROM pr_reviewer_periods rp
+                  JOIN users past ON past.id = rp.user_id
+                  WHERE rp.pull_request_id = NEW.pull_request_id
+              )
+          )
+      );
+END;
+
+CREATE TRIGGER pr_reviewers_invariants_update
+BEFORE UPDATE OF pull_request_id, user_id ON pr_reviewers
+BEGIN
+    SELECT RAISE(ABORT, 'reviewer_invariant:author')
+    FROM pull_requests p
+    WHERE p.id = NEW.pull_request_id AND p.author_id = NEW.user_id;
+
+    SELECT RAISE(ABORT, 'reviewer_invariant:limit')
+    FROM pull_requests p
+    JOIN users a ON a.id = p.author_id
+    LEFT JOIN teams t ON t.id = a.team_id
+    WHERE p.id = NEW.pull_request_id
+      AND (
+          SELECT count(*) FROM pr_reviewers r
+          WHERE r.pull_request_id = NEW.pull_request_id
+            AND NOT (r.pull_request_id = OLD.pull_request_id AND r.user_id = OLD.user_id)
+      ) >= CASE WHEN t.max_reviewers > 0 THEN t.max_reviewers ELSE 2 END;
+
+    SELECT RAISE(ABORT, 'reviewer_invariant:team')
+    FROM pull_requests p
+    JOIN users a ON a.id = p.author_id
+    WHERE p.id = NEW.pull_request_id
+      AND NOT EXISTS (
+          SELECT 1 FROM users u
+          WHERE u.id = NEW.user_id AND (
+              u.team_id = a.team_id OR u.team_id IN (
+                  SELECT past.team_id FROM pr_reviewer_periods rp
+                  JOIN users past ON past.id = rp.user_id
+                  WHERE rp.pull_request_id = NEW.pull_request_id
+              )
+          )
+      );
+END;
@@ -0,0 +1,5 @@
+-- Аренда задачи: писать результаты может только обработчик, который её держит, и только до истечения срока.
+-- attempts считает прерванные выполнения, чтобы задача с постоянной ошибкой завершалась, а не крутилась в очереди.
+ALTER TABLE jobs ADD COLUMN locked_by text;
+ALTER TABLE jobs ADD COLUMN lease_until datetime;
+ALTER TABLE jobs ADD COLUMN attempts integer NOT NULL DEFAULT 0;
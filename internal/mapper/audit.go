@@ -0,0 +1,28 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// MapAuditEventsToDTO переводит события журнала назначений в DTO.
+func MapAuditEventsToDTO(events []model.AuditEvent) []dto.AuditEvent {
+	items := make([]dto.AuditEvent, 0, len(events))
+	for _, e := range events {
+		items = append(items, dto.AuditEvent{
+			EventID:        e.ID,
+			Type:           e.Type,
+			Reason:         e.Reason,
+			Actor:          e.Actor,
+			PRID:           e.PRID,
+			UserID:         e.UserID,
+			PreviousUserID: e.PreviousUserID,
+			TeamName:       e.TeamName,
+			Details:        e.Details,
+			OccurredAt:     e.OccurredAt.Format(time.RFC3339),
+		})
+	}
+	return items
+}
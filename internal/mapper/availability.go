@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// MapAbsenceToDTO переводит отсутствие в DTO; пользователь должен быть предзагружен.
+func MapAbsenceToDTO(absence model.Absence) dto.Absence {
+	return dto.Absence{
+		AbsenceID: absence.ID,
+		UserID:    absence.User.UserID,
+		StartsAt:  absence.StartsAt.Format(time.RFC3339),
+		EndsAt:    absence.EndsAt.Format(time.RFC3339),
+		Reason:    absence.Reason,
+	}
+}
+
+// MapAbsencesToDTO переводит список отсутствий в DTO.
+func MapAbsencesToDTO(absences []model.Absence) []dto.Absence {
+	items := make([]dto.Absence, 0, len(absences))
+	for _, a := range absences {
+		items = append(items, MapAbsenceToDTO(a))
+	}
+	return items
+}
+
+// MapTeamHolidayToDTO переводит праздник команды в DTO; команда должна быть предзагружена.
+func MapTeamHolidayToDTO(holiday model.TeamHoliday) dto.TeamHoliday {
+	return dto.TeamHoliday{
+		HolidayID: holiday.ID,
+		TeamName:  holiday.Team.Name,
+		StartsAt:  holiday.StartsAt.Format(time.RFC3339),
+		EndsAt:    holiday.EndsAt.Format(time.RFC3339),
+		Name:      holiday.Name,
+	}
+}
+
+// MapTeamHolidaysToDTO переводит календарь команды в DTO.
+func MapTeamHolidaysToDTO(holidays []model.TeamHoliday) []dto.TeamHoliday {
+	items := make([]dto.TeamHoliday, 0, len(holidays))
+	for _, h := range holidays {
+		items = append(items, MapTeamHolidayToDTO(h))
+	}
+	return items
+}
+
+// MapDelegationToDTO переводит делегирование в DTO; пользователь и делегат должны быть предзагружены.
+func MapDelegationToDTO(delegation model.Delegation) dto.Delegation {
+	return dto.Delegation{
+		DelegationID: delegation.ID,
+		UserID:       delegation.User.UserID,
+		DelegateID:   delegation.Delegate.UserID,
+		StartsAt:     delegation.StartsAt.Format(time.RFC3339),
+		EndsAt:       delegation.EndsAt.Format(time.RFC3339),
+	}
+}
+
+// MapDelegationsToDTO переводит список делегирований в DTO.
+func MapDelegationsToDTO(delegations []model.Delegation) []dto.Delegation {
+	items := make([]dto.Delegation, 0, len(delegations))
+	for _, d := range delegations {
+		items = append(items, MapDelegationToDTO(d))
+	}
+	return items
+}
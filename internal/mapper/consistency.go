@@ -0,0 +1,24 @@
+package mapper
+
+import (
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+)
+
+// MapConsistencyReportToDTO переводит отчёт проверки согласованности в DTO; используется и ручкой, и CLI.
+func MapConsistencyReportToDTO(report *service.ConsistencyReport) dto.ConsistencyReportResponse {
+	findings := make([]dto.ConsistencyFinding, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		findings = append(findings, dto.ConsistencyFinding{
+			Kind:       f.Kind,
+			OtherKinds: f.OtherKinds,
+			PRID:       f.PRID,
+			UserID:     f.UserID,
+			Repair:     f.Repair,
+			Outcome:    f.Outcome,
+			ReplacedBy: f.ReplacedBy,
+			Error:      f.Error,
+		})
+	}
+	return dto.ConsistencyReportResponse{Applied: report.Applied, Findings: findings}
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// MapJobToDTO превращает задачу с элементами в DTO; результат элемента передаётся как есть, он уже в JSON.
+func MapJobToDTO(job *model.Job) dto.Job {
+	items := make([]dto.JobItem, 0, len(job.Items))
+	for _, item := range job.Items {
+		out := dto.JobItem{
+			Key:        item.Key,
+			Status:     item.Status,
+			Error:      item.Error,
+			FinishedAt: item.FinishedAt,
+		}
+		if item.Result != "" {
+			out.Result = json.RawMessage(item.Result)
+		}
+		items = append(items, out)
+	}
+	return dto.Job{
+		JobID:           job.ID,
+		Kind:            job.Kind,
+		Status:          job.Status,
+		Total:           job.Total,
+		Processed:       job.Processed,
+		CancelRequested: job.CancelRequested,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+		Items:           items,
+	}
+}
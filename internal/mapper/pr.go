@@ -18,13 +18,15 @@ func MapCreatePRRequestToModel(req dto.CreatePRRequest) model.PullRequest {
 // MapPullRequestToDTO собирает расширенный DTO из модели PR с ревьюверами.
 func MapPullRequestToDTO(pr model.PullRequest) dto.PullRequest {
 	return dto.PullRequest{
-		PRID:              pr.PRID,
-		Name:              pr.Name,
-		AuthorID:          authorExternalID(pr),
-		Status:            pr.Status,
-		AssignedReviewers: mapAssignedReviewers(pr.AssignedReviewers),
-		CreatedAT:         stringPtrFromTime(pr.CreatedAt),
-		MergedAt:          stringPtrFromTimePtr(pr.UpdatedAt),
+		PRID:               pr.PRID,
+		Name:               pr.Name,
+		AuthorID:           authorExternalID(pr),
+		Status:             pr.Status,
+		AssignedReviewers:  mapAssignedReviewers(pr.AssignedReviewers),
+		CreatedAT:          stringPtrFromTime(pr.CreatedAt),
+		MergedAt:           stringPtrFromTimePtr(pr.MergedAt),
+		AssignmentWarnings: pr.AssignmentWarnings,
+		DelegatedReviewers: mapDelegatedReviewers(pr),
 	}
 }
 
@@ -64,6 +66,20 @@ func mapAssignedReviewers(reviewers []model.User) []string {
 	return ids
 }
 
+// mapDelegatedReviewers перечисляет ревьюверов, назначенных за другого участника.
+func mapDelegatedReviewers(pr model.PullRequest) []dto.DelegatedReviewer {
+	var delegated []dto.DelegatedReviewer
+	for _, reviewer := range pr.AssignedReviewers {
+		if principal := pr.DelegatedFor(reviewer.ID); principal != nil {
+			delegated = append(delegated, dto.DelegatedReviewer{
+				ReviewerID: reviewer.UserID,
+				OnBehalfOf: principal.UserID,
+			})
+		}
+	}
+	return delegated
+}
+
 // help func - возвращает строкове external значение идентификатора, если автор предзагружен ОРМ
 func authorExternalID(pr model.PullRequest) string {
 	if pr.Author.UserID != "" {
@@ -1,6 +1,9 @@
 package mapper
 
 import (
+	"math"
+	"time"
+
 	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
 	"github.com/Leganyst/avitoTrainee/internal/service"
 )
@@ -30,3 +33,107 @@ func MapAssignmentsByPR(stats []service.AssignmentByPR) []dto.AssignmentByPR {
 	}
 	return items
 }
+
+// MapReviewSLA переводит сервисные данные SLA в DTO; часы округляются до сотых.
+func MapReviewSLA(stats []service.ReviewSLAItem) []dto.ReviewSLAItem {
+	items := make([]dto.ReviewSLAItem, 0, len(stats))
+	for _, s := range stats {
+		items = append(items, dto.ReviewSLAItem{
+			PRID:         s.PRID,
+			Name:         s.Name,
+			ReviewerID:   s.ReviewerID,
+			AssignedAt:   s.AssignedAt.Format(time.RFC3339),
+			WorkingHours: round2(s.WorkingHours),
+			SLAHours:     s.SLAHours,
+			Breached:     s.Breached,
+		})
+	}
+	return items
+}
+
+// MapReviewDurations переводит недельные метрики длительности в DTO; часы округляются до сотых.
+func MapReviewDurations(report service.ReviewDurationsReport) dto.ReviewDurationsResponse {
+	items := make([]dto.DurationBucket, 0, len(report.Buckets))
+	for _, b := range report.Buckets {
+		items = append(items, dto.DurationBucket{
+			Group:               b.Group,
+			WeekStart:           b.WeekStart.Format(time.DateOnly),
+			TimeToMerge:         mapDurationSummary(b.TimeToMerge),
+			TimeToFirstReview:   mapDurationSummary(b.TimeToFirstReview),
+			ReassignmentLatency: mapDurationSummary(b.ReassignmentLatency),
+		})
+	}
+	return dto.ReviewDurationsResponse{
+		GroupBy: report.GroupBy,
+		From:    report.From.Format(time.RFC3339),
+		To:      report.To.Format(time.RFC3339),
+		Items:   items,
+	}
+}
+
+func mapDurationSummary(s service.DurationSummary) dto.DurationSummary {
+	return dto.DurationSummary{
+		Count:       s.Count,
+		AvgHours:    roundHours(s.Avg),
+		MedianHours: roundHours(s.Median),
+		MaxHours:    roundHours(s.Max),
+	}
+}
+
+func roundHours(d time.Duration) float64 {
+	return round2(d.Hours())
+}
+
+// MapFairness переводит отчёт о нагрузке в DTO; дробные значения округляются до сотых.
+func MapFairness(report service.FairnessReport) dto.FairnessResponse {
+	members := make([]dto.MemberFairness, 0, len(report.Members))
+	for _, m := range report.Members {
+		members = append(members, dto.MemberFairness{
+			UserID:           m.UserID,
+			Username:         m.Username,
+			IsActive:         m.IsActive,
+			OpenAssignments:  m.OpenAssignments,
+			TotalAssignments: m.TotalAssignments,
+			Deviation:        round2(m.Deviation),
+		})
+	}
+	return dto.FairnessResponse{
+		TeamName:      report.TeamName,
+		MeanTotal:     round2(report.MeanTotal),
+		MeanOpen:      round2(report.MeanOpen),
+		GiniTotal:     round2(report.GiniTotal),
+		GiniOpen:      round2(report.GiniOpen),
+		Members:       members,
+		NeverAssigned: report.NeverAssigned,
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func MapUserReplacements(stats []service.UserReplacements) []dto.UserReplacements {
+	items := make([]dto.UserReplacements, 0, len(stats))
+	for _, s := range stats {
+		items = append(items, dto.UserReplacements{
+			UserID:   s.UserID,
+			Total:    s.Total,
+			ByReason: s.ByReason,
+		})
+	}
+	return items
+}
+
+func MapPRChurn(stats []service.PRChurn) []dto.PRChurn {
+	items := make([]dto.PRChurn, 0, len(stats))
+	for _, s := range stats {
+		items = append(items, dto.PRChurn{
+			PRID:              s.PRID,
+			Name:              s.Name,
+			Status:            s.Status,
+			DistinctReviewers: s.DistinctReviewers,
+			Removals:          s.Removals,
+		})
+	}
+	return items
+}
@@ -8,8 +8,26 @@ import (
 // MapCreateTeamRequestToModel переводит входящий запрос создания команды в модель.
 func MapCreateTeamRequestToModel(req dto.CreateTeamRequest) model.Team {
 	return model.Team{
-		Name:  req.TeamName,
-		Users: MapTeamMemberDTOsToUsers(req.Members),
+		Name:             req.TeamName,
+		Users:            MapTeamMemberDTOsToUsers(req.Members),
+		MaxReviewers:     req.MaxReviewers,
+		RequireSenior:    req.RequireSenior,
+		ForbidSoleJunior: req.ForbidSoleJunior,
+		AssignmentMode:   req.AssignmentMode,
+		ReviewSLAHours:   req.ReviewSLAHours,
+	}
+}
+
+// MapTeamToDTO собирает DTO команды вместе с её настройками.
+func MapTeamToDTO(team model.Team) dto.Team {
+	return dto.Team{
+		TeamName:         team.Name,
+		Members:          MapUsersToTeamMemberDTO(team.Users),
+		MaxReviewers:     team.ReviewerLimit(),
+		RequireSenior:    team.RequireSenior,
+		ForbidSoleJunior: team.ForbidSoleJunior,
+		AssignmentMode:   team.AssignmentModeOrDefault(),
+		ReviewSLAHours:   team.ReviewSLAHours,
 	}
 }
 
@@ -18,9 +36,13 @@ func MapUsersToTeamMemberDTO(users []model.User) []dto.TeamMember {
 	members := make([]dto.TeamMember, 0, len(users))
 	for _, user := range users {
 		members = append(members, dto.TeamMember{
-			UserID:   user.UserID,
-			Username: user.Username,
-			IsActive: user.IsActive,
+			UserID:    user.UserID,
+			Username:  user.Username,
+			IsActive:  user.IsActive,
+			Seniority: user.SeniorityLevel(),
+			TimeZone:  user.Location().String(),
+			WorkStart: user.WorkStart,
+			WorkEnd:   user.WorkEnd,
 		})
 	}
 	return members
@@ -3,15 +3,20 @@ package mapper
 import (
 	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
 	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/service"
 )
 
 // MapUserToDTO превращает модель User в DTO для ответов.
 func MapUserToDTO(user model.User) dto.User {
 	return dto.User{
-		UserID:   user.UserID,
-		Username: user.Username,
-		TeamName: user.Team.Name,
-		IsActive: user.IsActive,
+		UserID:    user.UserID,
+		Username:  user.Username,
+		TeamName:  user.Team.Name,
+		IsActive:  user.IsActive,
+		Seniority: user.SeniorityLevel(),
+		TimeZone:  user.Location().String(),
+		WorkStart: user.WorkStart,
+		WorkEnd:   user.WorkEnd,
 	}
 }
 
@@ -27,9 +32,13 @@ func MapUsersToDTO(users []model.User) []dto.User {
 // MapTeamMemberDTOToUser собирает модель User из DTO участника команды.
 func MapTeamMemberDTOToUser(member dto.TeamMember) model.User {
 	return model.User{
-		UserID:   member.UserID,
-		Username: member.Username,
-		IsActive: member.IsActive,
+		UserID:    member.UserID,
+		Username:  member.Username,
+		IsActive:  member.IsActive,
+		Seniority: member.Seniority,
+		TimeZone:  member.TimeZone,
+		WorkStart: member.WorkStart,
+		WorkEnd:   member.WorkEnd,
 	}
 }
 
@@ -49,3 +58,28 @@ func MapUserRequestToUser(req dto.UserRequest) model.User {
 		IsActive: *req.IsActive,
 	}
 }
+
+// MapBulkDeactivateResultToDTO превращает результат или план массовой деактивации в DTO ответа.
+func MapBulkDeactivateResultToDTO(result *service.BulkDeactivateResult) dto.BulkDeactivateResponse {
+	plan := make([]dto.BulkDeactivatePlanItem, 0, len(result.Plan))
+	for _, step := range result.Plan {
+		plan = append(plan, dto.BulkDeactivatePlanItem{
+			PullRequestID:     step.PRID,
+			RemovedUserID:     step.RemovedUserID,
+			ReplacementUserID: step.ReplacementUserID,
+			Delegated:         step.Delegated,
+			SkipReason:        step.SkipReason,
+		})
+	}
+	return dto.BulkDeactivateResponse{
+		Team:             result.TeamName,
+		Deactivated:      result.DeactivatedUsers,
+		Reassigned:       result.ReassignmentsDone,
+		Skipped:          result.ReassignmentsSkipped,
+		AffectedPRs:      result.AffectedPullRequests,
+		PolicyViolations: result.PolicyViolations,
+		Plan:             plan,
+		DryRun:           result.DryRun,
+		PlanToken:        result.PlanToken,
+	}
+}
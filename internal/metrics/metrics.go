@@ -0,0 +1,139 @@
+// Package metrics собирает метрики сервиса в формате Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_reviewer"
+
+// Registry — реестр метрик сервиса; отдельный от глобального, чтобы в /metrics попадало только своё.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	prsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prs_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	prsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prs_merged_total",
+		Help:      "Pull requests merged.",
+	})
+
+	reviewersAssigned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_assigned_total",
+		Help:      "Reviewers assigned to pull requests, by reason.",
+	}, []string{"reason"})
+
+	reviewersUnassigned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_unassigned_total",
+		Help:      "Reviewers removed from pull requests without a replacement, by reason.",
+	}, []string{"reason"})
+
+	reassignments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reassignments_total",
+		Help:      "Reviewers replaced on pull requests, by reason.",
+	}, []string{"reason"})
+
+	noCandidates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_failures_total",
+		Help:      "Operations that failed with NO_CANDIDATE, by operation.",
+	}, []string{"operation"})
+
+	bulkDeactivation = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bulk_deactivation_total",
+		Help:      "Bulk deactivation outcomes: deactivated users, reassigned and skipped reviewers, affected PRs.",
+	}, []string{"outcome"})
+)
+
+// Исходы массовой деактивации.
+const (
+	OutcomeDeactivated = "deactivated"
+	OutcomeReassigned  = "reassigned"
+	OutcomeSkipped     = "skipped"
+	OutcomeAffectedPRs = "affected_prs"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		prsCreated,
+		prsMerged,
+		reviewersAssigned,
+		reviewersUnassigned,
+		reassignments,
+		noCandidates,
+		bulkDeactivation,
+		dbQueryDuration,
+	)
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest учитывает завершённый запрос; route — шаблон маршрута, а не фактический путь.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func PRCreated() {
+	prsCreated.Inc()
+}
+
+func PRMerged() {
+	prsMerged.Inc()
+}
+
+func ReviewerAssigned(reason string) {
+	reviewersAssigned.WithLabelValues(reason).Inc()
+}
+
+func ReviewerUnassigned(reason string) {
+	reviewersUnassigned.WithLabelValues(reason).Inc()
+}
+
+func Reassigned(reason string) {
+	reassignments.WithLabelValues(reason).Inc()
+}
+
+func NoCandidate(operation string) {
+	noCandidates.WithLabelValues(operation).Inc()
+}
+
+func BulkDeactivation(outcome string, count int) {
+	bulkDeactivation.WithLabelValues(outcome).Add(float64(count))
+}
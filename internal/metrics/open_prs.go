@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var openPRsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_prs"),
+	"Open pull requests by author team.",
+	[]string{"team"}, nil,
+)
+
+// OpenPRsSource — откуда брать число открытых PR по командам при каждом опросе /metrics.
+type OpenPRsSource interface {
+	GetOpenPRsByTeam(ctx context.Context) ([]repository.TeamOpenPRs, error)
+}
+
+type openPRsCollector struct {
+	source OpenPRsSource
+}
+
+// RegisterOpenPRs добавляет gauge открытых PR по командам; значения читаются из БД при опросе.
+func RegisterOpenPRs(source OpenPRsSource) error {
+	return Registry.Register(openPRsCollector{source: source})
+}
+
+func (c openPRsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openPRsDesc
+}
+
+func (c openPRsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Collect не получает контекст запроса /metrics, поэтому запрос к БД идёт без трейса.
+	teams, err := c.source.GetOpenPRsByTeam(context.Background())
+	if err != nil {
+		config.Logger().Errorw("collect open PRs metric failed", "error", err)
+		ch <- prometheus.NewInvalidMetric(openPRsDesc, err)
+		return
+	}
+	for _, t := range teams {
+		ch <- prometheus.MustNewConstMetric(openPRsDesc, prometheus.GaugeValue, float64(t.OpenPRs), t.TeamName)
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Absence — период отсутствия пользователя (отпуск, больничный и т.п.), интервал [StartsAt, EndsAt).
+type Absence struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement"`
+	UserID   uint      `gorm:"not null;index:idx_absences_user_period"`
+	StartsAt time.Time `gorm:"not null;index:idx_absences_user_period"`
+	EndsAt   time.Time `gorm:"not null;index:idx_absences_user_period"`
+	Reason   string
+
+	User User `gorm:"constraint:OnDelete:CASCADE"`
+
+	// Когда фоновая задача переназначила открытые ревью пользователя; nil — ещё не обрабатывалось.
+	ReassignedAt *time.Time
+	CreatedAt    time.Time
+}
+
+// TeamHoliday — период, когда не работает вся команда, интервал [StartsAt, EndsAt).
+type TeamHoliday struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement"`
+	TeamID   uint      `gorm:"not null;index:idx_team_holidays_period"`
+	StartsAt time.Time `gorm:"not null;index:idx_team_holidays_period"`
+	EndsAt   time.Time `gorm:"not null;index:idx_team_holidays_period"`
+	Name     string
+
+	Team Team `gorm:"constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
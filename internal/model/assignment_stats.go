@@ -0,0 +1,22 @@
+package model
+
+// ReviewerAssignmentStat — счётчик текущих назначений ревьювера на PR одного автора в одном статусе,
+// созданные в один день. TeamID — нынешняя команда автора: при переводе автора в другую команду
+// его счётчики переезжают вместе с ним, поэтому статистика команды читается без соединения с users.
+type ReviewerAssignmentStat struct {
+	ReviewerID uint   `gorm:"primaryKey;autoIncrement:false"`
+	AuthorID   uint   `gorm:"primaryKey;autoIncrement:false;index"`
+	Status     string `gorm:"primaryKey"`
+	// CreatedOn — день создания PR по UTC в виде YYYY-MM-DD.
+	CreatedOn   string `gorm:"primaryKey"`
+	TeamID      uint   `gorm:"index:idx_reviewer_assignment_stats_team"`
+	Assignments int64  `gorm:"not null;default:0"`
+}
+
+// PRAssignmentStat — число текущих ревьюверов PR.
+type PRAssignmentStat struct {
+	PullRequestID uint  `gorm:"primaryKey;autoIncrement:false"`
+	Reviewers     int64 `gorm:"not null;default:0;index"`
+
+	PullRequest PullRequest `gorm:"constraint:OnDelete:CASCADE"`
+}
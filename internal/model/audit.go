@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// Типы событий журнала назначений.
+const (
+	AuditAssigned        = "assigned"
+	AuditUnassigned      = "unassigned"
+	AuditReplaced        = "replaced"
+	AuditMerged          = "merged"
+	AuditReviewed        = "reviewed"
+	AuditActivityChanged = "activity_changed"
+
+	AuditAbsenceAdded      = "absence_added"
+	AuditAbsenceDeleted    = "absence_deleted"
+	AuditHolidayAdded      = "holiday_added"
+	AuditDelegationAdded   = "delegation_added"
+	AuditDelegationDeleted = "delegation_deleted"
+)
+
+// Коды причин, по которым произошло событие журнала.
+const (
+	ReasonAutoAssign     = "auto_assign"
+	ReasonManual         = "manual"
+	ReasonReassign       = "reassign"
+	ReasonDelegation     = "delegation"
+	ReasonAbsence        = "absence"
+	ReasonBulkDeactivate = "bulk_deactivate"
+	ReasonMerge          = "merge"
+	ReasonReview         = "review"
+	ReasonDecline        = "decline"
+	ReasonSLAEscalation  = "sla_escalation"
+	ReasonSetActive      = "set_is_active"
+	ReasonConsistency    = "consistency_repair"
+)
+
+// Инициаторы, которые не приходят из запроса.
+const (
+	ActorSystem    = "system"
+	ActorAnonymous = "anonymous"
+)
+
+// AuditEvent — запись append-only журнала назначений.
+// Идентификаторы хранятся внешними (user_id, pull_request_id, имя команды), чтобы запись не зависела от дальнейших изменений.
+type AuditEvent struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement"`
+	Type   string `gorm:"not null;index"`
+	Reason string `gorm:"not null"`
+	Actor  string `gorm:"not null"`
+
+	PRID string `gorm:"index"`
+	// Пользователь, которого касается событие; для replaced — новый ревьювер.
+	UserID string `gorm:"index"`
+	// Для replaced — снятый ревьювер.
+	PreviousUserID string `gorm:"index"`
+	// Команда автора PR, для событий пользователя (activity_changed, отсутствия, делегирования) — его команда,
+	// для holiday_added — команда праздника.
+	TeamName string `gorm:"index"`
+	Details  string
+
+	OccurredAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Delegation — на интервале [StartsAt, EndsAt) ревью пользователя UserID по возможности получает DelegateID.
+type Delegation struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	UserID     uint      `gorm:"not null;index:idx_delegations_user_period"`
+	DelegateID uint      `gorm:"not null;index"`
+	StartsAt   time.Time `gorm:"not null;index:idx_delegations_user_period"`
+	EndsAt     time.Time `gorm:"not null;index:idx_delegations_user_period"`
+
+	User     User `gorm:"constraint:OnDelete:CASCADE"`
+	Delegate User `gorm:"constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// IdempotencyRecord — результат запроса с заголовком Idempotency-Key, который отдаётся повторно на ретраи.
+// Ключ действует в пределах маршрута (метод и шаблон пути).
+type IdempotencyRecord struct {
+	Key   string `gorm:"primaryKey;size:255"`
+	Route string `gorm:"primaryKey;size:255"`
+	// Fingerprint — хеш метода, пути, query и тела запроса; повтор ключа с другим запросом отклоняется.
+	Fingerprint string `gorm:"not null"`
+
+	// StatusCode 0 — запрос ещё выполняется, ответа пока нет.
+	StatusCode int `gorm:"not null;default:0"`
+	// Headers — сохранённые заголовки ответа в JSON.
+	Headers string
+	Body    []byte
+
+	CreatedAt time.Time
+	// ExpiresAt — после этого момента запись игнорируется и удаляется.
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// Completed сообщает, что ответ на запрос уже сохранён.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
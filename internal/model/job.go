@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Виды фоновых операций.
+const (
+	JobKindBulkDeactivate = "bulk_deactivate"
+)
+
+// Статусы задачи.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	// JobCompleted — все элементы обработаны; ошибки отдельных элементов остаются в их статусах.
+	JobCompleted = "completed"
+	JobCanceled  = "canceled"
+	// JobFailed — выполнение прерывалось слишком много раз; невыполненные элементы завершаются с ошибкой.
+	JobFailed = "failed"
+)
+
+// Статусы элемента задачи.
+const (
+	JobItemPending   = "pending"
+	JobItemSucceeded = "succeeded"
+	JobItemFailed    = "failed"
+	JobItemCanceled  = "canceled"
+)
+
+// Job — фоновая операция, которую выполняет пул обработчиков. Прогресс хранится в БД,
+// поэтому задачу, брошенную остановленным экземпляром, продолжит другой.
+type Job struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement"`
+	Kind   string `gorm:"not null"`
+	Status string `gorm:"not null;index"`
+	// Actor — инициатор из запроса; от его имени изменения пишутся в журнал.
+	Actor string
+	// Payload — параметры операции в JSON, общие для всех элементов.
+	Payload   string `gorm:"not null"`
+	Total     int    `gorm:"not null;default:0"`
+	Processed int    `gorm:"not null;default:0"`
+	// CancelRequested — запрошена отмена; обработчик останавливается перед следующим элементом.
+	CancelRequested bool `gorm:"not null;default:false"`
+
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	// HeartbeatAt — когда обработчик последний раз отмечался.
+	HeartbeatAt *time.Time
+	// LockedBy — обработчик, который держит задачу. Записывать её результаты может только он и только до LeaseUntil,
+	// а после LeaseUntil задачу подхватывает другой.
+	LockedBy   string
+	LeaseUntil *time.Time
+	// Attempts — сколько раз выполнение прерывалось ошибкой или падением обработчика.
+	Attempts int `gorm:"not null;default:0"`
+
+	Items []JobItem `gorm:"foreignKey:JobID"`
+}
+
+// Finished сообщает, что задача больше не будет выполняться.
+func (j Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobCanceled || j.Status == JobFailed
+}
+
+// JobItem — элемент задачи, например один деактивируемый пользователь, со своим результатом.
+type JobItem struct {
+	JobID uint `gorm:"primaryKey"`
+	Seq   int  `gorm:"primaryKey"`
+	// Key — над чем выполняется элемент, например user_id.
+	Key    string `gorm:"not null"`
+	Status string `gorm:"not null"`
+	// Result — итог элемента в JSON.
+	Result     string
+	Error      string
+	FinishedAt *time.Time
+}
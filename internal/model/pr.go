@@ -19,7 +19,28 @@ type PullRequest struct {
 			user_id (uint)
 	*/
 	AssignedReviewers []User `gorm:"many2many:pr_reviewers"`
+	// Строки pr_reviewers с признаком делегирования.
+	ReviewerLinks []PRReviewer `gorm:"foreignKey:PullRequestID"`
+
+	// Version растёт при каждом изменении PR и его ревьюверов; запись с устаревшей версией отклоняется.
+	Version uint `gorm:"not null;default:1"`
 
 	CreatedAt time.Time
 	UpdatedAt *time.Time
+	// MergedAt — время merge; у открытого PR пусто. UpdatedAt для этого не годится: GORM сдвигает его при каждом сохранении.
+	MergedAt *time.Time
+
+	// Пояснения, почему набор ревьюверов не удовлетворяет правилам команды. Не хранится в БД.
+	AssignmentWarnings []string `gorm:"-"`
+}
+
+// DelegatedFor возвращает пользователя, за которого ревьювер выполняет ревью, или nil.
+// Связи ReviewerLinks.DelegatedFor должны быть предзагружены.
+func (pr PullRequest) DelegatedFor(reviewerID uint) *User {
+	for _, link := range pr.ReviewerLinks {
+		if link.UserID == reviewerID && link.DelegatedForID != nil {
+			return link.DelegatedFor
+		}
+	}
+	return nil
 }
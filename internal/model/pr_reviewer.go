@@ -1,7 +1,16 @@
 package model
 
+import "time"
+
 // PRReviewer описывает join-таблицу pr_reviewers с индексами для ускорения вставок/поиска.
 type PRReviewer struct {
 	PullRequestID uint `gorm:"primaryKey;column:pull_request_id"`
 	UserID        uint `gorm:"primaryKey;column:user_id"`
+
+	// Когда ревьювер назначен на PR; от этого момента считается SLA.
+	AssignedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	// За кого ревьювер выполняет ревью по делегированию; nil — назначен сам за себя.
+	DelegatedForID *uint `gorm:"column:delegated_for_id"`
+	DelegatedFor   *User `gorm:"foreignKey:DelegatedForID"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// PRReviewerPeriod — интервал [ValidFrom, ValidTo), в течение которого пользователь был ревьювером PR.
+// pr_reviewers хранит только текущее состояние, история назначений живёт здесь.
+type PRReviewerPeriod struct {
+	ID            uint `gorm:"primaryKey;autoIncrement"`
+	PullRequestID uint `gorm:"not null;index:idx_reviewer_periods_pr"`
+	UserID        uint `gorm:"not null;index:idx_reviewer_periods_user"`
+
+	ValidFrom time.Time `gorm:"not null;index:idx_reviewer_periods_pr;index:idx_reviewer_periods_user"`
+	// nil — назначение действует до сих пор.
+	ValidTo *time.Time
+
+	// За кого ревьювер выполнял ревью по делегированию.
+	DelegatedForID *uint
+
+	// Первое ревью-действие ревьювера в этом назначении; nil — ещё не было.
+	ReviewedAt *time.Time
+
+	PullRequest  PullRequest `gorm:"constraint:OnDelete:CASCADE"`
+	User         User        `gorm:"constraint:OnDelete:CASCADE"`
+	DelegatedFor *User       `gorm:"foreignKey:DelegatedForID"`
+}
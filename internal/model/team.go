@@ -1,7 +1,50 @@
 package model
 
+// DefaultMaxReviewers — лимит ревьюверов на PR по ТЗ, если команда не задала собственный.
+const DefaultMaxReviewers = 2
+
+// Режимы выбора ревьюверов.
+const (
+	// AssignmentModeRandom — случайный выбор среди доступных участников (по ТЗ).
+	AssignmentModeRandom = "random"
+	// AssignmentModeWorkingHours — сначала участники, у которых сейчас рабочее время.
+	AssignmentModeWorkingHours = "working_hours"
+)
+
 type Team struct {
 	ID    uint   `gorm:"primaryKey;autoIncrement"`
 	Name  string `gorm:"uniqueIndex;not null"`
 	Users []User `gorm:"foreignKey:TeamID"`
+
+	// Сколько ревьюверов максимум может быть назначено на PR авторов этой команды.
+	MaxReviewers int `gorm:"not null;default:2"`
+	// В каждом наборе ревьюверов должен быть хотя бы один senior или lead.
+	RequireSenior bool `gorm:"not null;default:false"`
+	// Junior не может оставаться единственным ревьювером PR.
+	ForbidSoleJunior bool `gorm:"not null;default:false"`
+	// Один из AssignmentMode*; пустое значение трактуется как random.
+	AssignmentMode string `gorm:"not null;default:random"`
+	// Сколько рабочих часов ревьювера даётся на ревью; 0 — SLA не отслеживается.
+	ReviewSLAHours int `gorm:"not null;default:0"`
+}
+
+// ReviewerLimit возвращает лимит ревьюверов команды с учётом значения по умолчанию.
+func (t Team) ReviewerLimit() int {
+	if t.MaxReviewers > 0 {
+		return t.MaxReviewers
+	}
+	return DefaultMaxReviewers
+}
+
+// AssignmentModeOrDefault возвращает режим выбора ревьюверов с учётом значения по умолчанию.
+func (t Team) AssignmentModeOrDefault() string {
+	if t.AssignmentMode == "" {
+		return AssignmentModeRandom
+	}
+	return t.AssignmentMode
+}
+
+// PrefersWorkingHours сообщает, что команда выбирает ревьюверов с учётом их рабочего времени.
+func (t Team) PrefersWorkingHours() bool {
+	return t.AssignmentMode == AssignmentModeWorkingHours
 }
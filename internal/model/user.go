@@ -1,12 +1,48 @@
 package model
 
+// Уровни сеньорности участников команды.
+const (
+	SeniorityJunior = "junior"
+	SeniorityMiddle = "middle"
+	SenioritySenior = "senior"
+	SeniorityLead   = "lead"
+)
+
 type User struct {
 	ID       uint   `gorm:"primaryKey;autoIncrement"`
 	UserID   string `gorm:"uniqueIndex;not null"`
 	Username string `gorm:"not null"`
 	IsActive bool   `gorm:"default:true"`
 
+	// Один из Seniority*; пустое значение трактуется как middle.
+	Seniority string `gorm:"not null;default:middle"`
+
+	// IANA-зона пользователя (например, Europe/Moscow) и рабочее окно "HH:MM" в её локальном времени.
+	// Рабочие дни — понедельник–пятница; пустые значения трактуются как Default*.
+	TimeZone  string `gorm:"not null;default:UTC"`
+	WorkStart string `gorm:"not null;default:09:00"`
+	WorkEnd   string `gorm:"not null;default:18:00"`
+
 	TeamID uint
 	// Позволяет удалить всех юзеров вместе с Team объектом
 	Team Team `gorm:"constraint:OnDelete:CASCADE"`
 }
+
+// SeniorityLevel возвращает уровень пользователя с учётом значения по умолчанию.
+func (u User) SeniorityLevel() string {
+	if u.Seniority == "" {
+		return SeniorityMiddle
+	}
+	return u.Seniority
+}
+
+// IsJunior сообщает, что пользователь — junior.
+func (u User) IsJunior() bool {
+	return u.SeniorityLevel() == SeniorityJunior
+}
+
+// IsSenior сообщает, что пользователь закрывает требование «хотя бы один senior» (senior или lead).
+func (u User) IsSenior() bool {
+	level := u.SeniorityLevel()
+	return level == SenioritySenior || level == SeniorityLead
+}
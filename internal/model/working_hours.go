@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+// Значения рабочего времени пользователя по умолчанию.
+const (
+	DefaultTimeZone  = "UTC"
+	DefaultWorkStart = "09:00"
+	DefaultWorkEnd   = "18:00"
+)
+
+// ParseClock разбирает время суток "HH:MM" в минуты от полуночи.
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Location возвращает часовой пояс пользователя; неизвестная зона трактуется как UTC.
+func (u User) Location() *time.Location {
+	if u.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// workWindow возвращает начало и конец рабочего окна в минутах от полуночи.
+func (u User) workWindow() (int, int) {
+	start, err := ParseClock(u.WorkStart)
+	if err != nil {
+		start, _ = ParseClock(DefaultWorkStart)
+	}
+	end, err := ParseClock(u.WorkEnd)
+	if err != nil || end <= start {
+		start, _ = ParseClock(DefaultWorkStart)
+		end, _ = ParseClock(DefaultWorkEnd)
+	}
+	return start, end
+}
+
+// IsWorkingAt сообщает, попадает ли момент t в рабочее окно пользователя.
+func (u User) IsWorkingAt(t time.Time) bool {
+	local := t.In(u.Location())
+	if !isWorkday(local.Weekday()) {
+		return false
+	}
+	start, end := u.workWindow()
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= start && minute < end
+}
+
+// WorkingDuration возвращает, сколько рабочего времени пользователя приходится на интервал [from, to).
+func (u User) WorkingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	loc := u.Location()
+	start, end := u.workWindow()
+	from, to = from.In(loc), to.In(loc)
+
+	var total time.Duration
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for day.Before(to) {
+		if isWorkday(day.Weekday()) {
+			windowStart := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, loc)
+			windowEnd := time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, loc)
+			if windowStart.Before(from) {
+				windowStart = from
+			}
+			if windowEnd.After(to) {
+				windowEnd = to
+			}
+			if windowEnd.After(windowStart) {
+				total += windowEnd.Sub(windowStart)
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return total
+}
+
+func isWorkday(day time.Weekday) bool {
+	return day != time.Saturday && day != time.Sunday
+}
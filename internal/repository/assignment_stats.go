@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// assignmentKey — измерения, по которым PR попадает в счётчики назначений.
+type assignmentKey struct {
+	AuthorID  uint
+	TeamID    uint
+	Status    string
+	CreatedOn string
+}
+
+func loadAssignmentKey(tx *gorm.DB, prID uint) (assignmentKey, error) {
+	var row struct {
+		AuthorID  uint
+		TeamID    uint
+		Status    string
+		CreatedAt time.Time
+	}
+	err := tx.Table("pull_requests p").
+		Select("p.author_id AS author_id, a.team_id AS team_id, p.status AS status, p.created_at AS created_at").
+		Joins("JOIN users a ON a.id = p.author_id").
+		Where("p.id = ?", prID).
+		Take(&row).Error
+	return assignmentKey{AuthorID: row.AuthorID, TeamID: row.TeamID, Status: row.Status, CreatedOn: createdOn(row.CreatedAt)}, err
+}
+
+// createdOn переводит время создания PR в день счётчика.
+func createdOn(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// createdOnSQL — то же, что createdOn, для столбца created_at таблицы с алиасом p.
+func createdOnSQL(db *gorm.DB) string {
+	if db.Dialector.Name() == "postgres" {
+		return "to_char(p.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	}
+	return "strftime('%Y-%m-%d', p.created_at)"
+}
+
+// trackAssignments обновляет счётчики после изменения ревьюверов PR в той же транзакции.
+// added и removed — ревьюверы, которые действительно появились и исчезли в pr_reviewers.
+func trackAssignments(tx *gorm.DB, prID uint, added, removed []uint) error {
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	key, err := loadAssignmentKey(tx, prID)
+	if err != nil {
+		return err
+	}
+	if err := adjustReviewerStats(tx, key, added, 1); err != nil {
+		return err
+	}
+	if err := adjustReviewerStats(tx, key, removed, -1); err != nil {
+		return err
+	}
+	return adjustPRStats(tx, prID, int64(len(added)-len(removed)))
+}
+
+// moveAssignments переносит назначения ревьюверов PR между ключами, например при merge или смене автора.
+func moveAssignments(tx *gorm.DB, prID uint, from, to assignmentKey) error {
+	if from == to {
+		return nil
+	}
+	var reviewers []uint
+	if err := tx.Model(&model.PRReviewer{}).Where("pull_request_id = ?", prID).Pluck("user_id", &reviewers).Error; err != nil {
+		return err
+	}
+	if err := adjustReviewerStats(tx, from, reviewers, -1); err != nil {
+		return err
+	}
+	return adjustReviewerStats(tx, to, reviewers, 1)
+}
+
+func adjustReviewerStats(tx *gorm.DB, key assignmentKey, reviewerIDs []uint, delta int64) error {
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+	rows := make([]model.ReviewerAssignmentStat, 0, len(reviewerIDs))
+	for _, id := range reviewerIDs {
+		rows = append(rows, model.ReviewerAssignmentStat{
+			ReviewerID:  id,
+			AuthorID:    key.AuthorID,
+			Status:      key.Status,
+			CreatedOn:   key.CreatedOn,
+			TeamID:      key.TeamID,
+			Assignments: delta,
+		})
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "reviewer_id"}, {Name: "author_id"}, {Name: "status"}, {Name: "created_on"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"assignments": gorm.Expr("reviewer_assignment_stats.assignments + excluded.assignments"),
+		}),
+	}).Create(&rows).Error
+}
+
+func adjustPRStats(tx *gorm.DB, prID uint, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+	row := model.PRAssignmentStat{PullRequestID: prID, Reviewers: delta}
+	return tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "pull_request_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"reviewers": gorm.Expr("pr_assignment_stats.reviewers + excluded.reviewers"),
+		}),
+	}).Create(&row).Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"gorm.io/gorm"
+)
+
+type (
+	// AuditFilter — условия выборки журнала; пустые поля не ограничивают выборку.
+	AuditFilter struct {
+		PRID     string
+		UserID   string
+		TeamName string
+		From     *time.Time
+		To       *time.Time
+		Limit    int
+	}
+
+	// AuditRepository — append-only журнал назначений: записи только добавляются.
+	AuditRepository interface {
+		Append(ctx context.Context, events []model.AuditEvent) error
+		Find(ctx context.Context, filter AuditFilter) ([]model.AuditEvent, error)
+	}
+
+	GormAuditRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewAuditRepository(db *gorm.DB) *GormAuditRepository {
+	return &GormAuditRepository{db}
+}
+
+func (r *GormAuditRepository) Append(ctx context.Context, events []model.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := conn(ctx, r.db).Create(&events).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db append audit events failed", "count", len(events), "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db audit events appended", "count", len(events))
+	return nil
+}
+
+// Find возвращает события от новых к старым; фильтр по пользователю учитывает и снятого ревьювера.
+func (r *GormAuditRepository) Find(ctx context.Context, filter AuditFilter) ([]model.AuditEvent, error) {
+	query := conn(ctx, r.db).Model(&model.AuditEvent{})
+	if filter.PRID != "" {
+		query = query.Where("pr_id = ?", filter.PRID)
+	}
+	if filter.UserID != "" {
+		query = query.Where("(user_id = ? OR previous_user_id = ?)", filter.UserID, filter.UserID)
+	}
+	if filter.TeamName != "" {
+		query = query.Where("team_name = ?", filter.TeamName)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []model.AuditEvent
+	if err := query.Order("occurred_at DESC, id DESC").Find(&events).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db audit lookup failed", "filter", filter, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db audit events loaded", "count", len(events))
+	return events, nil
+}
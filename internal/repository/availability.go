@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// AvailabilityRepository хранит отсутствия пользователей, праздники команд и делегирования ревью.
+	AvailabilityRepository interface {
+		CreateAbsence(ctx context.Context, absence *model.Absence) error
+		// GetAbsence возвращает отсутствие с пользователем и его командой.
+		GetAbsence(ctx context.Context, id uint) (*model.Absence, error)
+		DeleteAbsence(ctx context.Context, id uint) error
+		GetAbsencesByUser(ctx context.Context, userID uint) ([]model.Absence, error)
+		// HasOverlappingAbsence сообщает, есть ли у пользователя отсутствие, пересекающееся с [startsAt, endsAt).
+		// Строка пользователя блокируется до конца транзакции, чтобы параллельные проверки не пропустили друг друга.
+		HasOverlappingAbsence(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error)
+		// GetPendingAbsences возвращает начавшиеся и ещё не закончившиеся отсутствия, по которым не переназначались ревью.
+		GetPendingAbsences(ctx context.Context, at time.Time) ([]model.Absence, error)
+		MarkAbsenceReassigned(ctx context.Context, id uint, at time.Time) error
+
+		CreateHoliday(ctx context.Context, holiday *model.TeamHoliday) error
+		GetHolidaysByTeam(ctx context.Context, teamID uint) ([]model.TeamHoliday, error)
+
+		// GetAbsentUserIDs возвращает участников команды, которые отсутствуют в момент at
+		// (личное отсутствие или праздник команды).
+		GetAbsentUserIDs(ctx context.Context, teamID uint, at time.Time) ([]uint, error)
+
+		CreateDelegation(ctx context.Context, delegation *model.Delegation) error
+		// GetDelegation возвращает делегирование с пользователем, его командой и делегатом.
+		GetDelegation(ctx context.Context, id uint) (*model.Delegation, error)
+		DeleteDelegation(ctx context.Context, id uint) error
+		GetDelegationsByUser(ctx context.Context, userID uint) ([]model.Delegation, error)
+		// HasOverlappingDelegation сообщает, есть ли у пользователя делегирование, пересекающееся с [startsAt, endsAt).
+		HasOverlappingDelegation(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error)
+		// GetActiveDelegations возвращает делегирования участников команды, действующие в момент at, с предзагруженным Delegate.
+		GetActiveDelegations(ctx context.Context, teamID uint, at time.Time) ([]model.Delegation, error)
+	}
+
+	GormAvailabilityRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewAvailabilityRepository(db *gorm.DB) *GormAvailabilityRepository {
+	return &GormAvailabilityRepository{db}
+}
+
+func (r *GormAvailabilityRepository) CreateAbsence(ctx context.Context, absence *model.Absence) error {
+	if err := conn(ctx, r.db).Omit("User").Create(absence).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db create absence failed", "user_id", absence.UserID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db absence created", "absence_id", absence.ID, "user_id", absence.UserID)
+	return nil
+}
+
+func (r *GormAvailabilityRepository) GetAbsence(ctx context.Context, id uint) (*model.Absence, error) {
+	var absence model.Absence
+	if err := conn(ctx, r.db).Preload("User.Team").First(&absence, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			config.LoggerFrom(ctx).Warnw("db absence not found", "absence_id", id)
+			return nil, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get absence failed", "absence_id", id, "error", err)
+		return nil, err
+	}
+	return &absence, nil
+}
+
+func (r *GormAvailabilityRepository) DeleteAbsence(ctx context.Context, id uint) error {
+	res := conn(ctx, r.db).Delete(&model.Absence{}, id)
+	if res.Error != nil {
+		config.LoggerFrom(ctx).Errorw("db delete absence failed", "absence_id", id, "error", res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		config.LoggerFrom(ctx).Warnw("db absence not found for delete", "absence_id", id)
+		return repoerrs.ErrNotFound
+	}
+	config.LoggerFrom(ctx).Debugw("db absence deleted", "absence_id", id)
+	return nil
+}
+
+func (r *GormAvailabilityRepository) GetAbsencesByUser(ctx context.Context, userID uint) ([]model.Absence, error) {
+	var absences []model.Absence
+	if err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("starts_at ASC").
+		Find(&absences).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db list absences failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db absences loaded", "user_id", userID, "count", len(absences))
+	return absences, nil
+}
+
+func (r *GormAvailabilityRepository) HasOverlappingAbsence(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error) {
+	db := conn(ctx, r.db)
+	var locked int64
+	if err := db.Model(&model.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", userID).
+		Scan(&locked).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db user lock failed", "user_id", userID, "error", err)
+		return false, err
+	}
+
+	var count int64
+	if err := db.Model(&model.Absence{}).
+		Where("user_id = ? AND starts_at < ? AND ends_at > ?", userID, endsAt, startsAt).
+		Count(&count).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db overlapping absence lookup failed", "user_id", userID, "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GormAvailabilityRepository) GetPendingAbsences(ctx context.Context, at time.Time) ([]model.Absence, error) {
+	var absences []model.Absence
+	if err := conn(ctx, r.db).
+		Preload("User").
+		Where("starts_at <= ? AND ends_at > ? AND reassigned_at IS NULL", at, at).
+		Order("starts_at ASC").
+		Find(&absences).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db list pending absences failed", "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db pending absences loaded", "count", len(absences))
+	return absences, nil
+}
+
+func (r *GormAvailabilityRepository) MarkAbsenceReassigned(ctx context.Context, id uint, at time.Time) error {
+	if err := conn(ctx, r.db).Model(&model.Absence{}).
+		Where("id = ?", id).
+		Update("reassigned_at", at).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db mark absence reassigned failed", "absence_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *GormAvailabilityRepository) CreateHoliday(ctx context.Context, holiday *model.TeamHoliday) error {
+	if err := conn(ctx, r.db).Omit("Team").Create(holiday).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db create team holiday failed", "team_id", holiday.TeamID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db team holiday created", "holiday_id", holiday.ID, "team_id", holiday.TeamID)
+	return nil
+}
+
+func (r *GormAvailabilityRepository) GetHolidaysByTeam(ctx context.Context, teamID uint) ([]model.TeamHoliday, error) {
+	var holidays []model.TeamHoliday
+	if err := conn(ctx, r.db).
+		Where("team_id = ?", teamID).
+		Order("starts_at ASC").
+		Find(&holidays).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db list team holidays failed", "team_id", teamID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db team holidays loaded", "team_id", teamID, "count", len(holidays))
+	return holidays, nil
+}
+
+func (r *GormAvailabilityRepository) GetAbsentUserIDs(ctx context.Context, teamID uint, at time.Time) ([]uint, error) {
+	var ids []uint
+	err := conn(ctx, r.db).
+		Model(&model.User{}).
+		Where("users.team_id = ?", teamID).
+		Where(conn(ctx, r.db).
+			Where("EXISTS (SELECT 1 FROM absences a WHERE a.user_id = users.id AND a.starts_at <= ? AND a.ends_at > ?)", at, at).
+			Or("EXISTS (SELECT 1 FROM team_holidays h WHERE h.team_id = users.team_id AND h.starts_at <= ? AND h.ends_at > ?)", at, at)).
+		Pluck("users.id", &ids).Error
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db absent users lookup failed", "team_id", teamID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db absent users loaded", "team_id", teamID, "count", len(ids))
+	return ids, nil
+}
+
+func (r *GormAvailabilityRepository) CreateDelegation(ctx context.Context, delegation *model.Delegation) error {
+	if err := conn(ctx, r.db).Omit("User", "Delegate").Create(delegation).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db create delegation failed", "user_id", delegation.UserID, "delegate_id", delegation.DelegateID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db delegation created", "delegation_id", delegation.ID, "user_id", delegation.UserID)
+	return nil
+}
+
+func (r *GormAvailabilityRepository) GetDelegation(ctx context.Context, id uint) (*model.Delegation, error) {
+	var delegation model.Delegation
+	if err := conn(ctx, r.db).Preload("User.Team").Preload("Delegate").First(&delegation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			config.LoggerFrom(ctx).Warnw("db delegation not found", "delegation_id", id)
+			return nil, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get delegation failed", "delegation_id", id, "error", err)
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *GormAvailabilityRepository) DeleteDelegation(ctx context.Context, id uint) error {
+	res := conn(ctx, r.db).Delete(&model.Delegation{}, id)
+	if res.Error != nil {
+		config.LoggerFrom(ctx).Errorw("db delete delegation failed", "delegation_id", id, "error", res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		config.LoggerFrom(ctx).Warnw("db delegation not found for delete", "delegation_id", id)
+		return repoerrs.ErrNotFound
+	}
+	config.LoggerFrom(ctx).Debugw("db delegation deleted", "delegation_id", id)
+	return nil
+}
+
+func (r *GormAvailabilityRepository) GetDelegationsByUser(ctx context.Context, userID uint) ([]model.Delegation, error) {
+	var delegations []model.Delegation
+	if err := conn(ctx, r.db).
+		Preload("Delegate").
+		Where("user_id = ?", userID).
+		Order("starts_at ASC").
+		Find(&delegations).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db list delegations failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db delegations loaded", "user_id", userID, "count", len(delegations))
+	return delegations, nil
+}
+
+func (r *GormAvailabilityRepository) HasOverlappingDelegation(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error) {
+	var count int64
+	if err := conn(ctx, r.db).
+		Model(&model.Delegation{}).
+		Where("user_id = ? AND starts_at < ? AND ends_at > ?", userID, endsAt, startsAt).
+		Count(&count).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db overlapping delegation lookup failed", "user_id", userID, "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GormAvailabilityRepository) GetActiveDelegations(ctx context.Context, teamID uint, at time.Time) ([]model.Delegation, error) {
+	var delegations []model.Delegation
+	if err := conn(ctx, r.db).
+		Preload("Delegate").
+		Joins("JOIN users ON users.id = delegations.user_id").
+		Where("users.team_id = ?", teamID).
+		Where("delegations.starts_at <= ? AND delegations.ends_at > ?", at, at).
+		Find(&delegations).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db active delegations lookup failed", "team_id", teamID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db active delegations loaded", "team_id", teamID, "count", len(delegations))
+	return delegations, nil
+}
@@ -6,4 +6,29 @@ var (
 	ErrNotFound   = errors.New("entity not found")
 	ErrDuplicate  = errors.New("entity already exists")
 	ErrConstraint = errors.New("constraint violation")
+	// ErrConflict — запись изменилась с момента чтения (устаревшая версия).
+	ErrConflict = errors.New("entity was modified concurrently")
+	// ErrLeaseLost — обработчик больше не держит задачу: срок аренды истёк или её забрал другой.
+	ErrLeaseLost = errors.New("job lease lost")
 )
+
+// Инварианты состава ревьюверов, которые БД проверяет триггерами на pr_reviewers.
+const (
+	InvariantReviewerIsAuthor = "author"
+	InvariantReviewerLimit    = "limit"
+	InvariantReviewerTeam     = "team"
+)
+
+// ReviewerInvariantError — запись в pr_reviewers нарушила инвариант состава ревьюверов.
+// errors.Is(err, ErrConstraint) для неё истинно.
+type ReviewerInvariantError struct {
+	Invariant string
+}
+
+func (e *ReviewerInvariantError) Error() string {
+	return "reviewer invariant violated: " + e.Invariant
+}
+
+func (e *ReviewerInvariantError) Is(target error) bool {
+	return target == ErrConstraint
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"gorm.io/gorm"
+)
+
+type (
+	IdempotencyRepository interface {
+		Get(ctx context.Context, key, route string) (*model.IdempotencyRecord, error)
+		// Create резервирует ключ; если он уже занят, возвращает repoerrs.ErrDuplicate.
+		Create(ctx context.Context, record *model.IdempotencyRecord) error
+		Save(ctx context.Context, record *model.IdempotencyRecord) error
+		Delete(ctx context.Context, key, route string) error
+		// DeleteExpired удаляет записи, истёкшие к моменту before, и возвращает их число.
+		DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+	}
+
+	GormIdempotencyRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewIdempotencyRepository(db *gorm.DB) *GormIdempotencyRepository {
+	return &GormIdempotencyRepository{db}
+}
+
+func (r *GormIdempotencyRepository) Get(ctx context.Context, key, route string) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	if err := conn(ctx, r.db).Where("key = ? AND route = ?", key, route).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get idempotency record failed", "key", key, "route", route, "error", err)
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *GormIdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := conn(ctx, r.db).Create(record).Error; err != nil {
+		if isDuplicate(r.db, err) {
+			config.LoggerFrom(ctx).Debugw("db idempotency key already reserved", "key", record.Key, "route", record.Route)
+			return repoerrs.ErrDuplicate
+		}
+		config.LoggerFrom(ctx).Errorw("db reserve idempotency key failed", "key", record.Key, "route", record.Route, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *GormIdempotencyRepository) Save(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := conn(ctx, r.db).Save(record).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db save idempotency record failed", "key", record.Key, "route", record.Route, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *GormIdempotencyRepository) Delete(ctx context.Context, key, route string) error {
+	if err := conn(ctx, r.db).Where("key = ? AND route = ?", key, route).Delete(&model.IdempotencyRecord{}).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db delete idempotency record failed", "key", key, "route", route, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *GormIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res := conn(ctx, r.db).Where("expires_at <= ?", before).Delete(&model.IdempotencyRecord{})
+	if res.Error != nil {
+		config.LoggerFrom(ctx).Errorw("db purge idempotency records failed", "error", res.Error)
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
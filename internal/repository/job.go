@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"gorm.io/gorm"
+)
+
+type (
+	JobRepository interface {
+		// Create сохраняет задачу вместе с элементами.
+		Create(ctx context.Context, job *model.Job) error
+		// Get возвращает задачу с элементами в порядке Seq.
+		Get(ctx context.Context, id uint) (*model.Job, error)
+		// ClaimNext отдаёт обработчику owner до leaseUntil самую старую задачу из очереди или задачу, чья аренда
+		// истекла к now, и возвращает её с элементами. Повторный захват брошенной задачи увеличивает Attempts.
+		// Если брать нечего — repoerrs.ErrNotFound. Задачу забирает только один из конкурирующих обработчиков.
+		ClaimNext(ctx context.Context, owner string, now, leaseUntil time.Time) (*model.Job, error)
+		// SaveItem записывает статус и результат элемента, если задачу на момент now держит owner,
+		// иначе возвращает repoerrs.ErrLeaseLost.
+		SaveItem(ctx context.Context, owner string, now time.Time, item *model.JobItem) error
+		// SaveProgress записывает статус, прогресс, аренду и попытки задачи, если её на момент now держит owner,
+		// иначе возвращает repoerrs.ErrLeaseLost. Флаг отмены не трогает.
+		SaveProgress(ctx context.Context, owner string, now time.Time, job *model.Job) error
+		// RequestCancel ставит флаг отмены, а задачу из очереди сразу отменяет. Возвращает задачу без элементов.
+		RequestCancel(ctx context.Context, id uint, now time.Time) (*model.Job, error)
+		// CancelRequested сообщает, запрошена ли отмена задачи.
+		CancelRequested(ctx context.Context, id uint) (bool, error)
+	}
+
+	GormJobRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewJobRepository(db *gorm.DB) *GormJobRepository {
+	return &GormJobRepository{db}
+}
+
+func (r *GormJobRepository) Create(ctx context.Context, job *model.Job) error {
+	if err := conn(ctx, r.db).Create(job).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db create job failed", "kind", job.Kind, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db job created", "job_id", job.ID, "kind", job.Kind, "items", len(job.Items))
+	return nil
+}
+
+func (r *GormJobRepository) Get(ctx context.Context, id uint) (*model.Job, error) {
+	var job model.Job
+	if err := conn(ctx, r.db).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("seq") }).
+		First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			config.LoggerFrom(ctx).Warnw("db job not found", "job_id", id)
+			return nil, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get job failed", "job_id", id, "error", err)
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *GormJobRepository) ClaimNext(ctx context.Context, owner string, now, leaseUntil time.Time) (*model.Job, error) {
+	logger := config.LoggerFrom(ctx)
+	// Задачи, взятые до появления аренды, срока не имеют и считаются брошенными.
+	claimable := func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? OR (status = ? AND (lease_until IS NULL OR lease_until <= ?))", model.JobQueued, model.JobRunning, now)
+	}
+	// Без SKIP LOCKED, чтобы запрос работал и в SQLite: кандидат забирается условным UPDATE,
+	// и если его успел забрать другой обработчик, берётся следующий.
+	for {
+		var job model.Job
+		if err := conn(ctx, r.db).Scopes(claimable).Order("id").First(&job).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, repoerrs.ErrNotFound
+			}
+			logger.Errorw("db find job to claim failed", "error", err)
+			return nil, err
+		}
+
+		res := conn(ctx, r.db).Model(&model.Job{}).
+			Where("id = ?", job.ID).
+			Scopes(claimable).
+			Updates(map[string]any{
+				"status":       model.JobRunning,
+				"started_at":   gorm.Expr("COALESCE(started_at, ?)", now),
+				"heartbeat_at": now,
+				"locked_by":    owner,
+				"lease_until":  leaseUntil,
+				"attempts":     gorm.Expr("attempts + CASE WHEN status = ? THEN 1 ELSE 0 END", model.JobRunning),
+			})
+		if res.Error != nil {
+			logger.Errorw("db claim job failed", "job_id", job.ID, "error", res.Error)
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			logger.Debugw("db job claimed by another worker", "job_id", job.ID)
+			continue
+		}
+		if job.Status == model.JobRunning {
+			logger.Warnw("db stale job reclaimed", "job_id", job.ID, "locked_by", job.LockedBy, "lease_until", job.LeaseUntil)
+		}
+		return r.Get(ctx, job.ID)
+	}
+}
+
+func (r *GormJobRepository) SaveItem(ctx context.Context, owner string, now time.Time, item *model.JobItem) error {
+	logger := config.LoggerFrom(ctx)
+	res := conn(ctx, r.db).Model(item).
+		Where("EXISTS (SELECT 1 FROM jobs WHERE jobs.id = job_items.job_id AND jobs.locked_by = ? AND jobs.lease_until > ?)", owner, now).
+		Select("Status", "Result", "Error", "FinishedAt").
+		Updates(item)
+	if res.Error != nil {
+		logger.Errorw("db save job item failed", "job_id", item.JobID, "seq", item.Seq, "error", res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		logger.Warnw("db job item not saved, lease lost", "job_id", item.JobID, "seq", item.Seq, "owner", owner)
+		return repoerrs.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *GormJobRepository) SaveProgress(ctx context.Context, owner string, now time.Time, job *model.Job) error {
+	logger := config.LoggerFrom(ctx)
+	res := conn(ctx, r.db).Model(job).
+		Where("locked_by = ? AND lease_until > ?", owner, now).
+		Select("Status", "Processed", "FinishedAt", "HeartbeatAt", "LockedBy", "LeaseUntil", "Attempts").
+		Updates(job)
+	if res.Error != nil {
+		logger.Errorw("db save job progress failed", "job_id", job.ID, "error", res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		logger.Warnw("db job progress not saved, lease lost", "job_id", job.ID, "owner", owner)
+		return repoerrs.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *GormJobRepository) RequestCancel(ctx context.Context, id uint, now time.Time) (*model.Job, error) {
+	logger := config.LoggerFrom(ctx)
+	res := conn(ctx, r.db).Model(&model.Job{}).
+		Where("id = ? AND status IN ?", id, []string{model.JobQueued, model.JobRunning}).
+		Update("cancel_requested", true)
+	if res.Error != nil {
+		logger.Errorw("db request job cancel failed", "job_id", id, "error", res.Error)
+		return nil, res.Error
+	}
+	// Задачу из очереди обработчик ещё не взял, поэтому её элементы отменяются сразу.
+	if err := conn(ctx, r.db).Model(&model.Job{}).
+		Where("id = ? AND status = ?", id, model.JobQueued).
+		Updates(map[string]any{"status": model.JobCanceled, "finished_at": now}).Error; err != nil {
+		logger.Errorw("db cancel queued job failed", "job_id", id, "error", err)
+		return nil, err
+	}
+	if err := conn(ctx, r.db).Model(&model.JobItem{}).
+		Where("job_id = ? AND status = ?", id, model.JobItemPending).
+		Where("EXISTS (SELECT 1 FROM jobs WHERE jobs.id = job_items.job_id AND jobs.status = ?)", model.JobCanceled).
+		Updates(map[string]any{"status": model.JobItemCanceled, "finished_at": now}).Error; err != nil {
+		logger.Errorw("db cancel queued job items failed", "job_id", id, "error", err)
+		return nil, err
+	}
+
+	var job model.Job
+	if err := conn(ctx, r.db).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warnw("db job to cancel not found", "job_id", id)
+			return nil, repoerrs.ErrNotFound
+		}
+		logger.Errorw("db get canceled job failed", "job_id", id, "error", err)
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *GormJobRepository) CancelRequested(ctx context.Context, id uint) (bool, error) {
+	var job model.Job
+	if err := conn(ctx, r.db).Select("cancel_requested").First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get job cancel flag failed", "job_id", id, "error", err)
+		return false, err
+	}
+	return job.CancelRequested, nil
+}
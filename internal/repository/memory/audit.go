@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+)
+
+// AuditRepository — append-only журнал назначений в памяти.
+type AuditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+func (r *AuditRepository) Append(ctx context.Context, events []model.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.store.exec(ctx, func(t *tables) error {
+		t.write(tableAudit)
+		for i := range events {
+			t.seq.audit++
+			events[i].ID = t.seq.audit
+			if events[i].OccurredAt.IsZero() {
+				events[i].OccurredAt = time.Now()
+			}
+			t.audit = append(t.audit, events[i])
+		}
+		return nil
+	})
+}
+
+// Find возвращает события от новых к старым; фильтр по пользователю учитывает и снятого ревьювера.
+func (r *AuditRepository) Find(ctx context.Context, filter repository.AuditFilter) ([]model.AuditEvent, error) {
+	var events []model.AuditEvent
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, event := range t.audit {
+			if filter.PRID != "" && event.PRID != filter.PRID {
+				continue
+			}
+			if filter.UserID != "" && event.UserID != filter.UserID && event.PreviousUserID != filter.UserID {
+				continue
+			}
+			if filter.TeamName != "" && event.TeamName != filter.TeamName {
+				continue
+			}
+			if filter.From != nil && event.OccurredAt.Before(*filter.From) {
+				continue
+			}
+			if filter.To != nil && !event.OccurredAt.Before(*filter.To) {
+				continue
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// События хранятся в порядке ID, поэтому разворот и устойчивая сортировка дают ORDER BY occurred_at DESC, id DESC.
+	slices.Reverse(events)
+	slices.SortStableFunc(events, func(a, b model.AuditEvent) int { return b.OccurredAt.Compare(a.OccurredAt) })
+	if filter.Limit > 0 && filter.Limit < len(events) {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type AvailabilityRepository struct {
+	store *Store
+}
+
+func NewAvailabilityRepository(store *Store) *AvailabilityRepository {
+	return &AvailabilityRepository{store: store}
+}
+
+func (r *AvailabilityRepository) CreateAbsence(ctx context.Context, absence *model.Absence) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if absence.CreatedAt.IsZero() {
+			absence.CreatedAt = time.Now()
+		}
+		t.seq.absence++
+		absence.ID = t.seq.absence
+
+		row := *absence
+		row.User = model.User{}
+		t.write(tableAbsences)
+		t.absences[row.ID] = row
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) GetAbsence(ctx context.Context, id uint) (*model.Absence, error) {
+	var absence model.Absence
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.absences[id]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		found.User = t.withTeam(t.users[found.UserID])
+		absence = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &absence, nil
+}
+
+func (r *AvailabilityRepository) DeleteAbsence(ctx context.Context, id uint) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if _, ok := t.absences[id]; !ok {
+			return repoerrs.ErrNotFound
+		}
+		t.write(tableAbsences)
+		delete(t.absences, id)
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) GetAbsencesByUser(ctx context.Context, userID uint) ([]model.Absence, error) {
+	var absences []model.Absence
+	err := r.store.exec(ctx, func(t *tables) error {
+		absences = absencesWhere(t, func(a model.Absence) bool { return a.UserID == userID })
+		return nil
+	})
+	return absences, err
+}
+
+func (r *AvailabilityRepository) HasOverlappingAbsence(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error) {
+	var overlaps bool
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, a := range t.absences {
+			if a.UserID == userID && a.StartsAt.Before(endsAt) && a.EndsAt.After(startsAt) {
+				overlaps = true
+				break
+			}
+		}
+		return nil
+	})
+	return overlaps, err
+}
+
+func (r *AvailabilityRepository) GetPendingAbsences(ctx context.Context, at time.Time) ([]model.Absence, error) {
+	var absences []model.Absence
+	err := r.store.exec(ctx, func(t *tables) error {
+		absences = absencesWhere(t, func(a model.Absence) bool {
+			return activeAt(a.StartsAt, a.EndsAt, at) && a.ReassignedAt == nil
+		})
+		for i := range absences {
+			absences[i].User = t.users[absences[i].UserID]
+		}
+		return nil
+	})
+	return absences, err
+}
+
+func (r *AvailabilityRepository) MarkAbsenceReassigned(ctx context.Context, id uint, at time.Time) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if absence, ok := t.absences[id]; ok {
+			absence.ReassignedAt = &at
+			t.write(tableAbsences)
+			t.absences[id] = absence
+		}
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) CreateHoliday(ctx context.Context, holiday *model.TeamHoliday) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if holiday.CreatedAt.IsZero() {
+			holiday.CreatedAt = time.Now()
+		}
+		t.seq.holiday++
+		holiday.ID = t.seq.holiday
+
+		row := *holiday
+		row.Team = model.Team{}
+		t.write(tableHolidays)
+		t.holidays[row.ID] = row
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) GetHolidaysByTeam(ctx context.Context, teamID uint) ([]model.TeamHoliday, error) {
+	var holidays []model.TeamHoliday
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, id := range slices.Sorted(maps.Keys(t.holidays)) {
+			if holiday := t.holidays[id]; holiday.TeamID == teamID {
+				holidays = append(holidays, holiday)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(holidays, func(a, b model.TeamHoliday) int { return a.StartsAt.Compare(b.StartsAt) })
+	return holidays, err
+}
+
+func (r *AvailabilityRepository) GetAbsentUserIDs(ctx context.Context, teamID uint, at time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.store.exec(ctx, func(t *tables) error {
+		holiday := false
+		for _, h := range t.holidays {
+			if h.TeamID == teamID && activeAt(h.StartsAt, h.EndsAt, at) {
+				holiday = true
+				break
+			}
+		}
+		for _, user := range t.usersWhere(func(u model.User) bool { return u.TeamID == teamID }) {
+			absent := holiday
+			for _, a := range t.absences {
+				if a.UserID == user.ID && activeAt(a.StartsAt, a.EndsAt, at) {
+					absent = true
+					break
+				}
+			}
+			if absent {
+				ids = append(ids, user.ID)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (r *AvailabilityRepository) CreateDelegation(ctx context.Context, delegation *model.Delegation) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if delegation.CreatedAt.IsZero() {
+			delegation.CreatedAt = time.Now()
+		}
+		t.seq.delegation++
+		delegation.ID = t.seq.delegation
+
+		row := *delegation
+		row.User = model.User{}
+		row.Delegate = model.User{}
+		t.write(tableDelegations)
+		t.delegations[row.ID] = row
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) GetDelegation(ctx context.Context, id uint) (*model.Delegation, error) {
+	var delegation model.Delegation
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.delegations[id]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		found.User = t.withTeam(t.users[found.UserID])
+		found.Delegate = t.users[found.DelegateID]
+		delegation = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *AvailabilityRepository) DeleteDelegation(ctx context.Context, id uint) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if _, ok := t.delegations[id]; !ok {
+			return repoerrs.ErrNotFound
+		}
+		t.write(tableDelegations)
+		delete(t.delegations, id)
+		return nil
+	})
+}
+
+func (r *AvailabilityRepository) GetDelegationsByUser(ctx context.Context, userID uint) ([]model.Delegation, error) {
+	var delegations []model.Delegation
+	err := r.store.exec(ctx, func(t *tables) error {
+		delegations = delegationsWhere(t, func(d model.Delegation) bool { return d.UserID == userID })
+		return nil
+	})
+	slices.SortStableFunc(delegations, func(a, b model.Delegation) int { return a.StartsAt.Compare(b.StartsAt) })
+	return delegations, err
+}
+
+func (r *AvailabilityRepository) HasOverlappingDelegation(ctx context.Context, userID uint, startsAt, endsAt time.Time) (bool, error) {
+	var overlaps bool
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, d := range t.delegations {
+			if d.UserID == userID && d.StartsAt.Before(endsAt) && d.EndsAt.After(startsAt) {
+				overlaps = true
+				break
+			}
+		}
+		return nil
+	})
+	return overlaps, err
+}
+
+func (r *AvailabilityRepository) GetActiveDelegations(ctx context.Context, teamID uint, at time.Time) ([]model.Delegation, error) {
+	var delegations []model.Delegation
+	err := r.store.exec(ctx, func(t *tables) error {
+		delegations = delegationsWhere(t, func(d model.Delegation) bool {
+			user, ok := t.users[d.UserID]
+			return ok && user.TeamID == teamID && activeAt(d.StartsAt, d.EndsAt, at)
+		})
+		return nil
+	})
+	return delegations, err
+}
+
+// absencesWhere возвращает отсутствия, подходящие под условие, по началу периода.
+func absencesWhere(t *tables, match func(model.Absence) bool) []model.Absence {
+	var absences []model.Absence
+	for _, id := range slices.Sorted(maps.Keys(t.absences)) {
+		if absence := t.absences[id]; match(absence) {
+			absences = append(absences, absence)
+		}
+	}
+	slices.SortStableFunc(absences, func(a, b model.Absence) int { return a.StartsAt.Compare(b.StartsAt) })
+	return absences
+}
+
+// delegationsWhere возвращает делегирования, подходящие под условие, в порядке ID с предзагруженным Delegate.
+func delegationsWhere(t *tables, match func(model.Delegation) bool) []model.Delegation {
+	var delegations []model.Delegation
+	for _, id := range slices.Sorted(maps.Keys(t.delegations)) {
+		if delegation := t.delegations[id]; match(delegation) {
+			delegation.Delegate = t.users[delegation.DelegateID]
+			delegations = append(delegations, delegation)
+		}
+	}
+	return delegations
+}
+
+// activeAt сообщает, покрывает ли интервал [startsAt, endsAt) момент at.
+func activeAt(startsAt, endsAt, at time.Time) bool {
+	return !startsAt.After(at) && endsAt.After(at)
+}
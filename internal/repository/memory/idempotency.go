@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type IdempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{store: store}
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, key, route string) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.idempotency[idempotencyKey{key, route}]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		record = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		k := idempotencyKey{record.Key, record.Route}
+		if _, ok := t.idempotency[k]; ok {
+			return repoerrs.ErrDuplicate
+		}
+		t.write(tableIdempotency)
+		t.idempotency[k] = *record
+		return nil
+	})
+}
+
+func (r *IdempotencyRepository) Save(ctx context.Context, record *model.IdempotencyRecord) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		t.write(tableIdempotency)
+		t.idempotency[idempotencyKey{record.Key, record.Route}] = *record
+		return nil
+	})
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, key, route string) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		t.write(tableIdempotency)
+		delete(t.idempotency, idempotencyKey{key, route})
+		return nil
+	})
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.store.exec(ctx, func(t *tables) error {
+		for k, record := range t.idempotency {
+			if !record.ExpiresAt.After(before) {
+				t.write(tableIdempotency)
+				delete(t.idempotency, k)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
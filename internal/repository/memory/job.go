@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type JobRepository struct {
+	store *Store
+}
+
+func NewJobRepository(store *Store) *JobRepository {
+	return &JobRepository{store: store}
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *model.Job) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		t.seq.job++
+		job.ID = t.seq.job
+		if job.CreatedAt.IsZero() {
+			job.CreatedAt = time.Now()
+		}
+		for i := range job.Items {
+			job.Items[i].JobID = job.ID
+		}
+		row := *job
+		row.Items = nil
+		t.write(tableJobs)
+		t.write(tableJobItems)
+		t.jobs[job.ID] = row
+		t.jobItems[job.ID] = append([]model.JobItem(nil), job.Items...)
+		return nil
+	})
+}
+
+func (r *JobRepository) Get(ctx context.Context, id uint) (*model.Job, error) {
+	var job model.Job
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.jobs[id]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		job = t.withItems(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) ClaimNext(ctx context.Context, owner string, now, leaseUntil time.Time) (*model.Job, error) {
+	var job model.Job
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, id := range slices.Sorted(maps.Keys(t.jobs)) {
+			found := t.jobs[id]
+			stale := found.Status == model.JobRunning && !leaseHeld(found, now)
+			if found.Status != model.JobQueued && !stale {
+				continue
+			}
+			if stale {
+				found.Attempts++
+			}
+			found.Status = model.JobRunning
+			if found.StartedAt == nil {
+				found.StartedAt = &now
+			}
+			found.HeartbeatAt = &now
+			found.LockedBy, found.LeaseUntil = owner, &leaseUntil
+			t.write(tableJobs)
+			t.jobs[id] = found
+			job = t.withItems(found)
+			return nil
+		}
+		return repoerrs.ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) SaveItem(ctx context.Context, owner string, now time.Time, item *model.JobItem) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if job, ok := t.jobs[item.JobID]; !ok || job.LockedBy != owner || !leaseHeld(job, now) {
+			return repoerrs.ErrLeaseLost
+		}
+		items := t.jobItems[item.JobID]
+		i := slices.IndexFunc(items, func(it model.JobItem) bool { return it.Seq == item.Seq })
+		if i < 0 {
+			return nil
+		}
+		// Ключ элемента не меняется, как и в GORM-реализации, которая обновляет только результат.
+		t.write(tableJobItems)
+		saved := items[i]
+		saved.Status, saved.Result, saved.Error, saved.FinishedAt = item.Status, item.Result, item.Error, item.FinishedAt
+		items[i] = saved
+		return nil
+	})
+}
+
+func (r *JobRepository) SaveProgress(ctx context.Context, owner string, now time.Time, job *model.Job) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		saved, ok := t.jobs[job.ID]
+		if !ok || saved.LockedBy != owner || !leaseHeld(saved, now) {
+			return repoerrs.ErrLeaseLost
+		}
+		saved.Status, saved.Processed, saved.FinishedAt, saved.HeartbeatAt = job.Status, job.Processed, job.FinishedAt, job.HeartbeatAt
+		saved.LockedBy, saved.LeaseUntil, saved.Attempts = job.LockedBy, job.LeaseUntil, job.Attempts
+		t.write(tableJobs)
+		t.jobs[job.ID] = saved
+		return nil
+	})
+}
+
+func (r *JobRepository) RequestCancel(ctx context.Context, id uint, now time.Time) (*model.Job, error) {
+	var job model.Job
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.jobs[id]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		switch found.Status {
+		case model.JobQueued:
+			found.CancelRequested = true
+			found.Status = model.JobCanceled
+			found.FinishedAt = &now
+			t.write(tableJobItems)
+			items := t.jobItems[id]
+			for i := range items {
+				if items[i].Status == model.JobItemPending {
+					items[i].Status = model.JobItemCanceled
+					items[i].FinishedAt = &now
+				}
+			}
+		case model.JobRunning:
+			found.CancelRequested = true
+		}
+		t.write(tableJobs)
+		t.jobs[id] = found
+		job = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) CancelRequested(ctx context.Context, id uint) (bool, error) {
+	var requested bool
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.jobs[id]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		requested = found.CancelRequested
+		return nil
+	})
+	return requested, err
+}
+
+// leaseHeld сообщает, что аренда задачи на момент now ещё не истекла.
+func leaseHeld(job model.Job, now time.Time) bool {
+	return job.LeaseUntil != nil && job.LeaseUntil.After(now)
+}
+
+// withItems возвращает копию задачи с копией её элементов.
+func (t *tables) withItems(job model.Job) model.Job {
+	job.Items = append([]model.JobItem(nil), t.jobItems[job.ID]...)
+	return job
+}
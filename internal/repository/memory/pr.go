@@ -0,0 +1,467 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type PRRepository struct {
+	store *Store
+}
+
+func NewPRRepository(store *Store) *PRRepository {
+	return &PRRepository{store: store}
+}
+
+func (r *PRRepository) CreatePR(ctx context.Context, pr *model.PullRequest) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if _, ok := t.prByExternalID(pr.PRID); ok {
+			return repoerrs.ErrDuplicate
+		}
+		if pr.Version == 0 {
+			pr.Version = 1
+		}
+		// Автоматические отметки времени GORM: при создании заполняются обе.
+		now := time.Now()
+		if pr.CreatedAt.IsZero() {
+			pr.CreatedAt = now
+		}
+		if pr.UpdatedAt == nil {
+			pr.UpdatedAt = &now
+		}
+		t.seq.pr++
+		pr.ID = t.seq.pr
+		t.write(tablePRs)
+		t.prs[pr.ID] = stripPR(*pr)
+		return nil
+	})
+}
+
+func (r *PRRepository) GetPRByExternalID(ctx context.Context, prID string) (*model.PullRequest, error) {
+	var pr model.PullRequest
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.prByExternalID(prID)
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		pr = found
+		pr.Author = t.withTeam(t.users[pr.AuthorID])
+		pr.AssignedReviewers = t.assignedReviewers(pr.ID)
+		pr.ReviewerLinks = t.reviewerLinks(pr.ID, true)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// UpdatePR сохраняет собственные поля PR; ревьюверы меняются только отдельными методами.
+func (r *PRRepository) UpdatePR(ctx context.Context, pr *model.PullRequest) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if _, ok := t.prs[pr.ID]; !ok {
+			return repoerrs.ErrNotFound
+		}
+		if err := t.bumpVersion(pr); err != nil {
+			return err
+		}
+		now := time.Now()
+		pr.UpdatedAt = &now
+		t.write(tablePRs)
+		t.prs[pr.ID] = stripPR(*pr)
+		return nil
+	})
+}
+
+func (r *PRRepository) AddReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.User) error {
+	if len(reviewers) == 0 {
+		return nil
+	}
+	return r.store.exec(ctx, func(t *tables) error {
+		if err := t.checkVersion(pr); err != nil {
+			return err
+		}
+		added := make([]uint, 0, len(reviewers))
+		for _, reviewer := range reviewers {
+			added = append(added, reviewer.ID)
+		}
+		if err := t.checkReviewers(pr.ID, t.reviewerIDs(pr.ID), added); err != nil {
+			return err
+		}
+		t.bump(pr)
+		now := time.Now()
+		for _, reviewer := range reviewers {
+			t.addReviewer(pr.ID, model.PRReviewer{PullRequestID: pr.ID, UserID: reviewer.ID, AssignedAt: now}, now)
+		}
+		return nil
+	})
+}
+
+// RemoveReviewer снимает ревьювера с PR без назначения замены.
+func (r *PRRepository) RemoveReviewer(ctx context.Context, pr *model.PullRequest, reviewerID uint) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if err := t.checkVersion(pr); err != nil {
+			return err
+		}
+		if !t.hasReviewer(pr.ID, reviewerID) {
+			return repoerrs.ErrNotFound
+		}
+		t.bump(pr)
+		now := time.Now()
+		t.dropReviewer(pr.ID, reviewerID)
+		t.closePeriods(pr.ID, []uint{reviewerID}, now)
+		return nil
+	})
+}
+
+func (r *PRRepository) ReplaceReviewer(ctx context.Context, pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if err := t.checkVersion(pr); err != nil {
+			return err
+		}
+		kept := slices.DeleteFunc(t.reviewerIDs(pr.ID), func(id uint) bool { return id == oldReviewerID })
+		if err := t.checkReviewers(pr.ID, kept, []uint{newReviewer.ID}); err != nil {
+			return err
+		}
+		t.bump(pr)
+		now := time.Now()
+		t.dropReviewer(pr.ID, oldReviewerID)
+		t.closePeriods(pr.ID, []uint{oldReviewerID}, now)
+		t.addReviewer(pr.ID, model.PRReviewer{PullRequestID: pr.ID, UserID: newReviewer.ID, AssignedAt: now}, now)
+		return nil
+	})
+}
+
+// ReplaceReviewers заменяет весь список ревьюверов, сохраняя время назначения и признак делегирования из reviewers.
+func (r *PRRepository) ReplaceReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.PRReviewer) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if err := t.checkVersion(pr); err != nil {
+			return err
+		}
+		added := make([]uint, 0, len(reviewers))
+		for _, reviewer := range reviewers {
+			added = append(added, reviewer.UserID)
+		}
+		if err := t.checkReviewers(pr.ID, nil, added); err != nil {
+			return err
+		}
+		t.bump(pr)
+		now := time.Now()
+		var removed []uint
+		for _, link := range t.reviewers[pr.ID] {
+			if !slices.ContainsFunc(reviewers, func(r model.PRReviewer) bool { return r.UserID == link.UserID }) {
+				removed = append(removed, link.UserID)
+			}
+		}
+		t.write(tableReviewers)
+		delete(t.reviewers, pr.ID)
+		t.closePeriods(pr.ID, removed, now)
+
+		for _, reviewer := range reviewers {
+			link := model.PRReviewer{
+				PullRequestID:  pr.ID,
+				UserID:         reviewer.UserID,
+				AssignedAt:     reviewer.AssignedAt,
+				DelegatedForID: reviewer.DelegatedForID,
+			}
+			if link.AssignedAt.IsZero() {
+				link.AssignedAt = now
+			}
+			t.addReviewer(pr.ID, link, now)
+		}
+		return nil
+	})
+}
+
+func (r *PRRepository) SetDelegatedFor(ctx context.Context, pr *model.PullRequest, reviewerID, delegatedForID uint) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if err := t.bumpVersion(pr); err != nil {
+			return err
+		}
+		t.write(tableReviewers)
+		links := t.reviewers[pr.ID]
+		for i := range links {
+			if links[i].UserID == reviewerID {
+				links[i].DelegatedForID = &delegatedForID
+			}
+		}
+		t.write(tablePeriods)
+		for i := range t.periods {
+			p := &t.periods[i]
+			if p.PullRequestID == pr.ID && p.UserID == reviewerID && p.ValidTo == nil {
+				p.DelegatedForID = &delegatedForID
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PRRepository) MarkReviewed(ctx context.Context, pr *model.PullRequest, reviewerID uint, at time.Time) (bool, error) {
+	var marked bool
+	err := r.store.exec(ctx, func(t *tables) error {
+		t.write(tablePeriods)
+		for i := range t.periods {
+			p := &t.periods[i]
+			if p.PullRequestID == pr.ID && p.UserID == reviewerID && p.ValidTo == nil && p.ReviewedAt == nil {
+				p.ReviewedAt = &at
+				marked = true
+			}
+		}
+		return nil
+	})
+	return marked, err
+}
+
+func (r *PRRepository) GetPRsWhereReviewer(ctx context.Context, userID uint) ([]model.PullRequest, error) {
+	var prs []model.PullRequest
+	err := r.store.exec(ctx, func(t *tables) error {
+		prs = t.prsWhere(func(pr model.PullRequest) bool { return t.hasReviewer(pr.ID, userID) })
+		for i := range prs {
+			prs[i].Author = t.users[prs[i].AuthorID]
+			prs[i].AssignedReviewers = t.assignedReviewers(prs[i].ID)
+		}
+		return nil
+	})
+	return prs, err
+}
+
+func (r *PRRepository) GetOpenPRsByReviewerIDs(ctx context.Context, reviewerIDs []uint) ([]model.PullRequest, error) {
+	if len(reviewerIDs) == 0 {
+		return nil, nil
+	}
+
+	var prs []model.PullRequest
+	err := r.store.exec(ctx, func(t *tables) error {
+		prs = t.prsWhere(func(pr model.PullRequest) bool {
+			return pr.Status == "OPEN" && slices.ContainsFunc(reviewerIDs, func(id uint) bool { return t.hasReviewer(pr.ID, id) })
+		})
+		for i := range prs {
+			prs[i].Author = t.users[prs[i].AuthorID]
+			prs[i].AssignedReviewers = t.assignedReviewers(prs[i].ID)
+			prs[i].ReviewerLinks = t.reviewerLinks(prs[i].ID, false)
+		}
+		return nil
+	})
+	return prs, err
+}
+
+func (r *PRRepository) GetOpenPRs(ctx context.Context) ([]model.PullRequest, error) {
+	var prs []model.PullRequest
+	err := r.store.exec(ctx, func(t *tables) error {
+		prs = t.prsWhere(func(pr model.PullRequest) bool { return pr.Status == "OPEN" })
+		for i := range prs {
+			prs[i].Author = t.withTeam(t.users[prs[i].AuthorID])
+			prs[i].AssignedReviewers = t.assignedReviewers(prs[i].ID)
+			prs[i].ReviewerLinks = t.reviewerLinks(prs[i].ID, false)
+		}
+		return nil
+	})
+	return prs, err
+}
+
+func (r *PRRepository) GetReviewerPeriodsAt(ctx context.Context, prID uint, at time.Time) ([]model.PRReviewerPeriod, error) {
+	var periods []model.PRReviewerPeriod
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, p := range t.periods {
+			if p.PullRequestID != prID || !periodActiveAt(p, at) {
+				continue
+			}
+			p.User = t.users[p.UserID]
+			if p.DelegatedForID != nil {
+				delegatedFor := t.users[*p.DelegatedForID]
+				p.DelegatedFor = &delegatedFor
+			}
+			periods = append(periods, p)
+		}
+		return nil
+	})
+	// Периоды хранятся в порядке ID, остаётся упорядочить по началу, как ORDER BY valid_from, id.
+	slices.SortStableFunc(periods, func(a, b model.PRReviewerPeriod) int { return a.ValidFrom.Compare(b.ValidFrom) })
+	return periods, err
+}
+
+func (r *PRRepository) GetPRsWhereReviewerAt(ctx context.Context, userID uint, at time.Time) ([]model.PullRequest, error) {
+	var prs []model.PullRequest
+	err := r.store.exec(ctx, func(t *tables) error {
+		prs = t.prsWhere(func(pr model.PullRequest) bool {
+			return slices.ContainsFunc(t.periods, func(p model.PRReviewerPeriod) bool {
+				return p.PullRequestID == pr.ID && p.UserID == userID && periodActiveAt(p, at)
+			})
+		})
+		for i := range prs {
+			prs[i].Author = t.users[prs[i].AuthorID]
+		}
+		return nil
+	})
+	return prs, err
+}
+
+// checkVersion сверяет версию PR с хранимой; отсутствующий PR, как и в UPDATE ... WHERE version = ?, — конфликт.
+func (t *tables) checkVersion(pr *model.PullRequest) error {
+	stored, ok := t.prs[pr.ID]
+	if !ok || stored.Version != pr.Version {
+		return repoerrs.ErrConflict
+	}
+	return nil
+}
+
+func (t *tables) bump(pr *model.PullRequest) {
+	stored := t.prs[pr.ID]
+	stored.Version++
+	t.write(tablePRs)
+	t.prs[pr.ID] = stored
+	pr.Version = stored.Version
+}
+
+// bumpVersion увеличивает версию PR, только если хранимая версия всё ещё pr.Version (compare-and-swap).
+func (t *tables) bumpVersion(pr *model.PullRequest) error {
+	if err := t.checkVersion(pr); err != nil {
+		return err
+	}
+	t.bump(pr)
+	return nil
+}
+
+func (t *tables) prByExternalID(prID string) (model.PullRequest, bool) {
+	for _, pr := range t.prs {
+		if pr.PRID == prID {
+			return pr, true
+		}
+	}
+	return model.PullRequest{}, false
+}
+
+// prsWhere возвращает PR, подходящие под условие, в порядке ID.
+func (t *tables) prsWhere(match func(model.PullRequest) bool) []model.PullRequest {
+	var prs []model.PullRequest
+	for _, id := range slices.Sorted(maps.Keys(t.prs)) {
+		if pr := t.prs[id]; match(pr) {
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
+
+func (t *tables) hasReviewer(prID, userID uint) bool {
+	return slices.ContainsFunc(t.reviewers[prID], func(link model.PRReviewer) bool { return link.UserID == userID })
+}
+
+func (t *tables) reviewerIDs(prID uint) []uint {
+	ids := make([]uint, 0, len(t.reviewers[prID]))
+	for _, link := range t.reviewers[prID] {
+		ids = append(ids, link.UserID)
+	}
+	return ids
+}
+
+// checkReviewers повторяет триггер инвариантов pr_reviewers для вставки added к уже записанным kept.
+// Проверка идёт до изменения таблиц: вне единицы работы откатывать частичную запись некому.
+func (t *tables) checkReviewers(prID uint, kept, added []uint) error {
+	pr, ok := t.prs[prID]
+	if !ok {
+		return nil
+	}
+	author := t.users[pr.AuthorID]
+	limit := t.teams[author.TeamID].ReviewerLimit()
+
+	assigned := slices.Clone(kept)
+	for _, id := range added {
+		switch {
+		case slices.Contains(assigned, id):
+			continue
+		case id == pr.AuthorID:
+			return &repoerrs.ReviewerInvariantError{Invariant: repoerrs.InvariantReviewerIsAuthor}
+		case len(assigned) >= limit:
+			return &repoerrs.ReviewerInvariantError{Invariant: repoerrs.InvariantReviewerLimit}
+		case author.TeamID == 0 || t.users[id].TeamID != author.TeamID:
+			return &repoerrs.ReviewerInvariantError{Invariant: repoerrs.InvariantReviewerTeam}
+		}
+		assigned = append(assigned, id)
+	}
+	return nil
+}
+
+func (t *tables) assignedReviewers(prID uint) []model.User {
+	links := t.reviewers[prID]
+	if len(links) == 0 {
+		return nil
+	}
+	users := make([]model.User, 0, len(links))
+	for _, link := range links {
+		users = append(users, t.users[link.UserID])
+	}
+	return users
+}
+
+// reviewerLinks копирует строки pr_reviewers PR; withDelegatedFor подгружает DelegatedFor.
+func (t *tables) reviewerLinks(prID uint, withDelegatedFor bool) []model.PRReviewer {
+	links := slices.Clone(t.reviewers[prID])
+	if withDelegatedFor {
+		for i := range links {
+			if links[i].DelegatedForID != nil {
+				delegatedFor := t.users[*links[i].DelegatedForID]
+				links[i].DelegatedFor = &delegatedFor
+			}
+		}
+	}
+	return links
+}
+
+// addReviewer добавляет строку pr_reviewers, если ревьювера ещё нет (ON CONFLICT DO NOTHING),
+// и открывает ему период назначения, если открытого периода нет.
+func (t *tables) addReviewer(prID uint, link model.PRReviewer, at time.Time) {
+	if !t.hasReviewer(prID, link.UserID) {
+		link.DelegatedFor = nil
+		t.write(tableReviewers)
+		t.reviewers[prID] = append(t.reviewers[prID], link)
+	}
+	open := slices.ContainsFunc(t.periods, func(p model.PRReviewerPeriod) bool {
+		return p.PullRequestID == prID && p.UserID == link.UserID && p.ValidTo == nil
+	})
+	if open {
+		return
+	}
+	t.seq.period++
+	t.write(tablePeriods)
+	t.periods = append(t.periods, model.PRReviewerPeriod{
+		ID:             t.seq.period,
+		PullRequestID:  prID,
+		UserID:         link.UserID,
+		ValidFrom:      at,
+		DelegatedForID: link.DelegatedForID,
+	})
+}
+
+func (t *tables) dropReviewer(prID, userID uint) {
+	t.write(tableReviewers)
+	t.reviewers[prID] = slices.DeleteFunc(t.reviewers[prID], func(link model.PRReviewer) bool { return link.UserID == userID })
+}
+
+// closePeriods завершает открытые периоды назначения ревьюверов в момент at.
+func (t *tables) closePeriods(prID uint, userIDs []uint, at time.Time) {
+	t.write(tablePeriods)
+	for i := range t.periods {
+		p := &t.periods[i]
+		if p.PullRequestID == prID && p.ValidTo == nil && slices.Contains(userIDs, p.UserID) {
+			p.ValidTo = &at
+		}
+	}
+}
+
+// periodActiveAt сообщает, покрывает ли период назначения момент at.
+func periodActiveAt(p model.PRReviewerPeriod, at time.Time) bool {
+	return !p.ValidFrom.After(at) && (p.ValidTo == nil || p.ValidTo.After(at))
+}
+
+func stripPR(pr model.PullRequest) model.PullRequest {
+	pr.Author = model.User{}
+	pr.AssignedReviewers = nil
+	pr.ReviewerLinks = nil
+	pr.AssignmentWarnings = nil
+	return pr
+}
@@ -0,0 +1,451 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+)
+
+// StatsRepository считает статистику по текущему состоянию таблиц. Отдельных счётчиков назначений
+// в памяти нет: они всегда совпадают с pr_reviewers, поэтому RebuildAssignmentStats ничего не делает.
+type StatsRepository struct {
+	store *Store
+}
+
+func NewStatsRepository(store *Store) *StatsRepository {
+	return &StatsRepository{store: store}
+}
+
+func (r *StatsRepository) GetAssignmentsByUser(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.AssignmentStatByUser, error) {
+	var stats []repository.AssignmentStatByUser
+	err := r.store.exec(ctx, func(t *tables) error {
+		counts := map[uint]int64{}
+		for _, pr := range t.prsWhere(func(pr model.PullRequest) bool { return t.matchPR(pr, filter) }) {
+			for _, link := range t.reviewers[pr.ID] {
+				counts[link.UserID]++
+			}
+		}
+		for userID, count := range counts {
+			user, ok := t.users[userID]
+			if !ok {
+				continue
+			}
+			stats = append(stats, repository.AssignmentStatByUser{UserID: user.UserID, Username: user.Username, Assignments: count})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	compare := func(a, b repository.AssignmentStatByUser) int {
+		switch filter.Sort {
+		case repository.SortAssignmentsAsc:
+			return cmp.Or(cmp.Compare(a.Assignments, b.Assignments), cmp.Compare(a.UserID, b.UserID))
+		case repository.SortUserIDAsc:
+			return cmp.Compare(a.UserID, b.UserID)
+		case repository.SortUserIDDesc:
+			return cmp.Compare(b.UserID, a.UserID)
+		default:
+			return cmp.Or(cmp.Compare(b.Assignments, a.Assignments), cmp.Compare(a.UserID, b.UserID))
+		}
+	}
+	slices.SortFunc(stats, compare)
+	return page(stats, filter, compare, func(c repository.StatsCursor) repository.AssignmentStatByUser {
+		return repository.AssignmentStatByUser{UserID: c.ID, Assignments: c.Count}
+	}), nil
+}
+
+func (r *StatsRepository) GetAssignmentsByPR(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.AssignmentStatByPR, error) {
+	var stats []repository.AssignmentStatByPR
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, pr := range t.prsWhere(func(pr model.PullRequest) bool { return t.matchPR(pr, filter) }) {
+			if reviewers := len(t.reviewers[pr.ID]); reviewers > 0 {
+				stats = append(stats, repository.AssignmentStatByPR{
+					PRID: pr.PRID, Name: pr.Name, Reviewers: int64(reviewers), CreatedAt: pr.CreatedAt,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	compare := func(a, b repository.AssignmentStatByPR) int {
+		switch filter.Sort {
+		case repository.SortReviewersAsc:
+			return cmp.Or(cmp.Compare(a.Reviewers, b.Reviewers), cmp.Compare(a.PRID, b.PRID))
+		case repository.SortPRIDAsc:
+			return cmp.Compare(a.PRID, b.PRID)
+		case repository.SortPRIDDesc:
+			return cmp.Compare(b.PRID, a.PRID)
+		case repository.SortCreatedAtDesc:
+			return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.PRID, b.PRID))
+		case repository.SortCreatedAtAsc:
+			return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.PRID, b.PRID))
+		default:
+			return cmp.Or(cmp.Compare(b.Reviewers, a.Reviewers), cmp.Compare(a.PRID, b.PRID))
+		}
+	}
+	slices.SortFunc(stats, compare)
+	return page(stats, filter, compare, func(c repository.StatsCursor) repository.AssignmentStatByPR {
+		return repository.AssignmentStatByPR{PRID: c.ID, Reviewers: c.Count, CreatedAt: c.CreatedAt}
+	}), nil
+}
+
+func (r *StatsRepository) GetOpenReviewAssignments(ctx context.Context) ([]repository.OpenReviewAssignment, error) {
+	var rows []repository.OpenReviewAssignment
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, pr := range t.prsWhere(func(pr model.PullRequest) bool { return pr.Status == "OPEN" }) {
+			team, ok := t.authorTeam(pr)
+			if !ok {
+				continue
+			}
+			for _, link := range t.reviewers[pr.ID] {
+				reviewer, ok := t.users[link.UserID]
+				if !ok {
+					continue
+				}
+				rows = append(rows, repository.OpenReviewAssignment{
+					PRID:       pr.PRID,
+					Name:       pr.Name,
+					ReviewerID: reviewer.UserID,
+					TimeZone:   reviewer.TimeZone,
+					WorkStart:  reviewer.WorkStart,
+					WorkEnd:    reviewer.WorkEnd,
+					AssignedAt: link.AssignedAt,
+					SLAHours:   team.ReviewSLAHours,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.OpenReviewAssignment) int {
+		return cmp.Or(a.AssignedAt.Compare(b.AssignedAt), cmp.Compare(a.PRID, b.PRID), cmp.Compare(a.ReviewerID, b.ReviewerID))
+	})
+	return rows, nil
+}
+
+func (r *StatsRepository) GetMergeSamples(ctx context.Context, from, to time.Time) ([]repository.MergeSample, error) {
+	var rows []repository.MergeSample
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, pr := range t.prsWhere(func(pr model.PullRequest) bool {
+			return pr.Status == "MERGED" && pr.MergedAt != nil && inRange(*pr.MergedAt, from, to)
+		}) {
+			team, ok := t.authorTeam(pr)
+			if !ok {
+				continue
+			}
+			rows = append(rows, repository.MergeSample{
+				PRID:      pr.PRID,
+				TeamName:  team.Name,
+				AuthorID:  t.users[pr.AuthorID].UserID,
+				CreatedAt: pr.CreatedAt,
+				MergedAt:  *pr.MergedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.MergeSample) int {
+		return cmp.Or(a.MergedAt.Compare(b.MergedAt), cmp.Compare(a.PRID, b.PRID))
+	})
+	return rows, nil
+}
+
+func (r *StatsRepository) GetReviewActionSamples(ctx context.Context, from, to time.Time) ([]repository.ReviewActionSample, error) {
+	var rows []repository.ReviewActionSample
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, p := range t.periods {
+			if p.ReviewedAt == nil || !inRange(*p.ReviewedAt, from, to) {
+				continue
+			}
+			pr, team, reviewer, ok := t.periodContext(p)
+			if !ok {
+				continue
+			}
+			first := *p.ReviewedAt
+			for _, other := range t.periods {
+				if other.PullRequestID == p.PullRequestID && other.ReviewedAt != nil && other.ReviewedAt.Before(first) {
+					first = *other.ReviewedAt
+				}
+			}
+			rows = append(rows, repository.ReviewActionSample{
+				PRID:            pr.PRID,
+				TeamName:        team.Name,
+				ReviewerID:      reviewer.UserID,
+				PRCreatedAt:     pr.CreatedAt,
+				AssignedAt:      p.ValidFrom,
+				ReviewedAt:      *p.ReviewedAt,
+				FirstReviewedAt: first,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.ReviewActionSample) int {
+		return cmp.Or(a.ReviewedAt.Compare(b.ReviewedAt), cmp.Compare(a.PRID, b.PRID), cmp.Compare(a.ReviewerID, b.ReviewerID))
+	})
+	return rows, nil
+}
+
+// GetReassignmentSamples считает заменой закрытый период, вместо которого в тот же момент открылся другой.
+func (r *StatsRepository) GetReassignmentSamples(ctx context.Context, from, to time.Time) ([]repository.ReassignmentSample, error) {
+	var rows []repository.ReassignmentSample
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, p := range t.periods {
+			if p.ValidTo == nil || !inRange(*p.ValidTo, from, to) {
+				continue
+			}
+			replaced := slices.ContainsFunc(t.periods, func(n model.PRReviewerPeriod) bool {
+				return n.PullRequestID == p.PullRequestID && n.ValidFrom.Equal(*p.ValidTo)
+			})
+			if !replaced {
+				continue
+			}
+			pr, team, reviewer, ok := t.periodContext(p)
+			if !ok {
+				continue
+			}
+			rows = append(rows, repository.ReassignmentSample{
+				PRID:       pr.PRID,
+				TeamName:   team.Name,
+				ReviewerID: reviewer.UserID,
+				AssignedAt: p.ValidFrom,
+				ReplacedAt: *p.ValidTo,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.ReassignmentSample) int {
+		return cmp.Or(a.ReplacedAt.Compare(b.ReplacedAt), cmp.Compare(a.PRID, b.PRID), cmp.Compare(a.ReviewerID, b.ReviewerID))
+	})
+	return rows, nil
+}
+
+func (r *StatsRepository) GetTeamLoad(ctx context.Context, teamID uint) ([]repository.MemberLoad, error) {
+	var rows []repository.MemberLoad
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, user := range t.usersWhere(func(u model.User) bool { return u.TeamID == teamID }) {
+			load := repository.MemberLoad{UserID: user.UserID, Username: user.Username, IsActive: user.IsActive}
+			for prID, links := range t.reviewers {
+				if t.prs[prID].Status == "OPEN" && slices.ContainsFunc(links, func(l model.PRReviewer) bool { return l.UserID == user.ID }) {
+					load.OpenAssignments++
+				}
+			}
+			for _, p := range t.periods {
+				if p.UserID == user.ID {
+					load.TotalAssignments++
+				}
+			}
+			rows = append(rows, load)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.MemberLoad) int { return cmp.Compare(a.UserID, b.UserID) })
+	return rows, nil
+}
+
+func (r *StatsRepository) GetReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]repository.ReplacementStat, error) {
+	type key struct{ userID, reason string }
+	counts := map[key]int64{}
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, event := range t.audit {
+			if event.Type != model.AuditReplaced && event.Type != model.AuditUnassigned {
+				continue
+			}
+			if teamName != "" && event.TeamName != teamName {
+				continue
+			}
+			if (from != nil && event.OccurredAt.Before(*from)) || (to != nil && !event.OccurredAt.Before(*to)) {
+				continue
+			}
+			// Для replaced снятый ревьювер лежит в PreviousUserID, для unassigned — в UserID.
+			userID := event.UserID
+			if event.Type == model.AuditReplaced {
+				userID = event.PreviousUserID
+			}
+			counts[key{userID, event.Reason}]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []repository.ReplacementStat
+	for k, count := range counts {
+		rows = append(rows, repository.ReplacementStat{UserID: k.userID, Reason: k.reason, Count: count})
+	}
+	slices.SortFunc(rows, func(a, b repository.ReplacementStat) int {
+		return cmp.Or(cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.Reason, b.Reason))
+	})
+	return rows, nil
+}
+
+func (r *StatsRepository) GetPRChurn(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.PRChurnStat, error) {
+	var stats []repository.PRChurnStat
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, pr := range t.prsWhere(func(pr model.PullRequest) bool { return t.matchPR(pr, filter) }) {
+			stat := repository.PRChurnStat{PRID: pr.PRID, Name: pr.Name, Status: pr.Status, CreatedAt: pr.CreatedAt}
+			reviewers := map[uint]struct{}{}
+			for _, p := range t.periods {
+				if p.PullRequestID != pr.ID {
+					continue
+				}
+				reviewers[p.UserID] = struct{}{}
+				if p.ValidTo != nil {
+					stat.Removals++
+				}
+			}
+			if stat.Removals == 0 {
+				continue
+			}
+			stat.DistinctReviewers = int64(len(reviewers))
+			stats = append(stats, stat)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	compare := func(a, b repository.PRChurnStat) int {
+		switch filter.Sort {
+		case repository.SortRemovalsAsc:
+			return cmp.Or(cmp.Compare(a.Removals, b.Removals), cmp.Compare(a.DistinctReviewers, b.DistinctReviewers), cmp.Compare(a.PRID, b.PRID))
+		case repository.SortPRIDAsc:
+			return cmp.Compare(a.PRID, b.PRID)
+		case repository.SortPRIDDesc:
+			return cmp.Compare(b.PRID, a.PRID)
+		case repository.SortCreatedAtDesc:
+			return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.PRID, b.PRID))
+		case repository.SortCreatedAtAsc:
+			return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.PRID, b.PRID))
+		default:
+			return cmp.Or(cmp.Compare(b.Removals, a.Removals), cmp.Compare(b.DistinctReviewers, a.DistinctReviewers), cmp.Compare(a.PRID, b.PRID))
+		}
+	}
+	slices.SortFunc(stats, compare)
+	return page(stats, filter, compare, func(c repository.StatsCursor) repository.PRChurnStat {
+		return repository.PRChurnStat{PRID: c.ID, Removals: c.Count, DistinctReviewers: c.Count2, CreatedAt: c.CreatedAt}
+	}), nil
+}
+
+func (r *StatsRepository) GetOpenPRsByTeam(ctx context.Context) ([]repository.TeamOpenPRs, error) {
+	var rows []repository.TeamOpenPRs
+	err := r.store.exec(ctx, func(t *tables) error {
+		for _, team := range t.teams {
+			row := repository.TeamOpenPRs{TeamName: team.Name}
+			for _, pr := range t.prs {
+				if pr.Status == "OPEN" && t.users[pr.AuthorID].TeamID == team.ID {
+					row.OpenPRs++
+				}
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b repository.TeamOpenPRs) int { return cmp.Compare(a.TeamName, b.TeamName) })
+	return rows, nil
+}
+
+func (r *StatsRepository) RebuildAssignmentStats(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// matchPR повторяет фильтры статистики по PR и его автору; From/To относятся к времени создания PR.
+func (t *tables) matchPR(pr model.PullRequest, filter repository.AssignmentStatsFilter) bool {
+	author := t.users[pr.AuthorID]
+	if filter.TeamName != "" {
+		if team, ok := t.teams[author.TeamID]; !ok || team.Name != filter.TeamName {
+			return false
+		}
+	}
+	if filter.AuthorID != "" && author.UserID != filter.AuthorID {
+		return false
+	}
+	if filter.Status != "" && pr.Status != filter.Status {
+		return false
+	}
+	if filter.From != nil && pr.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !pr.CreatedAt.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+// authorTeam возвращает команду автора PR; PR без команды автора в статистику не попадает, как при JOIN teams.
+func (t *tables) authorTeam(pr model.PullRequest) (model.Team, bool) {
+	author, ok := t.users[pr.AuthorID]
+	if !ok {
+		return model.Team{}, false
+	}
+	team, ok := t.teams[author.TeamID]
+	return team, ok
+}
+
+// periodContext возвращает PR, команду его автора и ревьювера периода назначения.
+func (t *tables) periodContext(p model.PRReviewerPeriod) (model.PullRequest, model.Team, model.User, bool) {
+	pr, ok := t.prs[p.PullRequestID]
+	if !ok {
+		return model.PullRequest{}, model.Team{}, model.User{}, false
+	}
+	team, ok := t.authorTeam(pr)
+	if !ok {
+		return model.PullRequest{}, model.Team{}, model.User{}, false
+	}
+	reviewer, ok := t.users[p.UserID]
+	return pr, team, reviewer, ok
+}
+
+// inRange сообщает, попадает ли момент в интервал [from, to).
+func inRange(at, from, to time.Time) bool {
+	return !at.Before(from) && at.Before(to)
+}
+
+// page начинает выборку со строки после filter.After и ограничивает её страницей; нулевой Limit — без ограничения.
+// Строки отсортированы compare, at собирает из курсора строку с тем же ключом сортировки.
+func page[T any](rows []T, filter repository.AssignmentStatsFilter, compare func(a, b T) int, at func(repository.StatsCursor) T) []T {
+	if filter.After != nil {
+		last := at(*filter.After)
+		skip := 0
+		for skip < len(rows) && compare(rows[skip], last) <= 0 {
+			skip++
+		}
+		rows = rows[skip:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(rows) {
+		rows = rows[:filter.Limit]
+	}
+	return rows
+}
@@ -0,0 +1,211 @@
+// Package memory хранит данные сервиса в памяти процесса: для демо и быстрых тестов без PostgreSQL.
+// Репозитории повторяют поведение GORM-реализаций, включая ошибки из repository/errs.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// Store — общее состояние всех репозиториев в памяти. Операции выполняются под одной блокировкой,
+// поэтому изменения видны всем репозиториям сразу, а единицы работы выполняются строго по очереди.
+type Store struct {
+	mu   sync.Mutex
+	data *tables
+}
+
+type tables struct {
+	teams map[uint]model.Team
+	users map[uint]model.User
+	prs   map[uint]model.PullRequest
+	// Строки pr_reviewers по ID PR в порядке назначения.
+	reviewers   map[uint][]model.PRReviewer
+	periods     []model.PRReviewerPeriod
+	absences    map[uint]model.Absence
+	holidays    map[uint]model.TeamHoliday
+	delegations map[uint]model.Delegation
+	audit       []model.AuditEvent
+	idempotency map[idempotencyKey]model.IdempotencyRecord
+	jobs        map[uint]model.Job
+	// Элементы задач по ID задачи в порядке Seq.
+	jobItems map[uint][]model.JobItem
+
+	// Последовательности, как и в PostgreSQL, не откатываются вместе с транзакцией.
+	seq *sequences
+
+	// Точки отката открытых единиц работы, от внешней к вложенной.
+	savepoints []savepoint
+}
+
+// table — таблица хранилища, которую единица работы сохраняет перед первой записью.
+type table int
+
+const (
+	tableTeams table = iota
+	tableUsers
+	tablePRs
+	tableReviewers
+	tablePeriods
+	tableAbsences
+	tableHolidays
+	tableDelegations
+	tableAudit
+	tableIdempotency
+	tableJobs
+	tableJobItems
+)
+
+// savepoint — копии таблиц, изменённых после начала единицы работы, в виде функций их восстановления.
+// Таблицы, в которые единица работы не писала, не копируются.
+type savepoint map[table]func(t *tables)
+
+type sequences struct {
+	team, user, pr, period, absence, holiday, delegation, audit, job uint
+}
+
+type idempotencyKey struct {
+	key, route string
+}
+
+func NewStore() *Store {
+	return &Store{data: &tables{
+		teams:       map[uint]model.Team{},
+		users:       map[uint]model.User{},
+		prs:         map[uint]model.PullRequest{},
+		reviewers:   map[uint][]model.PRReviewer{},
+		absences:    map[uint]model.Absence{},
+		holidays:    map[uint]model.TeamHoliday{},
+		delegations: map[uint]model.Delegation{},
+		idempotency: map[idempotencyKey]model.IdempotencyRecord{},
+		jobs:        map[uint]model.Job{},
+		jobItems:    map[uint][]model.JobItem{},
+		seq:         &sequences{},
+	}}
+}
+
+// write вызывается перед изменением таблицы. Каждая открытая единица работы, ещё не сохранившая её,
+// получает копию текущего состояния: до этой записи таблица с начала единицы работы не менялась,
+// поэтому одна копия годится для всех таких уровней.
+func (t *tables) write(name table) {
+	var restore func(t *tables)
+	for _, sp := range t.savepoints {
+		if _, ok := sp[name]; ok {
+			continue
+		}
+		if restore == nil {
+			restore = t.snapshot(name)
+		}
+		sp[name] = restore
+	}
+}
+
+// snapshot копирует таблицу и возвращает функцию её восстановления. Строки хранятся без связей, а указатели в них
+// (ValidTo, DelegatedForID и т.п.) при изменении заменяются целиком, поэтому копии значений достаточно.
+// Восстановление снова копирует сохранённое состояние: его же может держать внешняя единица работы.
+func (t *tables) snapshot(name table) func(t *tables) {
+	switch name {
+	case tableTeams:
+		saved := maps.Clone(t.teams)
+		return func(t *tables) { t.teams = maps.Clone(saved) }
+	case tableUsers:
+		saved := maps.Clone(t.users)
+		return func(t *tables) { t.users = maps.Clone(saved) }
+	case tablePRs:
+		saved := maps.Clone(t.prs)
+		return func(t *tables) { t.prs = maps.Clone(saved) }
+	case tableReviewers:
+		saved := cloneRows(t.reviewers)
+		return func(t *tables) { t.reviewers = cloneRows(saved) }
+	case tablePeriods:
+		saved := slices.Clone(t.periods)
+		return func(t *tables) { t.periods = slices.Clone(saved) }
+	case tableAbsences:
+		saved := maps.Clone(t.absences)
+		return func(t *tables) { t.absences = maps.Clone(saved) }
+	case tableHolidays:
+		saved := maps.Clone(t.holidays)
+		return func(t *tables) { t.holidays = maps.Clone(saved) }
+	case tableDelegations:
+		saved := maps.Clone(t.delegations)
+		return func(t *tables) { t.delegations = maps.Clone(saved) }
+	case tableAudit:
+		saved := slices.Clone(t.audit)
+		return func(t *tables) { t.audit = slices.Clone(saved) }
+	case tableIdempotency:
+		saved := maps.Clone(t.idempotency)
+		return func(t *tables) { t.idempotency = maps.Clone(saved) }
+	case tableJobs:
+		saved := maps.Clone(t.jobs)
+		return func(t *tables) { t.jobs = maps.Clone(saved) }
+	case tableJobItems:
+		saved := cloneRows(t.jobItems)
+		return func(t *tables) { t.jobItems = cloneRows(saved) }
+	}
+	panic("memory: unknown table")
+}
+
+// cloneRows копирует таблицу, строки которой сгруппированы по ключу и меняются на месте.
+func cloneRows[V any](m map[uint][]V) map[uint][]V {
+	c := make(map[uint][]V, len(m))
+	for k, rows := range m {
+		c[k] = slices.Clone(rows)
+	}
+	return c
+}
+
+type txKey struct{}
+
+// exec выполняет fn над таблицами под блокировкой хранилища; внутри единицы работы блокировка уже взята.
+// Как и запрос к БД, операция не начинается, если контекст уже отменён.
+func (s *Store) exec(ctx context.Context, fn func(t *tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Value(txKey{}) == s {
+		return fn(s.data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+// UnitOfWork — единица работы над Store: при ошибке или панике fn таблицы возвращаются к состоянию до Do.
+// Вложенный Do откатывает только свои изменения, как точка сохранения. Копируются лишь таблицы,
+// в которые fn пишет, и только при первой записи (см. tables.write).
+type UnitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := u.store
+	if ctx.Value(txKey{}) != s {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		ctx = context.WithValue(ctx, txKey{}, s)
+	}
+
+	sp := savepoint{}
+	s.data.savepoints = append(s.data.savepoints, sp)
+	committed := false
+	defer func() {
+		s.data.savepoints = s.data.savepoints[:len(s.data.savepoints)-1]
+		if !committed {
+			for _, restore := range sp {
+				restore(s.data)
+			}
+		}
+	}()
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type TeamRepository struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{store: store}
+}
+
+func (r *TeamRepository) CreateTeam(ctx context.Context, team *model.Team) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		if _, ok := t.teamByName(team.Name); ok {
+			return repoerrs.ErrDuplicate
+		}
+		// Значения по умолчанию из тегов модели, которые в PostgreSQL подставляет БД.
+		if team.MaxReviewers == 0 {
+			team.MaxReviewers = model.DefaultMaxReviewers
+		}
+		if team.AssignmentMode == "" {
+			team.AssignmentMode = model.AssignmentModeRandom
+		}
+		t.seq.team++
+		team.ID = t.seq.team
+
+		row := *team
+		row.Users = nil
+		t.write(tableTeams)
+		t.teams[row.ID] = row
+		return nil
+	})
+}
+
+func (r *TeamRepository) GetTeamByName(ctx context.Context, name string) (*model.Team, error) {
+	var team model.Team
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.teamByName(name)
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		team = found
+		team.Users = t.usersWhere(func(u model.User) bool { return u.TeamID == team.ID })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *TeamRepository) TeamExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := r.store.exec(ctx, func(t *tables) error {
+		_, exists = t.teamByName(name)
+		return nil
+	})
+	return exists, err
+}
+
+func (r *TeamRepository) SetMaxReviewers(ctx context.Context, teamID uint, maxReviewers int) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		team, ok := t.teams[teamID]
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		team.MaxReviewers = maxReviewers
+		t.write(tableTeams)
+		t.teams[teamID] = team
+		return nil
+	})
+}
+
+func (t *tables) teamByName(name string) (model.Team, bool) {
+	for _, team := range t.teams {
+		if team.Name == name {
+			return team, true
+		}
+	}
+	return model.Team{}, false
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// CreateOrUpdate повторяет FirstOrCreate с Assign: существующему пользователю переписываются только непустые поля,
+// новому подставляются значения по умолчанию из тегов модели.
+func (r *UserRepository) CreateOrUpdate(ctx context.Context, user *model.User) error {
+	return r.store.exec(ctx, func(t *tables) error {
+		existing, ok := t.userByUserID(user.UserID)
+		if !ok {
+			applyUserDefaults(user)
+			t.seq.user++
+			user.ID = t.seq.user
+			t.write(tableUsers)
+			t.users[user.ID] = stripUser(*user)
+			return nil
+		}
+
+		if user.Username != "" {
+			existing.Username = user.Username
+		}
+		if user.IsActive {
+			existing.IsActive = true
+		}
+		if user.Seniority != "" {
+			existing.Seniority = user.Seniority
+		}
+		if user.TimeZone != "" {
+			existing.TimeZone = user.TimeZone
+		}
+		if user.WorkStart != "" {
+			existing.WorkStart = user.WorkStart
+		}
+		if user.WorkEnd != "" {
+			existing.WorkEnd = user.WorkEnd
+		}
+		if user.TeamID != 0 {
+			existing.TeamID = user.TeamID
+		}
+		t.write(tableUsers)
+		t.users[existing.ID] = existing
+		*user = existing
+		return nil
+	})
+}
+
+func (r *UserRepository) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	var user model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.userByUserID(userID)
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		user = t.withTeam(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error) {
+	var users []model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		users = t.usersWhere(func(u model.User) bool { return u.TeamID == teamID })
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error) {
+	var users []model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		users = t.usersWhere(func(u model.User) bool { return u.TeamID == teamID && u.IsActive })
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userID string, active bool) (*model.User, error) {
+	var user model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.userByUserID(userID)
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		found.IsActive = active
+		t.write(tableUsers)
+		t.users[found.ID] = found
+		user = t.withTeam(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) SetWorkingHours(ctx context.Context, userID, timeZone, workStart, workEnd string) (*model.User, error) {
+	var user model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		found, ok := t.userByUserID(userID)
+		if !ok {
+			return repoerrs.ErrNotFound
+		}
+		found.TimeZone = timeZone
+		found.WorkStart = workStart
+		found.WorkEnd = workEnd
+		t.write(tableUsers)
+		t.users[found.ID] = found
+		user = t.withTeam(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// BulkDeactivate возвращает пользователей в состоянии до деактивации, как и GORM-реализация.
+func (r *UserRepository) BulkDeactivate(ctx context.Context, teamID uint, userIDs []string) ([]model.User, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var users []model.User
+	err := r.store.exec(ctx, func(t *tables) error {
+		users = t.usersWhere(func(u model.User) bool {
+			return u.TeamID == teamID && u.IsActive && slices.Contains(userIDs, u.UserID)
+		})
+		if len(users) == 0 {
+			return repoerrs.ErrNotFound
+		}
+		t.write(tableUsers)
+		for _, u := range users {
+			u.IsActive = false
+			t.users[u.ID] = u
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func applyUserDefaults(user *model.User) {
+	// У is_active default:true, поэтому false при создании, как и в GORM, превращается в true.
+	user.IsActive = true
+	if user.Seniority == "" {
+		user.Seniority = model.SeniorityMiddle
+	}
+	if user.TimeZone == "" {
+		user.TimeZone = model.DefaultTimeZone
+	}
+	if user.WorkStart == "" {
+		user.WorkStart = model.DefaultWorkStart
+	}
+	if user.WorkEnd == "" {
+		user.WorkEnd = model.DefaultWorkEnd
+	}
+}
+
+// stripUser убирает связи: в таблицах хранятся только собственные поля строк.
+func stripUser(user model.User) model.User {
+	user.Team = model.Team{}
+	return user
+}
+
+func (t *tables) userByUserID(userID string) (model.User, bool) {
+	for _, user := range t.users {
+		if user.UserID == userID {
+			return user, true
+		}
+	}
+	return model.User{}, false
+}
+
+// usersWhere возвращает пользователей, подходящих под условие, в порядке ID.
+func (t *tables) usersWhere(match func(model.User) bool) []model.User {
+	var users []model.User
+	for _, id := range slices.Sorted(maps.Keys(t.users)) {
+		if user := t.users[id]; match(user) {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// withTeam подгружает команду пользователя, как Preload("Team").
+func (t *tables) withTeam(user model.User) model.User {
+	user.Team = t.teams[user.TeamID]
+	return user
+}
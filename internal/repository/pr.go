@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
 	"github.com/Leganyst/avitoTrainee/internal/model"
@@ -12,17 +15,32 @@ import (
 )
 
 type (
+	// Методы, меняющие PR или его ревьюверов, сверяют pr.Version с версией в БД и увеличивают обе;
+	// если PR успели изменить после чтения, возвращается repoerrs.ErrConflict.
 	PRRepository interface {
-		CreatePR(pr *model.PullRequest) error
-		GetPRByExternalID(prID string) (*model.PullRequest, error)
-		UpdatePR(pr *model.PullRequest) error
+		CreatePR(ctx context.Context, pr *model.PullRequest) error
+		GetPRByExternalID(ctx context.Context, prID string) (*model.PullRequest, error)
+		UpdatePR(ctx context.Context, pr *model.PullRequest) error
 
-		AddReviewers(pr *model.PullRequest, reviewers []model.User) error
-		ReplaceReviewer(pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error
-		ReplaceReviewers(prID uint, reviewerIDs []uint) error
+		AddReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.User) error
+		RemoveReviewer(ctx context.Context, pr *model.PullRequest, reviewerID uint) error
+		ReplaceReviewer(ctx context.Context, pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error
+		ReplaceReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.PRReviewer) error
+		// SetDelegatedFor помечает, что ревьювер назначен вместо delegatedForID.
+		SetDelegatedFor(ctx context.Context, pr *model.PullRequest, reviewerID, delegatedForID uint) error
+		// MarkReviewed фиксирует первое ревью-действие ревьювера в текущем назначении.
+		// false — действие уже было зафиксировано раньше.
+		MarkReviewed(ctx context.Context, pr *model.PullRequest, reviewerID uint, at time.Time) (bool, error)
 
-		GetPRsWhereReviewer(userID uint) ([]model.PullRequest, error)
-		GetOpenPRsByReviewerIDs(reviewerIDs []uint) ([]model.PullRequest, error)
+		GetPRsWhereReviewer(ctx context.Context, userID uint) ([]model.PullRequest, error)
+		GetOpenPRsByReviewerIDs(ctx context.Context, reviewerIDs []uint) ([]model.PullRequest, error)
+		// GetOpenPRs возвращает все открытые PR с автором, его командой и ревьюверами в порядке создания.
+		GetOpenPRs(ctx context.Context) ([]model.PullRequest, error)
+
+		// GetReviewerPeriodsAt возвращает назначения PR, действовавшие в момент at.
+		GetReviewerPeriodsAt(ctx context.Context, prID uint, at time.Time) ([]model.PRReviewerPeriod, error)
+		// GetPRsWhereReviewerAt возвращает PR, где пользователь был ревьювером в момент at.
+		GetPRsWhereReviewerAt(ctx context.Context, userID uint, at time.Time) ([]model.PullRequest, error)
 	}
 
 	GormPRRepository struct {
@@ -34,117 +52,336 @@ func NewPRRepository(db *gorm.DB) *GormPRRepository {
 	return &GormPRRepository{db}
 }
 
-func (r *GormPRRepository) CreatePR(pr *model.PullRequest) error {
-	if err := r.db.Create(pr).Error; err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) || isUniqueViolation(err) {
-			config.Logger().Warnw("db PR duplicate", "pr_id", pr.PRID)
+func (r *GormPRRepository) CreatePR(ctx context.Context, pr *model.PullRequest) error {
+	if pr.Version == 0 {
+		pr.Version = 1
+	}
+	if err := conn(ctx, r.db).Create(pr).Error; err != nil {
+		if isDuplicate(r.db, err) {
+			config.LoggerFrom(ctx).Warnw("db PR duplicate", "pr_id", pr.PRID)
 			return repoerrs.ErrDuplicate
 		}
-		config.Logger().Errorw("db create PR failed", "pr_id", pr.PRID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db create PR failed", "pr_id", pr.PRID, "error", err)
 		return err
 	}
-	config.Logger().Debugw("db PR created", "pr_id", pr.PRID, "author_id", pr.AuthorID)
+	config.LoggerFrom(ctx).Debugw("db PR created", "pr_id", pr.PRID, "author_id", pr.AuthorID)
 	return nil
 }
 
-func (r *GormPRRepository) GetPRByExternalID(prID string) (*model.PullRequest, error) {
+func (r *GormPRRepository) GetPRByExternalID(ctx context.Context, prID string) (*model.PullRequest, error) {
 	var pr model.PullRequest
-	if err := r.db.
-		Preload("Author").
+	if err := conn(ctx, r.db).
+		Preload("Author.Team").
 		Preload("AssignedReviewers").
+		Preload("ReviewerLinks").
 		Where("pr_id = ?", prID).
 		First(&pr).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			config.Logger().Warnw("db PR not found", "pr_id", prID)
+			config.LoggerFrom(ctx).Warnw("db PR not found", "pr_id", prID)
 			return nil, repoerrs.ErrNotFound
 		}
-		config.Logger().Errorw("db get PR failed", "pr_id", prID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get PR failed", "pr_id", prID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db PR loaded", "pr_id", prID, "reviewers", len(pr.AssignedReviewers))
+	config.LoggerFrom(ctx).Debugw("db PR loaded", "pr_id", prID, "reviewers", len(pr.AssignedReviewers))
 	return &pr, nil
 }
 
-func (r *GormPRRepository) UpdatePR(pr *model.PullRequest) error {
-	res := r.db.Save(pr)
-	if res.Error != nil {
-		config.Logger().Errorw("db update PR failed", "pr_id", pr.PRID, "error", res.Error)
-		return res.Error
+// UpdatePR сохраняет PR и при смене статуса переносит его назначения в счётчиках статистики.
+func (r *GormPRRepository) UpdatePR(ctx context.Context, pr *model.PullRequest) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var locked int64
+		res := tx.Model(&model.PullRequest{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", pr.ID).
+			Limit(1).
+			Scan(&locked)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return repoerrs.ErrNotFound
+		}
+		prev, err := loadAssignmentKey(tx, pr.ID)
+		if err != nil {
+			return err
+		}
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Save(pr).Error; err != nil {
+			return err
+		}
+		next, err := loadAssignmentKey(tx, pr.ID)
+		if err != nil {
+			return err
+		}
+		return moveAssignments(tx, pr.ID, prev, next)
+	})
+	if errors.Is(err, repoerrs.ErrNotFound) {
+		config.LoggerFrom(ctx).Warnw("db update PR no rows", "pr_id", pr.PRID)
+		return err
 	}
-	if res.RowsAffected == 0 {
-		config.Logger().Warnw("db update PR no rows", "pr_id", pr.PRID)
-		return repoerrs.ErrNotFound
+	if errors.Is(err, repoerrs.ErrConflict) {
+		config.LoggerFrom(ctx).Warnw("db update PR version conflict", "pr_id", pr.PRID, "version", pr.Version)
+		return err
 	}
-	config.Logger().Debugw("db PR updated", "pr_id", pr.PRID)
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db update PR failed", "pr_id", pr.PRID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db PR updated", "pr_id", pr.PRID)
 	return nil
 }
 
-func (r *GormPRRepository) AddReviewers(pr *model.PullRequest, reviewers []model.User) error {
+func (r *GormPRRepository) AddReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.User) error {
 	if len(reviewers) == 0 {
 		return nil
 	}
 
-	rows := make([]map[string]interface{}, 0, len(reviewers))
+	ids := make([]uint, 0, len(reviewers))
 	for _, reviewer := range reviewers {
-		rows = append(rows, map[string]interface{}{
-			"pull_request_id": pr.ID,
-			"user_id":         reviewer.ID,
-		})
+		ids = append(ids, reviewer.ID)
 	}
 
-	if err := r.db.Table("pr_reviewers").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
-		config.Logger().Errorw("db append reviewers failed", "pr_id", pr.PRID, "error", err)
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+		var existing []uint
+		if err := tx.Model(&model.PRReviewer{}).
+			Where("pull_request_id = ? AND user_id IN ?", pr.ID, ids).
+			Pluck("user_id", &existing).Error; err != nil {
+			return err
+		}
+		added := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if !slices.Contains(existing, id) {
+				added = append(added, id)
+			}
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		rows := make([]map[string]interface{}, 0, len(added))
+		links := make([]model.PRReviewer, 0, len(added))
+		for _, id := range added {
+			rows = append(rows, map[string]interface{}{
+				"pull_request_id": pr.ID,
+				"user_id":         id,
+			})
+			links = append(links, model.PRReviewer{UserID: id})
+		}
+		if err := tx.Table("pr_reviewers").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+		if err := openPeriods(tx, pr.ID, links, time.Now()); err != nil {
+			return err
+		}
+		return trackAssignments(tx, pr.ID, added, nil)
+	})
+	if err = reviewerInvariantErr(err); errors.Is(err, repoerrs.ErrConstraint) {
+		config.LoggerFrom(ctx).Warnw("db reviewer invariant violated", "pr_id", pr.PRID, "error", err)
 		return err
 	}
-	config.Logger().Debugw("db reviewers appended", "pr_id", pr.PRID, "count", len(reviewers))
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db append reviewers failed", "pr_id", pr.PRID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewers appended", "pr_id", pr.PRID, "count", len(reviewers))
 	return nil
 }
 
-func (r *GormPRRepository) ReplaceReviewer(pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
-	if err := r.db.
-		Model(pr).
-		Association("AssignedReviewers").
-		Delete(&model.User{ID: oldReviewerID}); err != nil {
-		config.Logger().Errorw("db delete reviewer failed", "pr_id", pr.PRID, "old_user", oldReviewerID, "error", err)
+// RemoveReviewer снимает ревьювера с PR без назначения замены.
+func (r *GormPRRepository) RemoveReviewer(ctx context.Context, pr *model.PullRequest, reviewerID uint) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+		res := tx.
+			Where("pull_request_id = ? AND user_id = ?", pr.ID, reviewerID).
+			Delete(&model.PRReviewer{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return repoerrs.ErrNotFound
+		}
+		if err := closePeriods(tx, pr.ID, []uint{reviewerID}, time.Now()); err != nil {
+			return err
+		}
+		return trackAssignments(tx, pr.ID, nil, []uint{reviewerID})
+	})
+	if errors.Is(err, repoerrs.ErrNotFound) {
+		config.LoggerFrom(ctx).Warnw("db remove reviewer no rows", "pr_id", pr.PRID, "user_id", reviewerID)
 		return err
 	}
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db remove reviewer failed", "pr_id", pr.PRID, "user_id", reviewerID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewer removed", "pr_id", pr.PRID, "user_id", reviewerID)
+	return nil
+}
 
-	if err := r.db.Model(pr).Association("AssignedReviewers").Append(&newReviewer); err != nil {
-		config.Logger().Errorw("db append new reviewer failed", "pr_id", pr.PRID, "new_user", newReviewer.UserID, "error", err)
+// ReplaceReviewer меняет ревьювера: текущая строка pr_reviewers заменяется, период старого ревьювера закрывается.
+func (r *GormPRRepository) ReplaceReviewer(ctx context.Context, pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+		now := time.Now()
+		var added, removed []uint
+		res := tx.
+			Where("pull_request_id = ? AND user_id = ?", pr.ID, oldReviewerID).
+			Delete(&model.PRReviewer{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			removed = append(removed, oldReviewerID)
+		}
+		link := model.PRReviewer{PullRequestID: pr.ID, UserID: newReviewer.ID, AssignedAt: now}
+		res = tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&link)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			added = append(added, newReviewer.ID)
+		}
+		if err := closePeriods(tx, pr.ID, []uint{oldReviewerID}, now); err != nil {
+			return err
+		}
+		if err := openPeriods(tx, pr.ID, []model.PRReviewer{link}, now); err != nil {
+			return err
+		}
+		return trackAssignments(tx, pr.ID, added, removed)
+	})
+	if err = reviewerInvariantErr(err); errors.Is(err, repoerrs.ErrConstraint) {
+		config.LoggerFrom(ctx).Warnw("db reviewer invariant violated", "pr_id", pr.PRID, "error", err)
 		return err
 	}
-	config.Logger().Debugw("db reviewer replaced", "pr_id", pr.PRID, "old_user", oldReviewerID, "new_user", newReviewer.UserID)
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db replace reviewer failed", "pr_id", pr.PRID, "old_user", oldReviewerID, "new_user", newReviewer.UserID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewer replaced", "pr_id", pr.PRID, "old_user", oldReviewerID, "new_user", newReviewer.UserID)
 	return nil
 }
 
-// ReplaceReviewers заменяет весь список ревьюверов за один проход.
-func (r *GormPRRepository) ReplaceReviewers(prID uint, reviewerIDs []uint) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// ReplaceReviewers заменяет весь список ревьюверов за один проход, сохраняя время назначения и признак делегирования из reviewers.
+func (r *GormPRRepository) ReplaceReviewers(ctx context.Context, pr *model.PullRequest, reviewers []model.PRReviewer) error {
+	prID := pr.ID
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+		var current []uint
+		if err := tx.Model(&model.PRReviewer{}).Where("pull_request_id = ?", prID).Pluck("user_id", &current).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("pull_request_id = ?", prID).Delete(&model.PRReviewer{}).Error; err != nil {
 			return err
 		}
-		if len(reviewerIDs) == 0 {
+
+		now := time.Now()
+		kept := make(map[uint]struct{}, len(reviewers))
+		for _, reviewer := range reviewers {
+			kept[reviewer.UserID] = struct{}{}
+		}
+		var removed, added []uint
+		for _, id := range current {
+			if _, ok := kept[id]; !ok {
+				removed = append(removed, id)
+			}
+		}
+		for _, reviewer := range reviewers {
+			if !slices.Contains(current, reviewer.UserID) && !slices.Contains(added, reviewer.UserID) {
+				added = append(added, reviewer.UserID)
+			}
+		}
+		if err := closePeriods(tx, prID, removed, now); err != nil {
+			return err
+		}
+		if err := trackAssignments(tx, prID, added, removed); err != nil {
+			return err
+		}
+		if len(reviewers) == 0 {
 			return nil
 		}
 
-		rows := make([]map[string]interface{}, 0, len(reviewerIDs))
-		for _, id := range reviewerIDs {
-			rows = append(rows, map[string]interface{}{
-				"pull_request_id": prID,
-				"user_id":         id,
+		// Новые ревьюверы получают текущее время назначения, оставшиеся сохраняют исходное.
+		rows := make([]model.PRReviewer, 0, len(reviewers))
+		for _, reviewer := range reviewers {
+			assignedAt := reviewer.AssignedAt
+			if assignedAt.IsZero() {
+				assignedAt = now
+			}
+			rows = append(rows, model.PRReviewer{
+				PullRequestID:  prID,
+				UserID:         reviewer.UserID,
+				AssignedAt:     assignedAt,
+				DelegatedForID: reviewer.DelegatedForID,
 			})
 		}
 
-		if err := tx.Table("pr_reviewers").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
 			return err
 		}
-		return nil
+		return openPeriods(tx, prID, rows, now)
 	})
+	if err = reviewerInvariantErr(err); errors.Is(err, repoerrs.ErrConstraint) {
+		config.LoggerFrom(ctx).Warnw("db reviewer invariant violated", "pr_id", pr.PRID, "error", err)
+		return err
+	}
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db replace reviewers failed", "pr_id", pr.PRID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewers replaced", "pr_id", pr.PRID, "count", len(reviewers))
+	return nil
+}
+
+func (r *GormPRRepository) SetDelegatedFor(ctx context.Context, pr *model.PullRequest, reviewerID, delegatedForID uint) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, pr); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.PRReviewer{}).
+			Where("pull_request_id = ? AND user_id = ?", pr.ID, reviewerID).
+			Update("delegated_for_id", delegatedForID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PRReviewerPeriod{}).
+			Where("pull_request_id = ? AND user_id = ? AND valid_to IS NULL", pr.ID, reviewerID).
+			Update("delegated_for_id", delegatedForID).Error
+	})
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db set delegated reviewer failed", "pr_id", pr.PRID, "user_id", reviewerID, "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewer marked as delegated", "pr_id", pr.PRID, "user_id", reviewerID, "delegated_for", delegatedForID)
+	return nil
+}
+
+func (r *GormPRRepository) MarkReviewed(ctx context.Context, pr *model.PullRequest, reviewerID uint, at time.Time) (bool, error) {
+	res := conn(ctx, r.db).Model(&model.PRReviewerPeriod{}).
+		Where("pull_request_id = ? AND user_id = ? AND valid_to IS NULL AND reviewed_at IS NULL", pr.ID, reviewerID).
+		Update("reviewed_at", at)
+	if res.Error != nil {
+		config.LoggerFrom(ctx).Errorw("db mark reviewed failed", "pr_id", pr.PRID, "user_id", reviewerID, "error", res.Error)
+		return false, res.Error
+	}
+	config.LoggerFrom(ctx).Debugw("db review action recorded", "pr_id", pr.PRID, "user_id", reviewerID, "first", res.RowsAffected > 0)
+	return res.RowsAffected > 0, nil
 }
 
-func (r *GormPRRepository) GetPRsWhereReviewer(userID uint) ([]model.PullRequest, error) {
+func (r *GormPRRepository) GetPRsWhereReviewer(ctx context.Context, userID uint) ([]model.PullRequest, error) {
 	var prs []model.PullRequest
-	err := r.db.
+	err := conn(ctx, r.db).
 		Model(&model.PullRequest{}).
 		Joins("JOIN pr_reviewers ON pr_reviewers.pull_request_id = pull_requests.id").
 		Where("pr_reviewers.user_id = ?", userID).
@@ -153,36 +390,182 @@ func (r *GormPRRepository) GetPRsWhereReviewer(userID uint) ([]model.PullRequest
 		Find(&prs).Error
 
 	if err != nil {
-		config.Logger().Errorw("db list PRs for reviewer failed", "user_id", userID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db list PRs for reviewer failed", "user_id", userID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db PRs for reviewer loaded", "user_id", userID, "count", len(prs))
+	config.LoggerFrom(ctx).Debugw("db PRs for reviewer loaded", "user_id", userID, "count", len(prs))
 	return prs, err
 }
 
-func (r *GormPRRepository) GetOpenPRsByReviewerIDs(reviewerIDs []uint) ([]model.PullRequest, error) {
+func (r *GormPRRepository) GetOpenPRsByReviewerIDs(ctx context.Context, reviewerIDs []uint) ([]model.PullRequest, error) {
 	if len(reviewerIDs) == 0 {
 		return nil, nil
 	}
 
 	var prs []model.PullRequest
-	err := r.db.
+	err := conn(ctx, r.db).
 		Model(&model.PullRequest{}).
-		Distinct("pull_requests.id").
+		Distinct("pull_requests.*").
 		Joins("JOIN pr_reviewers ON pr_reviewers.pull_request_id = pull_requests.id").
 		Where("pr_reviewers.user_id IN ?", reviewerIDs).
 		Where("pull_requests.status = ?", "OPEN").
 		Preload("Author").
 		Preload("AssignedReviewers").
+		Preload("ReviewerLinks").
+		Find(&prs).Error
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db open PRs by reviewer ids failed", "reviewer_ids", reviewerIDs, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db open PRs by reviewer ids loaded", "reviewer_ids_len", len(reviewerIDs), "prs", len(prs))
+	return prs, nil
+}
+
+func (r *GormPRRepository) GetOpenPRs(ctx context.Context) ([]model.PullRequest, error) {
+	var prs []model.PullRequest
+	err := conn(ctx, r.db).
+		Where("status = ?", "OPEN").
+		Order("id").
+		Preload("Author.Team").
+		Preload("AssignedReviewers").
+		Preload("ReviewerLinks").
+		Find(&prs).Error
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db list open PRs failed", "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db open PRs loaded", "prs", len(prs))
+	return prs, nil
+}
+
+func (r *GormPRRepository) GetReviewerPeriodsAt(ctx context.Context, prID uint, at time.Time) ([]model.PRReviewerPeriod, error) {
+	var periods []model.PRReviewerPeriod
+	err := conn(ctx, r.db).
+		Where("pull_request_id = ?", prID).
+		Scopes(periodActiveAt(at)).
+		Preload("User").
+		Preload("DelegatedFor").
+		Order("valid_from, id").
+		Find(&periods).Error
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db reviewer periods at failed", "pr_id", prID, "at", at, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db reviewer periods at loaded", "pr_id", prID, "at", at, "count", len(periods))
+	return periods, nil
+}
+
+func (r *GormPRRepository) GetPRsWhereReviewerAt(ctx context.Context, userID uint, at time.Time) ([]model.PullRequest, error) {
+	var prs []model.PullRequest
+	err := conn(ctx, r.db).
+		Model(&model.PullRequest{}).
+		Distinct("pull_requests.*").
+		Joins("JOIN pr_reviewer_periods ON pr_reviewer_periods.pull_request_id = pull_requests.id").
+		Where("pr_reviewer_periods.user_id = ?", userID).
+		Scopes(periodActiveAt(at)).
+		Preload("Author").
 		Find(&prs).Error
 	if err != nil {
-		config.Logger().Errorw("db open PRs by reviewer ids failed", "reviewer_ids", reviewerIDs, "error", err)
+		config.LoggerFrom(ctx).Errorw("db list PRs for reviewer at failed", "user_id", userID, "at", at, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db open PRs by reviewer ids loaded", "reviewer_ids_len", len(reviewerIDs), "prs", len(prs))
+	config.LoggerFrom(ctx).Debugw("db PRs for reviewer at loaded", "user_id", userID, "at", at, "count", len(prs))
 	return prs, nil
 }
 
-func isUniqueViolation(err error) bool {
-	return strings.Contains(strings.ToLower(err.Error()), "duplicate key value")
+// bumpVersion увеличивает версию PR, только если в БД всё ещё версия pr.Version (compare-and-swap).
+// Обновление строки заодно блокирует её до конца транзакции, поэтому конкурирующие изменения PR выполняются по очереди.
+func bumpVersion(tx *gorm.DB, pr *model.PullRequest) error {
+	res := tx.Model(&model.PullRequest{}).
+		Where("id = ? AND version = ?", pr.ID, pr.Version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return repoerrs.ErrConflict
+	}
+	pr.Version++
+	return nil
+}
+
+// periodActiveAt оставляет периоды назначения, покрывающие момент at.
+func periodActiveAt(at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("pr_reviewer_periods.valid_from <= ? AND (pr_reviewer_periods.valid_to IS NULL OR pr_reviewer_periods.valid_to > ?)", at, at)
+	}
+}
+
+// openPeriods начинает периоды назначения для ревьюверов, у которых ещё нет открытого периода в PR.
+func openPeriods(tx *gorm.DB, prID uint, links []model.PRReviewer, at time.Time) error {
+	if len(links) == 0 {
+		return nil
+	}
+	var open []uint
+	if err := tx.Model(&model.PRReviewerPeriod{}).
+		Where("pull_request_id = ? AND valid_to IS NULL", prID).
+		Pluck("user_id", &open).Error; err != nil {
+		return err
+	}
+	skip := make(map[uint]struct{}, len(open))
+	for _, id := range open {
+		skip[id] = struct{}{}
+	}
+
+	periods := make([]model.PRReviewerPeriod, 0, len(links))
+	for _, link := range links {
+		if _, ok := skip[link.UserID]; ok {
+			continue
+		}
+		skip[link.UserID] = struct{}{}
+		periods = append(periods, model.PRReviewerPeriod{
+			PullRequestID:  prID,
+			UserID:         link.UserID,
+			ValidFrom:      at,
+			DelegatedForID: link.DelegatedForID,
+		})
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+	return tx.Omit(clause.Associations).Create(&periods).Error
+}
+
+// closePeriods завершает открытые периоды назначения ревьюверов в момент at.
+func closePeriods(tx *gorm.DB, prID uint, userIDs []uint, at time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&model.PRReviewerPeriod{}).
+		Where("pull_request_id = ? AND user_id IN ? AND valid_to IS NULL", prID, userIDs).
+		Update("valid_to", at).Error
+}
+
+// reviewerInvariantErr переводит ошибку триггера инвариантов pr_reviewers в repoerrs.ReviewerInvariantError.
+// Триггер сообщает нарушение текстом reviewer_invariant:<имя>: ни PostgreSQL, ни SQLite не дают для него кода,
+// который TranslateError мог бы перевести.
+func reviewerInvariantErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	_, invariant, ok := strings.Cut(err.Error(), "reviewer_invariant:")
+	if !ok {
+		return err
+	}
+	if end := strings.IndexFunc(invariant, func(r rune) bool { return r < 'a' || r > 'z' }); end >= 0 {
+		invariant = invariant[:end]
+	}
+	return &repoerrs.ReviewerInvariantError{Invariant: invariant}
+}
+
+// isDuplicate распознаёт нарушение уникальности независимо от драйвера: диалект переводит ошибку драйвера
+// в gorm.ErrDuplicatedKey, даже если подключение открыто без TranslateError.
+func isDuplicate(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
 }
@@ -1,11 +1,103 @@
 package repository
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
 	"gorm.io/gorm"
 )
 
+// Варианты сортировки статистики назначений.
+const (
+	SortAssignmentsDesc = "assignments_desc"
+	SortAssignmentsAsc  = "assignments_asc"
+	SortUserIDAsc       = "user_id_asc"
+	SortUserIDDesc      = "user_id_desc"
+
+	SortReviewersDesc = "reviewers_desc"
+	SortReviewersAsc  = "reviewers_asc"
+	SortPRIDAsc       = "pr_id_asc"
+	SortPRIDDesc      = "pr_id_desc"
+	SortCreatedAtDesc = "created_at_desc"
+	SortCreatedAtAsc  = "created_at_asc"
+
+	SortRemovalsDesc = "removals_desc"
+	SortRemovalsAsc  = "removals_asc"
+)
+
+// Ключи сортировки ссылаются на столбцы страницы (подзапрос page), последний ключ — уникальный идентификатор строки.
+var (
+	userStatsOrder = map[string][]sortKey{
+		SortAssignmentsDesc: {{"assignments", true, cursorCount}, {"user_id", false, cursorID}},
+		SortAssignmentsAsc:  {{"assignments", false, cursorCount}, {"user_id", false, cursorID}},
+		SortUserIDAsc:       {{"user_id", false, cursorID}},
+		SortUserIDDesc:      {{"user_id", true, cursorID}},
+	}
+	prStatsOrder = map[string][]sortKey{
+		SortReviewersDesc: {{"reviewers", true, cursorCount}, {"pr_id", false, cursorID}},
+		SortReviewersAsc:  {{"reviewers", false, cursorCount}, {"pr_id", false, cursorID}},
+		SortPRIDAsc:       {{"pr_id", false, cursorID}},
+		SortPRIDDesc:      {{"pr_id", true, cursorID}},
+		SortCreatedAtDesc: {{"created_at", true, cursorCreatedAt}, {"pr_id", false, cursorID}},
+		SortCreatedAtAsc:  {{"created_at", false, cursorCreatedAt}, {"pr_id", false, cursorID}},
+	}
+	churnStatsOrder = map[string][]sortKey{
+		SortRemovalsDesc:  {{"removals", true, cursorCount}, {"distinct_reviewers", true, cursorCount2}, {"pr_id", false, cursorID}},
+		SortRemovalsAsc:   {{"removals", false, cursorCount}, {"distinct_reviewers", false, cursorCount2}, {"pr_id", false, cursorID}},
+		SortPRIDAsc:       {{"pr_id", false, cursorID}},
+		SortPRIDDesc:      {{"pr_id", true, cursorID}},
+		SortCreatedAtDesc: {{"created_at", true, cursorCreatedAt}, {"pr_id", false, cursorID}},
+		SortCreatedAtAsc:  {{"created_at", false, cursorCreatedAt}, {"pr_id", false, cursorID}},
+	}
+)
+
+// IsUserStatsSort сообщает, поддерживается ли сортировка статистики по пользователям.
+func IsUserStatsSort(sort string) bool {
+	_, ok := userStatsOrder[sort]
+	return ok
+}
+
+// IsPRStatsSort сообщает, поддерживается ли сортировка статистики по PR.
+func IsPRStatsSort(sort string) bool {
+	_, ok := prStatsOrder[sort]
+	return ok
+}
+
+// IsChurnStatsSort сообщает, поддерживается ли сортировка статистики смены ревьюверов по PR.
+func IsChurnStatsSort(sort string) bool {
+	_, ok := churnStatsOrder[sort]
+	return ok
+}
+
 type (
+	// AssignmentStatsFilter ограничивает PR, назначения на которые попадают в статистику.
+	// TeamName и AuthorID относятся к автору PR, From/To — к времени создания PR, интервал [From, To).
+	// Статистика по пользователям хранит день создания PR, поэтому для неё From/To — начало суток UTC.
+	AssignmentStatsFilter struct {
+		TeamName string
+		Status   string
+		AuthorID string
+		From     *time.Time
+		To       *time.Time
+
+		Sort  string
+		Limit int
+		// After — ключ последней строки предыдущей страницы; nil — первая страница.
+		After *StatsCursor
+	}
+
+	// StatsCursor — ключ сортировки строки статистики: Count — assignments, reviewers или removals,
+	// Count2 — distinct_reviewers, ID — user_id или pr_id. Сортировка читает только свои поля.
+	StatsCursor struct {
+		Count     int64
+		Count2    int64
+		CreatedAt time.Time
+		ID        string
+	}
+
 	AssignmentStatByUser struct {
 		UserID      string
 		Username    string
@@ -16,11 +108,109 @@ type (
 		PRID      string
 		Name      string
 		Reviewers int64
+		CreatedAt time.Time
+	}
+
+	// OpenReviewAssignment — назначение ревьювера на открытый PR вместе с рабочим временем ревьювера и SLA команды автора.
+	OpenReviewAssignment struct {
+		PRID       string
+		Name       string
+		ReviewerID string
+		TimeZone   string
+		WorkStart  string
+		WorkEnd    string
+		AssignedAt time.Time
+		SLAHours   int
+	}
+
+	// MergeSample — PR, смёрженный в запрошенном периоде.
+	MergeSample struct {
+		PRID      string
+		TeamName  string
+		AuthorID  string
+		CreatedAt time.Time
+		MergedAt  time.Time
+	}
+
+	// ReviewActionSample — первое ревью-действие ревьювера в назначении.
+	// FirstReviewedAt — самое раннее ревью-действие по PR среди всех его ревьюверов.
+	ReviewActionSample struct {
+		PRID            string
+		TeamName        string
+		ReviewerID      string
+		PRCreatedAt     time.Time
+		AssignedAt      time.Time
+		ReviewedAt      time.Time
+		FirstReviewedAt time.Time
+	}
+
+	// ReassignmentSample — назначение, которое закончилось заменой ревьювера.
+	ReassignmentSample struct {
+		PRID       string
+		TeamName   string
+		ReviewerID string
+		AssignedAt time.Time
+		ReplacedAt time.Time
+	}
+
+	// ReplacementStat — сколько раз ревьювера сняли с PR по одной причине.
+	ReplacementStat struct {
+		UserID string
+		Reason string
+		Count  int64
+	}
+
+	// PRChurnStat — смена ревьюверов PR: сколько разных людей было назначено и сколько раз ревьювера снимали.
+	PRChurnStat struct {
+		PRID              string
+		Name              string
+		Status            string
+		DistinctReviewers int64
+		Removals          int64
+		CreatedAt         time.Time
+	}
+
+	// TeamOpenPRs — число открытых PR, авторы которых состоят в команде.
+	TeamOpenPRs struct {
+		TeamName string
+		OpenPRs  int64 `gorm:"column:open_prs"`
+	}
+
+	// MemberLoad — нагрузка участника команды: текущие назначения на открытые PR и все назначения за историю.
+	MemberLoad struct {
+		UserID           string
+		Username         string
+		IsActive         bool
+		OpenAssignments  int64
+		TotalAssignments int64
 	}
 
 	StatsRepository interface {
-		GetAssignmentsByUser() ([]AssignmentStatByUser, error)
-		GetAssignmentsByPR() ([]AssignmentStatByPR, error)
+		// GetAssignmentsByUser и GetAssignmentsByPR читают счётчики, которые репозиторий PR ведёт в транзакциях записи,
+		// а триггеры БД — при переводе автора в другую команду и при удалении пользователей и PR.
+		GetAssignmentsByUser(ctx context.Context, filter AssignmentStatsFilter) ([]AssignmentStatByUser, error)
+		GetAssignmentsByPR(ctx context.Context, filter AssignmentStatsFilter) ([]AssignmentStatByPR, error)
+		GetOpenReviewAssignments(ctx context.Context) ([]OpenReviewAssignment, error)
+
+		// Выборки для метрик длительности; событие (merge, ревью, замена) попадает в [from, to).
+		GetMergeSamples(ctx context.Context, from, to time.Time) ([]MergeSample, error)
+		GetReviewActionSamples(ctx context.Context, from, to time.Time) ([]ReviewActionSample, error)
+		GetReassignmentSamples(ctx context.Context, from, to time.Time) ([]ReassignmentSample, error)
+
+		// GetTeamLoad возвращает нагрузку всех участников команды, включая тех, кто ни разу не назначался.
+		GetTeamLoad(ctx context.Context, teamID uint) ([]MemberLoad, error)
+
+		// GetReplacementsByUser группирует по снятому ревьюверу и причине события replaced и unassigned журнала;
+		// teamName — команда автора PR (пустая — все), событие попадает в [from, to).
+		GetReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]ReplacementStat, error)
+		// GetPRChurn возвращает только PR, с которых хотя бы раз снимали ревьювера.
+		GetPRChurn(ctx context.Context, filter AssignmentStatsFilter) ([]PRChurnStat, error)
+
+		// GetOpenPRsByTeam возвращает все команды, включая те, у которых нет открытых PR.
+		GetOpenPRsByTeam(ctx context.Context) ([]TeamOpenPRs, error)
+
+		// RebuildAssignmentStats пересчитывает счётчики назначений с нуля по pr_reviewers.
+		RebuildAssignmentStats(ctx context.Context) error
 	}
 
 	GormStatsRepository struct {
@@ -32,36 +222,416 @@ func NewStatsRepository(db *gorm.DB) *GormStatsRepository {
 	return &GormStatsRepository{db: db}
 }
 
-func (r *GormStatsRepository) GetAssignmentsByUser() ([]AssignmentStatByUser, error) {
+// GetAssignmentsByUser читает только счётчики reviewer_assignment_stats: команда и день создания PR
+// в них уже записаны, поэтому фильтры не требуют обращения к pr_reviewers.
+func (r *GormStatsRepository) GetAssignmentsByUser(ctx context.Context, filter AssignmentStatsFilter) ([]AssignmentStatByUser, error) {
 	var stats []AssignmentStatByUser
-	query := `
-		SELECT u.user_id AS user_id, u.username AS username, COUNT(prr.pull_request_id) AS assignments
-		FROM pr_reviewers prr
-		JOIN users u ON u.id = prr.user_id
-		GROUP BY u.id, u.user_id, u.username
-		ORDER BY assignments DESC, u.user_id ASC`
+	keys, ok := userStatsOrder[filter.Sort]
+	if !ok {
+		keys = userStatsOrder[SortAssignmentsDesc]
+	}
 
-	if err := r.db.Raw(query).Scan(&stats).Error; err != nil {
-		config.Logger().Errorw("db stats assignments by user failed", "error", err)
+	rows := conn(ctx, r.db).Table("reviewer_assignment_stats s").
+		Select("u.user_id AS user_id, u.username AS username, SUM(s.assignments) AS assignments").
+		Joins("JOIN users u ON u.id = s.reviewer_id").
+		Scopes(counterFilter(filter)).
+		Group("u.id, u.user_id, u.username").
+		Having("SUM(s.assignments) > 0")
+	query := conn(ctx, r.db).Table("(?) AS page", rows).Scopes(page(filter, keys))
+
+	if err := query.Scan(&stats).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats assignments by user failed", "error", err)
 		return nil, err
 	}
 
 	return stats, nil
 }
 
-func (r *GormStatsRepository) GetAssignmentsByPR() ([]AssignmentStatByPR, error) {
+func (r *GormStatsRepository) GetAssignmentsByPR(ctx context.Context, filter AssignmentStatsFilter) ([]AssignmentStatByPR, error) {
 	var stats []AssignmentStatByPR
+	keys, ok := prStatsOrder[filter.Sort]
+	if !ok {
+		keys = prStatsOrder[SortReviewersDesc]
+	}
+
+	rows := conn(ctx, r.db).Table("pr_assignment_stats s").
+		Select("p.pr_id AS pr_id, p.name AS name, s.reviewers AS reviewers, p.created_at AS created_at").
+		Joins("JOIN pull_requests p ON p.id = s.pull_request_id").
+		Where("s.reviewers > 0").
+		Scopes(assignmentFilter(filter))
+	query := conn(ctx, r.db).Table("(?) AS page", rows).Scopes(page(filter, keys))
+
+	if err := query.Scan(&stats).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats assignments by pr failed", "error", err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// assignmentFilter накладывает фильтры по PR (алиас p) и его автору.
+func assignmentFilter(filter AssignmentStatsFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.TeamName != "" || filter.AuthorID != "" {
+			db = db.Joins("JOIN users a ON a.id = p.author_id")
+		}
+		if filter.TeamName != "" {
+			db = db.Joins("JOIN teams t ON t.id = a.team_id").Where("t.name = ?", filter.TeamName)
+		}
+		if filter.AuthorID != "" {
+			db = db.Where("a.user_id = ?", filter.AuthorID)
+		}
+		if filter.Status != "" {
+			db = db.Where("p.status = ?", filter.Status)
+		}
+		if filter.From != nil {
+			db = db.Where("p.created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("p.created_at < ?", *filter.To)
+		}
+		return db
+	}
+}
+
+// counterFilter накладывает фильтры на счётчики reviewer_assignment_stats (алиас s).
+// From/To сравниваются с днём создания PR, поэтому должны приходиться на начало суток UTC.
+func counterFilter(filter AssignmentStatsFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.TeamName != "" {
+			db = db.Where("s.team_id = (SELECT id FROM teams WHERE name = ?)", filter.TeamName)
+		}
+		if filter.AuthorID != "" {
+			db = db.Where("s.author_id = (SELECT id FROM users WHERE user_id = ?)", filter.AuthorID)
+		}
+		if filter.Status != "" {
+			db = db.Where("s.status = ?", filter.Status)
+		}
+		if filter.From != nil {
+			db = db.Where("s.created_on >= ?", createdOn(*filter.From))
+		}
+		if filter.To != nil {
+			db = db.Where("s.created_on < ?", createdOn(*filter.To))
+		}
+		return db
+	}
+}
+
+// sortKey — столбец страницы, направление сортировки по нему и его значение в курсоре.
+type sortKey struct {
+	column string
+	desc   bool
+	value  func(StatsCursor) any
+}
+
+func cursorCount(c StatsCursor) any     { return c.Count }
+func cursorCount2(c StatsCursor) any    { return c.Count2 }
+func cursorCreatedAt(c StatsCursor) any { return c.CreatedAt }
+func cursorID(c StatsCursor) any        { return c.ID }
+
+// page сортирует строки по ключам, начинает со строки после filter.After и ограничивает выборку страницей;
+// нулевой Limit — без ограничения.
+func page(filter AssignmentStatsFilter, keys []sortKey) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.After != nil {
+			cond, args := afterCursor(keys, *filter.After)
+			db = db.Where(cond, args...)
+		}
+		for _, key := range keys {
+			if key.desc {
+				db = db.Order(key.column + " DESC")
+			} else {
+				db = db.Order(key.column + " ASC")
+			}
+		}
+		if filter.Limit > 0 {
+			db = db.Limit(filter.Limit)
+		}
+		return db
+	}
+}
+
+// afterCursor строит условие «строка идёт после курсора». При одном направлении всех ключей это сравнение
+// кортежей (a, id) > (?, ?); при разных оно раскладывается в a < ? OR (a = ? AND id > ?).
+func afterCursor(keys []sortKey, cursor StatsCursor) (string, []any) {
+	columns := make([]string, 0, len(keys))
+	marks := make([]string, 0, len(keys))
+	args := make([]any, 0, len(keys))
+	sameDirection := true
+	for _, key := range keys {
+		columns = append(columns, key.column)
+		marks = append(marks, "?")
+		args = append(args, key.value(cursor))
+		sameDirection = sameDirection && key.desc == keys[0].desc
+	}
+	if sameDirection {
+		op := " > "
+		if keys[0].desc {
+			op = " < "
+		}
+		return "(" + strings.Join(columns, ", ") + ")" + op + "(" + strings.Join(marks, ", ") + ")", args
+	}
+
+	var (
+		branches   []string
+		branchArgs []any
+	)
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for _, prev := range keys[:i] {
+			parts = append(parts, prev.column+" = ?")
+			branchArgs = append(branchArgs, prev.value(cursor))
+		}
+		if key.desc {
+			parts = append(parts, key.column+" < ?")
+		} else {
+			parts = append(parts, key.column+" > ?")
+		}
+		branchArgs = append(branchArgs, key.value(cursor))
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", branchArgs
+}
+
+func (r *GormStatsRepository) GetOpenReviewAssignments(ctx context.Context) ([]OpenReviewAssignment, error) {
+	var rows []OpenReviewAssignment
 	query := `
-		SELECT p.pr_id AS pr_id, p.name AS name, COUNT(prr.user_id) AS reviewers
+		SELECT p.pr_id AS pr_id, p.name AS name,
+			u.user_id AS reviewer_id, u.time_zone AS time_zone, u.work_start AS work_start, u.work_end AS work_end,
+			prr.assigned_at AS assigned_at, t.review_sla_hours AS sla_hours
 		FROM pr_reviewers prr
 		JOIN pull_requests p ON p.id = prr.pull_request_id
-		GROUP BY p.id, p.pr_id, p.name
-		ORDER BY reviewers DESC, p.pr_id ASC`
+		JOIN users u ON u.id = prr.user_id
+		JOIN users a ON a.id = p.author_id
+		JOIN teams t ON t.id = a.team_id
+		WHERE p.status = 'OPEN'
+		ORDER BY prr.assigned_at ASC, p.pr_id ASC, u.user_id ASC`
+
+	if err := conn(ctx, r.db).Raw(query).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats open review assignments failed", "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *GormStatsRepository) GetMergeSamples(ctx context.Context, from, to time.Time) ([]MergeSample, error) {
+	var rows []MergeSample
+	query := `
+		SELECT p.pr_id AS pr_id, t.name AS team_name, a.user_id AS author_id,
+			p.created_at AS created_at, p.merged_at AS merged_at
+		FROM pull_requests p
+		JOIN users a ON a.id = p.author_id
+		JOIN teams t ON t.id = a.team_id
+		WHERE p.status = 'MERGED' AND p.merged_at >= ? AND p.merged_at < ?
+		ORDER BY p.merged_at ASC, p.pr_id ASC`
+
+	if err := conn(ctx, r.db).Raw(query, from, to).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats merge samples failed", "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// sqlFragment — часть запроса, которая пишется по-разному в диалектах: выражение для SELECT и нужный ему JOIN.
+type sqlFragment struct {
+	column string
+	join   string
+}
+
+// firstReviewedAt — самое раннее ревью-действие по PR строки rp. Агрегат в SQLite теряет тип столбца
+// и возвращает время строкой, поэтому там время читается из самой строки с первым ревью.
+var firstReviewedAt = map[string]sqlFragment{
+	"postgres": {
+		column: "(SELECT MIN(x.reviewed_at) FROM pr_reviewer_periods x WHERE x.pull_request_id = rp.pull_request_id)",
+	},
+	"sqlite": {
+		column: "f.reviewed_at",
+		join: `
+		JOIN pr_reviewer_periods f ON f.id = (
+			SELECT x.id FROM pr_reviewer_periods x
+			WHERE x.pull_request_id = rp.pull_request_id AND x.reviewed_at IS NOT NULL
+			ORDER BY x.reviewed_at ASC, x.id ASC
+			LIMIT 1
+		)`,
+	},
+}
+
+func (r *GormStatsRepository) GetReviewActionSamples(ctx context.Context, from, to time.Time) ([]ReviewActionSample, error) {
+	var rows []ReviewActionSample
+	tx := conn(ctx, r.db)
+	first, ok := firstReviewedAt[tx.Dialector.Name()]
+	if !ok {
+		first = firstReviewedAt["postgres"]
+	}
+	query := `
+		SELECT p.pr_id AS pr_id, t.name AS team_name, u.user_id AS reviewer_id,
+			p.created_at AS pr_created_at, rp.valid_from AS assigned_at, rp.reviewed_at AS reviewed_at,
+			` + first.column + ` AS first_reviewed_at
+		FROM pr_reviewer_periods rp` + first.join + `
+		JOIN pull_requests p ON p.id = rp.pull_request_id
+		JOIN users u ON u.id = rp.user_id
+		JOIN users a ON a.id = p.author_id
+		JOIN teams t ON t.id = a.team_id
+		WHERE rp.reviewed_at >= ? AND rp.reviewed_at < ?
+		ORDER BY rp.reviewed_at ASC, p.pr_id ASC, u.user_id ASC`
+
+	if err := tx.Raw(query, from, to).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats review action samples failed", "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// GetReassignmentSamples считает заменой закрытый период, вместо которого в тот же момент открылся другой.
+func (r *GormStatsRepository) GetReassignmentSamples(ctx context.Context, from, to time.Time) ([]ReassignmentSample, error) {
+	var rows []ReassignmentSample
+	query := `
+		SELECT p.pr_id AS pr_id, t.name AS team_name, u.user_id AS reviewer_id,
+			rp.valid_from AS assigned_at, rp.valid_to AS replaced_at
+		FROM pr_reviewer_periods rp
+		JOIN pull_requests p ON p.id = rp.pull_request_id
+		JOIN users u ON u.id = rp.user_id
+		JOIN users a ON a.id = p.author_id
+		JOIN teams t ON t.id = a.team_id
+		WHERE rp.valid_to >= ? AND rp.valid_to < ?
+			AND EXISTS (
+				SELECT 1 FROM pr_reviewer_periods n
+				WHERE n.pull_request_id = rp.pull_request_id AND n.valid_from = rp.valid_to
+			)
+		ORDER BY rp.valid_to ASC, p.pr_id ASC, u.user_id ASC`
 
-	if err := r.db.Raw(query).Scan(&stats).Error; err != nil {
-		config.Logger().Errorw("db stats assignments by pr failed", "error", err)
+	if err := conn(ctx, r.db).Raw(query, from, to).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats reassignment samples failed", "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *GormStatsRepository) GetTeamLoad(ctx context.Context, teamID uint) ([]MemberLoad, error) {
+	var rows []MemberLoad
+	query := `
+		SELECT u.user_id AS user_id, u.username AS username, u.is_active AS is_active,
+			(SELECT COUNT(*) FROM pr_reviewers prr
+				JOIN pull_requests p ON p.id = prr.pull_request_id
+				WHERE prr.user_id = u.id AND p.status = 'OPEN') AS open_assignments,
+			(SELECT COUNT(*) FROM pr_reviewer_periods rp WHERE rp.user_id = u.id) AS total_assignments
+		FROM users u
+		WHERE u.team_id = ?
+		ORDER BY u.user_id ASC`
+
+	if err := conn(ctx, r.db).Raw(query, teamID).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats team load failed", "team_id", teamID, "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *GormStatsRepository) GetReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]ReplacementStat, error) {
+	var rows []ReplacementStat
+	// Для replaced снятый ревьювер лежит в previous_user_id, для unassigned — в user_id.
+	query := conn(ctx, r.db).Model(&model.AuditEvent{}).
+		Select("CASE WHEN type = ? THEN previous_user_id ELSE user_id END AS user_id, reason, COUNT(*) AS count", model.AuditReplaced).
+		Where("type IN ?", []string{model.AuditReplaced, model.AuditUnassigned})
+	if teamName != "" {
+		query = query.Where("team_name = ?", teamName)
+	}
+	if from != nil {
+		query = query.Where("occurred_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("occurred_at < ?", *to)
+	}
+
+	if err := query.Group("1, 2").Order("1, 2").Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats replacements by user failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *GormStatsRepository) GetPRChurn(ctx context.Context, filter AssignmentStatsFilter) ([]PRChurnStat, error) {
+	var stats []PRChurnStat
+	keys, ok := churnStatsOrder[filter.Sort]
+	if !ok {
+		keys = churnStatsOrder[SortRemovalsDesc]
+	}
+
+	rows := conn(ctx, r.db).Table("pr_reviewer_periods rp").
+		Select("p.pr_id AS pr_id, p.name AS name, p.status AS status, p.created_at AS created_at, " +
+			"COUNT(DISTINCT rp.user_id) AS distinct_reviewers, COUNT(rp.valid_to) AS removals").
+		Joins("JOIN pull_requests p ON p.id = rp.pull_request_id").
+		Scopes(assignmentFilter(filter)).
+		Group("p.id, p.pr_id, p.name, p.status, p.created_at").
+		Having("COUNT(rp.valid_to) > 0")
+	query := conn(ctx, r.db).Table("(?) AS page", rows).Scopes(page(filter, keys))
+
+	if err := query.Scan(&stats).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats pr churn failed", "error", err)
 		return nil, err
 	}
 
 	return stats, nil
 }
+
+// RebuildAssignmentStats на время пересчёта блокирует запись в pr_reviewers, чтобы параллельные изменения
+// не попали в счётчики дважды.
+func (r *GormStatsRepository) RebuildAssignmentStats(ctx context.Context) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("LOCK TABLE pr_reviewers IN SHARE MODE").Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM reviewer_assignment_stats").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM pr_assignment_stats").Error; err != nil {
+			return err
+		}
+		createdOn := createdOnSQL(tx)
+		if err := tx.Exec(`
+			INSERT INTO reviewer_assignment_stats (reviewer_id, author_id, status, created_on, team_id, assignments)
+			SELECT prr.user_id, p.author_id, p.status, ` + createdOn + `, a.team_id, COUNT(*)
+			FROM pr_reviewers prr
+			JOIN pull_requests p ON p.id = prr.pull_request_id
+			JOIN users a ON a.id = p.author_id
+			GROUP BY prr.user_id, p.author_id, p.status, ` + createdOn + `, a.team_id`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO pr_assignment_stats (pull_request_id, reviewers)
+			SELECT pull_request_id, COUNT(*)
+			FROM pr_reviewers
+			GROUP BY pull_request_id`).Error
+	})
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("db rebuild assignment stats failed", "error", err)
+		return err
+	}
+	config.LoggerFrom(ctx).Infow("db assignment stats rebuilt")
+	return nil
+}
+
+func (r *GormStatsRepository) GetOpenPRsByTeam(ctx context.Context) ([]TeamOpenPRs, error) {
+	var rows []TeamOpenPRs
+	query := `
+		SELECT t.name AS team_name, COUNT(p.id) AS open_prs
+		FROM teams t
+		LEFT JOIN users a ON a.team_id = t.id
+		LEFT JOIN pull_requests p ON p.author_id = a.id AND p.status = 'OPEN'
+		GROUP BY t.id, t.name
+		ORDER BY t.name ASC`
+
+	if err := conn(ctx, r.db).Raw(query).Scan(&rows).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db stats open PRs by team failed", "error", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
@@ -11,9 +12,10 @@ import (
 
 type (
 	TeamRepository interface {
-		CreateTeam(team *model.Team) error
-		GetTeamByName(name string) (*model.Team, error)
-		TeamExists(name string) (bool, error)
+		CreateTeam(ctx context.Context, team *model.Team) error
+		GetTeamByName(ctx context.Context, name string) (*model.Team, error)
+		TeamExists(ctx context.Context, name string) (bool, error)
+		SetMaxReviewers(ctx context.Context, teamID uint, maxReviewers int) error
 	}
 
 	GormTeamRepository struct {
@@ -25,39 +27,60 @@ func NewTeamRepository(db *gorm.DB) *GormTeamRepository {
 	return &GormTeamRepository{db}
 }
 
-func (r *GormTeamRepository) CreateTeam(team *model.Team) error {
-	if err := r.db.Create(team).Error; err != nil {
-		config.Logger().Errorw("db create team failed", "team", team, "error", err)
+func (r *GormTeamRepository) CreateTeam(ctx context.Context, team *model.Team) error {
+	if err := conn(ctx, r.db).Create(team).Error; err != nil {
+		if isDuplicate(r.db, err) {
+			config.LoggerFrom(ctx).Warnw("db team duplicate", "team_name", team.Name)
+			return repoerrs.ErrDuplicate
+		}
+		config.LoggerFrom(ctx).Errorw("db create team failed", "team", team, "error", err)
 		return err
 	}
-	config.Logger().Debugw("db team created", "team", team)
+	config.LoggerFrom(ctx).Debugw("db team created", "team", team)
 	return nil
 }
 
-func (r *GormTeamRepository) GetTeamByName(name string) (*model.Team, error) {
+func (r *GormTeamRepository) GetTeamByName(ctx context.Context, name string) (*model.Team, error) {
 	var team model.Team
-	if err := r.db.Preload("Users").Where("name = ?", name).First(&team).Error; err != nil {
+	if err := conn(ctx, r.db).Preload("Users").Where("name = ?", name).First(&team).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			config.Logger().Warnw("db team not found", "team_name", name)
+			config.LoggerFrom(ctx).Warnw("db team not found", "team_name", name)
 			return nil, repoerrs.ErrNotFound
 		}
-		config.Logger().Errorw("db get team failed", "team_name", name, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get team failed", "team_name", name, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db team loaded", "team_name", name, "members", len(team.Users))
+	config.LoggerFrom(ctx).Debugw("db team loaded", "team_name", name, "members", len(team.Users))
 	return &team, nil
 }
 
-func (r *GormTeamRepository) TeamExists(name string) (bool, error) {
+func (r *GormTeamRepository) TeamExists(ctx context.Context, name string) (bool, error) {
 	var count int64
-	err := r.db.
+	err := conn(ctx, r.db).
 		Model(&model.Team{}).
 		Where("name = ?", name).
 		Count(&count).Error
 	if err != nil {
-		config.Logger().Errorw("db team exists check failed", "team_name", name, "error", err)
+		config.LoggerFrom(ctx).Errorw("db team exists check failed", "team_name", name, "error", err)
 		return false, err
 	}
-	config.Logger().Debugw("db team exists check", "team_name", name, "count", count)
+	config.LoggerFrom(ctx).Debugw("db team exists check", "team_name", name, "count", count)
 	return count > 0, err
 }
+
+func (r *GormTeamRepository) SetMaxReviewers(ctx context.Context, teamID uint, maxReviewers int) error {
+	res := conn(ctx, r.db).
+		Model(&model.Team{}).
+		Where("id = ?", teamID).
+		Update("max_reviewers", maxReviewers)
+	if res.Error != nil {
+		config.LoggerFrom(ctx).Errorw("db set max reviewers failed", "team_id", teamID, "error", res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		config.LoggerFrom(ctx).Warnw("db team not found for max reviewers update", "team_id", teamID)
+		return repoerrs.ErrNotFound
+	}
+	config.LoggerFrom(ctx).Debugw("db team max reviewers updated", "team_id", teamID, "max_reviewers", maxReviewers)
+	return nil
+}
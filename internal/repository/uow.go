@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork выполняет бизнес-операцию в одной транзакции.
+// Все репозитории, получившие контекст из fn, работают в этой транзакции: ошибка fn откатывает
+// все их изменения, иначе они фиксируются вместе. Вложенный Do становится точкой сохранения
+// внешней транзакции и при ошибке откатывает только свои изменения.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type GormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) *GormUnitOfWork {
+	return &GormUnitOfWork{db: db}
+}
+
+func (u *GormUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// На соединении, которое уже в транзакции, GORM открывает SAVEPOINT вместо новой транзакции.
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+type txKey struct{}
+
+// conn возвращает транзакцию текущей единицы работы из ctx, а вне её — общее подключение.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
@@ -11,13 +12,14 @@ import (
 
 type (
 	UserRepository interface {
-		CreateOrUpdate(user *model.User) error
-		GetByUserID(userID string) (*model.User, error)
-		GetUsersByTeam(teamID uint) ([]model.User, error)
-		SetActive(userID string, active bool) (*model.User, error)
-
-		GetActiveUsersByTeam(teamID uint) ([]model.User, error)
-		BulkDeactivate(teamID uint, userIDs []string) ([]model.User, error)
+		CreateOrUpdate(ctx context.Context, user *model.User) error
+		GetByUserID(ctx context.Context, userID string) (*model.User, error)
+		GetUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error)
+		SetActive(ctx context.Context, userID string, active bool) (*model.User, error)
+		SetWorkingHours(ctx context.Context, userID, timeZone, workStart, workEnd string) (*model.User, error)
+
+		GetActiveUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error)
+		BulkDeactivate(ctx context.Context, teamID uint, userIDs []string) ([]model.User, error)
 	}
 
 	GormUserRepository struct {
@@ -29,97 +31,99 @@ func NewUserRepository(db *gorm.DB) *GormUserRepository {
 	return &GormUserRepository{db}
 }
 
-func (r *GormUserRepository) CreateOrUpdate(user *model.User) error {
-	if err := r.db.
+func (r *GormUserRepository) CreateOrUpdate(ctx context.Context, user *model.User) error {
+	// Assign получает копию: FirstOrCreate сначала читает найденную строку в user,
+	// и по указателю обновление записало бы обратно прочитанные значения.
+	if err := conn(ctx, r.db).
 		Where("user_id = ?", user.UserID).
-		Assign(user).
+		Assign(*user).
 		FirstOrCreate(user).Error; err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			config.Logger().Warnw("db user duplicate", "user_id", user.UserID)
+		if isDuplicate(r.db, err) {
+			config.LoggerFrom(ctx).Warnw("db user duplicate", "user_id", user.UserID)
 			return repoerrs.ErrDuplicate
 		}
-		config.Logger().Errorw("db create/update user failed", "user_id", user.UserID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db create/update user failed", "user_id", user.UserID, "error", err)
 		return err
 	}
-	config.Logger().Debugw("db user upserted", "user_id", user.UserID, "team_id", user.TeamID)
+	config.LoggerFrom(ctx).Debugw("db user upserted", "user_id", user.UserID, "team_id", user.TeamID)
 	return nil
 }
 
-func (r *GormUserRepository) SetActive(userID string, active bool) (*model.User, error) {
+func (r *GormUserRepository) SetActive(ctx context.Context, userID string, active bool) (*model.User, error) {
 	var user model.User
-	if err := r.db.Where("user_id = ?", userID).
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).
 		Preload("Team").
 		First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			config.Logger().Warnw("db user not found for set active", "user_id", userID)
+			config.LoggerFrom(ctx).Warnw("db user not found for set active", "user_id", userID)
 			return nil, repoerrs.ErrNotFound
 		}
-		config.Logger().Errorw("db get user for set active failed", "user_id", userID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get user for set active failed", "user_id", userID, "error", err)
 		return nil, err
 	}
 
 	user.IsActive = active
-	if err := r.db.Save(&user).Error; err != nil {
-		config.Logger().Errorw("db save user active failed", "user_id", userID, "error", err)
+	if err := conn(ctx, r.db).Save(&user).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db save user active failed", "user_id", userID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db user active updated", "user_id", userID, "is_active", active)
+	config.LoggerFrom(ctx).Debugw("db user active updated", "user_id", userID, "is_active", active)
 	return &user, nil
 }
 
-func (r *GormUserRepository) GetUsersByTeam(teamID uint) ([]model.User, error) {
+func (r *GormUserRepository) GetUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error) {
 	var users []model.User
-	err := r.db.
+	err := conn(ctx, r.db).
 		Where("team_id = ?", teamID).
 		Find(&users).Error
 	if err != nil {
-		config.Logger().Errorw("db get users by team failed", "team_id", teamID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get users by team failed", "team_id", teamID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db users by team loaded", "team_id", teamID, "count", len(users))
+	config.LoggerFrom(ctx).Debugw("db users by team loaded", "team_id", teamID, "count", len(users))
 	return users, err
 }
 
-func (r *GormUserRepository) GetActiveUsersByTeam(teamID uint) ([]model.User, error) {
+func (r *GormUserRepository) GetActiveUsersByTeam(ctx context.Context, teamID uint) ([]model.User, error) {
 	var users []model.User
-	err := r.db.
+	err := conn(ctx, r.db).
 		Where("team_id = ? AND is_active = true", teamID).
 		Find(&users).Error
 	if err != nil {
-		config.Logger().Errorw("db get active users failed", "team_id", teamID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get active users failed", "team_id", teamID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db active users loaded", "team_id", teamID, "count", len(users))
+	config.LoggerFrom(ctx).Debugw("db active users loaded", "team_id", teamID, "count", len(users))
 	return users, err
 }
 
-func (r *GormUserRepository) GetByUserID(userID string) (*model.User, error) {
+func (r *GormUserRepository) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
 	var user model.User
-	if err := r.db.
+	if err := conn(ctx, r.db).
 		Where("user_id = ?", userID).
 		Preload("Team").
 		First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			config.Logger().Warnw("db user not found", "user_id", userID)
+			config.LoggerFrom(ctx).Warnw("db user not found", "user_id", userID)
 			return nil, repoerrs.ErrNotFound
 		}
-		config.Logger().Errorw("db get user failed", "user_id", userID, "error", err)
+		config.LoggerFrom(ctx).Errorw("db get user failed", "user_id", userID, "error", err)
 		return nil, err
 	}
-	config.Logger().Debugw("db user loaded", "user_id", userID, "team_id", user.TeamID)
+	config.LoggerFrom(ctx).Debugw("db user loaded", "user_id", userID, "team_id", user.TeamID)
 	return &user, nil
 }
 
-func (r *GormUserRepository) BulkDeactivate(teamID uint, userIDs []string) ([]model.User, error) {
+func (r *GormUserRepository) BulkDeactivate(ctx context.Context, teamID uint, userIDs []string) ([]model.User, error) {
 	if len(userIDs) == 0 {
 		return nil, nil
 	}
 
 	var users []model.User
-	if err := r.db.
+	if err := conn(ctx, r.db).
 		Where("team_id = ? AND user_id IN ? AND is_active = true", teamID, userIDs).
 		Find(&users).Error; err != nil {
-		config.Logger().Errorw("db find users for bulk deactivate failed", "team_id", teamID, "user_ids", userIDs, "error", err)
+		config.LoggerFrom(ctx).Errorw("db find users for bulk deactivate failed", "team_id", teamID, "user_ids", userIDs, "error", err)
 		return nil, err
 	}
 	if len(users) == 0 {
@@ -131,13 +135,37 @@ func (r *GormUserRepository) BulkDeactivate(teamID uint, userIDs []string) ([]mo
 		ids = append(ids, u.ID)
 	}
 
-	if err := r.db.Model(&model.User{}).
+	if err := conn(ctx, r.db).Model(&model.User{}).
 		Where("id IN ?", ids).
 		Update("is_active", false).Error; err != nil {
-		config.Logger().Errorw("db bulk deactivate failed", "ids", ids, "error", err)
+		config.LoggerFrom(ctx).Errorw("db bulk deactivate failed", "ids", ids, "error", err)
 		return nil, err
 	}
 
-	config.Logger().Infow("db bulk deactivate completed", "count", len(users), "team_id", teamID)
+	config.LoggerFrom(ctx).Infow("db bulk deactivate completed", "count", len(users), "team_id", teamID)
 	return users, nil
 }
+
+func (r *GormUserRepository) SetWorkingHours(ctx context.Context, userID, timeZone, workStart, workEnd string) (*model.User, error) {
+	var user model.User
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).
+		Preload("Team").
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			config.LoggerFrom(ctx).Warnw("db user not found for working hours", "user_id", userID)
+			return nil, repoerrs.ErrNotFound
+		}
+		config.LoggerFrom(ctx).Errorw("db get user for working hours failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	user.TimeZone = timeZone
+	user.WorkStart = workStart
+	user.WorkEnd = workEnd
+	if err := conn(ctx, r.db).Model(&user).Select("TimeZone", "WorkStart", "WorkEnd").Updates(&user).Error; err != nil {
+		config.LoggerFrom(ctx).Errorw("db save user working hours failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	config.LoggerFrom(ctx).Debugw("db user working hours updated", "user_id", userID, "time_zone", timeZone)
+	return &user, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type (
+	// Origin — кто и почему меняет назначения; попадает в журнал.
+	// Пустой Reason означает причину по умолчанию для операции.
+	Origin struct {
+		Actor  string
+		Reason string
+		// ExpectedVersion — версия PR, с которой работал инициатор; nil — без проверки.
+		ExpectedVersion *uint
+	}
+
+	AuditService interface {
+		// Events возвращает события журнала от новых к старым.
+		Events(ctx context.Context, filter repository.AuditFilter) ([]model.AuditEvent, error)
+	}
+
+	auditService struct {
+		repo repository.AuditRepository
+	}
+)
+
+// SystemOrigin описывает изменения, которые делают фоновые задачи сервиса.
+func SystemOrigin(reason string) Origin {
+	return Origin{Actor: model.ActorSystem, Reason: reason}
+}
+
+func (o Origin) actorName() string {
+	if o.Actor == "" {
+		return model.ActorAnonymous
+	}
+	return o.Actor
+}
+
+// reasonOr возвращает причину инициатора или причину по умолчанию для операции.
+func (o Origin) reasonOr(def string) string {
+	if o.Reason != "" {
+		return o.Reason
+	}
+	return def
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) Events(ctx context.Context, filter repository.AuditFilter) ([]model.AuditEvent, error) {
+	logger := config.LoggerFrom(ctx)
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		logger.Warnw("invalid audit period", "from", filter.From, "to", filter.To)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	events, err := s.repo.Find(ctx, filter)
+	if err != nil {
+		logger.Errorw("audit lookup failed", "error", err)
+		return nil, err
+	}
+	logger.Infow("audit events fetched", "count", len(events))
+	return events, nil
+}
+
+// journal дописывает события в журнал назначений от имени origin.
+func journal(ctx context.Context, repo repository.AuditRepository, origin Origin, events ...model.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := clock()
+	for i := range events {
+		events[i].Actor = origin.actorName()
+		events[i].OccurredAt = now
+	}
+	if err := repo.Append(ctx, events); err != nil {
+		config.LoggerFrom(ctx).Errorw("append audit events failed", "count", len(events), "error", err)
+		return err
+	}
+	observe(ctx, func() { observeEvents(events) })
+	return nil
+}
+
+type pendingMetricsKey struct{}
+
+// pendingMetrics — метрики единицы работы, которые учитываются только после её фиксации.
+type pendingMetrics struct {
+	observers []func()
+}
+
+// inUnitOfWork выполняет fn в единице работы uow. Метрики, отложенные в fn через observe, учитываются после фиксации
+// внешней единицы работы; при откате — и внешней, и вложенной точки сохранения — они пропадают вместе с изменениями.
+func inUnitOfWork(ctx context.Context, uow repository.UnitOfWork, fn func(ctx context.Context) error) error {
+	parent, nested := ctx.Value(pendingMetricsKey{}).(*pendingMetrics)
+	pending := &pendingMetrics{}
+	if err := uow.Do(context.WithValue(ctx, pendingMetricsKey{}, pending), fn); err != nil {
+		return err
+	}
+	if nested {
+		parent.observers = append(parent.observers, pending.observers...)
+		return nil
+	}
+	for _, observer := range pending.observers {
+		observer()
+	}
+	return nil
+}
+
+// observe учитывает метрику после фиксации текущей единицы работы, а вне её — сразу.
+func observe(ctx context.Context, observer func()) {
+	if pending, ok := ctx.Value(pendingMetricsKey{}).(*pendingMetrics); ok {
+		pending.observers = append(pending.observers, observer)
+		return
+	}
+	observer()
+}
+
+// observeEvents переводит записанные события журнала в доменные метрики.
+func observeEvents(events []model.AuditEvent) {
+	for _, e := range events {
+		switch e.Type {
+		case model.AuditAssigned:
+			metrics.ReviewerAssigned(e.Reason)
+		case model.AuditReplaced:
+			metrics.Reassigned(e.Reason)
+		case model.AuditUnassigned:
+			metrics.ReviewerUnassigned(e.Reason)
+		case model.AuditMerged:
+			metrics.PRMerged()
+		}
+	}
+}
+
+// assignedEvents описывает назначение ревьюверов на PR; делегаты получают причину delegation.
+func assignedEvents(pr *model.PullRequest, reviewers []model.User, origin Origin, reason string) []model.AuditEvent {
+	events := make([]model.AuditEvent, 0, len(reviewers))
+	for _, r := range reviewers {
+		event := model.AuditEvent{
+			Type:     model.AuditAssigned,
+			Reason:   origin.reasonOr(reason),
+			PRID:     pr.PRID,
+			UserID:   r.UserID,
+			TeamName: pr.Author.Team.Name,
+		}
+		if principal := pr.DelegatedFor(r.ID); principal != nil {
+			event.Reason = origin.reasonOr(model.ReasonDelegation)
+			event.Details = "on behalf of " + principal.UserID
+		}
+		events = append(events, event)
+	}
+	return events
+}
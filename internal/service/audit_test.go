@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+func TestJournal_ObservesDomainMetrics(t *testing.T) {
+	reassignedBefore := metricValue(t, "pr_reviewer_reassignments_total", "reason", model.ReasonDecline)
+	mergedBefore := metricValue(t, "pr_reviewer_prs_merged_total", "", "")
+
+	err := journal(context.Background(), newMemoryBackend(t).auditRepo, Origin{Actor: "u2"},
+		model.AuditEvent{Type: model.AuditReplaced, Reason: model.ReasonDecline, PRID: "pr-1", UserID: "u3", PreviousUserID: "u2"},
+		model.AuditEvent{Type: model.AuditMerged, Reason: model.ReasonMerge, PRID: "pr-1"},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := metricValue(t, "pr_reviewer_reassignments_total", "reason", model.ReasonDecline) - reassignedBefore; got != 1 {
+		t.Fatalf("expected one decline reassignment, got %v", got)
+	}
+	if got := metricValue(t, "pr_reviewer_prs_merged_total", "", "") - mergedBefore; got != 1 {
+		t.Fatalf("expected one merged PR, got %v", got)
+	}
+}
+
+// metricValue читает значение счётчика из реестра метрик; пустой label — счётчик без меток.
+func metricValue(t *testing.T, name, label, value string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if label == "" {
+				return m.GetCounter().GetValue()
+			}
+			for _, pair := range m.GetLabel() {
+				if pair.GetName() == label && pair.GetValue() == value {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestJournal_MetricsWaitForCommit(t *testing.T) {
+	b := newMemoryBackend(t)
+	mergedBefore := metricValue(t, "pr_reviewer_prs_merged_total", "", "")
+	merged := model.AuditEvent{Type: model.AuditMerged, Reason: model.ReasonMerge, PRID: "pr-1"}
+
+	// Откат единицы работы уносит и метрики её событий.
+	errRollback := errors.New("rollback")
+	err := inUnitOfWork(context.Background(), b.uow, func(ctx context.Context) error {
+		if err := journal(ctx, b.auditRepo, Origin{}, merged); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	if got := metricValue(t, "pr_reviewer_prs_merged_total", "", "") - mergedBefore; got != 0 {
+		t.Fatalf("expected no merged PRs after rollback, got %v", got)
+	}
+
+	err = inUnitOfWork(context.Background(), b.uow, func(ctx context.Context) error {
+		if err := journal(ctx, b.auditRepo, Origin{}, merged); err != nil {
+			return err
+		}
+		if got := metricValue(t, "pr_reviewer_prs_merged_total", "", "") - mergedBefore; got != 0 {
+			t.Fatalf("expected merged PR to wait for commit, got %v", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := metricValue(t, "pr_reviewer_prs_merged_total", "", "") - mergedBefore; got != 1 {
+		t.Fatalf("expected one merged PR after commit, got %v", got)
+	}
+}
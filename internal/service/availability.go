@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+/*
+Отсутствия дополняют флаг is_active, а не заменяют его:
+  - is_active = false — ручной оверрайд, пользователь не назначается никогда;
+  - отсутствие или праздник команды исключают активного пользователя только на свой интервал.
+
+Делегирование не исключает пользователя само по себе: пока оно действует, вместо пользователя
+сначала пробуют назначить делегата, а если тот не подходит — самого пользователя.
+*/
+type (
+	// AvailabilityService управляет отсутствиями, праздниками и делегированиями. Изменения записываются
+	// в журнал от имени origin в одной единице работы с самим изменением.
+	AvailabilityService interface {
+		// AddAbsence сохраняет отсутствие; пересечение с другим отсутствием пользователя — ErrAbsenceOverlap.
+		AddAbsence(ctx context.Context, origin Origin, userID string, startsAt, endsAt time.Time, reason string) (*model.Absence, error)
+		GetAbsences(ctx context.Context, userID string) ([]model.Absence, error)
+		DeleteAbsence(ctx context.Context, origin Origin, id uint) error
+
+		AddTeamHoliday(ctx context.Context, origin Origin, teamName string, startsAt, endsAt time.Time, name string) (*model.TeamHoliday, error)
+		GetTeamHolidays(ctx context.Context, teamName string) ([]model.TeamHoliday, error)
+
+		// AddDelegation назначает делегата, который получает ревью пользователя на указанный интервал.
+		AddDelegation(ctx context.Context, origin Origin, userID, delegateID string, startsAt, endsAt time.Time) (*model.Delegation, error)
+		GetDelegations(ctx context.Context, userID string) ([]model.Delegation, error)
+		DeleteDelegation(ctx context.Context, origin Origin, id uint) error
+
+		// ReassignAbsentReviewers переназначает открытые ревью пользователей, чьё отсутствие уже началось.
+		ReassignAbsentReviewers(ctx context.Context) (*AbsenceReassignResult, error)
+	}
+
+	availabilityService struct {
+		uow       repository.UnitOfWork
+		repo      repository.AvailabilityRepository
+		userRepo  repository.UserRepository
+		teamRepo  repository.TeamRepository
+		prRepo    repository.PRRepository
+		auditRepo repository.AuditRepository
+		prSvc     PRService
+	}
+
+	AbsenceReassignResult struct {
+		Absences             int
+		ReassignmentsDone    int
+		ReassignmentsSkipped int
+		// ReassignmentsFailed — PR, которые не удалось обработать; их отсутствия остаются необработанными до следующего запуска.
+		ReassignmentsFailed int
+	}
+)
+
+func NewAvailabilityService(
+	uow repository.UnitOfWork,
+	repo repository.AvailabilityRepository,
+	userRepo repository.UserRepository,
+	teamRepo repository.TeamRepository,
+	prRepo repository.PRRepository,
+	auditRepo repository.AuditRepository,
+	prSvc PRService,
+) AvailabilityService {
+	return &availabilityService{
+		uow:       uow,
+		repo:      repo,
+		userRepo:  userRepo,
+		teamRepo:  teamRepo,
+		prRepo:    prRepo,
+		auditRepo: auditRepo,
+		prSvc:     prSvc,
+	}
+}
+
+func (s *availabilityService) AddAbsence(ctx context.Context, origin Origin, userID string, startsAt, endsAt time.Time, reason string) (absence *model.Absence, err error) {
+	if !endsAt.After(startsAt) {
+		config.LoggerFrom(ctx).Warnw("invalid absence period", "user_id", userID, "starts_at", startsAt, "ends_at", endsAt)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		absence, err = s.addAbsence(ctx, origin, userID, startsAt, endsAt, reason)
+		return err
+	})
+	return absence, err
+}
+
+func (s *availabilityService) addAbsence(ctx context.Context, origin Origin, userID string, startsAt, endsAt time.Time, reason string) (*model.Absence, error) {
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("absence user not found", "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("get user for absence failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	overlaps, err := s.repo.HasOverlappingAbsence(ctx, user.ID, startsAt, endsAt)
+	if err != nil {
+		logger.Errorw("check absence overlap failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if overlaps {
+		logger.Warnw("absence overlaps existing one", "user_id", userID, "starts_at", startsAt, "ends_at", endsAt)
+		return nil, serviceerrs.ErrAbsenceOverlap
+	}
+
+	absence := &model.Absence{
+		UserID:   user.ID,
+		User:     *user,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Reason:   reason,
+	}
+	if err := s.repo.CreateAbsence(ctx, absence); err != nil {
+		logger.Errorw("create absence failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if err := journal(ctx, s.auditRepo, origin, absenceEvent(model.AuditAbsenceAdded, *absence, origin)); err != nil {
+		return nil, err
+	}
+
+	logger.Infow("absence added", "user_id", userID, "starts_at", startsAt, "ends_at", endsAt)
+	return absence, nil
+}
+
+func (s *availabilityService) GetAbsences(ctx context.Context, userID string) ([]model.Absence, error) {
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("absences user not found", "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("get user for absences failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	absences, err := s.repo.GetAbsencesByUser(ctx, user.ID)
+	if err != nil {
+		logger.Errorw("list absences failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	for i := range absences {
+		absences[i].User = *user
+	}
+	logger.Infow("absences fetched", "user_id", userID, "count", len(absences))
+	return absences, nil
+}
+
+func (s *availabilityService) DeleteAbsence(ctx context.Context, origin Origin, id uint) error {
+	logger := config.LoggerFrom(ctx)
+	err := inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		absence, err := s.repo.GetAbsence(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteAbsence(ctx, id); err != nil {
+			return err
+		}
+		return journal(ctx, s.auditRepo, origin, absenceEvent(model.AuditAbsenceDeleted, *absence, origin))
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("absence not found", "absence_id", id)
+			return serviceerrs.ErrAbsenceNotFound
+		}
+		logger.Errorw("delete absence failed", "absence_id", id, "error", err)
+		return err
+	}
+	logger.Infow("absence deleted", "absence_id", id)
+	return nil
+}
+
+func (s *availabilityService) AddTeamHoliday(ctx context.Context, origin Origin, teamName string, startsAt, endsAt time.Time, name string) (holiday *model.TeamHoliday, err error) {
+	if !endsAt.After(startsAt) {
+		config.LoggerFrom(ctx).Warnw("invalid holiday period", "team_name", teamName, "starts_at", startsAt, "ends_at", endsAt)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		holiday, err = s.addTeamHoliday(ctx, origin, teamName, startsAt, endsAt, name)
+		return err
+	})
+	return holiday, err
+}
+
+func (s *availabilityService) addTeamHoliday(ctx context.Context, origin Origin, teamName string, startsAt, endsAt time.Time, name string) (*model.TeamHoliday, error) {
+	logger := config.LoggerFrom(ctx)
+	team, err := s.teamRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("holiday team not found", "team_name", teamName)
+			return nil, serviceerrs.ErrTeamNotFound
+		}
+		logger.Errorw("get team for holiday failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	holiday := &model.TeamHoliday{
+		TeamID:   team.ID,
+		Team:     *team,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Name:     name,
+	}
+	if err := s.repo.CreateHoliday(ctx, holiday); err != nil {
+		logger.Errorw("create team holiday failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+	event := model.AuditEvent{
+		Type:     model.AuditHolidayAdded,
+		Reason:   origin.reasonOr(model.ReasonManual),
+		TeamName: team.Name,
+		Details:  fmt.Sprintf("holiday_id=%d name=%q period=%s", holiday.ID, name, periodDetails(startsAt, endsAt)),
+	}
+	if err := journal(ctx, s.auditRepo, origin, event); err != nil {
+		return nil, err
+	}
+
+	logger.Infow("team holiday added", "team_name", teamName, "starts_at", startsAt, "ends_at", endsAt)
+	return holiday, nil
+}
+
+func (s *availabilityService) GetTeamHolidays(ctx context.Context, teamName string) ([]model.TeamHoliday, error) {
+	logger := config.LoggerFrom(ctx)
+	team, err := s.teamRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("holidays team not found", "team_name", teamName)
+			return nil, serviceerrs.ErrTeamNotFound
+		}
+		logger.Errorw("get team for holidays failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	holidays, err := s.repo.GetHolidaysByTeam(ctx, team.ID)
+	if err != nil {
+		logger.Errorw("list team holidays failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+	for i := range holidays {
+		holidays[i].Team = *team
+	}
+	logger.Infow("team holidays fetched", "team_name", teamName, "count", len(holidays))
+	return holidays, nil
+}
+
+func (s *availabilityService) AddDelegation(ctx context.Context, origin Origin, userID, delegateID string, startsAt, endsAt time.Time) (delegation *model.Delegation, err error) {
+	logger := config.LoggerFrom(ctx)
+	if !endsAt.After(startsAt) {
+		logger.Warnw("invalid delegation period", "user_id", userID, "starts_at", startsAt, "ends_at", endsAt)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+	if userID == delegateID {
+		logger.Warnw("self delegation rejected", "user_id", userID)
+		return nil, serviceerrs.ErrDelegateSelf
+	}
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		delegation, err = s.addDelegation(ctx, origin, userID, delegateID, startsAt, endsAt)
+		return err
+	})
+	return delegation, err
+}
+
+func (s *availabilityService) addDelegation(ctx context.Context, origin Origin, userID, delegateID string, startsAt, endsAt time.Time) (*model.Delegation, error) {
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("delegation user not found", "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("get user for delegation failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	delegate, err := s.userRepo.GetByUserID(ctx, delegateID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("delegate not found", "delegate_id", delegateID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("get delegate failed", "delegate_id", delegateID, "error", err)
+		return nil, err
+	}
+	if delegate.TeamID != user.TeamID {
+		logger.Warnw("delegate from another team", "user_id", userID, "delegate_id", delegateID)
+		return nil, serviceerrs.ErrDelegateWrongTeam
+	}
+
+	overlaps, err := s.repo.HasOverlappingDelegation(ctx, user.ID, startsAt, endsAt)
+	if err != nil {
+		logger.Errorw("check delegation overlap failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if overlaps {
+		logger.Warnw("delegation overlaps existing one", "user_id", userID, "starts_at", startsAt, "ends_at", endsAt)
+		return nil, serviceerrs.ErrDelegationOverlap
+	}
+
+	delegation := &model.Delegation{
+		UserID:     user.ID,
+		DelegateID: delegate.ID,
+		User:       *user,
+		Delegate:   *delegate,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+	}
+	if err := s.repo.CreateDelegation(ctx, delegation); err != nil {
+		logger.Errorw("create delegation failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if err := journal(ctx, s.auditRepo, origin, delegationEvent(model.AuditDelegationAdded, *delegation, origin)); err != nil {
+		return nil, err
+	}
+
+	logger.Infow("delegation added", "user_id", userID, "delegate_id", delegateID, "starts_at", startsAt, "ends_at", endsAt)
+	return delegation, nil
+}
+
+func (s *availabilityService) GetDelegations(ctx context.Context, userID string) ([]model.Delegation, error) {
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("delegations user not found", "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("get user for delegations failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	delegations, err := s.repo.GetDelegationsByUser(ctx, user.ID)
+	if err != nil {
+		logger.Errorw("list delegations failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+	for i := range delegations {
+		delegations[i].User = *user
+	}
+	logger.Infow("delegations fetched", "user_id", userID, "count", len(delegations))
+	return delegations, nil
+}
+
+func (s *availabilityService) DeleteDelegation(ctx context.Context, origin Origin, id uint) error {
+	logger := config.LoggerFrom(ctx)
+	err := inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		delegation, err := s.repo.GetDelegation(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteDelegation(ctx, id); err != nil {
+			return err
+		}
+		return journal(ctx, s.auditRepo, origin, delegationEvent(model.AuditDelegationDeleted, *delegation, origin))
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("delegation not found", "delegation_id", id)
+			return serviceerrs.ErrDelegationNotFound
+		}
+		logger.Errorw("delete delegation failed", "delegation_id", id, "error", err)
+		return err
+	}
+	logger.Infow("delegation deleted", "delegation_id", id)
+	return nil
+}
+
+// ReassignAbsentReviewers обходит начавшиеся отсутствия и через обычный Reassign снимает пользователей с открытых PR.
+// Если замены нет, ревьювер остаётся на PR, а отсутствие всё равно помечается обработанным.
+// Сбой на одном PR не останавливает обход: необработанным остаётся только его отсутствие, следующий запуск повторит его.
+func (s *availabilityService) ReassignAbsentReviewers(ctx context.Context) (*AbsenceReassignResult, error) {
+	logger := config.LoggerFrom(ctx)
+	absences, err := s.repo.GetPendingAbsences(ctx, clock())
+	if err != nil {
+		logger.Errorw("list pending absences failed", "error", err)
+		return nil, err
+	}
+
+	result := &AbsenceReassignResult{Absences: len(absences)}
+	for _, absence := range absences {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		prs, err := s.prRepo.GetOpenPRsByReviewerIDs(ctx, []uint{absence.UserID})
+		if err != nil {
+			result.ReassignmentsFailed++
+			logger.Errorw("list open PRs of absent reviewer failed", "user_id", absence.User.UserID, "error", err)
+			continue
+		}
+
+		failed := false
+		for _, pr := range prs {
+			_, replacedBy, err := s.prSvc.Reassign(ctx, SystemOrigin(model.ReasonAbsence), pr.PRID, absence.User.UserID, "")
+			switch {
+			case err == nil:
+				result.ReassignmentsDone++
+				logger.Debugw("absent reviewer replaced", "pr_id", pr.PRID, "old_user", absence.User.UserID, "new_user", replacedBy)
+			case errors.Is(err, serviceerrs.ErrNoCandidates),
+				errors.Is(err, serviceerrs.ErrPRMerged),
+				errors.Is(err, serviceerrs.ErrReviewerMissing):
+				result.ReassignmentsSkipped++
+				logger.Warnw("absent reviewer kept", "pr_id", pr.PRID, "user_id", absence.User.UserID, "reason", err)
+			default:
+				failed = true
+				result.ReassignmentsFailed++
+				logger.Errorw("absent reviewer reassign failed", "pr_id", pr.PRID, "user_id", absence.User.UserID, "error", err)
+			}
+		}
+		if failed {
+			continue
+		}
+
+		if err := s.repo.MarkAbsenceReassigned(ctx, absence.ID, clock()); err != nil {
+			logger.Errorw("mark absence reassigned failed", "absence_id", absence.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Infow("absent reviewers processed", "absences", result.Absences, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "failed", result.ReassignmentsFailed)
+	return result, nil
+}
+
+// RunAbsenceReassignJob периодически вызывает ReassignAbsentReviewers, пока не закрыт stop.
+func RunAbsenceReassignJob(svc AvailabilityService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if _, err := svc.ReassignAbsentReviewers(ctx); err != nil {
+				config.LoggerFrom(ctx).Errorw("absence reassign job failed", "error", err)
+			}
+		}
+	}
+}
+
+// absenceEvent описывает добавление или удаление отсутствия для журнала.
+func absenceEvent(eventType string, absence model.Absence, origin Origin) model.AuditEvent {
+	return model.AuditEvent{
+		Type:     eventType,
+		Reason:   origin.reasonOr(model.ReasonManual),
+		UserID:   absence.User.UserID,
+		TeamName: absence.User.Team.Name,
+		Details:  fmt.Sprintf("absence_id=%d period=%s", absence.ID, periodDetails(absence.StartsAt, absence.EndsAt)),
+	}
+}
+
+// delegationEvent описывает добавление или удаление делегирования для журнала.
+func delegationEvent(eventType string, delegation model.Delegation, origin Origin) model.AuditEvent {
+	return model.AuditEvent{
+		Type:     eventType,
+		Reason:   origin.reasonOr(model.ReasonManual),
+		UserID:   delegation.User.UserID,
+		TeamName: delegation.User.Team.Name,
+		Details: fmt.Sprintf("delegation_id=%d delegate=%s period=%s",
+			delegation.ID, delegation.Delegate.UserID, periodDetails(delegation.StartsAt, delegation.EndsAt)),
+	}
+}
+
+// periodDetails записывает интервал [startsAt, endsAt) в журнал в UTC.
+func periodDetails(startsAt, endsAt time.Time) string {
+	return startsAt.UTC().Format(time.RFC3339) + "/" + endsAt.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestAvailabilityService_AddAbsence_InvalidPeriod(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1")
+
+	now := time.Now()
+	_, err := b.availability.AddAbsence(ctx, Origin{}, "u1", now, now.Add(-time.Hour), "vacation")
+	if !errors.Is(err, serviceerrs.ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+	if absences, _ := b.availability.GetAbsences(ctx, "u1"); len(absences) != 0 {
+		t.Fatalf("expected no absence to be stored, got %+v", absences)
+	}
+}
+
+func TestAvailabilityService_AddAbsence_Success(t *testing.T) {
+	b := newMemoryBackend(t, "u1")
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	absence, err := b.availability.AddAbsence(context.Background(), Origin{}, "u1", start, start.Add(72*time.Hour), "vacation")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if absence.ID == 0 || absence.UserID != b.user(t, "u1").ID || absence.User.UserID != "u1" {
+		t.Fatalf("unexpected absence: %+v", absence)
+	}
+}
+
+func TestAvailabilityService_AddAbsence_Overlap(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1")
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := b.availability.AddAbsence(ctx, Origin{}, "u1", start, start.Add(72*time.Hour), "vacation"); err != nil {
+		t.Fatalf("AddAbsence returned error: %v", err)
+	}
+	if _, err := b.availability.AddAbsence(ctx, Origin{}, "u1", start.Add(24*time.Hour), start.Add(96*time.Hour), "sick"); !errors.Is(err, serviceerrs.ErrAbsenceOverlap) {
+		t.Fatalf("expected ErrAbsenceOverlap, got %v", err)
+	}
+	// Интервалы полуоткрытые: отсутствие сразу после предыдущего с ним не пересекается.
+	if _, err := b.availability.AddAbsence(ctx, Origin{}, "u1", start.Add(72*time.Hour), start.Add(96*time.Hour), "sick"); err != nil {
+		t.Fatalf("expected adjacent absence to be added, got %v", err)
+	}
+}
+
+// failingAuditRepo — журнал в памяти, который не принимает записи.
+type failingAuditRepo struct {
+	repository.AuditRepository
+	err error
+}
+
+func (r failingAuditRepo) Append(ctx context.Context, events []model.AuditEvent) error {
+	return r.err
+}
+
+func TestAvailabilityService_ChangesAreJournaled(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2")
+	admin := Origin{Actor: "admin"}
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	absence, err := b.availability.AddAbsence(ctx, admin, "u1", start, start.Add(24*time.Hour), "vacation")
+	if err != nil {
+		t.Fatalf("AddAbsence returned error: %v", err)
+	}
+	if err := b.availability.DeleteAbsence(ctx, admin, absence.ID); err != nil {
+		t.Fatalf("DeleteAbsence returned error: %v", err)
+	}
+	delegation, err := b.availability.AddDelegation(ctx, admin, "u1", "u2", start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("AddDelegation returned error: %v", err)
+	}
+	if err := b.availability.DeleteDelegation(ctx, admin, delegation.ID); err != nil {
+		t.Fatalf("DeleteDelegation returned error: %v", err)
+	}
+	if _, err := b.availability.AddTeamHoliday(ctx, admin, "backend", start, start.Add(24*time.Hour), "New Year"); err != nil {
+		t.Fatalf("AddTeamHoliday returned error: %v", err)
+	}
+
+	events, err := b.auditRepo.Find(ctx, repository.AuditFilter{TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	want := []string{model.AuditHolidayAdded, model.AuditDelegationDeleted, model.AuditDelegationAdded, model.AuditAbsenceDeleted, model.AuditAbsenceAdded}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, event := range events {
+		if event.Type != want[i] || event.Actor != "admin" || event.Reason != model.ReasonManual {
+			t.Fatalf("event %d: expected %s by admin, got %+v", i, want[i], event)
+		}
+	}
+	if events[2].UserID != "u1" || events[2].Details != "delegation_id=1 delegate=u2 period=2025-11-01T00:00:00Z/2025-11-02T00:00:00Z" {
+		t.Fatalf("unexpected delegation event: %+v", events[2])
+	}
+
+	// Изменение и его запись в журнал — одна единица работы: без записи в журнал отсутствие не сохраняется.
+	svc := NewAvailabilityService(b.uow, b.availabilityRepo, b.userRepo, b.teamRepo, b.prRepo, failingAuditRepo{b.auditRepo, errors.New("db down")}, b.prs)
+	if _, err := svc.AddAbsence(ctx, admin, "u2", start, start.Add(24*time.Hour), "vacation"); err == nil {
+		t.Fatalf("expected journal error")
+	}
+	if absences, _ := b.availability.GetAbsences(ctx, "u2"); len(absences) != 0 {
+		t.Fatalf("expected absence to be rolled back with its event, got %+v", absences)
+	}
+}
+
+// startAbsence заводит отсутствие userID, которое уже началось.
+func startAbsence(t *testing.T, b *memoryBackend, userID string) {
+	t.Helper()
+	now := time.Now()
+	if _, err := b.availability.AddAbsence(context.Background(), Origin{}, userID, now.Add(-time.Hour), now.Add(24*time.Hour), "vacation"); err != nil {
+		t.Fatalf("AddAbsence returned error: %v", err)
+	}
+}
+
+func TestAvailabilityService_ReassignAbsentReviewers(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.createPR(t, "pr-2", "u1")
+	b.join(t, "backend", "u4", "u5")
+	startAbsence(t, b, "u2")
+
+	result, err := b.availability.ReassignAbsentReviewers(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Absences != 1 || result.ReassignmentsDone != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for _, prID := range []string{"pr-1", "pr-2"} {
+		event := b.prEvents(t, prID)[0]
+		if event.Type != model.AuditReplaced || event.PreviousUserID != "u2" ||
+			event.Actor != model.ActorSystem || event.Reason != model.ReasonAbsence {
+			t.Fatalf("expected system/absence replacement of u2 on %s, got %+v", prID, event)
+		}
+	}
+	if result, err := b.availability.ReassignAbsentReviewers(ctx); err != nil || result.Absences != 0 {
+		t.Fatalf("expected the absence to be marked processed, got %+v, %v", result, err)
+	}
+}
+
+func TestAvailabilityService_ReassignAbsentReviewers_NoCandidates(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	startAbsence(t, b, "u2")
+
+	result, err := b.availability.ReassignAbsentReviewers(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.ReassignmentsSkipped != 1 || result.ReassignmentsDone != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result, err := b.availability.ReassignAbsentReviewers(ctx); err != nil || result.Absences != 0 {
+		t.Fatalf("expected absence to be marked processed even without candidates, got %+v, %v", result, err)
+	}
+}
+
+// failingReassignPRService отказывает в Reassign ревьювера userID, остальные вызовы передаёт дальше.
+type failingReassignPRService struct {
+	PRService
+	userID string
+	err    error
+}
+
+func (s failingReassignPRService) Reassign(ctx context.Context, origin Origin, prID, oldReviewerID, newReviewerID string) (*model.PullRequest, string, error) {
+	if oldReviewerID == s.userID {
+		return nil, "", s.err
+	}
+	return s.PRService.Reassign(ctx, origin, prID, oldReviewerID, newReviewerID)
+}
+
+func TestAvailabilityService_ReassignAbsentReviewers_FailureKeepsAbsencePending(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.createPR(t, "pr-2", "u1")
+	b.join(t, "backend", "u4", "u5")
+	prs := failingReassignPRService{PRService: b.prs, userID: "u2", err: serviceerrs.ErrConcurrentUpdate}
+	svc := NewAvailabilityService(b.uow, b.availabilityRepo, b.userRepo, b.teamRepo, b.prRepo, b.auditRepo, prs)
+	startAbsence(t, b, "u2")
+	startAbsence(t, b, "u3")
+
+	result, err := svc.ReassignAbsentReviewers(ctx)
+	if err != nil {
+		t.Fatalf("expected per-PR failures not to abort the run, got %v", err)
+	}
+	if result.Absences != 2 || result.ReassignmentsFailed != 2 || result.ReassignmentsDone != 2 {
+		t.Fatalf("expected u2 to fail on both PRs and u3 to be replaced on both, got %+v", result)
+	}
+	if result, err := svc.ReassignAbsentReviewers(ctx); err != nil || result.Absences != 1 || result.ReassignmentsFailed != 2 {
+		t.Fatalf("expected only the failed absence to stay pending, got %+v, %v", result, err)
+	}
+}
+
+func TestAvailabilityService_AddDelegation_Rejected(t *testing.T) {
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		delegateID string
+		overlap    bool
+		want       error
+	}{
+		{name: "self", delegateID: "u1", want: serviceerrs.ErrDelegateSelf},
+		{name: "other team", delegateID: "u3", want: serviceerrs.ErrDelegateWrongTeam},
+		{name: "overlap", delegateID: "u2", overlap: true, want: serviceerrs.ErrDelegationOverlap},
+		{name: "unknown delegate", delegateID: "ghost", want: serviceerrs.ErrUserNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			b := newMemoryBackend(t, "u1", "u2")
+			b.addTeam(t, model.Team{Name: "frontend"}, "u3")
+			existing := 0
+			if tc.overlap {
+				if _, err := b.availability.AddDelegation(ctx, Origin{}, "u1", "u2", start.Add(-time.Hour), start.Add(time.Hour)); err != nil {
+					t.Fatalf("AddDelegation returned error: %v", err)
+				}
+				existing = 1
+			}
+
+			_, err := b.availability.AddDelegation(ctx, Origin{}, "u1", tc.delegateID, start, start.Add(24*time.Hour))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if delegations, _ := b.availability.GetDelegations(ctx, "u1"); len(delegations) != existing {
+				t.Fatalf("expected no delegation to be stored, got %+v", delegations)
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_AddDelegation_Success(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2")
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	delegation, err := b.availability.AddDelegation(context.Background(), Origin{}, "u1", "u2", start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if delegation.UserID != b.user(t, "u1").ID || delegation.DelegateID != b.user(t, "u2").ID || delegation.Delegate.UserID != "u2" {
+		t.Fatalf("unexpected delegation: %+v", delegation)
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+// Виды нарушений, которые находит проверка согласованности.
+const (
+	FindingInactiveReviewer  = "inactive_reviewer"
+	FindingAuthorReviewer    = "author_reviewer"
+	FindingDuplicateReviewer = "duplicate_reviewer"
+	FindingOverQuota         = "over_quota"
+	FindingWrongTeam         = "wrong_team"
+	FindingDeletedAuthor     = "deleted_author"
+)
+
+// Исправления нарушений. Все они выполняются обычными операциями prService.
+const (
+	// RepairReassign — Reassign, а если замены нет, RemoveReviewer, как при отказе от ревью.
+	RepairReassign = "reassign"
+	// RepairRemove — RemoveReviewer без замены.
+	RepairRemove = "remove"
+	// RepairReadd — RemoveReviewer и AddReviewer того же пользователя, чтобы осталась одна строка назначения.
+	RepairReadd = "readd"
+	// RepairNone — автоматического исправления нет, нарушение только попадает в отчёт.
+	RepairNone = "none"
+)
+
+// Результаты исправления в режиме apply.
+const (
+	OutcomeReassigned = "reassigned"
+	OutcomeRemoved    = "removed"
+	OutcomeReadded    = "readded"
+	OutcomeSkipped    = "skipped"
+	OutcomeFailed     = "failed"
+)
+
+type (
+	ConsistencyService interface {
+		// Check ищет нарушения инвариантов назначений в открытых PR.
+		// При apply нарушения исправляются от имени origin, результат каждого исправления попадает в отчёт.
+		Check(ctx context.Context, origin Origin, apply bool) (*ConsistencyReport, error)
+	}
+
+	consistencyService struct {
+		prRepo repository.PRRepository
+		prSvc  PRService
+	}
+
+	ConsistencyReport struct {
+		Applied  bool
+		Findings []ConsistencyFinding
+	}
+
+	ConsistencyFinding struct {
+		// Kind — нарушение, по которому выбрано исправление; OtherKinds — остальные нарушения того же ревьювера,
+		// их исправление Kind закрывает заодно.
+		Kind       string
+		OtherKinds []string
+		PRID       string
+		// UserID — ревьювер, которого касается нарушение; для deleted_author пуст.
+		UserID string
+		Repair string
+		// Outcome, ReplacedBy и Error заполняются только в режиме apply.
+		Outcome    string
+		ReplacedBy string
+		Error      string
+	}
+)
+
+func NewConsistencyService(prRepo repository.PRRepository, prSvc PRService) ConsistencyService {
+	return &consistencyService{prRepo: prRepo, prSvc: prSvc}
+}
+
+func (s *consistencyService) Check(ctx context.Context, origin Origin, apply bool) (*ConsistencyReport, error) {
+	logger := config.LoggerFrom(ctx)
+	prs, err := s.prRepo.GetOpenPRs(ctx)
+	if err != nil {
+		logger.Errorw("list open PRs for consistency check failed", "error", err)
+		return nil, err
+	}
+
+	report := &ConsistencyReport{Applied: apply}
+	for i := range prs {
+		report.Findings = append(report.Findings, inspectPR(&prs[i])...)
+	}
+	if !apply {
+		logger.Infow("consistency check finished", "prs", len(prs), "findings", len(report.Findings))
+		return report, nil
+	}
+
+	// Исправления проходят через обычные операции со своими проверками; ожидаемая версия PR к ним не относится.
+	origin.Reason = origin.reasonOr(model.ReasonConsistency)
+	origin.ExpectedVersion = nil
+	for i := range report.Findings {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.repair(ctx, origin, &report.Findings[i])
+	}
+	logger.Infow("consistency repair finished", "prs", len(prs), "findings", len(report.Findings))
+	return report, nil
+}
+
+// inspectPR возвращает нарушения одного открытого PR, по одному на ревьювера. Снятия идут раньше замен:
+// пока PR превышает лимит, БД не даст поставить замену ни одному ревьюверу.
+func inspectPR(pr *model.PullRequest) []ConsistencyFinding {
+	if pr.Author.ID == 0 {
+		// Без автора нельзя судить ни о команде, ни о лимите, и подобрать замены тоже не из кого.
+		return []ConsistencyFinding{{Kind: FindingDeletedAuthor, PRID: pr.PRID, Repair: RepairNone}}
+	}
+
+	var (
+		removals, reassigns, readds []ConsistencyFinding
+		kept, reviewers             []model.User
+		rows                        = make(map[uint]int, len(pr.AssignedReviewers))
+	)
+	for _, reviewer := range pr.AssignedReviewers {
+		if rows[reviewer.ID] == 0 {
+			reviewers = append(reviewers, reviewer)
+		}
+		rows[reviewer.ID]++
+	}
+	// Дубликат исправляется вместе с любым другим нарушением ревьювера: Reassign и RemoveReviewer снимают все его строки.
+	withDuplicate := func(f ConsistencyFinding, reviewer model.User) ConsistencyFinding {
+		if rows[reviewer.ID] > 1 {
+			f.OtherKinds = append(f.OtherKinds, FindingDuplicateReviewer)
+		}
+		return f
+	}
+	for _, reviewer := range reviewers {
+		kinds := reviewerViolations(pr, reviewer)
+		if len(kinds) == 0 {
+			kept = append(kept, reviewer)
+			if rows[reviewer.ID] > 1 {
+				readds = append(readds, ConsistencyFinding{Kind: FindingDuplicateReviewer, PRID: pr.PRID, UserID: reviewer.UserID, Repair: RepairReadd})
+			}
+			continue
+		}
+
+		finding := ConsistencyFinding{Kind: kinds[0], PRID: pr.PRID, UserID: reviewer.UserID}
+		if len(kinds) > 1 {
+			finding.OtherKinds = kinds[1:]
+		}
+		finding = withDuplicate(finding, reviewer)
+		finding.Repair = RepairReassign
+		reassigns = append(reassigns, finding)
+	}
+
+	// Сверх лимита сначала снимаются те, кого всё равно пришлось бы заменять, затем назначенные позже всех.
+	excess := len(kept) + len(reassigns) - pr.Author.Team.ReviewerLimit()
+	for excess > 0 && len(reassigns) > 0 {
+		last := len(reassigns) - 1
+		reassigns[last].Repair = RepairRemove
+		removals = append(removals, reassigns[last])
+		reassigns = reassigns[:last]
+		excess--
+	}
+	if excess > 0 {
+		slices.SortStableFunc(kept, func(a, b model.User) int {
+			return assignedAt(pr, b.ID).Compare(assignedAt(pr, a.ID))
+		})
+		for _, reviewer := range kept[:min(excess, len(kept))] {
+			removals = append(removals, withDuplicate(ConsistencyFinding{Kind: FindingOverQuota, PRID: pr.PRID, UserID: reviewer.UserID, Repair: RepairRemove}, reviewer))
+			readds = slices.DeleteFunc(readds, func(f ConsistencyFinding) bool { return f.UserID == reviewer.UserID })
+		}
+	}
+
+	return slices.Concat(removals, reassigns, readds)
+}
+
+// reviewerViolations возвращает нарушения ревьювера в порядке приоритета: исправление выбирается по первому.
+// Исправление у всех трёх одно — Reassign из команды автора, поэтому порядок определяет только, что попадёт в kind.
+func reviewerViolations(pr *model.PullRequest, reviewer model.User) []string {
+	var kinds []string
+	if reviewer.ID == pr.AuthorID {
+		kinds = append(kinds, FindingAuthorReviewer)
+	}
+	if reviewer.TeamID != pr.Author.TeamID {
+		kinds = append(kinds, FindingWrongTeam)
+	}
+	if !reviewer.IsActive {
+		kinds = append(kinds, FindingInactiveReviewer)
+	}
+	return kinds
+}
+
+// assignedAt возвращает время назначения ревьювера из строк pr_reviewers.
+func assignedAt(pr *model.PullRequest, reviewerID uint) time.Time {
+	for _, link := range pr.ReviewerLinks {
+		if link.UserID == reviewerID {
+			return link.AssignedAt
+		}
+	}
+	return time.Time{}
+}
+
+// repair исправляет одно нарушение и записывает результат в finding. Ошибка одного исправления не останавливает остальные.
+func (s *consistencyService) repair(ctx context.Context, origin Origin, finding *ConsistencyFinding) {
+	logger := config.LoggerFrom(ctx)
+	var err error
+	switch finding.Repair {
+	case RepairNone:
+		finding.Outcome = OutcomeSkipped
+		return
+	case RepairReassign:
+		var replacedBy string
+		_, replacedBy, err = s.prSvc.Reassign(ctx, origin, finding.PRID, finding.UserID, "")
+		if err == nil {
+			finding.Outcome, finding.ReplacedBy = OutcomeReassigned, replacedBy
+			break
+		}
+		if !errors.Is(err, serviceerrs.ErrNoCandidates) {
+			break
+		}
+		logger.Warnw("no replacement for inconsistent reviewer, removing", "pr_id", finding.PRID, "user_id", finding.UserID)
+		if _, err = s.prSvc.RemoveReviewer(ctx, origin, finding.PRID, finding.UserID); err == nil {
+			finding.Outcome = OutcomeRemoved
+		}
+	case RepairRemove:
+		if _, err = s.prSvc.RemoveReviewer(ctx, origin, finding.PRID, finding.UserID); err == nil {
+			finding.Outcome = OutcomeRemoved
+		}
+	case RepairReadd:
+		if _, err = s.prSvc.RemoveReviewer(ctx, origin, finding.PRID, finding.UserID); err != nil {
+			break
+		}
+		finding.Outcome = OutcomeRemoved
+		if _, err = s.prSvc.AddReviewer(ctx, origin, finding.PRID, finding.UserID); err == nil {
+			finding.Outcome = OutcomeReadded
+		}
+	}
+	if err == nil {
+		logger.Infow("inconsistency repaired", "kind", finding.Kind, "pr_id", finding.PRID, "user_id", finding.UserID, "outcome", finding.Outcome)
+		return
+	}
+
+	finding.Error = err.Error()
+	switch {
+	case finding.Outcome != "":
+		// Ревьювер снят, но вернуть его не удалось: результат частичный, ошибка объясняет почему.
+	case errors.Is(err, serviceerrs.ErrPRMerged),
+		errors.Is(err, serviceerrs.ErrPRNotFound),
+		errors.Is(err, serviceerrs.ErrReviewerMissing),
+		errors.Is(err, serviceerrs.ErrConcurrentUpdate):
+		// PR или назначение изменились после проверки; повторный запуск увидит актуальное состояние.
+		finding.Outcome = OutcomeSkipped
+	default:
+		finding.Outcome = OutcomeFailed
+	}
+	logger.Warnw("inconsistency not repaired", "kind", finding.Kind, "pr_id", finding.PRID, "user_id", finding.UserID, "outcome", finding.Outcome, "error", err)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+)
+
+// openPRsRepo отдаёт заранее собранные открытые PR. Автора среди ревьюверов, дубликат назначения
+// или превышение лимита настоящие репозитории записать не дают, поэтому такие PR собираются в тесте.
+type openPRsRepo struct {
+	repository.PRRepository
+	prs []model.PullRequest
+}
+
+func (r openPRsRepo) GetOpenPRs(ctx context.Context) ([]model.PullRequest, error) {
+	return r.prs, nil
+}
+
+func TestConsistencyService_DryRunReportsFindings(t *testing.T) {
+	now := time.Now()
+	team := model.Team{ID: 10, Name: "backend", MaxReviewers: 2}
+	author := model.User{ID: 1, UserID: "u1", TeamID: 10, IsActive: true, Team: team}
+	active := func(id uint, userID string) model.User {
+		return model.User{ID: id, UserID: userID, TeamID: 10, IsActive: true}
+	}
+	delegatedFor := active(4, "u4")
+	prs := []model.PullRequest{
+		{
+			// Автор среди ревьюверов, неактивный ревьювер с дубликатом и неактивный ревьювер из чужой команды.
+			PRID: "pr-1", AuthorID: 1, Author: author,
+			AssignedReviewers: []model.User{
+				{ID: 1, UserID: "u1", TeamID: 10, IsActive: true},
+				{ID: 2, UserID: "u2", TeamID: 10},
+				{ID: 7, UserID: "u7", TeamID: 20},
+				{ID: 2, UserID: "u2", TeamID: 10},
+			},
+		},
+		{
+			// Три ревьювера при лимите 2 и дубликат: снимается назначенный последним.
+			PRID: "pr-2", AuthorID: 1, Author: author,
+			AssignedReviewers: []model.User{active(3, "u3"), active(4, "u4"), active(5, "u5"), active(3, "u3")},
+			ReviewerLinks: []model.PRReviewer{
+				{UserID: 3, AssignedAt: now.Add(-3 * time.Hour)},
+				{UserID: 4, AssignedAt: now.Add(-time.Hour)},
+				{UserID: 5, AssignedAt: now.Add(-2 * time.Hour)},
+			},
+		},
+		{PRID: "pr-3", AuthorID: 8},
+		{
+			// u9 из чужой команды назначен делегатом за u4 из команды автора: делегат тоже должен быть из команды автора.
+			PRID: "pr-4", AuthorID: 1, Author: author,
+			AssignedReviewers: []model.User{active(3, "u3"), {ID: 9, UserID: "u9", TeamID: 20, IsActive: true}},
+			ReviewerLinks:     []model.PRReviewer{{UserID: 3}, {UserID: 9, DelegatedForID: &delegatedFor.ID, DelegatedFor: &delegatedFor}},
+		},
+	}
+	// prSvc не задан: dry-run не должен ничего исправлять.
+	svc := consistencyService{prRepo: openPRsRepo{prs: prs}}
+
+	report, err := svc.Check(context.Background(), Origin{}, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []ConsistencyFinding{
+		{Kind: FindingWrongTeam, OtherKinds: []string{FindingInactiveReviewer}, PRID: "pr-1", UserID: "u7", Repair: RepairRemove},
+		{Kind: FindingAuthorReviewer, PRID: "pr-1", UserID: "u1", Repair: RepairReassign},
+		{Kind: FindingInactiveReviewer, OtherKinds: []string{FindingDuplicateReviewer}, PRID: "pr-1", UserID: "u2", Repair: RepairReassign},
+		{Kind: FindingOverQuota, PRID: "pr-2", UserID: "u4", Repair: RepairRemove},
+		{Kind: FindingDuplicateReviewer, PRID: "pr-2", UserID: "u3", Repair: RepairReadd},
+		{Kind: FindingDeletedAuthor, PRID: "pr-3", Repair: RepairNone},
+		{Kind: FindingWrongTeam, PRID: "pr-4", UserID: "u9", Repair: RepairReassign},
+	}
+	if report.Applied || len(report.Findings) != len(want) {
+		t.Fatalf("expected %d findings in dry-run, got %+v", len(want), report)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(report.Findings[i], want[i]) {
+			t.Fatalf("finding %d: expected %+v, got %+v", i, want[i], report.Findings[i])
+		}
+	}
+}
+
+func TestConsistencyService_OverQuotaRemovesReviewersDueForReplacement(t *testing.T) {
+	author := model.User{ID: 1, UserID: "u1", TeamID: 10, IsActive: true, Team: model.Team{ID: 10}}
+	prs := []model.PullRequest{{
+		PRID: "pr-1", AuthorID: 1, Author: author,
+		AssignedReviewers: []model.User{
+			{ID: 2, UserID: "u2", TeamID: 10, IsActive: true},
+			{ID: 3, UserID: "u3", TeamID: 10, IsActive: true},
+			{ID: 4, UserID: "u4", TeamID: 10},
+		},
+	}}
+	svc := consistencyService{prRepo: openPRsRepo{prs: prs}}
+
+	report, err := svc.Check(context.Background(), Origin{}, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Kind != FindingInactiveReviewer || report.Findings[0].Repair != RepairRemove {
+		t.Fatalf("expected the inactive reviewer to be removed instead of replaced, got %+v", report.Findings)
+	}
+}
+
+func TestConsistencyService_ApplyRepairsThroughPRService(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	pr := b.createPR(t, "pr-1", "u1")
+	// u3 уходит в другую команду, u2 выключают без переназначения: замен в команде автора не остаётся.
+	b.addTeam(t, model.Team{Name: "frontend"}, "u3")
+	if _, err := b.userRepo.SetActive(ctx, "u2", false); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
+	}
+	svc := NewConsistencyService(b.prRepo, b.prs)
+
+	report, err := svc.Check(ctx, Origin{Actor: "admin", ExpectedVersion: &pr.Version}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Порядок ревьюверов в PR случайный, поэтому нарушения сравниваются по user_id.
+	slices.SortFunc(report.Findings, func(a, b ConsistencyFinding) int { return strings.Compare(a.UserID, b.UserID) })
+	want := []ConsistencyFinding{
+		{Kind: FindingInactiveReviewer, PRID: "pr-1", UserID: "u2", Repair: RepairReassign, Outcome: OutcomeRemoved},
+		{Kind: FindingWrongTeam, PRID: "pr-1", UserID: "u3", Repair: RepairReassign, Outcome: OutcomeRemoved},
+	}
+	if !report.Applied || !reflect.DeepEqual(report.Findings, want) {
+		t.Fatalf("expected both reviewers to be removed, got %+v", report)
+	}
+	// Вторая правка прошла, хотя первая сдвинула версию PR: ожидаемая версия к исправлениям не относится.
+	events := b.prEvents(t, "pr-1")
+	for _, event := range events[:2] {
+		if event.Type != model.AuditUnassigned || event.Actor != "admin" || event.Reason != model.ReasonConsistency {
+			t.Fatalf("expected admin unassignments with consistency_repair reason, got %+v", event)
+		}
+	}
+}
+
+func TestConsistencyService_ApplySkipsChangedPRs(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	if _, err := b.userRepo.SetActive(ctx, "u2", false); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
+	}
+	// PR смержили между чтением открытых PR и исправлением.
+	snapshot, err := b.prRepo.GetOpenPRs(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenPRs returned error: %v", err)
+	}
+	if _, err := b.prs.Merge(ctx, Origin{}, "pr-1"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	svc := consistencyService{prRepo: openPRsRepo{prs: snapshot}, prSvc: b.prs}
+
+	report, err := svc.Check(ctx, Origin{}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if f := report.Findings[0]; f.Outcome != OutcomeSkipped || f.Error == "" {
+		t.Fatalf("expected skipped repair with error, got %+v", f)
+	}
+}
+
+func TestConsistencyService_ReassignsDelegateFromAnotherTeam(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u5")
+	delegate(t, b, "u2", "u5")
+	if _, _, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u2", ""); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	// Делегат ушёл в другую команду: для проверки, как и для триггера, он такой же ревьювер из чужой команды.
+	b.addTeam(t, model.Team{Name: "frontend"}, "u5")
+
+	report, err := NewConsistencyService(b.prRepo, b.prs).Check(ctx, Origin{}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []ConsistencyFinding{{Kind: FindingWrongTeam, PRID: "pr-1", UserID: "u5", Repair: RepairReassign, Outcome: OutcomeReassigned, ReplacedBy: "u2"}}
+	if !reflect.DeepEqual(report.Findings, want) {
+		t.Fatalf("expected delegate u5 to be replaced from the author's team, got %+v", report.Findings)
+	}
+}
@@ -11,4 +11,44 @@ var (
 	ErrReviewerMissing = errors.New("reviewer not assigned to PR")
 	ErrNoCandidates    = errors.New("no active candidates")
 	ErrPRMerged        = errors.New("pull request already merged")
+
+	ErrVersionMismatch  = errors.New("pull request version does not match the expected one")
+	ErrConcurrentUpdate = errors.New("pull request was modified concurrently, retry the request")
+
+	ErrReviewerIsAuthor  = errors.New("author cannot review own pull request")
+	ErrReviewerInactive  = errors.New("reviewer is not active")
+	ErrReviewerAssigned  = errors.New("reviewer already assigned to PR")
+	ErrReviewerLimit     = errors.New("reviewer limit reached for PR")
+	ErrReviewerWrongTeam = errors.New("reviewer is not a member of eligible team")
+	ErrReviewerAbsent    = errors.New("reviewer is absent")
+
+	ErrInvalidPeriod   = errors.New("invalid period: ends_at must be after starts_at")
+	ErrAbsenceNotFound = errors.New("absence not found")
+	ErrAbsenceOverlap  = errors.New("absence overlaps with an existing one")
+
+	ErrDelegateSelf       = errors.New("user cannot delegate reviews to themselves")
+	ErrDelegateWrongTeam  = errors.New("delegate must be a member of the same team")
+	ErrDelegationOverlap  = errors.New("delegation overlaps with an existing one")
+	ErrDelegationNotFound = errors.New("delegation not found")
+
+	ErrInvalidTimeZone       = errors.New("unknown time zone")
+	ErrInvalidWorkingHours   = errors.New("invalid working hours: expected HH:MM with work_start before work_end")
+	ErrInvalidAssignmentMode = errors.New("unknown assignment mode")
+	ErrInvalidMaxReviewers   = errors.New("max_reviewers must be at least 1")
+
+	ErrInvalidGroupBy = errors.New("group_by must be team or user")
+	ErrInvalidStatus  = errors.New("status must be OPEN or MERGED")
+	ErrInvalidSort    = errors.New("unsupported sort")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrPeriodNotDays  = errors.New("from and to must be the start of a UTC day")
+
+	ErrUserIDsRequired  = errors.New("user_ids is required")
+	ErrInvalidPlanToken = errors.New("invalid plan token")
+	ErrPlanStale        = errors.New("data changed since the plan was made, request a new dry run")
+
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job already finished")
+
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
 )
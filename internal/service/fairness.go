@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+type (
+	// MemberFairness — нагрузка участника и её отклонение от среднего по активным участникам команды.
+	MemberFairness struct {
+		UserID           string
+		Username         string
+		IsActive         bool
+		OpenAssignments  int64
+		TotalAssignments int64
+		// TotalAssignments минус среднее по команде; для неактивных считается от того же среднего.
+		Deviation float64
+	}
+
+	// FairnessReport — распределение ревью в команде.
+	// Среднее и коэффициенты Джини считаются только по активным участникам: неактивных выбор не назначает.
+	FairnessReport struct {
+		TeamName  string
+		MeanTotal float64
+		MeanOpen  float64
+		// Коэффициент Джини: 0 — нагрузка распределена поровну, ближе к 1 — сосредоточена у немногих.
+		GiniTotal float64
+		GiniOpen  float64
+		Members   []MemberFairness
+		// Активные участники, которые ни разу не назначались ревьюверами.
+		NeverAssigned []string
+	}
+)
+
+func (s *statsService) Fairness(ctx context.Context, teamName string) (*FairnessReport, error) {
+	logger := config.LoggerFrom(ctx)
+	team, err := s.teamRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("fairness team not found", "team_name", teamName)
+			return nil, serviceerrs.ErrTeamNotFound
+		}
+		logger.Errorw("get team for fairness failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	load, err := s.repo.GetTeamLoad(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals, open []float64
+	for _, m := range load {
+		if !m.IsActive {
+			continue
+		}
+		totals = append(totals, float64(m.TotalAssignments))
+		open = append(open, float64(m.OpenAssignments))
+	}
+
+	report := &FairnessReport{
+		TeamName:      team.Name,
+		MeanTotal:     mean(totals),
+		MeanOpen:      mean(open),
+		GiniTotal:     gini(totals),
+		GiniOpen:      gini(open),
+		Members:       make([]MemberFairness, 0, len(load)),
+		NeverAssigned: []string{},
+	}
+	for _, m := range load {
+		report.Members = append(report.Members, MemberFairness{
+			UserID:           m.UserID,
+			Username:         m.Username,
+			IsActive:         m.IsActive,
+			OpenAssignments:  m.OpenAssignments,
+			TotalAssignments: m.TotalAssignments,
+			Deviation:        float64(m.TotalAssignments) - report.MeanTotal,
+		})
+		if m.IsActive && m.TotalAssignments == 0 {
+			report.NeverAssigned = append(report.NeverAssigned, m.UserID)
+		}
+	}
+
+	logger.Infow("fairness report computed", "team_name", team.Name, "members", len(load), "gini_total", report.GiniTotal)
+	return report, nil
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// gini — средняя попарная разница, нормированная на удвоенное среднее.
+func gini(values []float64) float64 {
+	m := mean(values)
+	if m == 0 {
+		return 0
+	}
+	var diff float64
+	for _, a := range values {
+		for _, b := range values {
+			diff += math.Abs(a - b)
+		}
+	}
+	n := float64(len(values))
+	return diff / (2 * n * n * m)
+}
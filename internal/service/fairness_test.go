@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestGini(t *testing.T) {
+	if got := gini([]float64{3, 3, 3}); got != 0 {
+		t.Fatalf("expected 0 for equal load, got %v", got)
+	}
+	// Вся нагрузка у одного из четырёх: (n-1)/n.
+	if got := gini([]float64{0, 0, 0, 8}); math.Abs(got-0.75) > 1e-9 {
+		t.Fatalf("expected 0.75, got %v", got)
+	}
+	if got := gini(nil); got != 0 {
+		t.Fatalf("expected 0 for empty team, got %v", got)
+	}
+}
+
+func TestStatsService_Fairness(t *testing.T) {
+	repo := &stubStatsRepo{teamLoad: []repository.MemberLoad{
+		{UserID: "u1", IsActive: true, OpenAssignments: 1, TotalAssignments: 6},
+		{UserID: "u2", IsActive: true, OpenAssignments: 1, TotalAssignments: 2},
+		{UserID: "u3", IsActive: true},
+		{UserID: "u4", IsActive: false, TotalAssignments: 10},
+	}}
+	svc := statsService{repo: repo, teamRepo: newMemoryBackend(t).teamRepo}
+
+	report, err := svc.Fairness(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Неактивный u4 в среднее не входит.
+	if report.MeanTotal != 8.0/3 {
+		t.Fatalf("expected mean over active members, got %v", report.MeanTotal)
+	}
+	if len(report.NeverAssigned) != 1 || report.NeverAssigned[0] != "u3" {
+		t.Fatalf("expected u3 to be never assigned, got %v", report.NeverAssigned)
+	}
+	if report.Members[0].Deviation <= 0 || report.Members[2].Deviation >= 0 {
+		t.Fatalf("unexpected deviations: %+v", report.Members)
+	}
+	if report.GiniTotal <= 0 || report.GiniTotal >= 1 {
+		t.Fatalf("expected gini in (0, 1), got %v", report.GiniTotal)
+	}
+}
+
+func TestStatsService_Fairness_TeamNotFound(t *testing.T) {
+	svc := statsService{repo: &stubStatsRepo{}, teamRepo: newMemoryBackend(t).teamRepo}
+	if _, err := svc.Fairness(context.Background(), "missing"); !errors.Is(err, serviceerrs.ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
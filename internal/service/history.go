@@ -0,0 +1,36 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// rewindPR приводит PR к состоянию на момент at: ревьюверы берутся из действовавших периодов назначения.
+func rewindPR(pr *model.PullRequest, periods []model.PRReviewerPeriod, at time.Time) {
+	reviewers := make([]model.User, 0, len(periods))
+	links := make([]model.PRReviewer, 0, len(periods))
+	for _, p := range periods {
+		reviewers = append(reviewers, p.User)
+		links = append(links, model.PRReviewer{
+			PullRequestID:  pr.ID,
+			UserID:         p.UserID,
+			AssignedAt:     p.ValidFrom,
+			DelegatedForID: p.DelegatedForID,
+			DelegatedFor:   p.DelegatedFor,
+		})
+	}
+	pr.AssignedReviewers = reviewers
+	pr.ReviewerLinks = links
+	// Предупреждения о составе описывают текущие правила команды и к прошлому не относятся.
+	pr.AssignmentWarnings = nil
+	rewindStatus(pr, at)
+}
+
+// rewindStatus возвращает PR в OPEN, если merge случился позже at.
+func rewindStatus(pr *model.PullRequest, at time.Time) {
+	if pr.Status == statusMerged && pr.MergedAt != nil && pr.MergedAt.After(at) {
+		pr.Status = statusOpen
+		pr.MergedAt = nil
+	}
+}
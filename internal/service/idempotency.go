@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+type (
+	// IdempotencyService хранит ответы на запросы с Idempotency-Key, чтобы ретраи получали исходный ответ,
+	// а не повторно выполняли операцию.
+	IdempotencyService interface {
+		// Begin резервирует ключ за запросом. Если ответ на такой же запрос уже сохранён, возвращает его;
+		// nil означает, что запрос нужно выполнить и затем вызвать Complete или Abort.
+		Begin(ctx context.Context, key, route, fingerprint string) (*model.IdempotencyRecord, error)
+		// Complete сохраняет ответ на зарезервированный запрос на время ttl.
+		Complete(ctx context.Context, key, route, fingerprint string, status int, headers string, body []byte) error
+		// Abort снимает резерв, чтобы повтор запроса выполнился заново.
+		Abort(ctx context.Context, key, route string) error
+		// PurgeExpired удаляет истёкшие записи.
+		PurgeExpired(ctx context.Context) (int64, error)
+	}
+
+	idempotencyService struct {
+		repo repository.IdempotencyRepository
+		// ttl — сколько хранится ответ; lockTTL — сколько держится резерв незавершённого запроса,
+		// чтобы ключ освободился, если процесс упал посреди запроса.
+		ttl     time.Duration
+		lockTTL time.Duration
+	}
+)
+
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl, lockTTL time.Duration) IdempotencyService {
+	return &idempotencyService{repo: repo, ttl: ttl, lockTTL: lockTTL}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, key, route, fingerprint string) (*model.IdempotencyRecord, error) {
+	logger := config.LoggerFrom(ctx)
+	record, err := s.repo.Get(ctx, key, route)
+	switch {
+	case errors.Is(err, repoerrs.ErrNotFound):
+	case err != nil:
+		return nil, err
+	case !record.ExpiresAt.After(clock()):
+		logger.Debugw("idempotency record expired", "key", key, "route", route)
+		if err := s.repo.Delete(ctx, key, route); err != nil {
+			return nil, err
+		}
+	default:
+		return s.existing(ctx, record, fingerprint)
+	}
+
+	now := clock()
+	err = s.repo.Create(ctx, &model.IdempotencyRecord{
+		Key:         key,
+		Route:       route,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.lockTTL),
+	})
+	if errors.Is(err, repoerrs.ErrDuplicate) {
+		// Параллельный запрос успел занять ключ между чтением и резервом.
+		record, err := s.repo.Get(ctx, key, route)
+		if err != nil {
+			return nil, err
+		}
+		return s.existing(ctx, record, fingerprint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugw("idempotency key reserved", "key", key, "route", route)
+	return nil, nil
+}
+
+// existing решает, что делать с уже занятым ключом: отдать сохранённый ответ или отклонить запрос.
+func (s *idempotencyService) existing(ctx context.Context, record *model.IdempotencyRecord, fingerprint string) (*model.IdempotencyRecord, error) {
+	logger := config.LoggerFrom(ctx)
+	if record.Fingerprint != fingerprint {
+		logger.Warnw("idempotency key reused with another request", "key", record.Key, "route", record.Route)
+		return nil, serviceerrs.ErrIdempotencyKeyReused
+	}
+	if !record.Completed() {
+		logger.Warnw("idempotent request still in progress", "key", record.Key, "route", record.Route)
+		return nil, serviceerrs.ErrIdempotencyInProgress
+	}
+	logger.Infow("idempotent response replayed", "key", record.Key, "route", record.Route, "status", record.StatusCode)
+	return record, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, key, route, fingerprint string, status int, headers string, body []byte) error {
+	now := clock()
+	return s.repo.Save(ctx, &model.IdempotencyRecord{
+		Key:         key,
+		Route:       route,
+		Fingerprint: fingerprint,
+		StatusCode:  status,
+		Headers:     headers,
+		Body:        body,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+}
+
+func (s *idempotencyService) Abort(ctx context.Context, key, route string) error {
+	return s.repo.Delete(ctx, key, route)
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	removed, err := s.repo.DeleteExpired(ctx, clock())
+	if err != nil {
+		return 0, err
+	}
+	config.LoggerFrom(ctx).Infow("expired idempotency records purged", "count", removed)
+	return removed, nil
+}
+
+// RunIdempotencyCleanupJob периодически удаляет истёкшие ответы, пока не закрыт stop.
+func RunIdempotencyCleanupJob(svc IdempotencyService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if _, err := svc.PurgeExpired(ctx); err != nil {
+				config.LoggerFrom(ctx).Errorw("idempotency cleanup job failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"github.com/Leganyst/avitoTrainee/internal/repository/memory"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestIdempotencyService_ReplaysCompletedResponse(t *testing.T) {
+	withClock(t, time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC))
+	svc := NewIdempotencyService(memory.NewIdempotencyRepository(memory.NewStore()), time.Hour, time.Minute)
+	ctx := context.Background()
+
+	record, err := svc.Begin(ctx, "key-1", "POST /api/pullRequest/create", "fp")
+	if err != nil || record != nil {
+		t.Fatalf("expected key to be reserved, got record=%v err=%v", record, err)
+	}
+	if _, err := svc.Begin(ctx, "key-1", "POST /api/pullRequest/create", "fp"); !errors.Is(err, serviceerrs.ErrIdempotencyInProgress) {
+		t.Fatalf("expected ErrIdempotencyInProgress while the first request runs, got %v", err)
+	}
+	if err := svc.Complete(ctx, "key-1", "POST /api/pullRequest/create", "fp", 201, `{}`, []byte(`{"pr":{}}`)); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	record, err = svc.Begin(ctx, "key-1", "POST /api/pullRequest/create", "fp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record == nil || record.StatusCode != 201 || string(record.Body) != `{"pr":{}}` {
+		t.Fatalf("expected stored response to be replayed, got %+v", record)
+	}
+}
+
+func TestIdempotencyService_KeyReusedWithAnotherRequest(t *testing.T) {
+	withClock(t, time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC))
+	svc := NewIdempotencyService(memory.NewIdempotencyRepository(memory.NewStore()), time.Hour, time.Minute)
+	ctx := context.Background()
+
+	if _, err := svc.Begin(ctx, "key-1", "POST /api/users/bulkDeactivate", "fp-1"); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := svc.Complete(ctx, "key-1", "POST /api/users/bulkDeactivate", "fp-1", 200, `{}`, nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if _, err := svc.Begin(ctx, "key-1", "POST /api/users/bulkDeactivate", "fp-2"); !errors.Is(err, serviceerrs.ErrIdempotencyKeyReused) {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	// Тот же ключ на другом маршруте — независимый запрос.
+	if record, err := svc.Begin(ctx, "key-1", "POST /api/pullRequest/create", "fp-2"); err != nil || record != nil {
+		t.Fatalf("expected key to be free on another route, got record=%v err=%v", record, err)
+	}
+}
+
+func TestIdempotencyService_ExpiredRecordsAreReleased(t *testing.T) {
+	start := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	withClock(t, start)
+	repo := memory.NewIdempotencyRepository(memory.NewStore())
+	svc := NewIdempotencyService(repo, time.Hour, time.Minute)
+	ctx := context.Background()
+
+	if _, err := svc.Begin(ctx, "stuck", "POST /api/pullRequest/create", "fp"); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := svc.Begin(ctx, "done", "POST /api/pullRequest/create", "fp"); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := svc.Complete(ctx, "done", "POST /api/pullRequest/create", "fp", 201, `{}`, nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	// Резерв брошенного запроса истекает через lockTTL, сохранённый ответ живёт ttl.
+	withClock(t, start.Add(2*time.Minute))
+	if record, err := svc.Begin(ctx, "stuck", "POST /api/pullRequest/create", "fp"); err != nil || record != nil {
+		t.Fatalf("expected abandoned reservation to be taken over, got record=%v err=%v", record, err)
+	}
+	if record, err := svc.Begin(ctx, "done", "POST /api/pullRequest/create", "fp"); err != nil || record == nil {
+		t.Fatalf("expected stored response within ttl, got record=%v err=%v", record, err)
+	}
+
+	withClock(t, start.Add(2*time.Hour))
+	removed, err := svc.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected both records purged, removed=%d", removed)
+	}
+	for _, key := range []string{"stuck", "done"} {
+		if _, err := repo.Get(ctx, key, "POST /api/pullRequest/create"); !errors.Is(err, repoerrs.ErrNotFound) {
+			t.Fatalf("expected %q to be purged, got %v", key, err)
+		}
+	}
+}
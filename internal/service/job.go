@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/Leganyst/avitoTrainee/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// jobLease — на сколько обработчик берёт задачу, прежде чем её сможет подхватить другой.
+// Аренда продлевается после каждого элемента, поэтому срок должен быть заметно больше времени одного элемента.
+const jobLease = 5 * time.Minute
+
+// maxJobAttempts — после стольких прерванных выполнений задача завершается как failed,
+// чтобы постоянная ошибка не возвращала её в очередь бесконечно.
+const maxJobAttempts = 3
+
+// errJobAttemptsExhausted — ошибка невыполненных элементов задачи, завершённой как failed.
+var errJobAttemptsExhausted = errors.New("job attempts exhausted")
+
+type (
+	JobService interface {
+		// SubmitBulkDeactivate ставит в очередь деактивацию пользователей команды, по элементу на пользователя.
+		SubmitBulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string) (*model.Job, error)
+		// GetJob возвращает задачу с прогрессом и результатами элементов.
+		GetJob(ctx context.Context, id uint) (*model.Job, error)
+		// CancelJob отменяет задачу из очереди сразу, а выполняемую — перед её следующим элементом.
+		CancelJob(ctx context.Context, id uint) (*model.Job, error)
+		// ProcessNext забирает одну задачу и выполняет её до конца или до отмены; false — задач нет.
+		ProcessNext(ctx context.Context) (bool, error)
+		// Submitted сигналит о новой задаче, чтобы обработчики не ждали следующего опроса.
+		Submitted() <-chan struct{}
+	}
+
+	jobService struct {
+		uow       repository.UnitOfWork
+		jobRepo   repository.JobRepository
+		teamRepo  repository.TeamRepository
+		userSvc   UserService
+		submitted chan struct{}
+		// worker — имя, под которым экземпляр сервиса берёт задачи в аренду.
+		worker string
+	}
+
+	// bulkDeactivatePayload — параметры задачи bulk_deactivate, общие для всех элементов.
+	bulkDeactivatePayload struct {
+		TeamName string `json:"team_name"`
+	}
+
+	// bulkDeactivateItemResult — результат деактивации одного пользователя в Result элемента.
+	bulkDeactivateItemResult struct {
+		Reassigned       int                     `json:"reassigned"`
+		Skipped          int                     `json:"skipped"`
+		AffectedPRs      int                     `json:"affected_prs"`
+		PolicyViolations int                     `json:"policy_violations"`
+		Plan             []bulkDeactivateJobStep `json:"plan"`
+	}
+
+	bulkDeactivateJobStep struct {
+		PRID              string `json:"pull_request_id"`
+		RemovedUserID     string `json:"removed_user_id"`
+		ReplacementUserID string `json:"replacement_user_id,omitempty"`
+		Delegated         bool   `json:"delegated,omitempty"`
+		SkipReason        string `json:"skip_reason,omitempty"`
+	}
+)
+
+func NewJobService(uow repository.UnitOfWork, jobRepo repository.JobRepository, teamRepo repository.TeamRepository, userSvc UserService) JobService {
+	return &jobService{
+		uow:       uow,
+		jobRepo:   jobRepo,
+		teamRepo:  teamRepo,
+		userSvc:   userSvc,
+		submitted: make(chan struct{}, 1),
+		worker:    workerName(),
+	}
+}
+
+// workerName отличает экземпляры сервиса, в том числе несколько в одном процессе.
+func workerName() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+func (s *jobService) SubmitBulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string) (job *model.Job, err error) {
+	ctx, span := tracing.Start(ctx, "jobService.SubmitBulkDeactivate", attribute.String("team_name", teamName), attribute.Int("users", len(userIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	if len(userIDs) == 0 {
+		return nil, serviceerrs.ErrUserIDsRequired
+	}
+	if _, err := s.teamRepo.GetTeamByName(ctx, teamName); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("bulk deactivate job team not found", "team_name", teamName)
+			return nil, serviceerrs.ErrTeamNotFound
+		}
+		logger.Errorw("bulk deactivate job get team failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	payload, err := json.Marshal(bulkDeactivatePayload{TeamName: teamName})
+	if err != nil {
+		return nil, err
+	}
+	job = &model.Job{
+		Kind:    model.JobKindBulkDeactivate,
+		Status:  model.JobQueued,
+		Actor:   origin.Actor,
+		Payload: string(payload),
+	}
+	for _, userID := range userIDs {
+		// Повтор пользователя в запросе не должен давать второй элемент, который заведомо завершится ошибкой.
+		if slices.ContainsFunc(job.Items, func(item model.JobItem) bool { return item.Key == userID }) {
+			continue
+		}
+		job.Items = append(job.Items, model.JobItem{Seq: len(job.Items) + 1, Key: userID, Status: model.JobItemPending})
+	}
+	job.Total = len(job.Items)
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	select {
+	case s.submitted <- struct{}{}:
+	default:
+	}
+	logger.Infow("bulk deactivate job submitted", "job_id", job.ID, "team_name", teamName, "items", job.Total)
+	return job, nil
+}
+
+func (s *jobService) GetJob(ctx context.Context, id uint) (job *model.Job, err error) {
+	ctx, span := tracing.Start(ctx, "jobService.GetJob", attribute.Int("job_id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err = s.jobRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, serviceerrs.ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *jobService) CancelJob(ctx context.Context, id uint) (job *model.Job, err error) {
+	ctx, span := tracing.Start(ctx, "jobService.CancelJob", attribute.Int("job_id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		current, err := s.jobRepo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.Finished() {
+			return serviceerrs.ErrJobFinished
+		}
+		if _, err := s.jobRepo.RequestCancel(ctx, id, clock()); err != nil {
+			return err
+		}
+		job, err = s.jobRepo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Задача могла завершиться между проверкой и отменой: RequestCancel её тогда не трогает.
+		if job.Finished() && job.Status != model.JobCanceled {
+			return serviceerrs.ErrJobFinished
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrNotFound):
+			logger.Warnw("job to cancel not found", "job_id", id)
+			return nil, serviceerrs.ErrJobNotFound
+		case errors.Is(err, serviceerrs.ErrJobFinished):
+			logger.Warnw("job already finished, nothing to cancel", "job_id", id)
+		}
+		return nil, err
+	}
+	logger.Infow("job cancel requested", "job_id", id, "status", job.Status)
+	return job, nil
+}
+
+func (s *jobService) Submitted() <-chan struct{} {
+	return s.submitted
+}
+
+func (s *jobService) ProcessNext(ctx context.Context) (bool, error) {
+	now := clock()
+	job, err := s.jobRepo.ClaimNext(ctx, s.worker, now, now.Add(jobLease))
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	// Обработчик задачи падал, не успев её отпустить, столько раз, что дальше она выполняться не будет.
+	if job.Attempts >= maxJobAttempts {
+		config.LoggerFrom(ctx).Errorw("job attempts exhausted", "job_id", job.ID, "attempts", job.Attempts)
+		return true, s.finish(ctx, job, model.JobFailed)
+	}
+	return true, s.runJob(ctx, job)
+}
+
+// runJob выполняет оставшиеся элементы задачи. Каждый элемент вместе с записью его результата и прогресса
+// выполняется в своей единице работы, поэтому после сбоя задача продолжается с первого невыполненного элемента.
+func (s *jobService) runJob(ctx context.Context, job *model.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "jobService.runJob", attribute.Int("job_id", int(job.ID)), attribute.String("kind", job.Kind))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx).With("job_id", job.ID, "kind", job.Kind)
+	logger.Infow("job started", "processed", job.Processed, "total", job.Total)
+	origin := Origin{Actor: job.Actor}
+	// Заменой не выбирается никто из пользователей задачи: иначе ревью достанется тому, кого деактивируют следующим элементом.
+	keys := make([]string, 0, len(job.Items))
+	for _, item := range job.Items {
+		keys = append(keys, item.Key)
+	}
+
+	// Если задача не завершена, обработчик возвращает её в очередь при любом выходе, а не ждёт истечения аренды.
+	// Задачу, аренду которой он потерял, держит уже другой обработчик, и трогать её нельзя.
+	finished := false
+	defer func() {
+		if !finished && !errors.Is(err, repoerrs.ErrLeaseLost) {
+			s.release(ctx, job, err)
+		}
+	}()
+
+	for i := range job.Items {
+		item := &job.Items[i]
+		if item.Status != model.JobItemPending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		canceled, err := s.jobRepo.CancelRequested(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		if canceled {
+			if err := s.finish(ctx, job, model.JobCanceled); err != nil {
+				return err
+			}
+			finished = true
+			return nil
+		}
+
+		// Откат единицы работы не должен оставить в job записанный прогресс: release сохранит его как есть.
+		saved, processed, heartbeatAt, leaseUntil := *item, job.Processed, job.HeartbeatAt, job.LeaseUntil
+		err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+			s.runItem(ctx, origin, job, item, keys)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			now := clock()
+			lease := now.Add(jobLease)
+			item.FinishedAt = &now
+			job.Processed++
+			job.HeartbeatAt, job.LeaseUntil = &now, &lease
+			if err := s.jobRepo.SaveItem(ctx, s.worker, now, item); err != nil {
+				return err
+			}
+			return s.jobRepo.SaveProgress(ctx, s.worker, now, job)
+		})
+		if err != nil {
+			*item, job.Processed, job.HeartbeatAt, job.LeaseUntil = saved, processed, heartbeatAt, leaseUntil
+			if errors.Is(err, repoerrs.ErrLeaseLost) {
+				logger.Warnw("job lease lost, stopping", "seq", item.Seq, "key", item.Key)
+				return err
+			}
+			logger.Errorw("job item not recorded", "seq", item.Seq, "key", item.Key, "error", err)
+			return err
+		}
+	}
+	if err := s.finish(ctx, job, model.JobCompleted); err != nil {
+		return err
+	}
+	finished = true
+	return nil
+}
+
+// runItem выполняет один элемент и записывает его статус, результат или ошибку в item.
+func (s *jobService) runItem(ctx context.Context, origin Origin, job *model.Job, item *model.JobItem, keys []string) {
+	logger := config.LoggerFrom(ctx)
+	result, err := s.bulkDeactivateItem(ctx, origin, job, item.Key, keys)
+	if err != nil {
+		item.Status, item.Error = model.JobItemFailed, err.Error()
+		logger.Warnw("job item failed", "job_id", job.ID, "seq", item.Seq, "key", item.Key, "error", err)
+		return
+	}
+	item.Status, item.Result = model.JobItemSucceeded, result
+	logger.Debugw("job item succeeded", "job_id", job.ID, "seq", item.Seq, "key", item.Key)
+}
+
+// bulkDeactivateItem деактивирует userID; остальные пользователи задачи из keys заменой не выбираются.
+func (s *jobService) bulkDeactivateItem(ctx context.Context, origin Origin, job *model.Job, userID string, keys []string) (string, error) {
+	var payload bulkDeactivatePayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return "", fmt.Errorf("decode job payload: %w", err)
+	}
+	res, err := s.userSvc.BulkDeactivate(ctx, origin, payload.TeamName, []string{userID}, BulkDeactivateOptions{Exclude: keys})
+	if err != nil {
+		return "", err
+	}
+
+	result := bulkDeactivateItemResult{
+		Reassigned:       res.ReassignmentsDone,
+		Skipped:          res.ReassignmentsSkipped,
+		AffectedPRs:      res.AffectedPullRequests,
+		PolicyViolations: res.PolicyViolations,
+		Plan:             make([]bulkDeactivateJobStep, 0, len(res.Plan)),
+	}
+	for _, step := range res.Plan {
+		result.Plan = append(result.Plan, bulkDeactivateJobStep(step))
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// finish завершает задачу и отпускает её аренду. При отмене невыполненные элементы помечаются отменёнными,
+// а у задачи, исчерпавшей попытки, — завершёнными с ошибкой.
+func (s *jobService) finish(ctx context.Context, job *model.Job, status string) error {
+	now := clock()
+	err := inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		for i := range job.Items {
+			item := &job.Items[i]
+			if item.Status != model.JobItemPending {
+				continue
+			}
+			item.Status, item.FinishedAt = model.JobItemCanceled, &now
+			if status == model.JobFailed {
+				item.Status, item.Error = model.JobItemFailed, errJobAttemptsExhausted.Error()
+			}
+			if err := s.jobRepo.SaveItem(ctx, s.worker, now, item); err != nil {
+				return err
+			}
+		}
+		job.Status, job.FinishedAt, job.HeartbeatAt = status, &now, &now
+		job.LockedBy, job.LeaseUntil = "", nil
+		return s.jobRepo.SaveProgress(ctx, s.worker, now, job)
+	})
+	if err != nil {
+		return err
+	}
+	config.LoggerFrom(ctx).Infow("job finished", "job_id", job.ID, "status", status, "processed", job.Processed, "total", job.Total)
+	return nil
+}
+
+// release возвращает задачу в очередь, когда обработчик останавливается посреди неё из-за cause.
+// Остановка сервиса попыткой не считается, а задача, прерванная ошибкой maxJobAttempts раз, завершается как failed.
+func (s *jobService) release(ctx context.Context, job *model.Job, cause error) {
+	shutdown := ctx.Err() != nil
+	ctx = context.WithoutCancel(ctx)
+	logger := config.LoggerFrom(ctx)
+	if !shutdown {
+		job.Attempts++
+		if job.Attempts >= maxJobAttempts {
+			logger.Errorw("job attempts exhausted", "job_id", job.ID, "attempts", job.Attempts, "error", cause)
+			err := s.finish(ctx, job, model.JobFailed)
+			if err == nil {
+				return
+			}
+			// Попытки всё равно сохраняются: следующий обработчик завершит задачу сразу после захвата.
+			logger.Errorw("fail job failed", "job_id", job.ID, "error", err)
+		}
+	}
+	job.Status, job.FinishedAt, job.HeartbeatAt = model.JobQueued, nil, nil
+	job.LockedBy, job.LeaseUntil = "", nil
+	if err := s.jobRepo.SaveProgress(ctx, s.worker, clock(), job); err != nil {
+		logger.Errorw("release job failed", "job_id", job.ID, "error", err)
+		return
+	}
+	logger.Infow("job released", "job_id", job.ID, "processed", job.Processed, "total", job.Total, "attempts", job.Attempts)
+}
+
+// RunJobWorkers запускает workers обработчиков задач и ждёт их остановки после закрытия stop.
+// Обработчик берёт задачи, пока они есть, затем ждёт новой задачи или следующего опроса через interval:
+// опрос нужен, чтобы подхватывать задачи, поставленные другими экземплярами, и брошенные задачи.
+func RunJobWorkers(svc JobService, workers int, interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() { runJobWorker(ctx, svc, interval) })
+	}
+	wg.Wait()
+}
+
+func runJobWorker(ctx context.Context, svc JobService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := svc.ProcessNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				config.LoggerFrom(ctx).Errorw("job worker failed", "error", err)
+			}
+			if !processed || err != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-svc.Submitted():
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"github.com/Leganyst/avitoTrainee/internal/repository/memory"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+// cancelingUserService запрашивает отмену задачи во время первого элемента, как если бы она пришла посреди выполнения.
+type cancelingUserService struct {
+	UserService
+	jobRepo repository.JobRepository
+	jobID   uint
+	calls   []string
+}
+
+func (s *cancelingUserService) BulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string, opts BulkDeactivateOptions) (*BulkDeactivateResult, error) {
+	s.calls = append(s.calls, userIDs...)
+	if len(s.calls) == 1 {
+		if _, err := s.jobRepo.RequestCancel(ctx, s.jobID, clock()); err != nil {
+			return nil, err
+		}
+	}
+	return s.UserService.BulkDeactivate(ctx, origin, teamName, userIDs, opts)
+}
+
+func TestJobService_BulkDeactivateRecordsItemResults(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4", "u5")
+
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	reviewer := reviewerIDs(pr)[0]
+
+	job, err := b.jobs.SubmitBulkDeactivate(ctx, Origin{Actor: "admin"}, "backend", []string{reviewer, "missing", reviewer})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	if job.Status != model.JobQueued || job.Total != 2 {
+		t.Fatalf("expected queued job with duplicate user dropped, got %+v", job)
+	}
+
+	if processed, err := b.jobs.ProcessNext(ctx); err != nil || !processed {
+		t.Fatalf("expected job processed, got %t, %v", processed, err)
+	}
+	if processed, err := b.jobs.ProcessNext(ctx); err != nil || processed {
+		t.Fatalf("expected empty queue, got %t, %v", processed, err)
+	}
+
+	job, err = b.jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	if job.Status != model.JobCompleted || job.Processed != 2 || job.FinishedAt == nil {
+		t.Fatalf("expected completed job, got %+v", job)
+	}
+	done, failed := job.Items[0], job.Items[1]
+	if done.Status != model.JobItemSucceeded || failed.Status != model.JobItemFailed || failed.Error != serviceerrs.ErrUserNotFound.Error() {
+		t.Fatalf("expected first item succeeded and unknown user failed, got %+v", job.Items)
+	}
+	var result bulkDeactivateItemResult
+	if err := json.Unmarshal([]byte(done.Result), &result); err != nil {
+		t.Fatalf("decode item result: %v", err)
+	}
+	if len(result.Plan) != 1 || result.Plan[0].PRID != "pr-1" || result.Plan[0].RemovedUserID != reviewer {
+		t.Fatalf("expected item result with removed reviewer, got %+v", result)
+	}
+
+	pr, err = b.prs.GetPR(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	for _, id := range reviewerIDs(pr) {
+		if id == reviewer {
+			t.Fatalf("expected %s removed from pr-1, got %v", reviewer, reviewerIDs(pr))
+		}
+	}
+	events, err := b.auditRepo.Find(ctx, repository.AuditFilter{UserID: reviewer})
+	if err != nil || len(events) == 0 || events[0].Actor != "admin" {
+		t.Fatalf("expected journal events by job actor, got %+v, %v", events, err)
+	}
+}
+
+func TestJobService_CancelStopsBeforeNextItem(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+
+	users := &cancelingUserService{UserService: b.users, jobRepo: b.jobRepo}
+	jobs := NewJobService(b.uow, b.jobRepo, b.teamRepo, users)
+
+	job, err := jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u1", "u2", "u3"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	users.jobID = job.ID
+	if _, err := jobs.ProcessNext(ctx); err != nil {
+		t.Fatalf("ProcessNext returned error: %v", err)
+	}
+
+	job, err = jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	if job.Status != model.JobCanceled || job.Processed != 1 || len(users.calls) != 1 {
+		t.Fatalf("expected job canceled after first item, got %+v, calls %v", job, users.calls)
+	}
+	if job.Items[0].Status != model.JobItemSucceeded || job.Items[1].Status != model.JobItemCanceled || job.Items[2].Status != model.JobItemCanceled {
+		t.Fatalf("expected first item done and the rest canceled, got %+v", job.Items)
+	}
+
+	if _, err := jobs.CancelJob(ctx, job.ID); !errors.Is(err, serviceerrs.ErrJobFinished) {
+		t.Fatalf("expected repeated cancel to fail with ErrJobFinished, got %v", err)
+	}
+}
+
+func TestJobService_DoesNotPickUsersOfTheSameJob(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	// u2 и u3 — ревьюверы pr-1, u4 свободен, но тоже в задаче: замен для pr-1 нет.
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+
+	job, err := b.jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u2", "u3", "u4"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	if _, err := b.jobs.ProcessNext(ctx); err != nil {
+		t.Fatalf("ProcessNext returned error: %v", err)
+	}
+
+	job, err = b.jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	for _, item := range job.Items[:2] {
+		var result bulkDeactivateItemResult
+		if err := json.Unmarshal([]byte(item.Result), &result); err != nil {
+			t.Fatalf("decode item result: %v", err)
+		}
+		if len(result.Plan) != 1 || result.Plan[0].ReplacementUserID != "" || result.Plan[0].SkipReason == "" {
+			t.Fatalf("expected %s to be removed without replacement, got %+v", item.Key, result)
+		}
+	}
+	pr, err := b.prs.GetPR(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	if ids := reviewerIDs(pr); len(ids) != 0 {
+		t.Fatalf("expected no reviewers left on pr-1, got %v", ids)
+	}
+}
+
+func TestJobService_ReleasesJobWhenItemIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2")
+	jobRepo := failingJobRepo{JobRepository: b.jobRepo, saveItemErr: errors.New("db down")}
+	jobs := NewJobService(b.uow, jobRepo, b.teamRepo, b.users)
+
+	job, err := jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u2"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	if _, err := jobs.ProcessNext(ctx); !errors.Is(err, jobRepo.saveItemErr) {
+		t.Fatalf("expected item write error, got %v", err)
+	}
+
+	// Задача вернулась в очередь без прогресса откатившегося элемента и выполняется заново.
+	job, err = b.jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	if job.Status != model.JobQueued || job.Processed != 0 || job.Items[0].Status != model.JobItemPending {
+		t.Fatalf("expected queued job with pending item, got %+v", job)
+	}
+	if !b.user(t, "u2").IsActive {
+		t.Fatalf("expected deactivation to be rolled back with the item")
+	}
+	if processed, err := b.jobs.ProcessNext(ctx); err != nil || !processed {
+		t.Fatalf("expected released job to be processed again, got %t, %v", processed, err)
+	}
+	if job, err = b.jobs.GetJob(ctx, job.ID); err != nil || job.Status != model.JobCompleted {
+		t.Fatalf("expected completed job, got %+v, %v", job, err)
+	}
+}
+
+func TestJobService_Errors(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1")
+
+	if _, err := b.jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", nil); !errors.Is(err, serviceerrs.ErrUserIDsRequired) {
+		t.Fatalf("expected ErrUserIDsRequired, got %v", err)
+	}
+	if _, err := b.jobs.SubmitBulkDeactivate(ctx, Origin{}, "missing", []string{"u1"}); !errors.Is(err, serviceerrs.ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+	if _, err := b.jobs.GetJob(ctx, 42); !errors.Is(err, serviceerrs.ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+
+	job, err := b.jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u1"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	if _, err := b.jobs.ProcessNext(ctx); err != nil {
+		t.Fatalf("ProcessNext returned error: %v", err)
+	}
+	if _, err := b.jobs.CancelJob(ctx, job.ID); !errors.Is(err, serviceerrs.ErrJobFinished) {
+		t.Fatalf("expected ErrJobFinished, got %v", err)
+	}
+	if canceled, err := b.jobRepo.CancelRequested(ctx, job.ID); err != nil || canceled {
+		t.Fatalf("expected no cancel flag on a finished job, got %t, %v", canceled, err)
+	}
+}
+
+// resultlessJobRepo — репозиторий задач в памяти, который не может записать результат ни одного элемента,
+// а отказ элемента записывает.
+type resultlessJobRepo struct {
+	*memory.JobRepository
+}
+
+func (r resultlessJobRepo) SaveItem(ctx context.Context, owner string, now time.Time, item *model.JobItem) error {
+	if item.Status != model.JobItemFailed {
+		return errors.New("db down")
+	}
+	return r.JobRepository.SaveItem(ctx, owner, now, item)
+}
+
+func TestJobService_FailsJobAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2")
+	jobs := NewJobService(b.uow, resultlessJobRepo{b.jobRepo}, b.teamRepo, b.users)
+
+	job, err := jobs.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u2"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	for range maxJobAttempts {
+		if _, err := jobs.ProcessNext(ctx); err == nil {
+			t.Fatalf("expected item write error")
+		}
+	}
+
+	// Постоянная ошибка не возвращает задачу в очередь бесконечно: после последней попытки она завершена.
+	job, err = b.jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	if job.Status != model.JobFailed || job.Attempts != maxJobAttempts || job.FinishedAt == nil ||
+		job.Items[0].Status != model.JobItemFailed || job.Items[0].Error != errJobAttemptsExhausted.Error() {
+		t.Fatalf("expected failed job with failed item, got %+v", job)
+	}
+	if processed, err := jobs.ProcessNext(ctx); err != nil || processed {
+		t.Fatalf("expected nothing to process, got %t, %v", processed, err)
+	}
+	if _, err := jobs.CancelJob(ctx, job.ID); !errors.Is(err, serviceerrs.ErrJobFinished) {
+		t.Fatalf("expected ErrJobFinished for failed job, got %v", err)
+	}
+}
+
+// slowUserService выполняет элемент дольше аренды задачи.
+type slowUserService struct {
+	UserService
+	after time.Time
+}
+
+func (s *slowUserService) BulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string, opts BulkDeactivateOptions) (*BulkDeactivateResult, error) {
+	clock = func() time.Time { return s.after }
+	return s.UserService.BulkDeactivate(ctx, origin, teamName, userIDs, opts)
+}
+
+func TestJobService_StopsWhenLeaseExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	withClock(t, now)
+	b := newMemoryBackend(t, "u1", "u2")
+	later := now.Add(2 * jobLease)
+	slow := NewJobService(b.uow, b.jobRepo, b.teamRepo, &slowUserService{UserService: b.users, after: later})
+
+	job, err := slow.SubmitBulkDeactivate(ctx, Origin{}, "backend", []string{"u2"})
+	if err != nil {
+		t.Fatalf("SubmitBulkDeactivate returned error: %v", err)
+	}
+	if _, err := slow.ProcessNext(ctx); !errors.Is(err, repoerrs.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+
+	// Результат элемента после истечения аренды не записан, а задачу не отпустили: её подхватывает другой обработчик.
+	if !b.user(t, "u2").IsActive {
+		t.Fatalf("expected deactivation to be rolled back with the item")
+	}
+	job, err = b.jobs.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob returned error: %v", err)
+	}
+	if job.Status != model.JobRunning || job.Processed != 0 {
+		t.Fatalf("expected job still running under the expired lease, got %+v", job)
+	}
+	if processed, err := b.jobs.ProcessNext(ctx); err != nil || !processed {
+		t.Fatalf("expected another worker to reclaim the job, got %t, %v", processed, err)
+	}
+	if job, err = b.jobs.GetJob(ctx, job.ID); err != nil || job.Status != model.JobCompleted || job.Attempts != 1 {
+		t.Fatalf("expected completed job after one interrupted attempt, got %+v, %v", job, err)
+	}
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"github.com/Leganyst/avitoTrainee/internal/repository/memory"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+// memoryBackend — сервисы поверх репозиториев в памяти: сценарии проходят через настоящие репозитории без БД.
+type memoryBackend struct {
+	uow              *memory.UnitOfWork
+	teamRepo         *memory.TeamRepository
+	userRepo         *memory.UserRepository
+	prRepo           *memory.PRRepository
+	availabilityRepo *memory.AvailabilityRepository
+	statsRepo        *memory.StatsRepository
+	auditRepo        *memory.AuditRepository
+	jobRepo          *memory.JobRepository
+
+	teams        TeamService
+	prs          PRService
+	users        UserService
+	availability AvailabilityService
+	jobs         JobService
+}
+
+func newMemoryBackend(t *testing.T, members ...string) *memoryBackend {
+	t.Helper()
+	store := memory.NewStore()
+	b := &memoryBackend{
+		uow:              memory.NewUnitOfWork(store),
+		teamRepo:         memory.NewTeamRepository(store),
+		userRepo:         memory.NewUserRepository(store),
+		prRepo:           memory.NewPRRepository(store),
+		availabilityRepo: memory.NewAvailabilityRepository(store),
+		statsRepo:        memory.NewStatsRepository(store),
+		auditRepo:        memory.NewAuditRepository(store),
+		jobRepo:          memory.NewJobRepository(store),
+	}
+	b.teams = NewTeamService(b.uow, b.teamRepo, b.userRepo)
+	b.prs = NewPrService(b.uow, b.prRepo, b.userRepo, b.availabilityRepo, b.auditRepo)
+	b.users = NewUserService(b.uow, b.userRepo, b.prRepo, b.teamRepo, b.availabilityRepo, b.auditRepo)
+	b.availability = NewAvailabilityService(b.uow, b.availabilityRepo, b.userRepo, b.teamRepo, b.prRepo, b.auditRepo, b.prs)
+	b.jobs = NewJobService(b.uow, b.jobRepo, b.teamRepo, b.users)
+
+	b.addTeam(t, model.Team{Name: "backend"}, members...)
+	return b
+}
+
+// addTeam создаёт команду с настройками team и активными участниками members.
+// Участник, уже состоящий в другой команде, переходит в эту.
+func (b *memoryBackend) addTeam(t *testing.T, team model.Team, members ...string) *model.Team {
+	t.Helper()
+	for _, id := range members {
+		team.Users = append(team.Users, model.User{UserID: id, Username: id, IsActive: true})
+	}
+	created, err := b.teams.CreateTeam(context.Background(), team)
+	if err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
+	return created
+}
+
+// join добавляет активных участников в существующую команду, например после создания PR,
+// чтобы они не попали в его исходный набор ревьюверов.
+func (b *memoryBackend) join(t *testing.T, teamName string, members ...string) {
+	t.Helper()
+	ctx := context.Background()
+	team, err := b.teamRepo.GetTeamByName(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetTeamByName returned error: %v", err)
+	}
+	for _, id := range members {
+		if err := b.userRepo.CreateOrUpdate(ctx, &model.User{UserID: id, Username: id, TeamID: team.ID, IsActive: true}); err != nil {
+			t.Fatalf("CreateOrUpdate returned error: %v", err)
+		}
+	}
+}
+
+// createPR создаёт PR и возвращает его с назначенными ревьюверами.
+func (b *memoryBackend) createPR(t *testing.T, prID, authorID string) *model.PullRequest {
+	t.Helper()
+	pr, err := b.prs.CreatePR(context.Background(), Origin{}, prID, "feature", authorID)
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	return pr
+}
+
+// user возвращает сохранённого пользователя: внутренний ID нужен для DelegatedFor и проверок по ID.
+func (b *memoryBackend) user(t *testing.T, userID string) *model.User {
+	t.Helper()
+	user, err := b.userRepo.GetByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("GetByUserID returned error: %v", err)
+	}
+	return user
+}
+
+// prEvents возвращает журнал PR, от новых событий к старым.
+func (b *memoryBackend) prEvents(t *testing.T, prID string) []model.AuditEvent {
+	t.Helper()
+	events, err := b.auditRepo.Find(context.Background(), repository.AuditFilter{PRID: prID})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	return events
+}
+
+// countingUnitOfWork считает исходы внешних единиц работы поверх настоящей единицы работы в памяти.
+type countingUnitOfWork struct {
+	*memory.UnitOfWork
+	depth      int
+	committed  int
+	rolledBack int
+}
+
+func (u *countingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.depth++
+	err := u.UnitOfWork.Do(ctx, fn)
+	u.depth--
+	if u.depth > 0 {
+		return err
+	}
+	if err != nil {
+		u.rolledBack++
+	} else {
+		u.committed++
+	}
+	return err
+}
+
+// failingPRRepo — репозиторий PR в памяти, у которого заданные методы возвращают ошибку.
+// Так проверяются пути, которые настоящий репозиторий проходит только при сбое БД или гонке.
+type failingPRRepo struct {
+	*memory.PRRepository
+	updateErr  error
+	replaceErr error
+	reviewsErr error
+}
+
+func (r failingPRRepo) UpdatePR(ctx context.Context, pr *model.PullRequest) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	return r.PRRepository.UpdatePR(ctx, pr)
+}
+
+func (r failingPRRepo) ReplaceReviewer(ctx context.Context, pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
+	if r.replaceErr != nil {
+		return r.replaceErr
+	}
+	return r.PRRepository.ReplaceReviewer(ctx, pr, oldReviewerID, newReviewer)
+}
+
+func (r failingPRRepo) GetPRsWhereReviewer(ctx context.Context, userID uint) ([]model.PullRequest, error) {
+	if r.reviewsErr != nil {
+		return nil, r.reviewsErr
+	}
+	return r.PRRepository.GetPRsWhereReviewer(ctx, userID)
+}
+
+// failingUserRepo — репозиторий пользователей в памяти, у которого падает запись участника.
+type failingUserRepo struct {
+	*memory.UserRepository
+	createErr error
+}
+
+func (r failingUserRepo) CreateOrUpdate(ctx context.Context, user *model.User) error {
+	return r.createErr
+}
+
+// failingTeamRepo — репозиторий команд в памяти, у которого падает чтение команды.
+type failingTeamRepo struct {
+	*memory.TeamRepository
+	getErr error
+}
+
+func (r failingTeamRepo) GetTeamByName(ctx context.Context, name string) (*model.Team, error) {
+	return nil, r.getErr
+}
+
+// failingJobRepo — репозиторий задач в памяти, у которого падает запись результата элемента.
+type failingJobRepo struct {
+	*memory.JobRepository
+	saveItemErr error
+}
+
+func (r failingJobRepo) SaveItem(ctx context.Context, owner string, now time.Time, item *model.JobItem) error {
+	return r.saveItemErr
+}
+
+func reviewerIDs(pr *model.PullRequest) []string {
+	ids := make([]string, 0, len(pr.AssignedReviewers))
+	for _, u := range pr.AssignedReviewers {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func TestMemoryBackend_CreateReassignMerge(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4")
+
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	reviewers := reviewerIDs(pr)
+	if len(reviewers) != 2 || slices.Contains(reviewers, "u1") {
+		t.Fatalf("expected two reviewers other than the author, got %v", reviewers)
+	}
+
+	old := reviewers[0]
+	pr, replacement, err := b.prs.Reassign(ctx, Origin{}, "pr-1", old, "")
+	if err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if replacement == old || replacement == "u1" || slices.Contains(reviewers, replacement) {
+		t.Fatalf("unexpected replacement %q for reviewers %v", replacement, reviewers)
+	}
+	if got := reviewerIDs(pr); slices.Contains(got, old) || !slices.Contains(got, replacement) {
+		t.Fatalf("expected %q to replace %q, got %v", replacement, old, got)
+	}
+
+	pr, err = b.prs.Merge(ctx, Origin{}, "pr-1")
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if pr.Status != statusMerged {
+		t.Fatalf("expected status %q, got %q", statusMerged, pr.Status)
+	}
+
+	byUser, err := b.statsRepo.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{Status: statusMerged})
+	if err != nil {
+		t.Fatalf("GetAssignmentsByUser returned error: %v", err)
+	}
+	if len(byUser) != 2 {
+		t.Fatalf("expected current assignments of two reviewers, got %+v", byUser)
+	}
+	churn, err := b.statsRepo.GetPRChurn(ctx, repository.AssignmentStatsFilter{})
+	if err != nil {
+		t.Fatalf("GetPRChurn returned error: %v", err)
+	}
+	if len(churn) != 1 || churn[0].Removals != 1 || churn[0].DistinctReviewers != 3 {
+		t.Fatalf("expected one removal among three reviewers, got %+v", churn)
+	}
+	events, err := b.auditRepo.Find(ctx, repository.AuditFilter{PRID: "pr-1"})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if len(events) != 4 || events[0].Type != model.AuditMerged {
+		t.Fatalf("expected 2 assigned, replaced and merged events newest first, got %+v", events)
+	}
+}
+
+func TestMemoryBackend_AsOfUsesMergeTimeNotLastUpdate(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+
+	if _, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1"); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	merged, err := b.prs.Merge(ctx, Origin{}, "pr-1")
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	mergedAt := *merged.MergedAt
+	// Любое сохранение после merge сдвигает UpdatedAt, но не время merge.
+	if err := b.prRepo.UpdatePR(ctx, merged); err != nil {
+		t.Fatalf("UpdatePR returned error: %v", err)
+	}
+
+	got, err := b.prs.GetPR(ctx, "pr-1", &mergedAt)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	if got.Status != statusMerged || got.MergedAt == nil || !got.MergedAt.Equal(mergedAt) {
+		t.Fatalf("expected PR merged at %v as of merge time, got %s at %v", mergedAt, got.Status, got.MergedAt)
+	}
+	before := mergedAt.Add(-time.Nanosecond)
+	if got, err = b.prs.GetPR(ctx, "pr-1", &before); err != nil || got.Status != statusOpen {
+		t.Fatalf("expected PR to be OPEN just before merge, got %+v, %v", got, err)
+	}
+}
+
+func TestMemoryBackend_BulkDeactivateReassignsReviews(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4", "u5")
+
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	deactivated := reviewerIDs(pr)
+
+	res, err := b.users.BulkDeactivate(ctx, Origin{}, "backend", deactivated, BulkDeactivateOptions{})
+	if err != nil {
+		t.Fatalf("BulkDeactivate returned error: %v", err)
+	}
+	if res.DeactivatedUsers != len(deactivated) {
+		t.Fatalf("expected %d deactivated users, got %+v", len(deactivated), res)
+	}
+
+	pr, err = b.prs.GetPR(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	for _, u := range pr.AssignedReviewers {
+		if slices.Contains(deactivated, u.UserID) || !u.IsActive {
+			t.Fatalf("expected only active reviewers after bulk deactivation, got %v", reviewerIDs(pr))
+		}
+	}
+	if len(pr.AssignedReviewers) == 0 {
+		t.Fatalf("expected deactivated reviewers to be replaced")
+	}
+}
+
+func TestMemoryBackend_BulkDeactivateDryRunPlanIsApplied(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4", "u5", "u6")
+
+	for _, id := range []string{"pr-1", "pr-2"} {
+		if _, err := b.prs.CreatePR(ctx, Origin{}, id, "feature", "u1"); err != nil {
+			t.Fatalf("CreatePR returned error: %v", err)
+		}
+	}
+	pr, err := b.prs.GetPR(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	deactivated := reviewerIDs(pr)
+
+	plan, err := b.users.BulkDeactivate(ctx, Origin{}, "backend", deactivated, BulkDeactivateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if !plan.DryRun || plan.PlanToken == "" || len(plan.Plan) < len(deactivated) {
+		t.Fatalf("expected plan with token covering pr-1 reviewers, got %+v", plan)
+	}
+	if after, _ := b.prs.GetPR(ctx, "pr-1", nil); !slices.Equal(reviewerIDs(after), deactivated) {
+		t.Fatalf("dry run must not change reviewers, got %v", reviewerIDs(after))
+	}
+
+	applied, err := b.users.BulkDeactivate(ctx, Origin{}, "backend", deactivated, BulkDeactivateOptions{PlanToken: plan.PlanToken})
+	if err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+	if applied.DryRun || !slices.Equal(applied.Plan, plan.Plan) {
+		t.Fatalf("expected applied plan %+v, got %+v", plan.Plan, applied.Plan)
+	}
+	for _, step := range plan.Plan {
+		pr, err := b.prs.GetPR(ctx, step.PRID, nil)
+		if err != nil {
+			t.Fatalf("GetPR returned error: %v", err)
+		}
+		ids := reviewerIDs(pr)
+		if slices.Contains(ids, step.RemovedUserID) || (step.ReplacementUserID != "" && !slices.Contains(ids, step.ReplacementUserID)) {
+			t.Fatalf("expected step %+v to be applied, got reviewers %v", step, ids)
+		}
+	}
+}
+
+func TestMemoryBackend_BulkDeactivateStalePlanIsRejected(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4", "u5")
+
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	target := reviewerIDs(pr)[0]
+	plan, err := b.users.BulkDeactivate(ctx, Origin{}, "backend", []string{target}, BulkDeactivateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+
+	// Любое изменение PR меняет его версию, а с ней и хэш плана.
+	if _, err := b.prs.RemoveReviewer(ctx, Origin{}, "pr-1", reviewerIDs(pr)[1]); err != nil {
+		t.Fatalf("RemoveReviewer returned error: %v", err)
+	}
+	_, err = b.users.BulkDeactivate(ctx, Origin{}, "backend", []string{target}, BulkDeactivateOptions{PlanToken: plan.PlanToken})
+	if !errors.Is(err, serviceerrs.ErrPlanStale) {
+		t.Fatalf("expected ErrPlanStale, got %v", err)
+	}
+	if after, _ := b.prs.GetPR(ctx, "pr-1", nil); !slices.Contains(reviewerIDs(after), target) {
+		t.Fatalf("stale plan must not be applied, got %v", reviewerIDs(after))
+	}
+
+	_, err = b.users.BulkDeactivate(ctx, Origin{}, "backend", []string{target}, BulkDeactivateOptions{PlanToken: "not-a-token"})
+	if !errors.Is(err, serviceerrs.ErrInvalidPlanToken) {
+		t.Fatalf("expected ErrInvalidPlanToken, got %v", err)
+	}
+}
+
+func TestMemoryBackend_UnitOfWorkRollsBack(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t)
+	errBoom := errors.New("boom")
+
+	err := b.uow.Do(ctx, func(ctx context.Context) error {
+		if err := b.teamRepo.CreateTeam(ctx, &model.Team{Name: "kept"}); err != nil {
+			return err
+		}
+		// Вложенная единица работы откатывает только свои изменения.
+		nested := b.uow.Do(ctx, func(ctx context.Context) error {
+			if err := b.teamRepo.CreateTeam(ctx, &model.Team{Name: "nested"}); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(nested, errBoom) {
+			t.Fatalf("expected nested error, got %v", nested)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	err = b.uow.Do(ctx, func(ctx context.Context) error {
+		if err := b.teamRepo.CreateTeam(ctx, &model.Team{Name: "rolled-back"}); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error from Do, got %v", err)
+	}
+
+	for name, want := range map[string]bool{"kept": true, "nested": false, "rolled-back": false} {
+		exists, err := b.teamRepo.TeamExists(ctx, name)
+		if err != nil {
+			t.Fatalf("TeamExists returned error: %v", err)
+		}
+		if exists != want {
+			t.Fatalf("team %q: expected exists=%v, got %v", name, want, exists)
+		}
+	}
+}
+
+func TestMemoryBackend_StaleVersionConflicts(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	if _, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1"); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+
+	first, err := b.prRepo.GetPRByExternalID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetPRByExternalID returned error: %v", err)
+	}
+	second, err := b.prRepo.GetPRByExternalID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetPRByExternalID returned error: %v", err)
+	}
+
+	first.Status = statusMerged
+	if err := b.prRepo.UpdatePR(ctx, first); err != nil {
+		t.Fatalf("UpdatePR returned error: %v", err)
+	}
+	second.Name = "renamed"
+	if err := b.prRepo.UpdatePR(ctx, second); !errors.Is(err, repoerrs.ErrConflict) {
+		t.Fatalf("expected ErrConflict for stale version, got %v", err)
+	}
+	if _, err := b.prRepo.GetPRByExternalID(ctx, "missing"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryBackend_ConsistencyRepairRemovesReviewerFromAnotherTeam(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	moved := reviewerIDs(pr)[0]
+	// Переход в другую команду не трогает открытые ревью: ревьювер остаётся на PR чужой команды.
+	b.addTeam(t, model.Team{Name: "frontend"}, moved)
+
+	consistency := NewConsistencyService(b.prRepo, b.prs)
+	report, err := consistency.Check(ctx, Origin{}, false)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Kind != FindingWrongTeam || report.Findings[0].UserID != moved {
+		t.Fatalf("expected %q to be reported as wrong team, got %+v", moved, report.Findings)
+	}
+
+	report, err = consistency.Check(ctx, Origin{}, true)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if report.Findings[0].Outcome != OutcomeRemoved {
+		t.Fatalf("expected the reviewer to be removed, got %+v", report.Findings)
+	}
+	if report, err = consistency.Check(ctx, Origin{}, false); err != nil || len(report.Findings) != 0 {
+		t.Fatalf("expected no findings after repair, got %+v, %v", report, err)
+	}
+	events, err := b.auditRepo.Find(ctx, repository.AuditFilter{PRID: "pr-1"})
+	if err != nil {
+		t.Fatalf("Find returned error: %v", err)
+	}
+	if len(events) == 0 || events[0].Type != model.AuditUnassigned || events[0].Reason != model.ReasonConsistency {
+		t.Fatalf("expected the repair to be journaled as consistency_repair, got %+v", events)
+	}
+}
@@ -1,15 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
 	"github.com/Leganyst/avitoTrainee/internal/model"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
 	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
 	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/Leganyst/avitoTrainee/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 /*
@@ -23,18 +27,35 @@ import (
 Итого, в рамках выполняемой работы достаточно использовать model.* модели (ORM-модели)
 */
 type (
+	// PRService управляет PR и их ревьюверами. Все изменения назначений записываются в журнал от имени origin.
 	PRService interface {
 		// CreatePR создаёт PR и автоматически назначает ревьюверов согласно ТЗ.
-		CreatePR(prID, name, authorID string) (*model.PullRequest, error)
+		CreatePR(ctx context.Context, origin Origin, prID, name, authorID string) (*model.PullRequest, error)
 		// Merge помечает PR как MERGED, операция идемпотентна.
-		Merge(prID string) (*model.PullRequest, error)
-		// Reassign заменяет одного ревьювера на другого из его команды.
-		Reassign(prID string, oldReviewerID string) (*model.PullRequest, string, error)
+		Merge(ctx context.Context, origin Origin, prID string) (*model.PullRequest, error)
+		// Reassign заменяет одного ревьювера на другого из команды автора PR.
+		// Если newReviewerID пуст, замена выбирается случайно.
+		Reassign(ctx context.Context, origin Origin, prID, oldReviewerID, newReviewerID string) (*model.PullRequest, string, error)
+		// AddReviewer вручную добавляет ревьювера в открытый PR.
+		AddReviewer(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error)
+		// RemoveReviewer снимает ревьювера с открытого PR без замены.
+		RemoveReviewer(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error)
+		// Decline снимает ревьювера по его отказу и подбирает замену; если замены нет, ревьювер просто снимается.
+		// Второй результат — user_id замены или пустая строка.
+		Decline(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, string, error)
+		// SubmitReview фиксирует ревью-действие назначенного ревьювера; учитывается только первое.
+		SubmitReview(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error)
+
+		// GetPR возвращает PR с ревьюверами; если asOf задан — в состоянии на этот момент.
+		GetPR(ctx context.Context, prID string, asOf *time.Time) (*model.PullRequest, error)
 	}
 
 	prService struct {
-		repo     repository.PRRepository
-		userRepo repository.UserRepository
+		uow              repository.UnitOfWork
+		repo             repository.PRRepository
+		userRepo         repository.UserRepository
+		availabilityRepo repository.AvailabilityRepository
+		auditRepo        repository.AuditRepository
 	}
 )
 
@@ -45,14 +66,34 @@ const (
 
 var rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
 
-func NewPrService(repo repository.PRRepository, userRepo repository.UserRepository) PRService {
-	return &prService{repo: repo, userRepo: userRepo}
+// clock — источник текущего времени для проверок отсутствия; подменяется в тестах.
+var clock = time.Now
+
+func NewPrService(
+	uow repository.UnitOfWork,
+	repo repository.PRRepository,
+	userRepo repository.UserRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	auditRepo repository.AuditRepository,
+) PRService {
+	return &prService{uow: uow, repo: repo, userRepo: userRepo, availabilityRepo: availabilityRepo, auditRepo: auditRepo}
 }
 
-// CreatePR создаёт PR и разово назначает до двух случайных активных ревьюверов из команды автора.
-func (s *prService) CreatePR(prID, name, authorID string) (*model.PullRequest, error) {
-	logger := config.Logger()
-	author, err := s.userRepo.GetByUserID(authorID)
+// CreatePR создаёт PR и разово назначает случайных активных ревьюверов из команды автора в пределах её лимита.
+func (s *prService) CreatePR(ctx context.Context, origin Origin, prID, name, authorID string) (pr *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.CreatePR", attribute.String("pr_id", prID), attribute.String("author_id", authorID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, err = s.createPR(ctx, origin, prID, name, authorID)
+		return err
+	})
+	return pr, conflictErr(err)
+}
+
+func (s *prService) createPR(ctx context.Context, origin Origin, prID, name, authorID string) (*model.PullRequest, error) {
+	logger := config.LoggerFrom(ctx)
+	author, err := s.userRepo.GetByUserID(ctx, authorID)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("author not found", "author_id", authorID, "pr_id", prID)
@@ -62,8 +103,9 @@ func (s *prService) CreatePR(prID, name, authorID string) (*model.PullRequest, e
 		return nil, err
 	}
 
+	policy := policyForTeam(author.Team)
 	excluded := map[uint]struct{}{author.ID: {}}
-	reviewers, err := s.selectReviewers(author.TeamID, excluded, 2)
+	reviewers, delegatedFor, err := s.selectReviewers(ctx, author.TeamID, policy, nil, excluded, author.Team.ReviewerLimit())
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +119,7 @@ func (s *prService) CreatePR(prID, name, authorID string) (*model.PullRequest, e
 		Author:   *author,
 	}
 
-	if err := s.repo.CreatePR(pr); err != nil {
+	if err := s.repo.CreatePR(ctx, pr); err != nil {
 		if errors.Is(err, repoerrs.ErrDuplicate) {
 			logger.Warnw("PR already exists", "pr_id", prID)
 			return nil, serviceerrs.ErrPRExists
@@ -87,20 +129,39 @@ func (s *prService) CreatePR(prID, name, authorID string) (*model.PullRequest, e
 	}
 
 	if len(reviewers) > 0 {
-		if err := s.repo.AddReviewers(pr, reviewers); err != nil {
+		if err := s.repo.AddReviewers(ctx, pr, reviewers); err != nil {
 			return nil, err
 		}
 		pr.AssignedReviewers = reviewers
+		if err := s.recordDelegations(ctx, pr, delegatedFor); err != nil {
+			return nil, err
+		}
+		if err := journal(ctx, s.auditRepo, origin, assignedEvents(pr, reviewers, origin, model.ReasonAutoAssign)...); err != nil {
+			return nil, err
+		}
 	}
+	pr.AssignmentWarnings = policy.violations(pr.AssignedReviewers)
+	observe(ctx, metrics.PRCreated)
 
 	logger.Infow("PR created", "pr_id", prID, "author", authorID, "reviewers", len(pr.AssignedReviewers))
 	return pr, nil
 }
 
 // Merge переводит PR в состояние MERGED и безопасно повторяется без побочных эффектов.
-func (s *prService) Merge(prID string) (*model.PullRequest, error) {
-	logger := config.Logger()
-	pr, err := s.repo.GetPRByExternalID(prID)
+func (s *prService) Merge(ctx context.Context, origin Origin, prID string) (pr *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.Merge", attribute.String("pr_id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, err = s.merge(ctx, origin, prID)
+		return err
+	})
+	return pr, conflictErr(err)
+}
+
+func (s *prService) merge(ctx context.Context, origin Origin, prID string) (*model.PullRequest, error) {
+	logger := config.LoggerFrom(ctx)
+	pr, err := s.repo.GetPRByExternalID(ctx, prID)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("PR not found for merge", "pr_id", prID)
@@ -109,6 +170,9 @@ func (s *prService) Merge(prID string) (*model.PullRequest, error) {
 		logger.Errorw("failed to fetch PR for merge", "pr_id", prID, "error", err)
 		return nil, err
 	}
+	if err := checkVersion(ctx, origin, pr); err != nil {
+		return nil, err
+	}
 
 	if pr.Status == statusMerged {
 		logger.Debugw("merge called on already merged PR", "pr_id", prID)
@@ -117,9 +181,9 @@ func (s *prService) Merge(prID string) (*model.PullRequest, error) {
 
 	pr.Status = statusMerged
 	now := time.Now()
-	pr.UpdatedAt = &now
+	pr.MergedAt = &now
 
-	if err := s.repo.UpdatePR(pr); err != nil {
+	if err := s.repo.UpdatePR(ctx, pr); err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("PR not found on update", "pr_id", prID)
 			return nil, serviceerrs.ErrPRNotFound
@@ -128,111 +192,653 @@ func (s *prService) Merge(prID string) (*model.PullRequest, error) {
 		return nil, err
 	}
 
+	if err := journal(ctx, s.auditRepo, origin, model.AuditEvent{
+		Type:     model.AuditMerged,
+		Reason:   origin.reasonOr(model.ReasonMerge),
+		PRID:     pr.PRID,
+		TeamName: pr.Author.Team.Name,
+	}); err != nil {
+		return nil, err
+	}
+
 	logger.Infow("PR merged", "pr_id", prID)
 	return pr, nil
 }
 
-// Reassign заменяет указанного ревьювера активным участником из его команды.
-func (s *prService) Reassign(prID string, oldReviewerID string) (*model.PullRequest, string, error) {
-	logger := config.Logger()
-	pr, err := s.repo.GetPRByExternalID(prID)
+// Reassign заменяет указанного ревьювера активным участником команды автора PR.
+// Команда снимаемого ревьювера совпадает с ней, пока он из неё не ушёл; ушедшему замена подбирается всё равно из команды автора.
+// Если передан newReviewerID, замена проверяется теми же правилами, что и ручное добавление.
+// Иначе сначала пробуется действующий делегат снимаемого ревьювера, затем случайный кандидат.
+func (s *prService) Reassign(ctx context.Context, origin Origin, prID, oldReviewerID, newReviewerID string) (pr *model.PullRequest, replacedBy string, err error) {
+	ctx, span := tracing.Start(ctx, "prService.Reassign", attribute.String("pr_id", prID), attribute.String("old_user_id", oldReviewerID), attribute.String("new_user_id", newReviewerID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, replacedBy, err = s.reassign(ctx, origin, prID, oldReviewerID, newReviewerID)
+		return err
+	})
+	// Считается только отказ самого Reassign: отказ от ревью без замены (decline) кандидатов не искал впустую.
+	if errors.Is(err, serviceerrs.ErrNoCandidates) {
+		observe(ctx, func() { metrics.NoCandidate("reassign") })
+	}
+	return pr, replacedBy, conflictErr(err)
+}
+
+func (s *prService) reassign(ctx context.Context, origin Origin, prID, oldReviewerID, newReviewerID string) (*model.PullRequest, string, error) {
+	pr, err := s.loadOpenPR(ctx, origin, prID)
+	if err != nil {
+		return nil, "", err
+	}
+	oldReviewer, err := s.assignedReviewer(ctx, pr, oldReviewerID)
+	if err != nil {
+		return nil, "", err
+	}
+	replacedBy, err := s.replaceReviewer(ctx, origin, pr, oldReviewer, newReviewerID)
 	if err != nil {
-		if errors.Is(err, repoerrs.ErrNotFound) {
-			logger.Warnw("PR not found for reassign", "pr_id", prID)
-			return nil, "", serviceerrs.ErrPRNotFound
-		}
-		logger.Errorw("failed to fetch PR for reassign", "pr_id", prID, "error", err)
 		return nil, "", err
 	}
+	return pr, replacedBy, nil
+}
 
-	if pr.Status == statusMerged {
-		logger.Warnw("reassign attempted on merged PR", "pr_id", prID)
-		return nil, "", serviceerrs.ErrPRMerged
+// replaceReviewer заменяет ревьювера уже загруженного PR и возвращает user_id замены.
+// ErrNoCandidates возвращается до любой записи, поэтому вызывающий может продолжить в той же транзакции.
+func (s *prService) replaceReviewer(ctx context.Context, origin Origin, pr *model.PullRequest, oldReviewer *model.User, newReviewerID string) (string, error) {
+	logger := config.LoggerFrom(ctx)
+	prID := pr.PRID
+
+	var (
+		newReviewer  model.User
+		delegatedFor map[uint]model.User
+		reason       = model.ReasonReassign
+	)
+	if newReviewerID != "" {
+		candidate, err := s.userRepo.GetByUserID(ctx, newReviewerID)
+		if err != nil {
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				logger.Warnw("new reviewer not found", "pr_id", prID, "user_id", newReviewerID)
+				return "", serviceerrs.ErrUserNotFound
+			}
+			logger.Errorw("failed to fetch new reviewer", "pr_id", prID, "user_id", newReviewerID, "error", err)
+			return "", err
+		}
+		if err := checkReviewerCandidate(pr, candidate, pr.Author.TeamID); err != nil {
+			logger.Warnw("new reviewer rejected", "pr_id", prID, "user_id", newReviewerID, "reason", err)
+			return "", err
+		}
+		if err := s.ensureNotAbsent(ctx, candidate); err != nil {
+			return "", err
+		}
+		newReviewer = *candidate
+		reason = model.ReasonManual
+	} else if delegate, err := s.eligibleDelegate(ctx, pr, oldReviewer); err != nil {
+		return "", err
+	} else if delegate != nil {
+		newReviewer = *delegate
+		delegatedFor = map[uint]model.User{delegate.ID: *oldReviewer}
+		reason = model.ReasonDelegation
+	} else {
+		// Создаем map во избежание назначения ревьюером того же человека
+		excluded := make(map[uint]struct{}, len(pr.AssignedReviewers)+2)
+		excluded[oldReviewer.ID] = struct{}{}
+		excluded[pr.AuthorID] = struct{}{}
+		logger.Debugw("excluded reviewers for replacement", "pr_id", prID, "excluded_ids", excluded)
+
+		kept := make([]model.User, 0, len(pr.AssignedReviewers))
+		for _, r := range pr.AssignedReviewers {
+			excluded[r.ID] = struct{}{}
+			if r.ID != oldReviewer.ID {
+				kept = append(kept, r)
+			}
+		}
+
+		candidates, selectedFor, err := s.selectReviewers(ctx, pr.Author.TeamID, policyForTeam(pr.Author.Team), kept, excluded, 1)
+		if err != nil {
+			logger.Errorw("select replacement reviewers failed", "pr_id", prID, "error", err)
+			return "", err
+		}
+		if len(candidates) == 0 {
+			logger.Warnw("no candidates for reassign", "pr_id", prID)
+			return "", serviceerrs.ErrNoCandidates
+		}
+		newReviewer = candidates[0]
+		delegatedFor = selectedFor
+		if _, delegated := selectedFor[newReviewer.ID]; delegated {
+			reason = model.ReasonDelegation
+		}
+	}
+
+	if err := s.repo.ReplaceReviewer(ctx, pr, oldReviewer.ID, newReviewer); err != nil {
+		logger.Errorw("replace reviewer failed", "pr_id", prID, "old_user", oldReviewer.UserID, "new_user", newReviewer.UserID, "error", err)
+		return "", err
+	}
+
+	for i := range pr.AssignedReviewers {
+		if pr.AssignedReviewers[i].ID == oldReviewer.ID {
+			pr.AssignedReviewers[i] = newReviewer
+			break
+		}
+	}
+	dropReviewerLink(pr, oldReviewer.ID)
+	if err := s.recordDelegations(ctx, pr, delegatedFor); err != nil {
+		return "", err
+	}
+
+	event := model.AuditEvent{
+		Type:           model.AuditReplaced,
+		Reason:         origin.reasonOr(reason),
+		PRID:           pr.PRID,
+		UserID:         newReviewer.UserID,
+		PreviousUserID: oldReviewer.UserID,
+		TeamName:       pr.Author.Team.Name,
+	}
+	if principal := pr.DelegatedFor(newReviewer.ID); principal != nil {
+		event.Details = "on behalf of " + principal.UserID
+	}
+	if err := journal(ctx, s.auditRepo, origin, event); err != nil {
+		return "", err
+	}
+	pr.AssignmentWarnings = policyForTeam(pr.Author.Team).violations(pr.AssignedReviewers)
+
+	logger.Infow("reviewer replaced", "pr_id", prID, "old_user", oldReviewer.UserID, "new_user", newReviewer.UserID)
+	return newReviewer.UserID, nil
+}
+
+// AddReviewer добавляет конкретного пользователя в ревьюверы, соблюдая лимит команды автора.
+func (s *prService) AddReviewer(ctx context.Context, origin Origin, prID, userID string) (pr *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.AddReviewer", attribute.String("pr_id", prID), attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, err = s.addReviewer(ctx, origin, prID, userID)
+		return err
+	})
+	return pr, conflictErr(err)
+}
+
+func (s *prService) addReviewer(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error) {
+	logger := config.LoggerFrom(ctx)
+	pr, err := s.loadOpenPR(ctx, origin, prID)
+	if err != nil {
+		return nil, err
 	}
 
-	oldReviewer, err := s.userRepo.GetByUserID(oldReviewerID)
+	reviewer, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
-			logger.Warnw("old reviewer not found", "pr_id", prID, "user_id", oldReviewerID)
-			return nil, "", serviceerrs.ErrReviewerMissing
+			logger.Warnw("reviewer to add not found", "pr_id", prID, "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
 		}
-		logger.Errorw("failed to fetch old reviewer", "pr_id", prID, "user_id", oldReviewerID, "error", err)
-		return nil, "", err
+		logger.Errorw("failed to fetch reviewer to add", "pr_id", prID, "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	if err := checkReviewerCandidate(pr, reviewer, pr.Author.TeamID); err != nil {
+		logger.Warnw("reviewer rejected", "pr_id", prID, "user_id", userID, "reason", err)
+		return nil, err
+	}
+	if err := s.ensureNotAbsent(ctx, reviewer); err != nil {
+		return nil, err
 	}
 
-	if !isReviewerAssigned(pr, oldReviewer.ID) {
-		logger.Warnw("reviewer not assigned to PR", "pr_id", prID, "user_id", oldReviewerID)
-		return nil, "", serviceerrs.ErrReviewerMissing
+	limit := pr.Author.Team.ReviewerLimit()
+	if len(pr.AssignedReviewers) >= limit {
+		logger.Warnw("reviewer limit reached", "pr_id", prID, "limit", limit)
+		return nil, serviceerrs.ErrReviewerLimit
 	}
 
-	// Создаем map во избежание назначения ревьюером того же человека
-	excluded := make(map[uint]struct{}, len(pr.AssignedReviewers)+2)
-	excluded[oldReviewer.ID] = struct{}{}
-	excluded[pr.AuthorID] = struct{}{}
-	logger.Debugw("excluded reviewers for replacement", "pr_id", prID, "excluded_ids", excluded)
+	if err := s.repo.AddReviewers(ctx, pr, []model.User{*reviewer}); err != nil {
+		logger.Errorw("add reviewer failed", "pr_id", prID, "user_id", userID, "error", err)
+		return nil, err
+	}
+	pr.AssignedReviewers = append(pr.AssignedReviewers, *reviewer)
+	if err := journal(ctx, s.auditRepo, origin, assignedEvents(pr, []model.User{*reviewer}, origin, model.ReasonManual)...); err != nil {
+		return nil, err
+	}
+	pr.AssignmentWarnings = policyForTeam(pr.Author.Team).violations(pr.AssignedReviewers)
+
+	logger.Infow("reviewer added", "pr_id", prID, "user_id", userID, "reviewers", len(pr.AssignedReviewers))
+	return pr, nil
+}
+
+// RemoveReviewer снимает ревьювера с PR; замена не подбирается.
+func (s *prService) RemoveReviewer(ctx context.Context, origin Origin, prID, userID string) (pr *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.RemoveReviewer", attribute.String("pr_id", prID), attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
 
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, err = s.removeReviewer(ctx, origin, prID, userID)
+		return err
+	})
+	return pr, conflictErr(err)
+}
+
+func (s *prService) removeReviewer(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error) {
+	pr, err := s.loadOpenPR(ctx, origin, prID)
+	if err != nil {
+		return nil, err
+	}
+	reviewer, err := s.assignedReviewer(ctx, pr, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.unassignReviewer(ctx, origin, pr, reviewer); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// unassignReviewer снимает ревьювера с уже загруженного PR без замены.
+func (s *prService) unassignReviewer(ctx context.Context, origin Origin, pr *model.PullRequest, reviewer *model.User) error {
+	logger := config.LoggerFrom(ctx)
+	if err := s.repo.RemoveReviewer(ctx, pr, reviewer.ID); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrReviewerMissing
+		}
+		logger.Errorw("remove reviewer failed", "pr_id", pr.PRID, "user_id", reviewer.UserID, "error", err)
+		return err
+	}
+
+	remaining := pr.AssignedReviewers[:0]
 	for _, r := range pr.AssignedReviewers {
-		excluded[r.ID] = struct{}{}
+		if r.ID != reviewer.ID {
+			remaining = append(remaining, r)
+		}
+	}
+	pr.AssignedReviewers = remaining
+	dropReviewerLink(pr, reviewer.ID)
+	if err := journal(ctx, s.auditRepo, origin, model.AuditEvent{
+		Type:     model.AuditUnassigned,
+		Reason:   origin.reasonOr(model.ReasonManual),
+		PRID:     pr.PRID,
+		UserID:   reviewer.UserID,
+		TeamName: pr.Author.Team.Name,
+	}); err != nil {
+		return err
 	}
+	pr.AssignmentWarnings = policyForTeam(pr.Author.Team).violations(pr.AssignedReviewers)
+
+	logger.Infow("reviewer removed", "pr_id", pr.PRID, "user_id", reviewer.UserID, "reviewers", len(pr.AssignedReviewers))
+	return nil
+}
+
+// Decline снимает ревьювера по его отказу: замена подбирается как при Reassign без явного кандидата,
+// а если кандидатов нет, ревьювер снимается без замены в той же транзакции.
+func (s *prService) Decline(ctx context.Context, origin Origin, prID, userID string) (pr *model.PullRequest, replacedBy string, err error) {
+	ctx, span := tracing.Start(ctx, "prService.Decline", attribute.String("pr_id", prID), attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, replacedBy, err = s.decline(ctx, origin, prID, userID)
+		return err
+	})
+	return pr, replacedBy, conflictErr(err)
+}
 
-	candidates, err := s.selectReviewers(oldReviewer.TeamID, excluded, 1)
+func (s *prService) decline(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, string, error) {
+	origin.Reason = model.ReasonDecline
+	pr, err := s.loadOpenPR(ctx, origin, prID)
 	if err != nil {
-		logger.Errorw("select replacement reviewers failed", "pr_id", prID, "error", err)
 		return nil, "", err
 	}
-	if len(candidates) == 0 {
-		logger.Warnw("no candidates for reassign", "pr_id", prID)
-		return nil, "", serviceerrs.ErrNoCandidates
+	reviewer, err := s.assignedReviewer(ctx, pr, userID)
+	if err != nil {
+		return nil, "", err
 	}
-	newReviewer := candidates[0]
 
-	if err := s.repo.ReplaceReviewer(pr, oldReviewer.ID, newReviewer); err != nil {
-		logger.Errorw("replace reviewer failed", "pr_id", prID, "old_user", oldReviewerID, "new_user", newReviewer.UserID, "error", err)
+	replacedBy, err := s.replaceReviewer(ctx, origin, pr, reviewer, "")
+	if err == nil {
+		return pr, replacedBy, nil
+	}
+	if !errors.Is(err, serviceerrs.ErrNoCandidates) {
 		return nil, "", err
 	}
 
-	for i := range pr.AssignedReviewers {
-		if pr.AssignedReviewers[i].ID == oldReviewer.ID {
-			pr.AssignedReviewers[i] = newReviewer
-			break
+	config.LoggerFrom(ctx).Warnw("no replacement for declined review, removing reviewer", "pr_id", prID, "user_id", userID)
+	if err := s.unassignReviewer(ctx, origin, pr, reviewer); err != nil {
+		return nil, "", err
+	}
+	return pr, "", nil
+}
+
+// SubmitReview фиксирует ревью-действие назначенного ревьювера. Учитывается только первое действие
+// в текущем назначении: повтор ничего не меняет и событие reviewed в журнал не пишет.
+func (s *prService) SubmitReview(ctx context.Context, origin Origin, prID, userID string) (pr *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.SubmitReview", attribute.String("pr_id", prID), attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		pr, err = s.submitReview(ctx, origin, prID, userID)
+		return err
+	})
+	return pr, conflictErr(err)
+}
+
+func (s *prService) submitReview(ctx context.Context, origin Origin, prID, userID string) (*model.PullRequest, error) {
+	logger := config.LoggerFrom(ctx)
+	pr, err := s.loadOpenPR(ctx, origin, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("review submitted by unknown user", "pr_id", prID, "user_id", userID)
+			return nil, serviceerrs.ErrReviewerMissing
+		}
+		logger.Errorw("failed to fetch reviewer for review", "pr_id", prID, "user_id", userID, "error", err)
+		return nil, err
+	}
+	if !isReviewerAssigned(pr, reviewer.ID) {
+		logger.Warnw("review submitted by unassigned user", "pr_id", prID, "user_id", userID)
+		return nil, serviceerrs.ErrReviewerMissing
+	}
+
+	first, err := s.repo.MarkReviewed(ctx, pr, reviewer.ID, clock())
+	if err != nil {
+		logger.Errorw("record review action failed", "pr_id", prID, "user_id", userID, "error", err)
+		return nil, err
+	}
+	if first {
+		if err := journal(ctx, s.auditRepo, origin, model.AuditEvent{
+			Type:     model.AuditReviewed,
+			Reason:   origin.reasonOr(model.ReasonReview),
+			PRID:     pr.PRID,
+			UserID:   reviewer.UserID,
+			TeamName: pr.Author.Team.Name,
+		}); err != nil {
+			return nil, err
 		}
 	}
 
-	logger.Infow("reviewer replaced", "pr_id", prID, "old_user", oldReviewerID, "new_user", newReviewer.UserID)
-	return pr, newReviewer.UserID, nil
+	logger.Infow("review action recorded", "pr_id", prID, "user_id", userID, "first", first)
+	return pr, nil
 }
 
-func (s *prService) selectReviewers(teamID uint, exclude map[uint]struct{}, limit int) ([]model.User, error) {
-	logger := config.Logger()
-	users, err := s.userRepo.GetActiveUsersByTeam(teamID)
+func (s *prService) GetPR(ctx context.Context, prID string, asOf *time.Time) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "prService.GetPR", attribute.String("pr_id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	pr, err := s.repo.GetPRByExternalID(ctx, prID)
 	if err != nil {
-		logger.Errorw("failed to list active users", "team_id", teamID, "error", err)
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("PR not found", "pr_id", prID)
+			return nil, serviceerrs.ErrPRNotFound
+		}
+		logger.Errorw("failed to fetch PR", "pr_id", prID, "error", err)
+		return nil, err
+	}
+	if asOf == nil {
+		pr.AssignmentWarnings = policyForTeam(pr.Author.Team).violations(pr.AssignedReviewers)
+		logger.Infow("PR fetched", "pr_id", prID, "reviewers", len(pr.AssignedReviewers))
+		return pr, nil
+	}
+
+	if pr.CreatedAt.After(*asOf) {
+		logger.Warnw("PR did not exist at requested time", "pr_id", prID, "as_of", asOf)
+		return nil, serviceerrs.ErrPRNotFound
+	}
+	periods, err := s.repo.GetReviewerPeriodsAt(ctx, pr.ID, *asOf)
+	if err != nil {
+		logger.Errorw("failed to fetch reviewer periods", "pr_id", prID, "as_of", asOf, "error", err)
+		return nil, err
+	}
+	rewindPR(pr, periods, *asOf)
+
+	logger.Infow("PR fetched as of", "pr_id", prID, "as_of", asOf, "reviewers", len(pr.AssignedReviewers))
+	return pr, nil
+}
+
+// loadOpenPR загружает PR и проверяет, что его список ревьюверов ещё можно менять.
+func (s *prService) loadOpenPR(ctx context.Context, origin Origin, prID string) (*model.PullRequest, error) {
+	logger := config.LoggerFrom(ctx)
+	pr, err := s.repo.GetPRByExternalID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("PR not found", "pr_id", prID)
+			return nil, serviceerrs.ErrPRNotFound
+		}
+		logger.Errorw("failed to fetch PR", "pr_id", prID, "error", err)
 		return nil, err
 	}
+	if err := checkVersion(ctx, origin, pr); err != nil {
+		return nil, err
+	}
+
+	if pr.Status == statusMerged {
+		logger.Warnw("reviewers change attempted on merged PR", "pr_id", prID)
+		return nil, serviceerrs.ErrPRMerged
+	}
+	return pr, nil
+}
+
+// assignedReviewer загружает пользователя и проверяет, что он назначен ревьювером PR.
+func (s *prService) assignedReviewer(ctx context.Context, pr *model.PullRequest, userID string) (*model.User, error) {
+	logger := config.LoggerFrom(ctx)
+	reviewer, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("reviewer not found", "pr_id", pr.PRID, "user_id", userID)
+			return nil, serviceerrs.ErrReviewerMissing
+		}
+		logger.Errorw("failed to fetch reviewer", "pr_id", pr.PRID, "user_id", userID, "error", err)
+		return nil, err
+	}
+	if !isReviewerAssigned(pr, reviewer.ID) {
+		logger.Warnw("reviewer not assigned to PR", "pr_id", pr.PRID, "user_id", userID)
+		return nil, serviceerrs.ErrReviewerMissing
+	}
+	return reviewer, nil
+}
+
+// selectReviewers выбирает до limit случайных активных участников команды, соблюдая правила состава policy.
+// kept — ревьюверы, которые остаются на PR и учитываются при проверке правил.
+// Пользователь с действующим делегированием занимает место в пуле своим делегатом, если тот может ревьюить;
+// второй результат — за кого назначены выбранные делегаты (ID ревьювера → пользователь).
+func (s *prService) selectReviewers(ctx context.Context, teamID uint, policy reviewerPolicy, kept []model.User, exclude map[uint]struct{}, limit int) ([]model.User, map[uint]model.User, error) {
+	logger := config.LoggerFrom(ctx)
+	users, err := s.userRepo.GetActiveUsersByTeam(ctx, teamID)
+	if err != nil {
+		logger.Errorw("failed to list active users", "team_id", teamID, "error", err)
+		return nil, nil, err
+	}
 	logger.Debugw("active team users", "team_id", teamID, "count", len(users))
 
+	absent, err := s.absentUsers(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	delegates, err := s.activeDelegates(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	available := make(map[uint]model.User, len(users))
+	for _, user := range users {
+		if _, skip := exclude[user.ID]; skip {
+			continue
+		}
+		if _, away := absent[user.ID]; away {
+			continue
+		}
+		available[user.ID] = user
+	}
+
+	// Сначала подставляем делегатов: делегат и тот, за кого он работает, дальше в пул не попадают.
 	filtered := make([]model.User, 0, len(users))
+	onBehalfOf := make(map[uint]model.User)
+	used := make(map[uint]struct{}, len(users))
 	for _, user := range users {
 		if _, skip := exclude[user.ID]; skip {
 			continue
 		}
-		filtered = append(filtered, user)
+		delegate, ok := delegates[user.ID]
+		if !ok {
+			continue
+		}
+		candidate, ok := available[delegate.ID]
+		if !ok {
+			continue
+		}
+		if _, taken := used[candidate.ID]; taken {
+			continue
+		}
+		filtered = append(filtered, candidate)
+		onBehalfOf[candidate.ID] = user
+		used[candidate.ID] = struct{}{}
+		used[user.ID] = struct{}{}
+	}
+	for _, user := range users {
+		if _, taken := used[user.ID]; taken {
+			continue
+		}
+		if candidate, ok := available[user.ID]; ok {
+			filtered = append(filtered, candidate)
+		}
 	}
-	logger.Debugw("filtered reviewer candidates", "team_id", teamID, "count", len(filtered), "limit", limit)
+	logger.Debugw("filtered reviewer candidates", "team_id", teamID, "count", len(filtered), "delegated", len(onBehalfOf), "limit", limit)
 
 	if len(filtered) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	rnd.Shuffle(len(filtered), func(i, j int) {
 		filtered[i], filtered[j] = filtered[j], filtered[i]
 	})
 
-	if limit > 0 && len(filtered) > limit {
-		filtered = filtered[:limit]
+	picked := policy.pick(kept, policy.order(filtered, clock()), limit)
+	delegatedFor := make(map[uint]model.User)
+	for _, reviewer := range picked {
+		if principal, ok := onBehalfOf[reviewer.ID]; ok {
+			delegatedFor[reviewer.ID] = principal
+		}
+	}
+	return picked, delegatedFor, nil
+}
+
+// activeDelegates возвращает действующие делегирования участников команды: ID пользователя → делегат.
+func (s *prService) activeDelegates(ctx context.Context, teamID uint) (map[uint]model.User, error) {
+	delegations, err := s.availabilityRepo.GetActiveDelegations(ctx, teamID, clock())
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("failed to list active delegations", "team_id", teamID, "error", err)
+		return nil, err
+	}
+	delegates := make(map[uint]model.User, len(delegations))
+	for _, d := range delegations {
+		delegates[d.UserID] = d.Delegate
 	}
+	return delegates, nil
+}
 
-	return filtered, nil
+// eligibleDelegate возвращает делегата ревьювера, если его можно поставить на PR вместо ревьювера, иначе nil.
+func (s *prService) eligibleDelegate(ctx context.Context, pr *model.PullRequest, reviewer *model.User) (*model.User, error) {
+	delegates, err := s.activeDelegates(ctx, reviewer.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	delegate, ok := delegates[reviewer.ID]
+	if !ok {
+		return nil, nil
+	}
+	if err := checkReviewerCandidate(pr, &delegate, pr.Author.TeamID); err != nil {
+		config.LoggerFrom(ctx).Debugw("delegate skipped", "pr_id", pr.PRID, "user_id", reviewer.UserID, "delegate_id", delegate.UserID, "reason", err)
+		return nil, nil
+	}
+	if err := s.ensureNotAbsent(ctx, &delegate); err != nil {
+		if errors.Is(err, serviceerrs.ErrReviewerAbsent) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delegate, nil
+}
+
+// recordDelegations сохраняет, за кого назначены ревьюверы-делегаты, и отражает это в pr.ReviewerLinks.
+func (s *prService) recordDelegations(ctx context.Context, pr *model.PullRequest, delegatedFor map[uint]model.User) error {
+	for reviewerID, principal := range delegatedFor {
+		if err := s.repo.SetDelegatedFor(ctx, pr, reviewerID, principal.ID); err != nil {
+			config.LoggerFrom(ctx).Errorw("record delegation failed", "pr_id", pr.PRID, "reviewer_id", reviewerID, "error", err)
+			return err
+		}
+		principal := principal
+		pr.ReviewerLinks = append(pr.ReviewerLinks, model.PRReviewer{
+			PullRequestID:  pr.ID,
+			UserID:         reviewerID,
+			DelegatedForID: &principal.ID,
+			DelegatedFor:   &principal,
+		})
+	}
+	return nil
+}
+
+// absentUsers возвращает множество участников команды, отсутствующих прямо сейчас.
+func (s *prService) absentUsers(ctx context.Context, teamID uint) (map[uint]struct{}, error) {
+	ids, err := s.availabilityRepo.GetAbsentUserIDs(ctx, teamID, clock())
+	if err != nil {
+		config.LoggerFrom(ctx).Errorw("failed to list absent users", "team_id", teamID, "error", err)
+		return nil, err
+	}
+	absent := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		absent[id] = struct{}{}
+	}
+	return absent, nil
+}
+
+// ensureNotAbsent не даёт явно назначить ревьювером пользователя, который сейчас отсутствует.
+func (s *prService) ensureNotAbsent(ctx context.Context, user *model.User) error {
+	absent, err := s.absentUsers(ctx, user.TeamID)
+	if err != nil {
+		return err
+	}
+	if _, away := absent[user.ID]; away {
+		config.LoggerFrom(ctx).Warnw("absent user proposed as reviewer", "user_id", user.UserID)
+		return serviceerrs.ErrReviewerAbsent
+	}
+	return nil
+}
+
+// checkVersion отклоняет изменение PR, если инициатор работал с другой его версией.
+func checkVersion(ctx context.Context, origin Origin, pr *model.PullRequest) error {
+	if origin.ExpectedVersion == nil || *origin.ExpectedVersion == pr.Version {
+		return nil
+	}
+	config.LoggerFrom(ctx).Warnw("PR version mismatch", "pr_id", pr.PRID, "expected", *origin.ExpectedVersion, "actual", pr.Version)
+	return serviceerrs.ErrVersionMismatch
+}
+
+// reviewerInvariantErrs — доменные ошибки для инвариантов состава ревьюверов, которые проверяет БД.
+var reviewerInvariantErrs = map[string]error{
+	repoerrs.InvariantReviewerIsAuthor: serviceerrs.ErrReviewerIsAuthor,
+	repoerrs.InvariantReviewerLimit:    serviceerrs.ErrReviewerLimit,
+	repoerrs.InvariantReviewerTeam:     serviceerrs.ErrReviewerWrongTeam,
+}
+
+// conflictErr переводит в доменные ошибки конфликты записи с состоянием БД: проигранную гонку за версию PR
+// и нарушение инварианта ревьюверов, которое сервис не заметил, потому что данные изменились после чтения.
+func conflictErr(err error) error {
+	if errors.Is(err, repoerrs.ErrConflict) {
+		return serviceerrs.ErrConcurrentUpdate
+	}
+	var invariantErr *repoerrs.ReviewerInvariantError
+	if errors.As(err, &invariantErr) {
+		if mapped, ok := reviewerInvariantErrs[invariantErr.Invariant]; ok {
+			return mapped
+		}
+	}
+	return err
+}
+
+// dropReviewerLink убирает из pr.ReviewerLinks строку снятого ревьювера.
+func dropReviewerLink(pr *model.PullRequest, reviewerID uint) {
+	links := pr.ReviewerLinks[:0]
+	for _, link := range pr.ReviewerLinks {
+		if link.UserID != reviewerID {
+			links = append(links, link)
+		}
+	}
+	pr.ReviewerLinks = links
 }
 
 func isReviewerAssigned(pr *model.PullRequest, reviewerID uint) bool {
@@ -243,3 +849,19 @@ func isReviewerAssigned(pr *model.PullRequest, reviewerID uint) bool {
 	}
 	return false
 }
+
+// checkReviewerCandidate проверяет, что пользователя можно явно назначить ревьювером PR.
+// teamID — команда, из которой допускается выбор ревьювера.
+func checkReviewerCandidate(pr *model.PullRequest, candidate *model.User, teamID uint) error {
+	switch {
+	case candidate.ID == pr.AuthorID:
+		return serviceerrs.ErrReviewerIsAuthor
+	case !candidate.IsActive:
+		return serviceerrs.ErrReviewerInactive
+	case candidate.TeamID != teamID:
+		return serviceerrs.ErrReviewerWrongTeam
+	case isReviewerAssigned(pr, candidate.ID):
+		return serviceerrs.ErrReviewerAssigned
+	}
+	return nil
+}
@@ -1,242 +1,660 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/model"
 	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
 	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestPRService_Merge_SetsStatusMergedAndTimestamp(t *testing.T) {
-	pr := &model.PullRequest{PRID: "pr-1", Status: statusOpen}
-	repo := &stubPRRepo{pr: pr}
-	svc := prService{repo: repo, userRepo: &stubUserRepo{}}
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	created := b.createPR(t, "pr-1", "u1")
 
-	got, err := svc.Merge("pr-1")
+	got, err := b.prs.Merge(context.Background(), Origin{}, "pr-1")
 	if err != nil {
 		t.Fatalf("Merge returned error: %v", err)
 	}
 	if got.Status != statusMerged {
 		t.Fatalf("expected status %q, got %q", statusMerged, got.Status)
 	}
-	if got.UpdatedAt == nil || got.UpdatedAt.After(time.Now()) {
-		t.Fatalf("expected UpdatedAt to be set, got %v", got.UpdatedAt)
+	if got.MergedAt == nil || got.MergedAt.After(time.Now()) {
+		t.Fatalf("expected MergedAt to be set, got %v", got.MergedAt)
 	}
-	if !repo.updateCalled {
-		t.Fatalf("expected UpdatePR to be called")
+	if got.Version <= created.Version {
+		t.Fatalf("expected merge to bump version %d, got %d", created.Version, got.Version)
+	}
+	if stored, _ := b.prs.GetPR(context.Background(), "pr-1", nil); stored.Status != statusMerged {
+		t.Fatalf("expected merge to be saved, got %s", stored.Status)
 	}
 }
 
 func TestPRService_Merge_Idempotent(t *testing.T) {
-	now := time.Now()
-	pr := &model.PullRequest{PRID: "pr-merged", Status: statusMerged, UpdatedAt: &now}
-	repo := &stubPRRepo{pr: pr}
-	svc := prService{repo: repo, userRepo: &stubUserRepo{}}
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	merged, err := b.prs.Merge(ctx, Origin{}, "pr-1")
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	events := b.prEvents(t, "pr-1")
 
-	got, err := svc.Merge("pr-merged")
+	got, err := b.prs.Merge(ctx, Origin{}, "pr-1")
 	if err != nil {
 		t.Fatalf("Merge returned error: %v", err)
 	}
-	if got.Status != statusMerged {
-		t.Fatalf("expected status to stay %q, got %q", statusMerged, got.Status)
+	if got.Status != statusMerged || got.Version != merged.Version || !got.MergedAt.Equal(*merged.MergedAt) {
+		t.Fatalf("expected repeated merge to change nothing, got %+v", got)
 	}
-	if repo.updateCalled {
-		t.Fatalf("expected no repository update on idempotent merge")
+	if after := b.prEvents(t, "pr-1"); len(after) != len(events) {
+		t.Fatalf("expected no audit events on idempotent merge, got %+v", after[:len(after)-len(events)])
 	}
 }
 
 func TestPRService_Merge_NotFound(t *testing.T) {
-	repo := &stubPRRepo{getErr: repoerrs.ErrNotFound}
-	svc := prService{repo: repo, userRepo: &stubUserRepo{}}
+	b := newMemoryBackend(t)
 
-	_, err := svc.Merge("missing")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.prs.Merge(context.Background(), Origin{}, "missing")
 	if !errors.Is(err, serviceerrs.ErrPRNotFound) {
 		t.Fatalf("expected ErrPRNotFound, got %v", err)
 	}
 }
 
+func TestPRService_Merge_VersionMismatch(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	pr := b.createPR(t, "pr-1", "u1")
+
+	stale := pr.Version - 1
+	_, err := b.prs.Merge(context.Background(), Origin{ExpectedVersion: &stale}, "pr-1")
+	if !errors.Is(err, serviceerrs.ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+	if got, _ := b.prs.GetPR(context.Background(), "pr-1", nil); got.Status != statusOpen {
+		t.Fatalf("expected no update for stale version, got %s", got.Status)
+	}
+}
+
+func TestPRService_Merge_ConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	pr := b.createPR(t, "pr-1", "u1")
+	// Запись проиграла гонку за версию PR уже после проверки ожидаемой версии.
+	uow := &countingUnitOfWork{UnitOfWork: b.uow}
+	svc := prService{uow: uow, repo: failingPRRepo{PRRepository: b.prRepo, updateErr: repoerrs.ErrConflict}, userRepo: b.userRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
+	events := b.prEvents(t, "pr-1")
+
+	_, err := svc.Merge(ctx, Origin{ExpectedVersion: &pr.Version}, "pr-1")
+	if !errors.Is(err, serviceerrs.ErrConcurrentUpdate) {
+		t.Fatalf("expected ErrConcurrentUpdate, got %v", err)
+	}
+	if uow.rolledBack != 1 {
+		t.Fatalf("expected the lost race to roll back, got rolledBack=%d", uow.rolledBack)
+	}
+	if got, _ := b.prs.GetPR(ctx, "pr-1", nil); got.Status != statusOpen || got.Version != pr.Version {
+		t.Fatalf("expected the lost race to roll back, got %s v%d", got.Status, got.Version)
+	}
+	if after := b.prEvents(t, "pr-1"); len(after) != len(events) {
+		t.Fatalf("expected no journal entries for the lost race, got %+v", after)
+	}
+}
+
 func TestPRService_CreatePR_Success(t *testing.T) {
-	prevRnd := rnd
-	rnd = rand.New(rand.NewSource(1))
-	defer func() { rnd = prevRnd }()
-
-	userRepo := &stubUserRepo{
-		users: map[string]*model.User{
-			"author": {ID: 1, UserID: "author", TeamID: 10},
-		},
-		activeByTeam: map[uint][]model.User{
-			10: {
-				{ID: 2, UserID: "u2", TeamID: 10},
-				{ID: 3, UserID: "u3", TeamID: 10},
-			},
-		},
-	}
-	prRepo := &stubPRRepo{}
-	svc := prService{repo: prRepo, userRepo: userRepo}
-
-	pr, err := svc.CreatePR("pr-1", "New feature", "author")
+	b := newMemoryBackend(t, "author", "u2", "u3")
+
+	pr, err := b.prs.CreatePR(context.Background(), Origin{}, "pr-1", "New feature", "author")
 	if err != nil {
 		t.Fatalf("CreatePR returned error: %v", err)
 	}
 	if pr.Status != statusOpen || pr.Author.UserID != "author" {
 		t.Fatalf("unexpected PR fields: %+v", pr)
 	}
-	if len(pr.AssignedReviewers) != 2 {
-		t.Fatalf("expected 2 reviewers, got %d", len(pr.AssignedReviewers))
+	if got := reviewerIDs(pr); len(got) != 2 || !slices.Contains(got, "u2") || !slices.Contains(got, "u3") {
+		t.Fatalf("expected u2 and u3 to be assigned, got %v", got)
 	}
-	if !prRepo.addReviewersCall {
-		t.Fatalf("expected AddReviewers call")
+	stored, err := b.prs.GetPR(context.Background(), "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	if len(stored.AssignedReviewers) != 2 {
+		t.Fatalf("expected reviewers to be stored, got %v", reviewerIDs(stored))
 	}
 }
 
 func TestPRService_CreatePR_UserNotFound(t *testing.T) {
-	userRepo := &stubUserRepo{
-		getErrFor: map[string]error{"author": repoerrs.ErrNotFound},
-	}
-	prRepo := &stubPRRepo{}
-	svc := prService{repo: prRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
 
-	_, err := svc.CreatePR("pr-1", "New feature", "author")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.prs.CreatePR(context.Background(), Origin{}, "pr-1", "New feature", "author")
 	if !errors.Is(err, serviceerrs.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
 }
 
 func TestPRService_CreatePR_Duplicate(t *testing.T) {
-	userRepo := &stubUserRepo{
-		users: map[string]*model.User{
-			"author": {ID: 1, UserID: "author", TeamID: 10},
-		},
-	}
-	prRepo := &stubPRRepo{createErr: repoerrs.ErrDuplicate}
-	svc := prService{repo: prRepo, userRepo: userRepo}
+	b := newMemoryBackend(t, "author", "u2")
+	b.createPR(t, "pr-1", "author")
 
-	_, err := svc.CreatePR("pr-1", "New feature", "author")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.prs.CreatePR(context.Background(), Origin{}, "pr-1", "New feature", "author")
 	if !errors.Is(err, serviceerrs.ErrPRExists) {
 		t.Fatalf("expected ErrPRExists, got %v", err)
 	}
 }
 
 func TestPRService_Reassign_Success(t *testing.T) {
-	pr := &model.PullRequest{
-		PRID:   "pr-1",
-		Status: statusOpen,
-		AssignedReviewers: []model.User{
-			{ID: 2, UserID: "u2", TeamID: 20},
-			{ID: 3, UserID: "u3", TeamID: 20},
-		},
-	}
-	userRepo := &stubUserRepo{
-		users: map[string]*model.User{
-			"u2": {ID: 2, UserID: "u2", TeamID: 20},
-		},
-		activeByTeam: map[uint][]model.User{
-			20: {{ID: 4, UserID: "u4", TeamID: 20}},
-		},
-	}
-	prRepo := &stubPRRepo{pr: pr}
-	svc := prService{repo: prRepo, userRepo: userRepo}
-
-	result, replacedBy, err := svc.Reassign("pr-1", "u2")
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+	before := b.prEvents(t, "pr-1")
+
+	result, replacedBy, err := b.prs.Reassign(context.Background(), Origin{Actor: "lead"}, "pr-1", "u2", "")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if replacedBy != "u4" {
 		t.Fatalf("expected replacement u4, got %s", replacedBy)
 	}
-	found := false
-	for _, reviewer := range result.AssignedReviewers {
-		if reviewer.ID == 4 {
-			found = true
-			break
-		}
+	if got := reviewerIDs(result); slices.Contains(got, "u2") || !slices.Contains(got, "u4") {
+		t.Fatalf("expected assigned reviewers to include replacement, got %v", got)
 	}
-	if !found {
-		t.Fatalf("expected assigned reviewers to include replacement")
+	if stored, _ := b.prs.GetPR(context.Background(), "pr-1", nil); slices.Contains(reviewerIDs(stored), "u2") || !slices.Contains(reviewerIDs(stored), "u4") {
+		t.Fatalf("expected the replacement of u2 by u4 to be saved, got %v", reviewerIDs(stored))
 	}
-	if !prRepo.replacedCalled || prRepo.replaceOldID != 2 || prRepo.replaceNewID != 4 {
-		t.Fatalf("expected ReplaceReviewer to be called with correct IDs")
+	events := b.prEvents(t, "pr-1")
+	if len(events) != len(before)+1 {
+		t.Fatalf("expected one audit event, got %+v", events[:len(events)-len(before)])
+	}
+	event := events[0]
+	if event.Type != model.AuditReplaced || event.Reason != model.ReasonReassign || event.Actor != "lead" ||
+		event.UserID != "u4" || event.PreviousUserID != "u2" {
+		t.Fatalf("unexpected audit event: %+v", event)
 	}
 }
 
-func TestPRService_Reassign_Merged(t *testing.T) {
-	pr := &model.PullRequest{PRID: "pr-1", Status: statusMerged}
-	prRepo := &stubPRRepo{pr: pr}
-	svc := prService{repo: prRepo, userRepo: &stubUserRepo{}}
+func TestPRService_Reassign_ReviewerInvariantRejectedByDB(t *testing.T) {
+	for invariant, want := range reviewerInvariantErrs {
+		t.Run(invariant, func(t *testing.T) {
+			b := newMemoryBackend(t, "u1", "u2", "u3")
+			b.createPR(t, "pr-1", "u1")
+			b.join(t, "backend", "u4")
+			// Состав изменился после чтения, и БД отклонила запись, которую сервис посчитал допустимой.
+			repo := failingPRRepo{PRRepository: b.prRepo, replaceErr: &repoerrs.ReviewerInvariantError{Invariant: invariant}}
+			uow := &countingUnitOfWork{UnitOfWork: b.uow}
+			svc := prService{uow: uow, repo: repo, userRepo: b.userRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
+			events := b.prEvents(t, "pr-1")
 
-	_, _, err := svc.Reassign("pr-1", "u2")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+			_, _, err := svc.Reassign(context.Background(), Origin{}, "pr-1", "u2", "")
+			if !errors.Is(err, want) {
+				t.Fatalf("expected %v, got %v", want, err)
+			}
+			if uow.rolledBack != 1 {
+				t.Fatalf("expected the rejected write to roll back, got rolledBack=%d", uow.rolledBack)
+			}
+			if after := b.prEvents(t, "pr-1"); len(after) != len(events) {
+				t.Fatalf("expected the rejected write to roll back, got %+v", after)
+			}
+		})
 	}
+}
+
+func TestPRService_Reassign_Merged(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	if _, err := b.prs.Merge(context.Background(), Origin{}, "pr-1"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+
+	_, _, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u2", "")
 	if !errors.Is(err, serviceerrs.ErrPRMerged) {
 		t.Fatalf("expected ErrPRMerged, got %v", err)
 	}
 }
 
 func TestPRService_Reassign_ReviewerMissing(t *testing.T) {
-	pr := &model.PullRequest{
-		PRID:   "pr-1",
-		Status: statusOpen,
-		AssignedReviewers: []model.User{
-			{ID: 3, UserID: "u3", TeamID: 20},
-		},
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+
+	_, _, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u4", "")
+	if !errors.Is(err, serviceerrs.ErrReviewerMissing) {
+		t.Fatalf("expected ErrReviewerMissing, got %v", err)
+	}
+}
+
+func TestPRService_Reassign_NoCandidates(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+
+	_, _, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u2", "")
+	if !errors.Is(err, serviceerrs.ErrNoCandidates) {
+		t.Fatalf("expected ErrNoCandidates, got %v", err)
+	}
+}
+
+func TestPRService_Reassign_TargetedReviewer(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4", "u5")
+
+	result, replacedBy, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u2", "u5")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
 	}
-	userRepo := &stubUserRepo{
-		users: map[string]*model.User{
-			"u2": {ID: 2, UserID: "u2", TeamID: 20},
-		},
+	if replacedBy != "u5" || !slices.Contains(reviewerIDs(result), "u5") {
+		t.Fatalf("expected targeted replacement u5, got %s", replacedBy)
 	}
-	prRepo := &stubPRRepo{pr: pr}
-	svc := prService{repo: prRepo, userRepo: userRepo}
+}
+
+func TestPRService_Reassign_TargetedReviewerRejected(t *testing.T) {
+	cases := []struct {
+		name      string
+		candidate string
+		want      error
+	}{
+		{"author", "u1", serviceerrs.ErrReviewerIsAuthor},
+		{"inactive", "u4", serviceerrs.ErrReviewerInactive},
+		{"other team", "f1", serviceerrs.ErrReviewerWrongTeam},
+		{"duplicate", "u3", serviceerrs.ErrReviewerAssigned},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			b := newMemoryBackend(t, "u1", "u2", "u3")
+			b.createPR(t, "pr-1", "u1")
+			b.join(t, "backend", "u4")
+			if _, err := b.userRepo.SetActive(ctx, "u4", false); err != nil {
+				t.Fatalf("SetActive returned error: %v", err)
+			}
+			b.addTeam(t, model.Team{Name: "frontend"}, "f1")
 
-	_, _, err := svc.Reassign("pr-1", "u2")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+			_, _, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u2", tc.candidate)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if got, _ := b.prs.GetPR(ctx, "pr-1", nil); !slices.Contains(reviewerIDs(got), "u2") {
+				t.Fatalf("expected no replacement on rejected candidate, got %v", reviewerIDs(got))
+			}
+		})
+	}
+}
+
+func TestPRService_Reassign_ReviewerMovedTeams(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+	// u2 ушёл в другую команду, не сдав ревью: замена подбирается из команды автора, а не из его новой.
+	b.addTeam(t, model.Team{Name: "frontend"}, "u2", "f1")
+
+	if _, _, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u2", "f1"); !errors.Is(err, serviceerrs.ErrReviewerWrongTeam) {
+		t.Fatalf("expected ErrReviewerWrongTeam for a candidate from the reviewer's new team, got %v", err)
+	}
+	_, replacedBy, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u2", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if replacedBy != "u4" {
+		t.Fatalf("expected u4 from the author's team to replace u2, got %s", replacedBy)
 	}
+}
+
+func TestPRService_AddReviewer_Success(t *testing.T) {
+	b := newMemoryBackend(t, "author", "u2")
+	b.createPR(t, "pr-1", "author")
+	b.join(t, "backend", "u3")
+
+	got, err := b.prs.AddReviewer(context.Background(), Origin{}, "pr-1", "u3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ids := reviewerIDs(got); len(ids) != 2 || ids[1] != "u3" {
+		t.Fatalf("expected u3 to be appended, got %v", ids)
+	}
+	if stored, _ := b.prs.GetPR(context.Background(), "pr-1", nil); !slices.Contains(reviewerIDs(stored), "u3") {
+		t.Fatalf("expected u3 to be stored, got %v", reviewerIDs(stored))
+	}
+}
+
+func TestPRService_AddReviewer_LimitReached(t *testing.T) {
+	b := newMemoryBackend(t)
+	b.addTeam(t, model.Team{Name: "solo", MaxReviewers: 1}, "author", "u2")
+	b.createPR(t, "pr-1", "author")
+	b.join(t, "solo", "u3")
+
+	_, err := b.prs.AddReviewer(context.Background(), Origin{}, "pr-1", "u3")
+	if !errors.Is(err, serviceerrs.ErrReviewerLimit) {
+		t.Fatalf("expected ErrReviewerLimit, got %v", err)
+	}
+	if got, _ := b.prs.GetPR(context.Background(), "pr-1", nil); len(got.AssignedReviewers) != 1 {
+		t.Fatalf("expected no reviewer over the limit, got %v", reviewerIDs(got))
+	}
+}
+
+func TestPRService_AddReviewer_Merged(t *testing.T) {
+	b := newMemoryBackend(t, "author", "u2")
+	b.createPR(t, "pr-1", "author")
+	b.join(t, "backend", "u3")
+	if _, err := b.prs.Merge(context.Background(), Origin{}, "pr-1"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+
+	_, err := b.prs.AddReviewer(context.Background(), Origin{}, "pr-1", "u3")
+	if !errors.Is(err, serviceerrs.ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged, got %v", err)
+	}
+}
+
+func TestPRService_RemoveReviewer_Success(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+
+	got, err := b.prs.RemoveReviewer(context.Background(), Origin{}, "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ids := reviewerIDs(got); len(ids) != 1 || ids[0] != "u3" {
+		t.Fatalf("expected only u3 to remain, got %v", ids)
+	}
+	if stored, _ := b.prs.GetPR(context.Background(), "pr-1", nil); slices.Contains(reviewerIDs(stored), "u2") {
+		t.Fatalf("expected u2 to be removed from storage, got %v", reviewerIDs(stored))
+	}
+}
+
+func TestPRService_RemoveReviewer_NotAssigned(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+
+	_, err := b.prs.RemoveReviewer(context.Background(), Origin{}, "pr-1", "u4")
 	if !errors.Is(err, serviceerrs.ErrReviewerMissing) {
 		t.Fatalf("expected ErrReviewerMissing, got %v", err)
 	}
 }
 
-func TestPRService_Reassign_NoCandidates(t *testing.T) {
-	pr := &model.PullRequest{
-		PRID:   "pr-1",
-		Status: statusOpen,
-		AssignedReviewers: []model.User{
-			{ID: 2, UserID: "u2", TeamID: 20},
-		},
-	}
-	userRepo := &stubUserRepo{
-		users: map[string]*model.User{
-			"u2": {ID: 2, UserID: "u2", TeamID: 20},
-		},
-		activeByTeam: map[uint][]model.User{
-			20: {
-				{ID: 2, UserID: "u2", TeamID: 20}, // excluded
-			},
-		},
-	}
-	prRepo := &stubPRRepo{pr: pr}
-	svc := prService{repo: prRepo, userRepo: userRepo}
-
-	_, _, err := svc.Reassign("pr-1", "u2")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+func TestPRService_CreatePR_RequireSeniorWarning(t *testing.T) {
+	b := newMemoryBackend(t)
+	b.addTeam(t, model.Team{Name: "platform", RequireSenior: true, Users: []model.User{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true, Seniority: model.SeniorityJunior},
+		{UserID: "u3", Username: "u3", IsActive: true, Seniority: model.SeniorityMiddle},
+	}})
+
+	pr, err := b.prs.CreatePR(context.Background(), Origin{}, "pr-1", "New feature", "author")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
 	}
-	if !errors.Is(err, serviceerrs.ErrNoCandidates) {
-		t.Fatalf("expected ErrNoCandidates, got %v", err)
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected reviewers to be assigned despite the rule, got %d", len(pr.AssignedReviewers))
+	}
+	if len(pr.AssignmentWarnings) != 1 || pr.AssignmentWarnings[0] != warningNoSenior {
+		t.Fatalf("expected no-senior warning, got %v", pr.AssignmentWarnings)
+	}
+}
+
+func TestPRService_CreatePR_SkipsAbsentUsers(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "author", "u2", "u3", "u4")
+	now := time.Now()
+	for _, id := range []string{"u2", "u4"} {
+		if _, err := b.availability.AddAbsence(ctx, Origin{}, id, now.Add(-time.Hour), now.Add(time.Hour), "vacation"); err != nil {
+			t.Fatalf("AddAbsence returned error: %v", err)
+		}
+	}
+
+	pr, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "New feature", "author")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if ids := reviewerIDs(pr); len(ids) != 1 || ids[0] != "u3" {
+		t.Fatalf("expected only present user u3 to be assigned, got %v", ids)
+	}
+}
+
+func TestPRService_AddReviewer_Absent(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "author", "u2")
+	b.createPR(t, "pr-1", "author")
+	b.join(t, "backend", "u3")
+	now := time.Now()
+	if _, err := b.availability.AddAbsence(ctx, Origin{}, "u3", now.Add(-time.Hour), now.Add(time.Hour), "vacation"); err != nil {
+		t.Fatalf("AddAbsence returned error: %v", err)
+	}
+
+	_, err := b.prs.AddReviewer(ctx, Origin{}, "pr-1", "u3")
+	if !errors.Is(err, serviceerrs.ErrReviewerAbsent) {
+		t.Fatalf("expected ErrReviewerAbsent, got %v", err)
+	}
+	if got, _ := b.prs.GetPR(ctx, "pr-1", nil); slices.Contains(reviewerIDs(got), "u3") {
+		t.Fatalf("expected absent user not to be added, got %v", reviewerIDs(got))
+	}
+}
+
+// delegate заводит делегирование from → to, действующее сейчас.
+func delegate(t *testing.T, b *memoryBackend, from, to string) {
+	t.Helper()
+	now := time.Now()
+	if _, err := b.availability.AddDelegation(context.Background(), Origin{}, from, to, now.Add(-time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddDelegation returned error: %v", err)
+	}
+}
+
+func TestPRService_CreatePR_PrefersDelegate(t *testing.T) {
+	b := newMemoryBackend(t, "author", "u2", "u3", "u4")
+	delegate(t, b, "u2", "u3")
+
+	pr, err := b.prs.CreatePR(context.Background(), Origin{}, "pr-1", "New feature", "author")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if ids := reviewerIDs(pr); len(ids) != 2 || slices.Contains(ids, "u2") {
+		t.Fatalf("expected u2 to be replaced by delegate, got %v", ids)
+	}
+	if principal := pr.DelegatedFor(b.user(t, "u3").ID); principal == nil || principal.UserID != "u2" {
+		t.Fatalf("expected PR to expose delegation u3 -> u2, got %+v", principal)
+	}
+	stored, err := b.prs.GetPR(context.Background(), "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	if principal := stored.DelegatedFor(b.user(t, "u3").ID); principal == nil || principal.UserID != "u2" {
+		t.Fatalf("expected u3 to be recorded as delegate of u2, got %+v", principal)
+	}
+}
+
+func TestPRService_Reassign_PrefersDelegate(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4", "u5")
+	delegate(t, b, "u2", "u5")
+
+	result, replacedBy, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u2", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if replacedBy != "u5" {
+		t.Fatalf("expected delegate u5 to replace u2, got %s", replacedBy)
+	}
+	if principal := result.DelegatedFor(b.user(t, "u5").ID); principal == nil || principal.UserID != "u2" {
+		t.Fatalf("expected PR to expose delegation u5 -> u2, got %+v", principal)
+	}
+	stored, err := b.prs.GetPR(context.Background(), "pr-1", nil)
+	if err != nil {
+		t.Fatalf("GetPR returned error: %v", err)
+	}
+	if principal := stored.DelegatedFor(b.user(t, "u5").ID); principal == nil || principal.UserID != "u2" {
+		t.Fatalf("expected delegation to be recorded, got %+v", principal)
+	}
+}
+
+func TestPRService_Reassign_DelegateIneligibleFallsBack(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4")
+	// Делегат уже стоит на PR, поэтому замена выбирается обычным образом.
+	delegate(t, b, "u2", "u3")
+
+	result, replacedBy, err := b.prs.Reassign(context.Background(), Origin{}, "pr-1", "u2", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if replacedBy != "u4" {
+		t.Fatalf("expected fallback to u4, got %s", replacedBy)
+	}
+	if principal := result.DelegatedFor(b.user(t, "u4").ID); principal != nil {
+		t.Fatalf("expected no delegation to be recorded, got %+v", principal)
+	}
+	if stored, _ := b.prs.GetPR(context.Background(), "pr-1", nil); stored.DelegatedFor(b.user(t, "u4").ID) != nil {
+		t.Fatalf("expected no delegation to be stored, got %+v", stored.DelegatedFor(b.user(t, "u4").ID))
+	}
+}
+
+func TestPRService_GetPR_AsOf(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u4", "u5")
+	delegate(t, b, "u2", "u4")
+	if _, _, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u2", ""); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	asOf := time.Now()
+	if _, _, err := b.prs.Reassign(ctx, Origin{}, "pr-1", "u3", "u5"); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if _, err := b.prs.Merge(ctx, Origin{}, "pr-1"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+
+	got, err := b.prs.GetPR(ctx, "pr-1", &asOf)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Status != statusOpen || got.MergedAt != nil {
+		t.Fatalf("expected PR to be OPEN as of %v, got %s", asOf, got.Status)
+	}
+	if ids := reviewerIDs(got); len(ids) != 2 || !slices.Contains(ids, "u3") || !slices.Contains(ids, "u4") {
+		t.Fatalf("unexpected reviewers as of: %v", ids)
+	}
+	if principal := got.DelegatedFor(b.user(t, "u4").ID); principal == nil || principal.UserID != "u2" {
+		t.Fatalf("expected u4 to review on behalf of u2, got %+v", principal)
+	}
+}
+
+func TestPRService_GetPR_AsOfBeforeCreation(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2")
+	asOf := time.Now()
+	b.createPR(t, "pr-1", "u1")
+
+	if _, err := b.prs.GetPR(context.Background(), "pr-1", &asOf); !errors.Is(err, serviceerrs.ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+}
+
+func TestPRService_SubmitReview_RecordsFirstActionOnce(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2")
+	b.createPR(t, "pr-1", "u1")
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.prs.SubmitReview(ctx, Origin{Actor: "u2"}, "pr-1", "u2"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	samples, err := b.statsRepo.GetReviewActionSamples(ctx, time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetReviewActionSamples returned error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].ReviewerID != "u2" {
+		t.Fatalf("expected review action to be recorded, got %+v", samples)
+	}
+	var reviewed int
+	for _, event := range b.prEvents(t, "pr-1") {
+		if event.Type == model.AuditReviewed {
+			reviewed++
+		}
+	}
+	if reviewed != 1 {
+		t.Fatalf("expected exactly one reviewed event, got %d", reviewed)
+	}
+}
+
+func TestPRService_SubmitReview_NotAssigned(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "backend", "u3")
+
+	if _, err := b.prs.SubmitReview(context.Background(), Origin{}, "pr-1", "u3"); !errors.Is(err, serviceerrs.ErrReviewerMissing) {
+		t.Fatalf("expected ErrReviewerMissing, got %v", err)
+	}
+}
+
+func TestPRService_Decline_NoCandidatesRemovesReviewer(t *testing.T) {
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	uow := &countingUnitOfWork{UnitOfWork: b.uow}
+	svc := prService{uow: uow, repo: b.prRepo, userRepo: b.userRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
+	before := b.prEvents(t, "pr-1")
+	noCandidateBefore := metricValue(t, "pr_reviewer_no_candidate_failures_total", "operation", "reassign")
+
+	got, replacedBy, err := svc.Decline(context.Background(), Origin{Actor: "u2"}, "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if replacedBy != "" {
+		t.Fatalf("expected no replacement, got %s", replacedBy)
+	}
+	if ids := reviewerIDs(got); len(ids) != 1 || ids[0] != "u3" {
+		t.Fatalf("expected u2 to be removed, got %v", ids)
+	}
+	// Попытка замены и снятие ревьювера идут над одним загруженным PR в одной единице работы:
+	// неудачная замена не оставляет следов в журнале.
+	events := b.prEvents(t, "pr-1")
+	if len(events) != len(before)+1 || events[0].Type != model.AuditUnassigned || events[0].Reason != model.ReasonDecline {
+		t.Fatalf("expected unassigned event with decline reason, got %+v", events)
+	}
+	if uow.committed != 1 || uow.rolledBack != 0 {
+		t.Fatalf("expected a single committed unit of work, got committed=%d rolledBack=%d", uow.committed, uow.rolledBack)
+	}
+	// Отказ без замены — не ошибка NO_CANDIDATE: счётчик не растёт.
+	if got := metricValue(t, "pr_reviewer_no_candidate_failures_total", "operation", "reassign") - noCandidateBefore; got != 0 {
+		t.Fatalf("expected decline fallback not to count no_candidate, got %v", got)
+	}
+}
+
+func TestPRService_Merge_RecordsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	b := newMemoryBackend(t)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	if _, err := b.prs.Merge(ctx, Origin{}, "missing"); !errors.Is(err, serviceerrs.ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+	parent.End()
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "prService.Merge" {
+			span = s
+		}
+	}
+	if span == nil {
+		t.Fatalf("expected prService.Merge span, got %d spans", len(recorder.Ended()))
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("expected service span to be a child of the request span")
+	}
+	if span.Status().Code != codes.Error {
+		t.Fatalf("expected error status, got %v", span.Status())
 	}
 }
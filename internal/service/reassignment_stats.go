@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+type (
+	// UserReplacements — сколько раз ревьювера снимали с PR, всего и по причинам из журнала
+	// (reassign, manual, bulk_deactivate, decline, sla_escalation, consistency_repair и т.д.).
+	UserReplacements struct {
+		UserID   string
+		Total    int64
+		ByReason map[string]int64
+	}
+
+	// PRChurn — PR, с которого снимали ревьюверов.
+	PRChurn struct {
+		PRID              string
+		Name              string
+		Status            string
+		DistinctReviewers int64
+		Removals          int64
+	}
+)
+
+func (s *statsService) ReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]UserReplacements, error) {
+	if from != nil && to != nil && !to.After(*from) {
+		config.LoggerFrom(ctx).Warnw("invalid replacements period", "from", from, "to", to)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+
+	rows, err := s.repo.GetReplacementsByUser(ctx, teamName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string]*UserReplacements)
+	for _, row := range rows {
+		item, ok := byUser[row.UserID]
+		if !ok {
+			item = &UserReplacements{UserID: row.UserID, ByReason: make(map[string]int64)}
+			byUser[row.UserID] = item
+		}
+		item.ByReason[row.Reason] += row.Count
+		item.Total += row.Count
+	}
+
+	stats := make([]UserReplacements, 0, len(byUser))
+	for _, item := range byUser {
+		stats = append(stats, *item)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats, nil
+}
+
+func (s *statsService) PRChurn(ctx context.Context, query AssignmentQuery) ([]PRChurn, string, error) {
+	if query.Sort == "" {
+		query.Sort = repository.SortRemovalsDesc
+	}
+	if !repository.IsChurnStatsSort(query.Sort) {
+		return nil, "", serviceerrs.ErrInvalidSort
+	}
+	filter, err := assignmentFilter(ctx, query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := s.repo.GetPRChurn(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(data) == filter.Limit {
+		data = data[:filter.Limit-1]
+		last := data[len(data)-1]
+		next = encodeCursor(filter.Sort, repository.StatsCursor{
+			Count: last.Removals, Count2: last.DistinctReviewers, CreatedAt: last.CreatedAt, ID: last.PRID,
+		})
+	}
+
+	stats := make([]PRChurn, 0, len(data))
+	for _, item := range data {
+		stats = append(stats, PRChurn{
+			PRID:              item.PRID,
+			Name:              item.Name,
+			Status:            item.Status,
+			DistinctReviewers: item.DistinctReviewers,
+			Removals:          item.Removals,
+		})
+	}
+	return stats, next, nil
+}
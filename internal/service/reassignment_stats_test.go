@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestStatsService_ReplacementsByUser_GroupsReasons(t *testing.T) {
+	repo := &stubStatsRepo{replacements: []repository.ReplacementStat{
+		{UserID: "u1", Reason: model.ReasonDecline, Count: 1},
+		{UserID: "u2", Reason: model.ReasonBulkDeactivate, Count: 2},
+		{UserID: "u2", Reason: model.ReasonSLAEscalation, Count: 1},
+		{UserID: "u3", Reason: model.ReasonReassign, Count: 1},
+	}}
+	svc := statsService{repo: repo}
+
+	got, err := svc.ReplacementsByUser(context.Background(), "backend", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(got) != 3 || got[0].UserID != "u2" || got[1].UserID != "u1" || got[2].UserID != "u3" {
+		t.Fatalf("expected users ordered by total then user_id, got %+v", got)
+	}
+	if got[0].Total != 3 || got[0].ByReason[model.ReasonBulkDeactivate] != 2 || got[0].ByReason[model.ReasonSLAEscalation] != 1 {
+		t.Fatalf("unexpected totals for u2: %+v", got[0])
+	}
+}
+
+func TestStatsService_ReplacementsByUser_InvalidPeriod(t *testing.T) {
+	svc := statsService{repo: &stubStatsRepo{}}
+	from := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := svc.ReplacementsByUser(context.Background(), "", &from, &from); !errors.Is(err, serviceerrs.ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
+
+func TestStatsService_PRChurn_DefaultSortAndPagination(t *testing.T) {
+	repo := &stubStatsRepo{churn: []repository.PRChurnStat{
+		{PRID: "pr-1", DistinctReviewers: 5, Removals: 3},
+		{PRID: "pr-2", DistinctReviewers: 3, Removals: 1},
+	}}
+	svc := statsService{repo: repo}
+
+	got, cursor, err := svc.PRChurn(context.Background(), AssignmentQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.filter.Sort != repository.SortRemovalsDesc {
+		t.Fatalf("expected default sort %s, got %s", repository.SortRemovalsDesc, repo.filter.Sort)
+	}
+	if len(got) != 1 || got[0].PRID != "pr-1" || got[0].Removals != 3 || cursor == "" {
+		t.Fatalf("unexpected first page: %+v, cursor %q", got, cursor)
+	}
+
+	if _, _, err := svc.PRChurn(context.Background(), AssignmentQuery{Sort: repository.SortAssignmentsDesc}); !errors.Is(err, serviceerrs.ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort, got %v", err)
+	}
+}
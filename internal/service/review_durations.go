@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+// Разрезы метрик длительности.
+const (
+	DurationGroupTeam = "team"
+	DurationGroupUser = "user"
+)
+
+const defaultDurationWindow = 12 * 7 * 24 * time.Hour
+
+type (
+	// DurationSummary — сводка по набору длительностей.
+	DurationSummary struct {
+		Count  int
+		Avg    time.Duration
+		Median time.Duration
+		Max    time.Duration
+	}
+
+	// DurationBucket — метрики одной команды или пользователя за неделю, начинающуюся с WeekStart (понедельник, UTC).
+	// Для команды: PR команды автора, первое ревью — от создания PR до первого ревью-действия любого ревьювера.
+	// Для пользователя: merge его PR, его собственный отклик от назначения до ревью и замены его как ревьювера.
+	DurationBucket struct {
+		Group               string
+		WeekStart           time.Time
+		TimeToMerge         DurationSummary
+		TimeToFirstReview   DurationSummary
+		ReassignmentLatency DurationSummary
+	}
+
+	ReviewDurationsReport struct {
+		GroupBy string
+		From    time.Time
+		To      time.Time
+		Buckets []DurationBucket
+	}
+)
+
+func (s *statsService) ReviewDurations(ctx context.Context, groupBy string, from, to *time.Time) (*ReviewDurationsReport, error) {
+	logger := config.LoggerFrom(ctx)
+	if groupBy == "" {
+		groupBy = DurationGroupTeam
+	}
+	if groupBy != DurationGroupTeam && groupBy != DurationGroupUser {
+		return nil, serviceerrs.ErrInvalidGroupBy
+	}
+
+	end := clock()
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-defaultDurationWindow)
+	if from != nil {
+		start = *from
+	}
+	if !end.After(start) {
+		logger.Warnw("invalid durations period", "from", start, "to", end)
+		return nil, serviceerrs.ErrInvalidPeriod
+	}
+
+	merges, err := s.repo.GetMergeSamples(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.repo.GetReviewActionSamples(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	reassignments, err := s.repo.GetReassignmentSamples(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := newDurationAccumulator()
+	byUser := groupBy == DurationGroupUser
+	for _, m := range merges {
+		key := m.TeamName
+		if byUser {
+			key = m.AuthorID
+		}
+		acc.add(key, m.MergedAt, func(b *durationSamples) { b.merge = append(b.merge, m.MergedAt.Sub(m.CreatedAt)) })
+	}
+	for _, r := range reviews {
+		if byUser {
+			acc.add(r.ReviewerID, r.ReviewedAt, func(b *durationSamples) { b.review = append(b.review, r.ReviewedAt.Sub(r.AssignedAt)) })
+			continue
+		}
+		// Для команды учитывается только первое ревью-действие по PR.
+		if !r.ReviewedAt.Equal(r.FirstReviewedAt) {
+			continue
+		}
+		acc.add(r.TeamName, r.ReviewedAt, func(b *durationSamples) { b.review = append(b.review, r.ReviewedAt.Sub(r.PRCreatedAt)) })
+	}
+	for _, r := range reassignments {
+		key := r.TeamName
+		if byUser {
+			key = r.ReviewerID
+		}
+		acc.add(key, r.ReplacedAt, func(b *durationSamples) { b.reassign = append(b.reassign, r.ReplacedAt.Sub(r.AssignedAt)) })
+	}
+
+	report := &ReviewDurationsReport{GroupBy: groupBy, From: start, To: end, Buckets: acc.buckets()}
+	logger.Infow("review durations computed", "group_by", groupBy, "from", start, "to", end, "buckets", len(report.Buckets))
+	return report, nil
+}
+
+type (
+	durationKey struct {
+		group string
+		week  time.Time
+	}
+
+	durationSamples struct {
+		merge, review, reassign []time.Duration
+	}
+
+	durationAccumulator struct {
+		samples map[durationKey]*durationSamples
+	}
+)
+
+func newDurationAccumulator() *durationAccumulator {
+	return &durationAccumulator{samples: make(map[durationKey]*durationSamples)}
+}
+
+// add кладёт длительность в недельный бакет группы по моменту события at.
+func (a *durationAccumulator) add(group string, at time.Time, put func(*durationSamples)) {
+	key := durationKey{group: group, week: weekStart(at)}
+	b, ok := a.samples[key]
+	if !ok {
+		b = &durationSamples{}
+		a.samples[key] = b
+	}
+	put(b)
+}
+
+// buckets возвращает сводки, отсортированные по группе и неделе.
+func (a *durationAccumulator) buckets() []DurationBucket {
+	res := make([]DurationBucket, 0, len(a.samples))
+	for key, b := range a.samples {
+		res = append(res, DurationBucket{
+			Group:               key.group,
+			WeekStart:           key.week,
+			TimeToMerge:         summarize(b.merge),
+			TimeToFirstReview:   summarize(b.review),
+			ReassignmentLatency: summarize(b.reassign),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Group != res[j].Group {
+			return res[i].Group < res[j].Group
+		}
+		return res[i].WeekStart.Before(res[j].WeekStart)
+	})
+	return res
+}
+
+func summarize(values []time.Duration) DurationSummary {
+	if len(values) == 0 {
+		return DurationSummary{}
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, v := range sorted {
+		total += v
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return DurationSummary{
+		Count:  len(sorted),
+		Avg:    total / time.Duration(len(sorted)),
+		Median: median,
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// weekStart возвращает начало недели (понедельник 00:00 UTC), в которую попадает t.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
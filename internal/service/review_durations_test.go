@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestWeekStart_Monday(t *testing.T) {
+	// Воскресенье 26.10.2025 23:30 UTC относится к неделе с понедельника 20.10.
+	got := weekStart(time.Date(2025, 10, 26, 23, 30, 0, 0, time.UTC))
+	if want := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestStatsService_ReviewDurations_ByTeam(t *testing.T) {
+	monday := time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC)
+	nextMonday := monday.AddDate(0, 0, 7)
+	repo := &stubStatsRepo{
+		merges: []repository.MergeSample{
+			{PRID: "pr-1", TeamName: "backend", CreatedAt: monday, MergedAt: monday.Add(10 * time.Hour)},
+			{PRID: "pr-2", TeamName: "backend", CreatedAt: monday, MergedAt: monday.Add(30 * time.Hour)},
+			{PRID: "pr-3", TeamName: "backend", CreatedAt: monday, MergedAt: nextMonday},
+		},
+		reviews: []repository.ReviewActionSample{
+			// Первое ревью по pr-1 через 2 часа; второе ревью в метрику команды не попадает.
+			{PRID: "pr-1", TeamName: "backend", PRCreatedAt: monday, ReviewedAt: monday.Add(2 * time.Hour), FirstReviewedAt: monday.Add(2 * time.Hour)},
+			{PRID: "pr-1", TeamName: "backend", PRCreatedAt: monday, ReviewedAt: monday.Add(5 * time.Hour), FirstReviewedAt: monday.Add(2 * time.Hour)},
+		},
+		reassignments: []repository.ReassignmentSample{
+			{PRID: "pr-2", TeamName: "backend", AssignedAt: monday, ReplacedAt: monday.Add(4 * time.Hour)},
+		},
+	}
+	svc := statsService{repo: repo}
+	from, to := monday.AddDate(0, 0, -1), nextMonday.AddDate(0, 0, 7)
+
+	report, err := svc.ReviewDurations(context.Background(), DurationGroupTeam, &from, &to)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(report.Buckets) != 2 {
+		t.Fatalf("expected two weekly buckets, got %+v", report.Buckets)
+	}
+	first := report.Buckets[0]
+	if first.Group != "backend" || !first.WeekStart.Equal(weekStart(monday)) {
+		t.Fatalf("unexpected first bucket: %+v", first)
+	}
+	if first.TimeToMerge.Count != 2 || first.TimeToMerge.Avg != 20*time.Hour || first.TimeToMerge.Max != 30*time.Hour {
+		t.Fatalf("unexpected time to merge: %+v", first.TimeToMerge)
+	}
+	if first.TimeToFirstReview.Count != 1 || first.TimeToFirstReview.Median != 2*time.Hour {
+		t.Fatalf("unexpected time to first review: %+v", first.TimeToFirstReview)
+	}
+	if first.ReassignmentLatency.Count != 1 || first.ReassignmentLatency.Avg != 4*time.Hour {
+		t.Fatalf("unexpected reassignment latency: %+v", first.ReassignmentLatency)
+	}
+	if report.Buckets[1].TimeToMerge.Count != 1 {
+		t.Fatalf("expected merge of pr-3 in the next week, got %+v", report.Buckets[1])
+	}
+}
+
+func TestStatsService_ReviewDurations_ByUser(t *testing.T) {
+	monday := time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC)
+	repo := &stubStatsRepo{
+		reviews: []repository.ReviewActionSample{
+			{PRID: "pr-1", ReviewerID: "u2", PRCreatedAt: monday, AssignedAt: monday.Add(time.Hour), ReviewedAt: monday.Add(4 * time.Hour), FirstReviewedAt: monday.Add(2 * time.Hour)},
+		},
+	}
+	svc := statsService{repo: repo}
+
+	report, err := svc.ReviewDurations(context.Background(), DurationGroupUser, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(report.Buckets) != 1 || report.Buckets[0].Group != "u2" {
+		t.Fatalf("unexpected buckets: %+v", report.Buckets)
+	}
+	// Для пользователя — отклик от его назначения, а не от создания PR.
+	if got := report.Buckets[0].TimeToFirstReview.Avg; got != 3*time.Hour {
+		t.Fatalf("expected 3h response time, got %v", got)
+	}
+	if !repo.to.After(repo.from) || repo.to.Sub(repo.from) != defaultDurationWindow {
+		t.Fatalf("expected default window, got %v..%v", repo.from, repo.to)
+	}
+}
+
+func TestStatsService_ReviewDurations_Invalid(t *testing.T) {
+	svc := statsService{repo: &stubStatsRepo{}}
+	if _, err := svc.ReviewDurations(context.Background(), "author", nil, nil); !errors.Is(err, serviceerrs.ErrInvalidGroupBy) {
+		t.Fatalf("expected ErrInvalidGroupBy, got %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.ReviewDurations(context.Background(), DurationGroupTeam, &now, &now); !errors.Is(err, serviceerrs.ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// Предупреждения, которые попадают в ответ PR, если правила состава выполнить не удалось.
+const (
+	warningNoSenior   = "no senior reviewer available: team requires at least one senior or lead"
+	warningSoleJunior = "junior is the only reviewer: no middle or senior candidate available"
+)
+
+// reviewerPolicy — правила состава и режим выбора ревьюверов, которые задаёт команда автора PR.
+type reviewerPolicy struct {
+	requireSenior      bool
+	forbidSoleJunior   bool
+	preferWorkingHours bool
+}
+
+func policyForTeam(team model.Team) reviewerPolicy {
+	return reviewerPolicy{
+		requireSenior:      team.RequireSenior,
+		forbidSoleJunior:   team.ForbidSoleJunior,
+		preferWorkingHours: team.PrefersWorkingHours(),
+	}
+}
+
+// order задаёт приоритет кандидатов перемешанного пула перед pick.
+func (p reviewerPolicy) order(pool []model.User, at time.Time) []model.User {
+	if p.preferWorkingHours {
+		return preferWorking(pool, at)
+	}
+	return pool
+}
+
+// pick выбирает до limit ревьюверов из уже перемешанного pool так, чтобы вместе с kept соблюдались правила команды.
+// Если правило выполнить нельзя, выбор всё равно делается — нарушения потом возвращает violations.
+func (p reviewerPolicy) pick(kept, pool []model.User, limit int) []model.User {
+	if limit <= 0 || len(pool) == 0 {
+		return nil
+	}
+
+	rest := append([]model.User(nil), pool...)
+	picked := make([]model.User, 0, limit)
+
+	// Сначала закрываем требование senior, остальные места добираем в порядке пула.
+	if p.requireSenior && !hasSenior(kept) {
+		for i, u := range rest {
+			if u.IsSenior() {
+				picked = append(picked, u)
+				rest = append(rest[:i], rest[i+1:]...)
+				break
+			}
+		}
+	}
+	for len(picked) < limit && len(rest) > 0 {
+		picked = append(picked, rest[0])
+		rest = rest[1:]
+	}
+
+	// Единственного junior меняем на первого не-junior из оставшихся кандидатов.
+	if p.forbidSoleJunior && len(kept) == 0 && len(picked) == 1 && picked[0].IsJunior() {
+		for _, u := range rest {
+			if !u.IsJunior() {
+				picked[0] = u
+				break
+			}
+		}
+	}
+	return picked
+}
+
+// violations перечисляет правила команды, которые итоговый набор ревьюверов не выполняет.
+func (p reviewerPolicy) violations(reviewers []model.User) []string {
+	var warnings []string
+	if p.requireSenior && !hasSenior(reviewers) {
+		warnings = append(warnings, warningNoSenior)
+	}
+	if p.forbidSoleJunior && len(reviewers) == 1 && reviewers[0].IsJunior() {
+		warnings = append(warnings, warningSoleJunior)
+	}
+	return warnings
+}
+
+func hasSenior(users []model.User) bool {
+	for _, u := range users {
+		if u.IsSenior() {
+			return true
+		}
+	}
+	return false
+}
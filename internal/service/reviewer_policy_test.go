@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+func TestReviewerPolicy_Pick_PrefersSeniorWhenRequired(t *testing.T) {
+	policy := reviewerPolicy{requireSenior: true}
+	pool := []model.User{
+		{ID: 2, Seniority: model.SeniorityJunior},
+		{ID: 3, Seniority: model.SeniorityMiddle},
+		{ID: 4, Seniority: model.SeniorityLead},
+	}
+
+	picked := policy.pick(nil, pool, 2)
+	if len(picked) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(picked))
+	}
+	if !hasSenior(picked) {
+		t.Fatalf("expected a senior or lead in %+v", picked)
+	}
+	if warnings := policy.violations(picked); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestReviewerPolicy_Pick_SeniorAlreadyKept(t *testing.T) {
+	policy := reviewerPolicy{requireSenior: true}
+	kept := []model.User{{ID: 2, Seniority: model.SenioritySenior}}
+	pool := []model.User{
+		{ID: 3, Seniority: model.SeniorityJunior},
+		{ID: 4, Seniority: model.SenioritySenior},
+	}
+
+	picked := policy.pick(kept, pool, 1)
+	if len(picked) != 1 || picked[0].ID != 3 {
+		t.Fatalf("expected pool order to be kept when senior already assigned, got %+v", picked)
+	}
+}
+
+func TestReviewerPolicy_Pick_AvoidsSoleJunior(t *testing.T) {
+	policy := reviewerPolicy{forbidSoleJunior: true}
+	pool := []model.User{
+		{ID: 2, Seniority: model.SeniorityJunior},
+		{ID: 3},
+	}
+
+	picked := policy.pick(nil, pool, 1)
+	if len(picked) != 1 || picked[0].ID != 3 {
+		t.Fatalf("expected middle reviewer instead of sole junior, got %+v", picked)
+	}
+}
+
+func TestReviewerPolicy_Violations_ReportedWhenUnsatisfiable(t *testing.T) {
+	policy := reviewerPolicy{requireSenior: true, forbidSoleJunior: true}
+	pool := []model.User{{ID: 2, Seniority: model.SeniorityJunior}}
+
+	picked := policy.pick(nil, pool, 2)
+	if len(picked) != 1 {
+		t.Fatalf("expected the only candidate to be picked anyway, got %+v", picked)
+	}
+	warnings := policy.violations(picked)
+	if len(warnings) != 2 || warnings[0] != warningNoSenior || warnings[1] != warningSoleJunior {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
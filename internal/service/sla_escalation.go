@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+type SLAEscalationResult struct {
+	Breaches             int
+	ReassignmentsDone    int
+	ReassignmentsSkipped int
+}
+
+// EscalateSLABreaches через обычный Reassign заменяет ревьюверов, у которых нарушен SLA команды автора.
+// Если замены нет, ревьювер остаётся на PR и будет рассмотрен снова при следующем запуске.
+func EscalateSLABreaches(ctx context.Context, statsSvc StatsService, prSvc PRService) (*SLAEscalationResult, error) {
+	logger := config.LoggerFrom(ctx)
+	breaches, err := statsSvc.ReviewSLA(ctx, true)
+	if err != nil {
+		logger.Errorw("list SLA breaches failed", "error", err)
+		return nil, err
+	}
+
+	result := &SLAEscalationResult{Breaches: len(breaches)}
+	for _, b := range breaches {
+		_, replacedBy, err := prSvc.Reassign(ctx, SystemOrigin(model.ReasonSLAEscalation), b.PRID, b.ReviewerID, "")
+		switch {
+		case err == nil:
+			result.ReassignmentsDone++
+			logger.Debugw("SLA breach escalated", "pr_id", b.PRID, "old_user", b.ReviewerID, "new_user", replacedBy, "working_hours", b.WorkingHours)
+		case errors.Is(err, serviceerrs.ErrNoCandidates),
+			errors.Is(err, serviceerrs.ErrPRMerged),
+			errors.Is(err, serviceerrs.ErrReviewerMissing),
+			errors.Is(err, serviceerrs.ErrConcurrentUpdate):
+			result.ReassignmentsSkipped++
+			logger.Warnw("SLA breach not escalated", "pr_id", b.PRID, "user_id", b.ReviewerID, "reason", err)
+		default:
+			logger.Errorw("SLA escalation reassign failed", "pr_id", b.PRID, "user_id", b.ReviewerID, "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Infow("SLA breaches processed", "breaches", result.Breaches, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped)
+	return result, nil
+}
+
+// RunSLAEscalationJob периодически вызывает EscalateSLABreaches, пока не закрыт stop.
+func RunSLAEscalationJob(statsSvc StatsService, prSvc PRService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			if _, err := EscalateSLABreaches(ctx, statsSvc, prSvc); err != nil {
+				config.LoggerFrom(ctx).Errorw("SLA escalation job failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+)
+
+// breachSLA создаёт команду с SLA в один рабочий час и переводит часы на неделю вперёд:
+// все назначения на PR её авторов к этому моменту нарушают SLA.
+func breachSLA(t *testing.T, b *memoryBackend, members ...string) {
+	t.Helper()
+	b.addTeam(t, model.Team{Name: "platform", ReviewSLAHours: 1}, members...)
+	withClock(t, time.Now().Add(7*24*time.Hour))
+}
+
+func TestEscalateSLABreaches_ReassignsWithSystemOrigin(t *testing.T) {
+	b := newMemoryBackend(t)
+	breachSLA(t, b, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+	b.join(t, "platform", "u4", "u5")
+
+	result, err := EscalateSLABreaches(context.Background(), NewStatsService(b.statsRepo, b.teamRepo), b.prs)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Breaches != 2 || result.ReassignmentsDone != 2 || result.ReassignmentsSkipped != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for _, event := range b.prEvents(t, "pr-1")[:2] {
+		if event.Type != model.AuditReplaced || event.Actor != model.ActorSystem || event.Reason != model.ReasonSLAEscalation {
+			t.Fatalf("expected system replacement with sla_escalation reason, got %+v", event)
+		}
+	}
+}
+
+func TestEscalateSLABreaches_SkipsWithoutCandidates(t *testing.T) {
+	b := newMemoryBackend(t)
+	breachSLA(t, b, "u1", "u2", "u3")
+	b.createPR(t, "pr-1", "u1")
+
+	result, err := EscalateSLABreaches(context.Background(), NewStatsService(b.statsRepo, b.teamRepo), b.prs)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.ReassignmentsDone != 0 || result.ReassignmentsSkipped != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
@@ -1,10 +1,38 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/model"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+const (
+	defaultStatsLimit = 50
+	maxStatsLimit     = 500
 )
 
 type (
+	// AssignmentQuery — фильтры, сортировка и страница статистики назначений.
+	// TeamName и AuthorID относятся к автору PR, From/To — к времени создания PR.
+	// Cursor — непрозрачный курсор из предыдущей страницы; пустой — первая страница.
+	AssignmentQuery struct {
+		TeamName string
+		Status   string
+		AuthorID string
+		From     *time.Time
+		To       *time.Time
+		Sort     string
+		Limit    int
+		Cursor   string
+	}
+
 	AssignmentByUser struct {
 		UserID      string
 		Username    string
@@ -17,24 +45,75 @@ type (
 		Reviewers int64
 	}
 
+	// ReviewSLAItem — сколько рабочих часов ревьювера прошло с назначения на открытый PR.
+	ReviewSLAItem struct {
+		PRID         string
+		Name         string
+		ReviewerID   string
+		AssignedAt   time.Time
+		WorkingHours float64
+		// SLA команды автора в рабочих часах; 0 — не отслеживается.
+		SLAHours int
+		Breached bool
+	}
+
 	StatsService interface {
-		AssignmentsByUser() ([]AssignmentByUser, error)
-		AssignmentsByPR() ([]AssignmentByPR, error)
+		// AssignmentsByUser и AssignmentsByPR возвращают страницу статистики и курсор следующей;
+		// пустой курсор — страниц больше нет.
+		AssignmentsByUser(ctx context.Context, query AssignmentQuery) ([]AssignmentByUser, string, error)
+		AssignmentsByPR(ctx context.Context, query AssignmentQuery) ([]AssignmentByPR, string, error)
+		// ReviewSLA считает ожидание ревью только в рабочие часы каждого ревьювера.
+		ReviewSLA(ctx context.Context, breachedOnly bool) ([]ReviewSLAItem, error)
+		// ReviewDurations считает длительности (до merge, до первого ревью, до замены ревьювера)
+		// по неделям для команд или пользователей; nil-границы — последние 12 недель.
+		ReviewDurations(ctx context.Context, groupBy string, from, to *time.Time) (*ReviewDurationsReport, error)
+		// Fairness показывает, насколько равномерно распределены ревью между участниками команды.
+		Fairness(ctx context.Context, teamName string) (*FairnessReport, error)
+		// ReplacementsByUser считает, сколько раз и по каким причинам ревьюверов снимали с PR;
+		// teamName — команда автора PR, пустая — все команды.
+		ReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]UserReplacements, error)
+		// PRChurn возвращает страницу PR, с которых снимали ревьюверов, по умолчанию от самых «текучих».
+		PRChurn(ctx context.Context, query AssignmentQuery) ([]PRChurn, string, error)
+		// RebuildAssignmentStats пересчитывает счётчики назначений с нуля, например после ручной правки данных.
+		RebuildAssignmentStats(ctx context.Context) error
 	}
 
 	statsService struct {
-		repo repository.StatsRepository
+		repo     repository.StatsRepository
+		teamRepo repository.TeamRepository
 	}
 )
 
-func NewStatsService(repo repository.StatsRepository) StatsService {
-	return &statsService{repo: repo}
+func NewStatsService(repo repository.StatsRepository, teamRepo repository.TeamRepository) StatsService {
+	return &statsService{repo: repo, teamRepo: teamRepo}
 }
 
-func (s *statsService) AssignmentsByUser() ([]AssignmentByUser, error) {
-	data, err := s.repo.GetAssignmentsByUser()
+func (s *statsService) AssignmentsByUser(ctx context.Context, query AssignmentQuery) ([]AssignmentByUser, string, error) {
+	if query.Sort == "" {
+		query.Sort = repository.SortAssignmentsDesc
+	}
+	if !repository.IsUserStatsSort(query.Sort) {
+		return nil, "", serviceerrs.ErrInvalidSort
+	}
+	// Счётчики по пользователям знают только день создания PR.
+	if !startOfDay(query.From) || !startOfDay(query.To) {
+		config.LoggerFrom(ctx).Warnw("stats period is not whole days", "from", query.From, "to", query.To)
+		return nil, "", serviceerrs.ErrPeriodNotDays
+	}
+	filter, err := assignmentFilter(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	data, err := s.repo.GetAssignmentsByUser(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(data) == filter.Limit {
+		data = data[:filter.Limit-1]
+		last := data[len(data)-1]
+		next = encodeCursor(filter.Sort, repository.StatsCursor{Count: last.Assignments, ID: last.UserID})
 	}
 
 	stats := make([]AssignmentByUser, 0, len(data))
@@ -45,13 +124,30 @@ func (s *statsService) AssignmentsByUser() ([]AssignmentByUser, error) {
 			Assignments: item.Assignments,
 		})
 	}
-	return stats, nil
+	return stats, next, nil
 }
 
-func (s *statsService) AssignmentsByPR() ([]AssignmentByPR, error) {
-	data, err := s.repo.GetAssignmentsByPR()
+func (s *statsService) AssignmentsByPR(ctx context.Context, query AssignmentQuery) ([]AssignmentByPR, string, error) {
+	if query.Sort == "" {
+		query.Sort = repository.SortReviewersDesc
+	}
+	if !repository.IsPRStatsSort(query.Sort) {
+		return nil, "", serviceerrs.ErrInvalidSort
+	}
+	filter, err := assignmentFilter(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	data, err := s.repo.GetAssignmentsByPR(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(data) == filter.Limit {
+		data = data[:filter.Limit-1]
+		last := data[len(data)-1]
+		next = encodeCursor(filter.Sort, repository.StatsCursor{Count: last.Reviewers, CreatedAt: last.CreatedAt, ID: last.PRID})
 	}
 
 	stats := make([]AssignmentByPR, 0, len(data))
@@ -62,5 +158,118 @@ func (s *statsService) AssignmentsByPR() ([]AssignmentByPR, error) {
 			Reviewers: item.Reviewers,
 		})
 	}
-	return stats, nil
+	return stats, next, nil
+}
+
+func (s *statsService) RebuildAssignmentStats(ctx context.Context) error {
+	started := time.Now()
+	if err := s.repo.RebuildAssignmentStats(ctx); err != nil {
+		return err
+	}
+	config.LoggerFrom(ctx).Infow("assignment stats rebuilt", "took", time.Since(started))
+	return nil
+}
+
+// assignmentFilter проверяет запрос и переводит его в фильтр репозитория.
+// Limit фильтра на единицу больше страницы: лишняя строка означает, что есть следующая страница.
+func assignmentFilter(ctx context.Context, query AssignmentQuery) (repository.AssignmentStatsFilter, error) {
+	logger := config.LoggerFrom(ctx)
+	if query.Status != "" && query.Status != statusOpen && query.Status != statusMerged {
+		return repository.AssignmentStatsFilter{}, serviceerrs.ErrInvalidStatus
+	}
+	if query.From != nil && query.To != nil && !query.To.After(*query.From) {
+		logger.Warnw("invalid stats period", "from", query.From, "to", query.To)
+		return repository.AssignmentStatsFilter{}, serviceerrs.ErrInvalidPeriod
+	}
+	after, err := decodeCursor(query.Cursor, query.Sort)
+	if err != nil {
+		logger.Warnw("invalid stats cursor", "cursor", query.Cursor)
+		return repository.AssignmentStatsFilter{}, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultStatsLimit
+	}
+	if limit > maxStatsLimit {
+		limit = maxStatsLimit
+	}
+
+	return repository.AssignmentStatsFilter{
+		TeamName: query.TeamName,
+		Status:   query.Status,
+		AuthorID: query.AuthorID,
+		From:     query.From,
+		To:       query.To,
+		Sort:     query.Sort,
+		Limit:    limit + 1,
+		After:    after,
+	}, nil
+}
+
+// startOfDay сообщает, что t не задано или приходится на начало суток UTC.
+func startOfDay(t *time.Time) bool {
+	return t == nil || t.Equal(t.UTC().Truncate(24*time.Hour))
+}
+
+// pageCursor — содержимое курсора: сортировка, для которой он выдан, и ключ последней строки страницы.
+// Следующая страница начинается строго после этого ключа, поэтому вставки и удаления между запросами
+// не сдвигают её, как сдвигали бы смещение.
+type pageCursor struct {
+	Sort string `json:"sort"`
+	repository.StatsCursor
+}
+
+// encodeCursor и decodeCursor скрывают ключ страницы за непрозрачной строкой.
+func encodeCursor(sort string, last repository.StatsCursor) string {
+	raw, _ := json.Marshal(pageCursor{Sort: sort, StatsCursor: last})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor отклоняет курсор, выданный для другой сортировки: его ключ не задаёт позицию в этом порядке.
+func decodeCursor(cursor, sort string) (*repository.StatsCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, serviceerrs.ErrInvalidCursor
+	}
+	var decoded pageCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Sort != sort {
+		return nil, serviceerrs.ErrInvalidCursor
+	}
+	return &decoded.StatsCursor, nil
+}
+
+func (s *statsService) ReviewSLA(ctx context.Context, breachedOnly bool) ([]ReviewSLAItem, error) {
+	data, err := s.repo.GetOpenReviewAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock()
+	items := make([]ReviewSLAItem, 0, len(data))
+	for _, row := range data {
+		reviewer := model.User{TimeZone: row.TimeZone, WorkStart: row.WorkStart, WorkEnd: row.WorkEnd}
+		hours := reviewer.WorkingDuration(row.AssignedAt, now).Hours()
+		item := ReviewSLAItem{
+			PRID:         row.PRID,
+			Name:         row.Name,
+			ReviewerID:   row.ReviewerID,
+			AssignedAt:   row.AssignedAt,
+			WorkingHours: hours,
+			SLAHours:     row.SLAHours,
+			Breached:     row.SLAHours > 0 && hours > float64(row.SLAHours),
+		}
+		if breachedOnly && !item.Breached {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].WorkingHours > items[j].WorkingHours
+	})
+	return items, nil
 }
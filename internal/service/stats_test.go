@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestStatsService_AssignmentsByUser_Pagination(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4", "u5")
+	for _, author := range []string{"u1", "u2", "u3", "u4"} {
+		if _, err := b.prs.CreatePR(ctx, Origin{}, "pr-"+author, "feature", author); err != nil {
+			t.Fatalf("CreatePR returned error: %v", err)
+		}
+	}
+	svc := statsService{repo: b.statsRepo}
+
+	all, _, err := svc.AssignmentsByUser(ctx, AssignmentQuery{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var paged []AssignmentByUser
+	cursor := ""
+	for {
+		page, next, err := svc.AssignmentsByUser(ctx, AssignmentQuery{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page) > 2 {
+			t.Fatalf("page exceeds limit: %+v", page)
+		}
+		paged = append(paged, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !slices.Equal(paged, all) {
+		t.Fatalf("pages %+v differ from full listing %+v", paged, all)
+	}
+
+	if _, _, err := svc.AssignmentsByUser(ctx, AssignmentQuery{Sort: repository.SortUserIDAsc, Cursor: cursor}); !errors.Is(err, serviceerrs.ErrInvalidCursor) {
+		t.Fatalf("expected cursor of another sort to be rejected, got %v", err)
+	}
+}
+
+// Следующая страница начинается после ключа последней строки: новая строка перед курсором её не сдвигает.
+func TestStatsService_AssignmentsByPR_CursorSurvivesInserts(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3")
+	for _, id := range []string{"pr-2", "pr-3", "pr-4", "pr-5"} {
+		if _, err := b.prs.CreatePR(ctx, Origin{}, id, "feature", "u1"); err != nil {
+			t.Fatalf("CreatePR returned error: %v", err)
+		}
+	}
+	svc := statsService{repo: b.statsRepo}
+
+	first, cursor, err := svc.AssignmentsByPR(ctx, AssignmentQuery{Sort: repository.SortPRIDAsc, Limit: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(first) != 2 || first[1].PRID != "pr-3" || cursor == "" {
+		t.Fatalf("unexpected first page: %+v, cursor %q", first, cursor)
+	}
+
+	if _, err := b.prs.CreatePR(ctx, Origin{}, "pr-1", "feature", "u1"); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	second, cursor, err := svc.AssignmentsByPR(ctx, AssignmentQuery{Sort: repository.SortPRIDAsc, Limit: 2, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(second) != 2 || second[0].PRID != "pr-4" || second[1].PRID != "pr-5" || cursor != "" {
+		t.Fatalf("unexpected last page: %+v, cursor %q", second, cursor)
+	}
+}
+
+func TestStatsService_AssignmentsByPR_DefaultsAndLimitCap(t *testing.T) {
+	repo := &stubStatsRepo{}
+	svc := statsService{repo: repo}
+
+	if _, _, err := svc.AssignmentsByPR(context.Background(), AssignmentQuery{Limit: 10000}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.filter.Sort != repository.SortReviewersDesc || repo.filter.Limit != maxStatsLimit+1 {
+		t.Fatalf("unexpected repository filter: %+v", repo.filter)
+	}
+}
+
+func TestStatsService_Assignments_InvalidQuery(t *testing.T) {
+	svc := statsService{repo: &stubStatsRepo{}}
+	now := time.Now()
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	cases := map[string]struct {
+		query AssignmentQuery
+		want  error
+	}{
+		"status": {AssignmentQuery{Status: "CLOSED"}, serviceerrs.ErrInvalidStatus},
+		"sort":   {AssignmentQuery{Sort: repository.SortReviewersDesc}, serviceerrs.ErrInvalidSort},
+		"cursor": {AssignmentQuery{Cursor: "not a cursor"}, serviceerrs.ErrInvalidCursor},
+		"period": {AssignmentQuery{From: &today, To: &today}, serviceerrs.ErrInvalidPeriod},
+		"days":   {AssignmentQuery{From: &now}, serviceerrs.ErrPeriodNotDays},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := svc.AssignmentsByUser(context.Background(), tc.query); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
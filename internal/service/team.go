@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
@@ -24,29 +25,57 @@ import (
 
 type (
 	TeamService interface {
-		CreateTeam(teamName string, members []model.User) (*model.Team, error)
-		GetTeam(name string) (*model.Team, error)
+		// CreateTeam создаёт команду с настройками из team и добавляет team.Users как участников.
+		CreateTeam(ctx context.Context, team model.Team) (*model.Team, error)
+		GetTeam(ctx context.Context, name string) (*model.Team, error)
+		// SetMaxReviewers меняет лимит ревьюверов команды. Уже назначенные ревьюверы не снимаются,
+		// новый лимит действует для следующих назначений.
+		SetMaxReviewers(ctx context.Context, teamName string, maxReviewers int) (*model.Team, error)
 	}
 
 	teamService struct {
+		uow      repository.UnitOfWork
 		teamRepo repository.TeamRepository
 		userRepo repository.UserRepository
 	}
 )
 
 func NewTeamService(
+	uow repository.UnitOfWork,
 	teamRepo repository.TeamRepository,
 	userRepo repository.UserRepository,
 ) TeamService {
 	return &teamService{
+		uow:      uow,
 		teamRepo: teamRepo,
 		userRepo: userRepo,
 	}
 }
 
-func (s *teamService) CreateTeam(teamName string, members []model.User) (*model.Team, error) {
-	logger := config.Logger()
-	exists, err := s.teamRepo.TeamExists(teamName)
+func (s *teamService) CreateTeam(ctx context.Context, input model.Team) (team *model.Team, err error) {
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		team, err = s.createTeam(ctx, input)
+		return err
+	})
+	return team, err
+}
+
+func (s *teamService) createTeam(ctx context.Context, input model.Team) (*model.Team, error) {
+	logger := config.LoggerFrom(ctx)
+	teamName := input.Name
+	members := input.Users
+	if !validAssignmentMode(input.AssignmentMode) {
+		logger.Warnw("unknown assignment mode", "team_name", teamName, "mode", input.AssignmentMode)
+		return nil, errs.ErrInvalidAssignmentMode
+	}
+	for _, m := range members {
+		if err := validateWorkingHours(m.TimeZone, m.WorkStart, m.WorkEnd); err != nil {
+			logger.Warnw("invalid member working hours", "team_name", teamName, "user_id", m.UserID, "error", err)
+			return nil, err
+		}
+	}
+
+	exists, err := s.teamRepo.TeamExists(ctx, teamName)
 	if err != nil {
 		logger.Errorw("team exists check failed", "team_name", teamName, "error", err)
 		return nil, err
@@ -59,9 +88,14 @@ func (s *teamService) CreateTeam(teamName string, members []model.User) (*model.
 		return nil, errs.ErrTeamExists
 	} else {
 		team = &model.Team{
-			Name: teamName,
+			Name:             teamName,
+			MaxReviewers:     input.MaxReviewers,
+			RequireSenior:    input.RequireSenior,
+			ForbidSoleJunior: input.ForbidSoleJunior,
+			AssignmentMode:   input.AssignmentMode,
+			ReviewSLAHours:   input.ReviewSLAHours,
 		}
-		if err := s.teamRepo.CreateTeam(team); err != nil {
+		if err := s.teamRepo.CreateTeam(ctx, team); err != nil {
 			if errors.Is(err, repoerrs.ErrDuplicate) {
 				logger.Warnw("team create duplicate", "team_name", teamName)
 				return nil, errs.ErrTeamExists
@@ -76,7 +110,7 @@ func (s *teamService) CreateTeam(teamName string, members []model.User) (*model.
 		user := m
 		user.TeamID = team.ID
 
-		if err := s.userRepo.CreateOrUpdate(&user); err != nil {
+		if err := s.userRepo.CreateOrUpdate(ctx, &user); err != nil {
 			logger.Errorw("create or update member failed", "team_name", teamName, "user_id", user.UserID, "error", err)
 			return nil, err
 		}
@@ -90,9 +124,9 @@ func (s *teamService) CreateTeam(teamName string, members []model.User) (*model.
 	return team, nil
 }
 
-func (s *teamService) GetTeam(name string) (*model.Team, error) {
-	logger := config.Logger()
-	team, err := s.teamRepo.GetTeamByName(name)
+func (s *teamService) GetTeam(ctx context.Context, name string) (*model.Team, error) {
+	logger := config.LoggerFrom(ctx)
+	team, err := s.teamRepo.GetTeamByName(ctx, name)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("team not found", "team_name", name)
@@ -104,3 +138,35 @@ func (s *teamService) GetTeam(name string) (*model.Team, error) {
 	logger.Infow("team fetched", "team_name", name, "members", len(team.Users))
 	return team, nil
 }
+
+func (s *teamService) SetMaxReviewers(ctx context.Context, teamName string, maxReviewers int) (team *model.Team, err error) {
+	logger := config.LoggerFrom(ctx)
+	if maxReviewers < 1 {
+		logger.Warnw("invalid max reviewers", "team_name", teamName, "max_reviewers", maxReviewers)
+		return nil, errs.ErrInvalidMaxReviewers
+	}
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		found, err := s.teamRepo.GetTeamByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := s.teamRepo.SetMaxReviewers(ctx, found.ID, maxReviewers); err != nil {
+			return err
+		}
+		found.MaxReviewers = maxReviewers
+		team = found
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("team not found", "team_name", teamName)
+			return nil, errs.ErrTeamNotFound
+		}
+		logger.Errorw("set max reviewers failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+
+	logger.Infow("team max reviewers updated", "team_name", teamName, "max_reviewers", maxReviewers)
+	return team, nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -10,109 +11,127 @@ import (
 )
 
 func TestTeamService_CreateTeam_Success(t *testing.T) {
-	teamRepo := &stubTeamRepo{}
-	userRepo := &stubUserRepo{}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
+	uow := &countingUnitOfWork{UnitOfWork: b.uow}
+	svc := teamService{uow: uow, teamRepo: b.teamRepo, userRepo: b.userRepo}
 
 	members := []model.User{
 		{UserID: "u1", Username: "Alice"},
 		{UserID: "u2", Username: "Bob"},
 	}
 
-	team, err := svc.CreateTeam("backend", members)
+	team, err := svc.CreateTeam(context.Background(), model.Team{Name: "platform", Users: members})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if team.ID != 42 {
-		t.Fatalf("expected team ID assigned to 42, got %d", team.ID)
+	if team.ID == 0 {
+		t.Fatalf("expected team ID to be assigned")
 	}
 	if len(team.Users) != len(members) {
 		t.Fatalf("expected %d users in team, got %d", len(members), len(team.Users))
 	}
-	for _, user := range userRepo.created {
-		if user.TeamID != 42 {
-			t.Fatalf("expected user TeamID to be 42, got %d", user.TeamID)
+	for _, m := range members {
+		if user := b.user(t, m.UserID); user.TeamID != team.ID {
+			t.Fatalf("expected user %s in team %d, got %d", m.UserID, team.ID, user.TeamID)
 		}
 	}
+	if uow.committed != 1 {
+		t.Fatalf("expected team and members to be committed in one unit of work, got %d", uow.committed)
+	}
 }
 
 func TestTeamService_CreateTeam_Duplicate(t *testing.T) {
-	teamRepo := &stubTeamRepo{teamExists: true}
-	userRepo := &stubUserRepo{}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
 
-	_, err := svc.CreateTeam("backend", nil)
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.teams.CreateTeam(context.Background(), model.Team{Name: "backend", Users: []model.User{{UserID: "u1"}}})
 	if !errors.Is(err, serviceerrs.ErrTeamExists) {
 		t.Fatalf("expected ErrTeamExists, got %v", err)
 	}
-	if len(userRepo.created) != 0 {
-		t.Fatalf("expected no users to be created when team exists")
+	if _, err := b.userRepo.GetByUserID(context.Background(), "u1"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected no users to be created when team exists, got %v", err)
 	}
 }
 
 func TestTeamService_CreateTeam_UserRepoError(t *testing.T) {
-	teamRepo := &stubTeamRepo{}
-	userRepo := &stubUserRepo{createErr: errors.New("db error")}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
+	userRepo := failingUserRepo{UserRepository: b.userRepo, createErr: errors.New("db error")}
+	uow := &countingUnitOfWork{UnitOfWork: b.uow}
+	svc := teamService{uow: uow, teamRepo: b.teamRepo, userRepo: userRepo}
 
-	_, err := svc.CreateTeam("backend", []model.User{{UserID: "u1"}})
-	if err == nil {
-		t.Fatalf("expected error from user repo, got nil")
-	}
+	_, err := svc.CreateTeam(context.Background(), model.Team{Name: "platform", Users: []model.User{{UserID: "u1"}}})
 	if !errors.Is(err, userRepo.createErr) {
 		t.Fatalf("expected user repo error, got %v", err)
 	}
+	if exists, err := b.teamRepo.TeamExists(context.Background(), "platform"); err != nil || exists {
+		t.Fatalf("expected team creation to be rolled back, got exists=%v err=%v", exists, err)
+	}
+	if uow.rolledBack != 1 || uow.committed != 0 {
+		t.Fatalf("expected team creation to be rolled back, got committed=%d rolledBack=%d", uow.committed, uow.rolledBack)
+	}
 }
 
 func TestTeamService_GetTeam_Success(t *testing.T) {
-	expected := &model.Team{
-		ID:   10,
-		Name: "backend",
-		Users: []model.User{
-			{UserID: "u1"},
-		},
-	}
-	teamRepo := &stubTeamRepo{getTeam: expected}
-	userRepo := &stubUserRepo{}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
-
-	team, err := svc.GetTeam("backend")
+	b := newMemoryBackend(t, "u1")
+
+	team, err := b.teams.GetTeam(context.Background(), "backend")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if team != expected {
-		t.Fatalf("expected pointer to team %p, got %p", expected, team)
+	if team.Name != "backend" || len(team.Users) != 1 || team.Users[0].UserID != "u1" {
+		t.Fatalf("unexpected team: %+v", team)
 	}
 }
 
 func TestTeamService_GetTeam_NotFound(t *testing.T) {
-	teamRepo := &stubTeamRepo{getErr: repoerrs.ErrNotFound}
-	userRepo := &stubUserRepo{}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
 
-	_, err := svc.GetTeam("unknown")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.teams.GetTeam(context.Background(), "unknown")
 	if !errors.Is(err, serviceerrs.ErrTeamNotFound) {
 		t.Fatalf("expected ErrTeamNotFound, got %v", err)
 	}
 }
 
 func TestTeamService_GetTeam_RepoError(t *testing.T) {
-	teamRepo := &stubTeamRepo{getErr: errors.New("db down")}
-	userRepo := &stubUserRepo{}
-	svc := teamService{teamRepo: teamRepo, userRepo: userRepo}
+	b := newMemoryBackend(t)
+	teamRepo := failingTeamRepo{TeamRepository: b.teamRepo, getErr: errors.New("db down")}
+	svc := teamService{uow: b.uow, teamRepo: teamRepo, userRepo: b.userRepo}
 
-	_, err := svc.GetTeam("backend")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := svc.GetTeam(context.Background(), "backend")
 	if !errors.Is(err, teamRepo.getErr) {
 		t.Fatalf("expected repo error, got %v", err)
 	}
 }
+
+func TestTeamService_SetMaxReviewers(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2", "u3", "u4")
+
+	team, err := b.teams.SetMaxReviewers(ctx, "backend", 3)
+	if err != nil {
+		t.Fatalf("SetMaxReviewers returned error: %v", err)
+	}
+	if team.MaxReviewers != 3 || team.Name != "backend" {
+		t.Fatalf("expected backend with limit 3, got %+v", team)
+	}
+	if stored, _ := b.teams.GetTeam(ctx, "backend"); stored.ReviewerLimit() != 3 {
+		t.Fatalf("expected stored limit 3, got %d", stored.ReviewerLimit())
+	}
+
+	// Следующие назначения уже учитывают новый лимит.
+	if pr := b.createPR(t, "pr-1", "u1"); len(pr.AssignedReviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %d", len(pr.AssignedReviewers))
+	}
+}
+
+func TestTeamService_SetMaxReviewers_Errors(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1")
+
+	if _, err := b.teams.SetMaxReviewers(ctx, "backend", 0); !errors.Is(err, serviceerrs.ErrInvalidMaxReviewers) {
+		t.Fatalf("expected ErrInvalidMaxReviewers, got %v", err)
+	}
+	if _, err := b.teams.SetMaxReviewers(ctx, "unknown", 2); !errors.Is(err, serviceerrs.ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
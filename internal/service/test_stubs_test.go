@@ -1,218 +1,80 @@
 package service
 
 import (
-	"github.com/Leganyst/avitoTrainee/internal/model"
-	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
-)
+	"context"
+	"testing"
+	"time"
 
-// ----- User repository stub -----
-type stubUserRepo struct {
-	users        map[string]*model.User
-	getErrFor    map[string]error
-	createErr    error
-	setActiveErr error
-	activeByTeam map[uint][]model.User
-	usersByTeam  map[uint][]model.User
-	activeErr    error
-	created      []model.User
-	bulkErr      error
-}
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+)
 
-func (s *stubUserRepo) CreateOrUpdate(user *model.User) error {
-	if s.createErr != nil {
-		return s.createErr
-	}
-	cpy := *user
-	s.created = append(s.created, cpy)
-	return nil
+// withClock фиксирует clock на время теста.
+func withClock(t *testing.T, now time.Time) {
+	t.Helper()
+	prev := clock
+	clock = func() time.Time { return now }
+	t.Cleanup(func() { clock = prev })
 }
-func (s *stubUserRepo) GetByUserID(userID string) (*model.User, error) {
-	if s.getErrFor != nil {
-		if err, ok := s.getErrFor[userID]; ok {
-			return nil, err
-		}
-	}
-	if s.users != nil {
-		if u, ok := s.users[userID]; ok {
-			return u, nil
-		}
-	}
-	return nil, repoerrs.ErrNotFound
-}
-func (s *stubUserRepo) GetUsersByTeam(teamID uint) ([]model.User, error) {
-	if s.usersByTeam == nil {
-		return nil, nil
-	}
-	users := s.usersByTeam[teamID]
-	cpy := make([]model.User, len(users))
-	copy(cpy, users)
-	return cpy, nil
-}
-func (s *stubUserRepo) SetActive(userID string, active bool) (*model.User, error) {
-	if s.setActiveErr != nil {
-		return nil, s.setActiveErr
-	}
-	u, ok := s.users[userID]
-	if !ok {
-		return nil, repoerrs.ErrNotFound
-	}
-	cpy := *u
-	cpy.IsActive = active
-	return &cpy, nil
-}
-func (s *stubUserRepo) GetActiveUsersByTeam(teamID uint) ([]model.User, error) {
-	if s.activeErr != nil {
-		return nil, s.activeErr
-	}
-	users := s.activeByTeam[teamID]
-	cpy := make([]model.User, len(users))
-	copy(cpy, users)
-	return cpy, nil
-}
-func (s *stubUserRepo) BulkDeactivate(teamID uint, userIDs []string) ([]model.User, error) {
-	if s.bulkErr != nil {
-		return nil, s.bulkErr
-	}
-	if len(userIDs) == 0 {
-		return nil, nil
-	}
-
-	idSet := make(map[string]struct{}, len(userIDs))
-	for _, id := range userIDs {
-		idSet[id] = struct{}{}
-	}
-
-	var res []model.User
-	for _, u := range s.users {
-		if u.TeamID != teamID {
-			continue
-		}
-		if _, ok := idSet[u.UserID]; !ok {
-			continue
-		}
-		if !u.IsActive {
-			continue
-		}
-		cpy := *u
-		cpy.IsActive = false
-		res = append(res, cpy)
-	}
 
-	if len(res) == 0 {
-		return nil, repoerrs.ErrNotFound
-	}
-	return res, nil
+// stubStatsRepo отдаёт заранее заданные выборки: отчёты по длительностям, справедливости и SLA
+// считаются в сервисе, а строки с произвольным временем назначения и merge через операции с PR не получить.
+type stubStatsRepo struct {
+	openAssignments []repository.OpenReviewAssignment
+	merges          []repository.MergeSample
+	reviews         []repository.ReviewActionSample
+	reassignments   []repository.ReassignmentSample
+	from, to        time.Time
+	filter          repository.AssignmentStatsFilter
+	teamLoad        []repository.MemberLoad
+	replacements    []repository.ReplacementStat
+	churn           []repository.PRChurnStat
 }
 
-// ----- PR repository stub -----
-type stubPRRepo struct {
-	pr               *model.PullRequest
-	getErr           error
-	updateErr        error
-	updateCalled     bool
-	createErr        error
-	createdPR        *model.PullRequest
-	addReviewersErr  error
-	addedReviewers   []model.User
-	replaceErr       error
-	replaceOldID     uint
-	replaceNewID     uint
-	replacedCalled   bool
-	addReviewersCall bool
-	prsByReviewer    []model.PullRequest
-	prsErr           error
-	openPRs          []model.PullRequest
-	openPRsErr       error
-	replaceBulkErr   error
+func (s *stubStatsRepo) GetTeamLoad(ctx context.Context, teamID uint) ([]repository.MemberLoad, error) {
+	return s.teamLoad, nil
 }
 
-func (s *stubPRRepo) CreatePR(pr *model.PullRequest) error {
-	if s.createErr != nil {
-		return s.createErr
-	}
-	s.createdPR = pr
-	if s.pr == nil {
-		s.pr = pr
-	}
-	return nil
+func (s *stubStatsRepo) GetOpenPRsByTeam(ctx context.Context) ([]repository.TeamOpenPRs, error) {
+	return nil, nil
 }
-func (s *stubPRRepo) AddReviewers(pr *model.PullRequest, reviewers []model.User) error {
-	if s.addReviewersErr != nil {
-		return s.addReviewersErr
-	}
-	s.addReviewersCall = true
-	s.addedReviewers = append([]model.User(nil), reviewers...)
+func (s *stubStatsRepo) RebuildAssignmentStats(ctx context.Context) error {
 	return nil
 }
-func (s *stubPRRepo) ReplaceReviewer(pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
-	if s.replaceErr != nil {
-		return s.replaceErr
-	}
-	s.replacedCalled = true
-	s.replaceOldID = oldReviewerID
-	s.replaceNewID = newReviewer.ID
-	return nil
+func (s *stubStatsRepo) GetReplacementsByUser(ctx context.Context, teamName string, from, to *time.Time) ([]repository.ReplacementStat, error) {
+	return s.replacements, nil
 }
-func (s *stubPRRepo) GetPRByExternalID(prID string) (*model.PullRequest, error) {
-	if s.getErr != nil {
-		return nil, s.getErr
-	}
-	return s.pr, nil
+func (s *stubStatsRepo) GetPRChurn(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.PRChurnStat, error) {
+	s.filter = filter
+	return pageOf(s.churn, filter), nil
 }
-func (s *stubPRRepo) UpdatePR(pr *model.PullRequest) error {
-	s.updateCalled = true
-	if s.updateErr != nil {
-		return s.updateErr
-	}
-	s.pr.Status = pr.Status
-	s.pr.UpdatedAt = pr.UpdatedAt
-	return nil
+
+func (s *stubStatsRepo) GetAssignmentsByUser(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.AssignmentStatByUser, error) {
+	s.filter = filter
+	return nil, nil
 }
-func (s *stubPRRepo) GetPRsWhereReviewer(userID uint) ([]model.PullRequest, error) {
-	if s.prsErr != nil {
-		return nil, s.prsErr
-	}
-	cpy := make([]model.PullRequest, len(s.prsByReviewer))
-	copy(cpy, s.prsByReviewer)
-	return cpy, nil
+func (s *stubStatsRepo) GetAssignmentsByPR(ctx context.Context, filter repository.AssignmentStatsFilter) ([]repository.AssignmentStatByPR, error) {
+	s.filter = filter
+	return nil, nil
 }
-func (s *stubPRRepo) GetOpenPRsByReviewerIDs(reviewerIDs []uint) ([]model.PullRequest, error) {
-	if s.openPRsErr != nil {
-		return nil, s.openPRsErr
+
+// pageOf повторяет LIMIT репозитория над уже отсортированными строками; курсор заглушка не учитывает,
+// постраничный обход проверяется на репозитории в памяти.
+func pageOf[T any](rows []T, filter repository.AssignmentStatsFilter) []T {
+	if filter.Limit > 0 && len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
 	}
-	cpy := make([]model.PullRequest, len(s.openPRs))
-	copy(cpy, s.openPRs)
-	return cpy, nil
+	return append([]T(nil), rows...)
 }
-func (s *stubPRRepo) ReplaceReviewers(prID uint, reviewerIDs []uint) error {
-	if s.replaceBulkErr != nil {
-		return s.replaceBulkErr
-	}
-	s.replacedCalled = true
-	return nil
+func (s *stubStatsRepo) GetOpenReviewAssignments(ctx context.Context) ([]repository.OpenReviewAssignment, error) {
+	return s.openAssignments, nil
 }
-
-// ----- Team repository stub -----
-type stubTeamRepo struct {
-	teamExists bool
-	createErr  error
-	getTeam    *model.Team
-	getErr     error
+func (s *stubStatsRepo) GetMergeSamples(ctx context.Context, from, to time.Time) ([]repository.MergeSample, error) {
+	s.from, s.to = from, to
+	return s.merges, nil
 }
-
-func (s *stubTeamRepo) CreateTeam(team *model.Team) error {
-	if s.createErr != nil {
-		return s.createErr
-	}
-	if team.ID == 0 {
-		team.ID = 42
-	}
-	return nil
+func (s *stubStatsRepo) GetReviewActionSamples(ctx context.Context, from, to time.Time) ([]repository.ReviewActionSample, error) {
+	return s.reviews, nil
 }
-func (s *stubTeamRepo) GetTeamByName(name string) (*model.Team, error) {
-	if s.getErr != nil {
-		return nil, s.getErr
-	}
-	return s.getTeam, nil
+func (s *stubStatsRepo) GetReassignmentSamples(ctx context.Context, from, to time.Time) ([]repository.ReassignmentSample, error) {
+	return s.reassignments, nil
 }
-func (s *stubTeamRepo) TeamExists(name string) (bool, error) { return s.teamExists, nil }
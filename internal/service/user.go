@@ -1,14 +1,27 @@
 package service
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/config"
+	"github.com/Leganyst/avitoTrainee/internal/metrics"
 	"github.com/Leganyst/avitoTrainee/internal/model"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
 	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
 	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+	"github.com/Leganyst/avitoTrainee/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 /*
@@ -23,17 +36,26 @@ import (
 */
 type (
 	UserService interface {
-		SetActive(userID string, active bool) (*model.User, error)
-		GetUserByID(userID string) (*model.User, error)
-		GetUserReviews(userID string) ([]model.PullRequest, error)
-		BulkDeactivate(teamName string, userIDs []string) (*BulkDeactivateResult, error)
+		// SetActive и BulkDeactivate записывают изменения активности и назначений в журнал от имени origin.
+		SetActive(ctx context.Context, origin Origin, userID string, active bool) (*model.User, error)
+		// SetWorkingHours задаёт часовой пояс и рабочее окно пользователя; пустые значения сбрасывают их к умолчаниям.
+		SetWorkingHours(ctx context.Context, userID, timeZone, workStart, workEnd string) (*model.User, error)
+		GetUserByID(ctx context.Context, userID string) (*model.User, error)
+		// GetUserReviews возвращает PR, где пользователь ревьювер; если asOf задан — на этот момент.
+		GetUserReviews(ctx context.Context, userID string, asOf *time.Time) ([]model.PullRequest, error)
+		// BulkDeactivate с opts.DryRun только рассчитывает план и ничего не записывает;
+		// с opts.PlanToken выполняет ровно план из dry_run или возвращает ErrPlanStale, если данные изменились.
+		BulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string, opts BulkDeactivateOptions) (*BulkDeactivateResult, error)
 	}
 
 	userService struct {
-	userRepo repository.UserRepository
-	prRepo   repository.PRRepository
-	teamRepo repository.TeamRepository
-}
+		uow              repository.UnitOfWork
+		userRepo         repository.UserRepository
+		prRepo           repository.PRRepository
+		teamRepo         repository.TeamRepository
+		availabilityRepo repository.AvailabilityRepository
+		auditRepo        repository.AuditRepository
+	}
 
 	BulkDeactivateResult struct {
 		TeamName             string
@@ -41,20 +63,66 @@ type (
 		ReassignmentsDone    int
 		ReassignmentsSkipped int
 		AffectedPullRequests int
+		// Сколько PR после замен не удовлетворяют правилам состава команды.
+		PolicyViolations int
+		// Plan — снятые ревьюверы по PR в порядке выполнения.
+		Plan []BulkDeactivateStep
+		// DryRun и PlanToken заполняются, когда план только рассчитан.
+		DryRun    bool
+		PlanToken string
+	}
+
+	BulkDeactivateStep struct {
+		PRID          string
+		RemovedUserID string
+		// ReplacementUserID пуст, если замену не нашли; причина тогда в SkipReason.
+		ReplacementUserID string
+		// Delegated — замена выбрана как делегат снятого ревьювера.
+		Delegated  bool
+		SkipReason string
+	}
+
+	BulkDeactivateOptions struct {
+		DryRun    bool
+		PlanToken string
+		// Exclude — активные пользователи команды, которых нельзя выбирать заменой,
+		// например остальные пользователи задачи, которых деактивируют следом.
+		Exclude []string
 	}
 )
 
-func NewUserService(userRepo repository.UserRepository, prRepo repository.PRRepository, teamRepo repository.TeamRepository) UserService {
+func NewUserService(
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	prRepo repository.PRRepository,
+	teamRepo repository.TeamRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	auditRepo repository.AuditRepository,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
-		prRepo:   prRepo,
-		teamRepo: teamRepo,
+		uow:              uow,
+		userRepo:         userRepo,
+		prRepo:           prRepo,
+		teamRepo:         teamRepo,
+		availabilityRepo: availabilityRepo,
+		auditRepo:        auditRepo,
 	}
 }
 
-func (s *userService) SetActive(userID string, active bool) (*model.User, error) {
-	logger := config.Logger()
-	user, err := s.userRepo.SetActive(userID, active)
+func (s *userService) SetActive(ctx context.Context, origin Origin, userID string, active bool) (user *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "userService.SetActive", attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		user, err = s.setActive(ctx, origin, userID, active)
+		return err
+	})
+	return user, err
+}
+
+func (s *userService) setActive(ctx context.Context, origin Origin, userID string, active bool) (*model.User, error) {
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.SetActive(ctx, userID, active)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("set active user not found", "user_id", userID)
@@ -64,14 +132,54 @@ func (s *userService) SetActive(userID string, active bool) (*model.User, error)
 		return nil, err
 	}
 
+	if err := journal(ctx, s.auditRepo, origin, activityEvent(*user, user.Team.Name, origin, model.ReasonSetActive)); err != nil {
+		return nil, err
+	}
+
 	logger.Debugw("user entity after set active", "user", user)
 	logger.Infow("user activity updated", "user_id", userID, "is_active", active)
 	return user, nil
 }
 
-func (s *userService) GetUserByID(userID string) (*model.User, error) {
-	logger := config.Logger()
-	user, err := s.userRepo.GetByUserID(userID)
+func (s *userService) SetWorkingHours(ctx context.Context, userID, timeZone, workStart, workEnd string) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "userService.SetWorkingHours", attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	if err := validateWorkingHours(timeZone, workStart, workEnd); err != nil {
+		logger.Warnw("invalid working hours", "user_id", userID, "time_zone", timeZone, "work_start", workStart, "work_end", workEnd)
+		return nil, err
+	}
+	if timeZone == "" {
+		timeZone = model.DefaultTimeZone
+	}
+	if workStart == "" {
+		workStart = model.DefaultWorkStart
+	}
+	if workEnd == "" {
+		workEnd = model.DefaultWorkEnd
+	}
+
+	user, err := s.userRepo.SetWorkingHours(ctx, userID, timeZone, workStart, workEnd)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			logger.Warnw("working hours user not found", "user_id", userID)
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		logger.Errorw("set working hours failed", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	logger.Infow("user working hours updated", "user_id", userID, "time_zone", timeZone, "work_start", workStart, "work_end", workEnd)
+	return user, nil
+}
+
+func (s *userService) GetUserByID(ctx context.Context, userID string) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "userService.GetUserByID", attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("user not found", "user_id", userID)
@@ -85,9 +193,12 @@ func (s *userService) GetUserByID(userID string) (*model.User, error) {
 	return user, nil
 }
 
-func (s *userService) GetUserReviews(userID string) ([]model.PullRequest, error) {
-	logger := config.Logger()
-	user, err := s.userRepo.GetByUserID(userID)
+func (s *userService) GetUserReviews(ctx context.Context, userID string, asOf *time.Time) (_ []model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "userService.GetUserReviews", attribute.String("user_id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	logger := config.LoggerFrom(ctx)
+	user, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("reviews user not found", "user_id", userID)
@@ -97,7 +208,20 @@ func (s *userService) GetUserReviews(userID string) ([]model.PullRequest, error)
 		return nil, err
 	}
 
-	prs, err := s.prRepo.GetPRsWhereReviewer(user.ID)
+	if asOf != nil {
+		prs, err := s.prRepo.GetPRsWhereReviewerAt(ctx, user.ID, *asOf)
+		if err != nil {
+			logger.Errorw("get reviews as of failed", "user_id", userID, "as_of", asOf, "error", err)
+			return nil, err
+		}
+		for i := range prs {
+			rewindStatus(&prs[i], *asOf)
+		}
+		logger.Infow("user reviews fetched as of", "user_id", userID, "as_of", asOf, "count", len(prs))
+		return prs, nil
+	}
+
+	prs, err := s.prRepo.GetPRsWhereReviewer(ctx, user.ID)
 	if err != nil {
 		logger.Errorw("get reviews failed", "user_id", userID, "error", err)
 		return nil, err
@@ -109,13 +233,114 @@ func (s *userService) GetUserReviews(userID string) ([]model.PullRequest, error)
 
 // BulkDeactivate деактивирует пользователей команды и безопасно переназначает их в открытых PR.
 // Операция накладная, как минимум O(n * m), где N - количество PR, M - пользователей которых придется переназначать
-func (s *userService) BulkDeactivate(teamName string, userIDs []string) (*BulkDeactivateResult, error) {
-	logger := config.Logger()
+func (s *userService) BulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string, opts BulkDeactivateOptions) (result *BulkDeactivateResult, err error) {
+	ctx, span := tracing.Start(ctx, "userService.BulkDeactivate", attribute.String("team_name", teamName), attribute.Int("users", len(userIDs)), attribute.Bool("dry_run", opts.DryRun))
+	defer func() { tracing.End(span, err) }()
+
+	err = inUnitOfWork(ctx, s.uow, func(ctx context.Context) error {
+		result, err = s.bulkDeactivate(ctx, origin, teamName, userIDs, opts)
+		return err
+	})
+	return result, conflictErr(err)
+}
+
+func (s *userService) bulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs []string, opts BulkDeactivateOptions) (*BulkDeactivateResult, error) {
+	logger := config.LoggerFrom(ctx)
 	if len(userIDs) == 0 {
-		return nil, fmt.Errorf("user_ids is required")
+		return nil, serviceerrs.ErrUserIDsRequired
+	}
+
+	// С токеном план строится заново с тем же зерном: на неизменных данных он совпадёт с показанным при dry_run.
+	seed := rand.Int63()
+	var wantHash string
+	if opts.PlanToken != "" {
+		var err error
+		if seed, wantHash, err = parsePlanToken(opts.PlanToken); err != nil {
+			logger.Warnw("bulk deactivate invalid plan token", "team_name", teamName, "error", err)
+			return nil, err
+		}
 	}
 
-	team, err := s.teamRepo.GetTeamByName(teamName)
+	plan, err := s.planBulkDeactivate(ctx, origin, teamName, userIDs, opts.Exclude, seed)
+	if err != nil {
+		return nil, err
+	}
+	result := plan.result
+	if opts.DryRun {
+		result.DryRun = true
+		result.PlanToken = formatPlanToken(seed, plan.hash)
+		logger.Infow("bulk deactivate planned", "team_name", teamName, "deactivated", result.DeactivatedUsers, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "prs", result.AffectedPullRequests)
+		return result, nil
+	}
+	if opts.PlanToken != "" && plan.hash != wantHash {
+		logger.Warnw("bulk deactivate plan is stale", "team_name", teamName)
+		return nil, serviceerrs.ErrPlanStale
+	}
+
+	deactivated, err := s.userRepo.BulkDeactivate(ctx, plan.team.ID, userIDs)
+	if err != nil && !errors.Is(err, repoerrs.ErrNotFound) {
+		logger.Errorw("bulk deactivate failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+	if len(deactivated) != result.DeactivatedUsers {
+		// Кого-то из пользователей деактивировали между чтением и записью: план уже не тот, что был рассчитан.
+		logger.Warnw("bulk deactivate users changed after planning", "team_name", teamName, "planned", result.DeactivatedUsers, "deactivated", len(deactivated))
+		if opts.PlanToken != "" {
+			return nil, serviceerrs.ErrPlanStale
+		}
+		return nil, serviceerrs.ErrConcurrentUpdate
+	}
+	events := make([]model.AuditEvent, 0, len(deactivated))
+	for _, u := range deactivated {
+		events = append(events, activityEvent(u, plan.team.Name, origin, model.ReasonBulkDeactivate))
+	}
+	if err := journal(ctx, s.auditRepo, origin, events...); err != nil {
+		return nil, err
+	}
+
+	for _, item := range plan.prs {
+		if err := s.prRepo.ReplaceReviewers(ctx, item.pr, item.links); err != nil {
+			logger.Errorw("bulk replace reviewers failed", "pr_id", item.pr.PRID, "error", err)
+			return nil, err
+		}
+		if err := journal(ctx, s.auditRepo, origin, item.events...); err != nil {
+			return nil, err
+		}
+	}
+
+	observe(ctx, func() {
+		metrics.BulkDeactivation(metrics.OutcomeDeactivated, result.DeactivatedUsers)
+		metrics.BulkDeactivation(metrics.OutcomeReassigned, result.ReassignmentsDone)
+		metrics.BulkDeactivation(metrics.OutcomeSkipped, result.ReassignmentsSkipped)
+		metrics.BulkDeactivation(metrics.OutcomeAffectedPRs, result.AffectedPullRequests)
+	})
+
+	logger.Infow("bulk deactivate completed", "team_name", teamName, "deactivated", result.DeactivatedUsers, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "prs", result.AffectedPullRequests)
+	return result, nil
+}
+
+type (
+	// bulkPlan — рассчитанная массовая деактивация: что записать и что показать в ответе.
+	bulkPlan struct {
+		team   *model.Team
+		prs    []bulkPlanPR
+		result *BulkDeactivateResult
+		// hash описывает план и версии затронутых PR; входит в токен плана.
+		hash string
+	}
+
+	bulkPlanPR struct {
+		pr     *model.PullRequest
+		links  []model.PRReviewer
+		events []model.AuditEvent
+	}
+)
+
+// planBulkDeactivate только читает данные и рассчитывает замены. Кандидаты выбираются генератором с зерном seed,
+// а пользователи и PR перебираются в порядке ID, поэтому на тех же данных план повторяется.
+func (s *userService) planBulkDeactivate(ctx context.Context, origin Origin, teamName string, userIDs, exclude []string, seed int64) (*bulkPlan, error) {
+	logger := config.LoggerFrom(ctx)
+	team, err := s.teamRepo.GetTeamByName(ctx, teamName)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			logger.Warnw("bulk deactivate team not found", "team_name", teamName)
@@ -125,94 +350,247 @@ func (s *userService) BulkDeactivate(teamName string, userIDs []string) (*BulkDe
 		return nil, err
 	}
 
-	toDeactivate, err := s.userRepo.BulkDeactivate(team.ID, userIDs)
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(ctx, team.ID)
 	if err != nil {
-		if errors.Is(err, repoerrs.ErrNotFound) {
-			logger.Warnw("bulk deactivate users not found", "team_name", teamName, "user_ids", userIDs)
-			return nil, serviceerrs.ErrUserNotFound
-		}
-		logger.Errorw("bulk deactivate failed", "team_name", teamName, "error", err)
+		logger.Errorw("bulk deactivate list active users failed", "team_name", teamName, "error", err)
 		return nil, err
 	}
 
-	deactivatedByID := make(map[uint]model.User, len(toDeactivate))
-	reviewerIDs := make([]uint, 0, len(toDeactivate))
-	for _, u := range toDeactivate {
-		deactivatedByID[u.ID] = u
-		reviewerIDs = append(reviewerIDs, u.ID)
+	// Подготовим кэш активных и присутствующих пользователей команды (по ID) для быстрой замены.
+	deactivatedByID := make(map[uint]model.User, len(userIDs))
+	activeByID := make(map[uint]model.User, len(activeUsers))
+	for _, u := range activeUsers {
+		if slices.Contains(userIDs, u.UserID) {
+			deactivatedByID[u.ID] = u
+			continue
+		}
+		if slices.Contains(exclude, u.UserID) {
+			continue
+		}
+		activeByID[u.ID] = u
+	}
+	if len(deactivatedByID) == 0 {
+		logger.Warnw("bulk deactivate users not found", "team_name", teamName, "user_ids", userIDs)
+		return nil, serviceerrs.ErrUserNotFound
 	}
+	reviewerIDs := slices.Sorted(maps.Keys(deactivatedByID))
 
-	prs, err := s.prRepo.GetOpenPRsByReviewerIDs(reviewerIDs)
+	prs, err := s.prRepo.GetOpenPRsByReviewerIDs(ctx, reviewerIDs)
 	if err != nil {
 		logger.Errorw("bulk deactivate fetch open prs failed", "team_name", teamName, "error", err)
 		return nil, err
 	}
+	slices.SortFunc(prs, func(a, b model.PullRequest) int { return cmp.Compare(a.ID, b.ID) })
 
-	activeUsers, err := s.userRepo.GetActiveUsersByTeam(team.ID)
+	now := clock()
+	absentIDs, err := s.availabilityRepo.GetAbsentUserIDs(ctx, team.ID, now)
 	if err != nil {
-		logger.Errorw("bulk deactivate list active users failed", "team_name", teamName, "error", err)
+		logger.Errorw("bulk deactivate list absent users failed", "team_name", teamName, "error", err)
 		return nil, err
 	}
+	for _, id := range absentIDs {
+		delete(activeByID, id)
+	}
 
-	// Подготовим кэш активных пользователей команды (по ID) для быстрой замены.
-	activeByID := make(map[uint]model.User, len(activeUsers))
-	for _, u := range activeUsers {
-		activeByID[u.ID] = u
+	delegations, err := s.availabilityRepo.GetActiveDelegations(ctx, team.ID, now)
+	if err != nil {
+		logger.Errorw("bulk deactivate list delegations failed", "team_name", teamName, "error", err)
+		return nil, err
+	}
+	delegateOf := make(map[uint]uint, len(delegations))
+	for _, d := range delegations {
+		delegateOf[d.UserID] = d.DelegateID
 	}
 
-	result := &BulkDeactivateResult{
-		TeamName:         teamName,
-		DeactivatedUsers: len(toDeactivate),
+	plan := &bulkPlan{
+		team: team,
+		result: &BulkDeactivateResult{
+			TeamName:         teamName,
+			DeactivatedUsers: len(deactivatedByID),
+		},
+	}
+	hash := sha256.New()
+	for _, id := range reviewerIDs {
+		fmt.Fprintf(hash, "user %s\n", deactivatedByID[id].UserID)
 	}
 
+	rng := rand.New(rand.NewSource(seed))
+	policy := policyForTeam(*team)
 	for i := range prs {
 		pr := &prs[i]
 		affected := false
+		fmt.Fprintf(hash, "pr %s %d\n", pr.PRID, pr.Version)
 
-		newReviewers := make([]uint, 0, len(pr.AssignedReviewers))
+		// Сначала собираем ревьюверов, которые остаются, чтобы правила состава учитывали их при подборе замен.
+		kept := make([]model.User, 0, len(pr.AssignedReviewers))
+		links := make([]model.PRReviewer, 0, len(pr.AssignedReviewers))
+		var (
+			replaced []model.User
+			prEvents []model.AuditEvent
+		)
 		excluded := make(map[uint]struct{}, len(pr.AssignedReviewers)+2)
 		excluded[pr.AuthorID] = struct{}{}
-
 		for _, reviewer := range pr.AssignedReviewers {
-			if _, isDeactivated := deactivatedByID[reviewer.ID]; !isDeactivated {
-				newReviewers = append(newReviewers, reviewer.ID)
-				excluded[reviewer.ID] = struct{}{}
+			excluded[reviewer.ID] = struct{}{}
+			if _, isDeactivated := deactivatedByID[reviewer.ID]; isDeactivated {
+				replaced = append(replaced, reviewer)
 				continue
 			}
+			kept = append(kept, reviewer)
+			links = append(links, keptReviewerLink(*pr, reviewer.ID))
+		}
+		slices.SortFunc(replaced, func(a, b model.User) int { return cmp.Compare(a.ID, b.ID) })
 
-			candidate := selectReplacementCandidateCached(activeByID, excluded)
+		for _, old := range replaced {
+			link := model.PRReviewer{PullRequestID: pr.ID}
+			step := BulkDeactivateStep{PRID: pr.PRID, RemovedUserID: old.UserID}
+			// Делегат снимаемого ревьювера имеет приоритет перед случайным кандидатом.
+			candidate := delegateCandidateCached(activeByID, excluded, delegateOf, old.ID)
+			if candidate != nil {
+				oldID := old.ID
+				link.DelegatedForID = &oldID
+				step.Delegated = true
+			} else {
+				candidate = selectReplacementCandidateCached(activeByID, excluded, policy, kept, rng, now)
+			}
 			if candidate == nil {
-				result.ReassignmentsSkipped++
+				step.SkipReason = skipReasonNoCandidate
+				plan.result.Plan = append(plan.result.Plan, step)
+				fmt.Fprintf(hash, "skip %s\n", old.UserID)
+				plan.result.ReassignmentsSkipped++
+				prEvents = append(prEvents, model.AuditEvent{
+					Type:     model.AuditUnassigned,
+					Reason:   origin.reasonOr(model.ReasonBulkDeactivate),
+					PRID:     pr.PRID,
+					UserID:   old.UserID,
+					TeamName: team.Name,
+					Details:  skipReasonNoCandidate,
+				})
 				continue
 			}
 
-			newReviewers = append(newReviewers, candidate.ID)
+			step.ReplacementUserID = candidate.UserID
+			plan.result.Plan = append(plan.result.Plan, step)
+			fmt.Fprintf(hash, "replace %s %s %t\n", old.UserID, candidate.UserID, step.Delegated)
+
+			event := model.AuditEvent{
+				Type:           model.AuditReplaced,
+				Reason:         origin.reasonOr(model.ReasonBulkDeactivate),
+				PRID:           pr.PRID,
+				UserID:         candidate.UserID,
+				PreviousUserID: old.UserID,
+				TeamName:       team.Name,
+			}
+			if link.DelegatedForID != nil {
+				event.Details = "delegate of " + old.UserID
+			}
+			prEvents = append(prEvents, event)
+
+			link.UserID = candidate.ID
+			kept = append(kept, *candidate)
+			links = append(links, link)
 			excluded[candidate.ID] = struct{}{}
-			result.ReassignmentsDone++
+			plan.result.ReassignmentsDone++
 			affected = true
 		}
 
-		if err := s.prRepo.ReplaceReviewers(pr.ID, newReviewers); err != nil {
-			logger.Errorw("bulk replace reviewers failed", "pr_id", pr.PRID, "error", err)
-			return nil, err
-		}
-
+		plan.prs = append(plan.prs, bulkPlanPR{pr: pr, links: links, events: prEvents})
 		if affected {
-			result.AffectedPullRequests++
+			plan.result.AffectedPullRequests++
+		}
+		if len(policy.violations(kept)) > 0 {
+			plan.result.PolicyViolations++
 		}
 	}
 
-	logger.Infow("bulk deactivate completed", "team_name", teamName, "deactivated", result.DeactivatedUsers, "reassigned", result.ReassignmentsDone, "skipped", result.ReassignmentsSkipped, "prs", result.AffectedPullRequests)
-	return result, nil
+	plan.hash = hex.EncodeToString(hash.Sum(nil)[:planHashSize])
+	return plan, nil
 }
 
-// selectReplacementCandidateCached выбирает активного пользователя команды с учётом исключений по заранее загруженному кэшу.
-func selectReplacementCandidateCached(users map[uint]model.User, excluded map[uint]struct{}) *model.User {
-	for _, u := range users {
-		if _, skip := excluded[u.ID]; skip {
+// selectReplacementCandidateCached выбирает активного пользователя команды с учётом исключений и правил состава по заранее загруженному кэшу.
+// Пул перемешивается генератором rng из отсортированного по ID списка, чтобы выбор повторялся при том же зерне.
+func selectReplacementCandidateCached(users map[uint]model.User, excluded map[uint]struct{}, policy reviewerPolicy, kept []model.User, rng *rand.Rand, now time.Time) *model.User {
+	pool := make([]model.User, 0, len(users))
+	for _, id := range slices.Sorted(maps.Keys(users)) {
+		if _, skip := excluded[id]; skip {
 			continue
 		}
-		return &u
+		pool = append(pool, users[id])
+	}
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	picked := policy.pick(kept, policy.order(pool, now), 1)
+	if len(picked) == 0 {
+		return nil
+	}
+	return &picked[0]
+}
+
+// delegateCandidateCached возвращает делегата пользователя, если он есть в кэше доступных и не исключён.
+func delegateCandidateCached(users map[uint]model.User, excluded map[uint]struct{}, delegateOf map[uint]uint, userID uint) *model.User {
+	delegateID, ok := delegateOf[userID]
+	if !ok {
+		return nil
+	}
+	if _, skip := excluded[delegateID]; skip {
+		return nil
+	}
+	delegate, ok := users[delegateID]
+	if !ok {
+		return nil
+	}
+	return &delegate
+}
+
+// keptReviewerLink возвращает строку pr_reviewers оставшегося ревьювера с исходным временем назначения и признаком делегирования.
+func keptReviewerLink(pr model.PullRequest, reviewerID uint) model.PRReviewer {
+	for _, link := range pr.ReviewerLinks {
+		if link.UserID == reviewerID {
+			return model.PRReviewer{
+				PullRequestID:  pr.ID,
+				UserID:         reviewerID,
+				AssignedAt:     link.AssignedAt,
+				DelegatedForID: link.DelegatedForID,
+			}
+		}
+	}
+	return model.PRReviewer{PullRequestID: pr.ID, UserID: reviewerID}
+}
+
+// activityEvent описывает смену флага активности пользователя для журнала.
+func activityEvent(user model.User, teamName string, origin Origin, reason string) model.AuditEvent {
+	return model.AuditEvent{
+		Type:     model.AuditActivityChanged,
+		Reason:   origin.reasonOr(reason),
+		UserID:   user.UserID,
+		TeamName: teamName,
+		Details:  fmt.Sprintf("is_active=%t", user.IsActive),
+	}
+}
+
+// skipReasonNoCandidate — причина, по которой снятый ревьювер остался без замены.
+const skipReasonNoCandidate = "no replacement candidate"
+
+// planHashSize — сколько байт sha256 плана входит в токен: подделать план по токену всё равно нельзя,
+// а токен остаётся коротким.
+const planHashSize = 16
+
+// formatPlanToken собирает токен плана из зерна выбора кандидатов и хэша плана.
+func formatPlanToken(seed int64, hash string) string {
+	return strconv.FormatInt(seed, 16) + "." + hash
+}
+
+func parsePlanToken(token string) (int64, string, error) {
+	rawSeed, hash, ok := strings.Cut(token, ".")
+	if !ok || len(hash) != 2*planHashSize {
+		return 0, "", serviceerrs.ErrInvalidPlanToken
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return 0, "", serviceerrs.ErrInvalidPlanToken
+	}
+	seed, err := strconv.ParseInt(rawSeed, 16, 64)
+	if err != nil {
+		return 0, "", serviceerrs.ErrInvalidPlanToken
 	}
-	return nil
+	return seed, hash, nil
 }
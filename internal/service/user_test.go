@@ -1,155 +1,122 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
-	"github.com/Leganyst/avitoTrainee/internal/model"
-	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
 	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
 )
 
-type stubUserPRRepo struct {
-	prs    []model.PullRequest
-	prErr  error
-	called bool
-}
-
-func (s *stubUserPRRepo) CreatePR(pr *model.PullRequest) error { return nil }
-func (s *stubUserPRRepo) AddReviewers(pr *model.PullRequest, reviewers []model.User) error {
-	return nil
-}
-func (s *stubUserPRRepo) ReplaceReviewer(pr *model.PullRequest, oldReviewerID uint, newReviewer model.User) error {
-	return nil
-}
-func (s *stubUserPRRepo) GetPRByExternalID(prID string) (*model.PullRequest, error) { return nil, nil }
-func (s *stubUserPRRepo) UpdatePR(pr *model.PullRequest) error                      { return nil }
-func (s *stubUserPRRepo) GetOpenPRsByReviewerIDs(reviewerIDs []uint) ([]model.PullRequest, error) {
-	return nil, nil
-}
-func (s *stubUserPRRepo) ReplaceReviewers(prID uint, reviewerIDs []uint) error { return nil }
-
-func (s *stubUserPRRepo) GetPRsWhereReviewer(userID uint) ([]model.PullRequest, error) {
-	s.called = true
-	if s.prErr != nil {
-		return nil, s.prErr
-	}
-	return s.prs, nil
-}
-
 func TestUserService_SetActive_Success(t *testing.T) {
-	repo := &stubUserRepo{
-		users: map[string]*model.User{
-			"u1": {UserID: "u1", IsActive: false},
-		},
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1")
+	if _, err := b.userRepo.SetActive(ctx, "u1", false); err != nil {
+		t.Fatalf("SetActive returned error: %v", err)
 	}
-	svc := userService{userRepo: repo, prRepo: &stubUserPRRepo{}, teamRepo: &stubTeamRepo{}}
 
-	user, err := svc.SetActive("u1", true)
+	user, err := b.users.SetActive(ctx, Origin{}, "u1", true)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if !user.IsActive {
+	if !user.IsActive || !b.user(t, "u1").IsActive {
 		t.Fatalf("expected user to be active")
 	}
 }
 
 func TestUserService_SetActive_NotFound(t *testing.T) {
-	repo := &stubUserRepo{
-		setActiveErr: repoerrs.ErrNotFound,
-	}
-	svc := userService{userRepo: repo, prRepo: &stubUserPRRepo{}, teamRepo: &stubTeamRepo{}}
+	b := newMemoryBackend(t)
 
-	_, err := svc.SetActive("missing", true)
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.users.SetActive(context.Background(), Origin{}, "missing", true)
 	if !errors.Is(err, serviceerrs.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
 }
 
 func TestUserService_GetUserByID_Success(t *testing.T) {
-	expected := &model.User{UserID: "u1"}
-	repo := &stubUserRepo{
-		users: map[string]*model.User{"u1": expected},
-	}
-	svc := userService{userRepo: repo, prRepo: &stubUserPRRepo{}, teamRepo: &stubTeamRepo{}}
+	b := newMemoryBackend(t, "u1")
 
-	user, err := svc.GetUserByID("u1")
+	user, err := b.users.GetUserByID(context.Background(), "u1")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if user != expected {
-		t.Fatalf("expected pointer %p, got %p", expected, user)
+	if user.UserID != "u1" || user.Team.Name != "backend" {
+		t.Fatalf("unexpected user: %+v", user)
 	}
 }
 
 func TestUserService_GetUserByID_NotFound(t *testing.T) {
-	repo := &stubUserRepo{
-		getErrFor: map[string]error{"missing": repoerrs.ErrNotFound},
-	}
-	svc := userService{userRepo: repo, prRepo: &stubUserPRRepo{}, teamRepo: &stubTeamRepo{}}
+	b := newMemoryBackend(t)
 
-	_, err := svc.GetUserByID("missing")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := b.users.GetUserByID(context.Background(), "missing")
 	if !errors.Is(err, serviceerrs.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
 }
 
 func TestUserService_GetUserReviews_Success(t *testing.T) {
-	user := &model.User{ID: 10, UserID: "u1"}
-	userRepo := &stubUserRepo{users: map[string]*model.User{"u1": user}}
-	prRepo := &stubUserPRRepo{
-		prs: []model.PullRequest{{PRID: "pr-1"}},
-	}
-	svc := userService{userRepo: userRepo, prRepo: prRepo, teamRepo: &stubTeamRepo{}}
+	b := newMemoryBackend(t, "u1", "u2")
+	b.createPR(t, "pr-1", "u1")
 
-	prs, err := svc.GetUserReviews("u1")
+	prs, err := b.users.GetUserReviews(context.Background(), "u2", nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if len(prs) != 1 || prs[0].PRID != "pr-1" {
 		t.Fatalf("unexpected PRs result: %+v", prs)
 	}
-	if !prRepo.called {
-		t.Fatalf("expected PR repository to be called")
-	}
 }
 
 func TestUserService_GetUserReviews_UserNotFound(t *testing.T) {
-	userRepo := &stubUserRepo{
-		getErrFor: map[string]error{"missing": repoerrs.ErrNotFound},
-	}
-	prRepo := &stubUserPRRepo{}
-	svc := userService{userRepo: userRepo, prRepo: prRepo, teamRepo: &stubTeamRepo{}}
+	b := newMemoryBackend(t)
+	repo := failingPRRepo{PRRepository: b.prRepo, reviewsErr: errors.New("must not be called")}
+	svc := userService{uow: b.uow, userRepo: b.userRepo, prRepo: repo, teamRepo: b.teamRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
 
-	_, err := svc.GetUserReviews("missing")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
+	_, err := svc.GetUserReviews(context.Background(), "missing", nil)
 	if !errors.Is(err, serviceerrs.ErrUserNotFound) {
-		t.Fatalf("expected ErrUserNotFound, got %v", err)
-	}
-	if prRepo.called {
-		t.Fatalf("expected PR repo not to be called when user missing")
+		t.Fatalf("expected ErrUserNotFound before reading reviews, got %v", err)
 	}
 }
 
 func TestUserService_GetUserReviews_PRRepoError(t *testing.T) {
-	user := &model.User{ID: 10, UserID: "u1"}
-	userRepo := &stubUserRepo{users: map[string]*model.User{"u1": user}}
-	prRepo := &stubUserPRRepo{prErr: errors.New("db error")}
-	svc := userService{userRepo: userRepo, prRepo: prRepo, teamRepo: &stubTeamRepo{}}
-
-	_, err := svc.GetUserReviews("u1")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
-	}
-	if !errors.Is(err, prRepo.prErr) {
+	b := newMemoryBackend(t, "u1")
+	repo := failingPRRepo{PRRepository: b.prRepo, reviewsErr: errors.New("db error")}
+	svc := userService{uow: b.uow, userRepo: b.userRepo, prRepo: repo, teamRepo: b.teamRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
+
+	_, err := svc.GetUserReviews(context.Background(), "u1", nil)
+	if !errors.Is(err, repo.reviewsErr) {
 		t.Fatalf("expected PR repo error, got %v", err)
 	}
 }
+
+func TestUserService_GetUserReviews_AsOf(t *testing.T) {
+	ctx := context.Background()
+	b := newMemoryBackend(t, "u1", "u2")
+	b.createPR(t, "pr-1", "u1")
+	asOf := time.Now()
+	if _, err := b.prs.Merge(ctx, Origin{}, "pr-1"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+
+	// Текущий состав ревьюверов не читается: ответ строится по истории на момент asOf.
+	repo := failingPRRepo{PRRepository: b.prRepo, reviewsErr: errors.New("current reviews must not be read")}
+	svc := userService{uow: b.uow, userRepo: b.userRepo, prRepo: repo, teamRepo: b.teamRepo, availabilityRepo: b.availabilityRepo, auditRepo: b.auditRepo}
+
+	prs, err := svc.GetUserReviews(ctx, "u2", &asOf)
+	if err != nil {
+		t.Fatalf("expected history lookup at %v, got %v", asOf, err)
+	}
+	if len(prs) != 1 || prs[0].Status != statusOpen {
+		t.Fatalf("expected PR to be OPEN as of %v, got %+v", asOf, prs)
+	}
+}
+
+func TestUserService_BulkDeactivate_UserIDsRequired(t *testing.T) {
+	b := newMemoryBackend(t, "u1")
+
+	_, err := b.users.BulkDeactivate(context.Background(), Origin{}, "backend", nil, BulkDeactivateOptions{})
+	if !errors.Is(err, serviceerrs.ErrUserIDsRequired) {
+		t.Fatalf("expected ErrUserIDsRequired, got %v", err)
+	}
+}
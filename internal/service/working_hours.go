@@ -0,0 +1,58 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+// preferWorking переставляет пул так, что сначала идут участники, у которых в момент at рабочее время.
+// Порядок внутри каждой группы сохраняется, поэтому случайность предварительного перемешивания не теряется.
+func preferWorking(pool []model.User, at time.Time) []model.User {
+	ordered := make([]model.User, 0, len(pool))
+	var offHours []model.User
+	for _, u := range pool {
+		if u.IsWorkingAt(at) {
+			ordered = append(ordered, u)
+			continue
+		}
+		offHours = append(offHours, u)
+	}
+	return append(ordered, offHours...)
+}
+
+// validateWorkingHours проверяет часовой пояс и рабочее окно пользователя; пустые значения допустимы.
+func validateWorkingHours(timeZone, workStart, workEnd string) error {
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return serviceerrs.ErrInvalidTimeZone
+		}
+	}
+	if workStart == "" && workEnd == "" {
+		return nil
+	}
+	if workStart == "" {
+		workStart = model.DefaultWorkStart
+	}
+	if workEnd == "" {
+		workEnd = model.DefaultWorkEnd
+	}
+	start, err := model.ParseClock(workStart)
+	if err != nil {
+		return serviceerrs.ErrInvalidWorkingHours
+	}
+	end, err := model.ParseClock(workEnd)
+	if err != nil || end <= start {
+		return serviceerrs.ErrInvalidWorkingHours
+	}
+	return nil
+}
+
+func validAssignmentMode(mode string) bool {
+	switch mode {
+	case "", model.AssignmentModeRandom, model.AssignmentModeWorkingHours:
+		return true
+	}
+	return false
+}
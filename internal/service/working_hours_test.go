@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	serviceerrs "github.com/Leganyst/avitoTrainee/internal/service/errs"
+)
+
+func TestWorkingDuration_CountsOnlyWorkingWindow(t *testing.T) {
+	user := model.User{TimeZone: "Europe/Moscow", WorkStart: "10:00", WorkEnd: "19:00"}
+	// Пятница 17:00 МСК → понедельник 12:00 МСК: 2 часа в пятницу и 2 в понедельник.
+	from := time.Date(2025, 10, 24, 14, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 27, 9, 0, 0, 0, time.UTC)
+
+	if got := user.WorkingDuration(from, to); got != 4*time.Hour {
+		t.Fatalf("expected 4h of working time, got %v", got)
+	}
+}
+
+func TestPreferWorking_OrdersByWorkingWindow(t *testing.T) {
+	// Понедельник 07:00 UTC: в Новосибирске 14:00, в Нью-Йорке 03:00.
+	at := time.Date(2025, 10, 27, 7, 0, 0, 0, time.UTC)
+	pool := []model.User{
+		{ID: 1, TimeZone: "America/New_York"},
+		{ID: 2, TimeZone: "Asia/Novosibirsk"},
+		{ID: 3, TimeZone: "America/Los_Angeles"},
+	}
+
+	ordered := preferWorking(pool, at)
+	if ordered[0].ID != 2 || ordered[1].ID != 1 || ordered[2].ID != 3 {
+		t.Fatalf("expected working user first and original order otherwise, got %+v", ordered)
+	}
+}
+
+func TestValidateWorkingHours(t *testing.T) {
+	cases := []struct {
+		name               string
+		tz, workStart, end string
+		want               error
+	}{
+		{name: "defaults", want: nil},
+		{name: "valid", tz: "Asia/Tokyo", workStart: "08:30", end: "17:30", want: nil},
+		{name: "unknown zone", tz: "Mars/Olympus", want: serviceerrs.ErrInvalidTimeZone},
+		{name: "bad clock", workStart: "9am", end: "18:00", want: serviceerrs.ErrInvalidWorkingHours},
+		{name: "reversed window", workStart: "18:00", end: "09:00", want: serviceerrs.ErrInvalidWorkingHours},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateWorkingHours(tc.tz, tc.workStart, tc.end); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPRService_CreatePR_WorkingHoursMode(t *testing.T) {
+	withClock(t, time.Date(2025, 10, 27, 7, 0, 0, 0, time.UTC))
+	b := newMemoryBackend(t)
+	b.addTeam(t, model.Team{Name: "platform", MaxReviewers: 1, AssignmentMode: model.AssignmentModeWorkingHours, Users: []model.User{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true, TimeZone: "America/New_York"},
+		{UserID: "u3", Username: "u3", IsActive: true, TimeZone: "Asia/Novosibirsk"},
+		{UserID: "u4", Username: "u4", IsActive: true, TimeZone: "America/Los_Angeles"},
+	}})
+
+	for i := 0; i < 10; i++ {
+		pr := b.createPR(t, fmt.Sprintf("pr-%d", i), "author")
+		if ids := reviewerIDs(pr); len(ids) != 1 || ids[0] != "u3" {
+			t.Fatalf("expected reviewer inside working hours (u3), got %v", ids)
+		}
+	}
+}
+
+func TestStatsService_ReviewSLA(t *testing.T) {
+	withClock(t, time.Date(2025, 10, 27, 12, 0, 0, 0, time.UTC))
+	repo := &stubStatsRepo{openAssignments: []repository.OpenReviewAssignment{
+		// Пятница 15:00 → понедельник 12:00 UTC: 3 + 3 = 6 рабочих часов.
+		{PRID: "pr-1", ReviewerID: "u2", TimeZone: "UTC", WorkStart: "09:00", WorkEnd: "18:00",
+			AssignedAt: time.Date(2025, 10, 24, 15, 0, 0, 0, time.UTC), SLAHours: 4},
+		{PRID: "pr-2", ReviewerID: "u3", TimeZone: "UTC", WorkStart: "09:00", WorkEnd: "18:00",
+			AssignedAt: time.Date(2025, 10, 27, 10, 0, 0, 0, time.UTC), SLAHours: 4},
+	}}
+	svc := statsService{repo: repo}
+
+	items, err := svc.ReviewSLA(context.Background(), false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(items) != 2 || items[0].PRID != "pr-1" || items[0].WorkingHours != 6 || !items[0].Breached {
+		t.Fatalf("unexpected SLA items: %+v", items)
+	}
+	if items[1].WorkingHours != 2 || items[1].Breached {
+		t.Fatalf("expected pr-2 within SLA, got %+v", items[1])
+	}
+
+	breached, err := svc.ReviewSLA(context.Background(), true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(breached) != 1 || breached[0].PRID != "pr-1" {
+		t.Fatalf("expected only pr-1 to be reported, got %+v", breached)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/Leganyst/avitoTrainee/internal/model"
+	appdb "github.com/Leganyst/avitoTrainee/internal/db"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -34,13 +34,8 @@ func connectTestDB(t *testing.T) *gorm.DB {
 
 func migrateTestDB(t *testing.T, db *gorm.DB) {
 	t.Helper()
-	if err := db.AutoMigrate(
-		&model.Team{},
-		&model.User{},
-		&model.PullRequest{},
-		&model.PRReviewer{},
-	); err != nil {
-		t.Fatalf("auto migrate failed: %v", err)
+	if err := appdb.Migrate(db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
 	}
 }
 
@@ -54,10 +49,8 @@ func getenv(key, def string) string {
 func prepareDB(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	_ = db.Migrator().DropTable("pr_reviewers")
-
 	err := db.Exec(
-		"TRUNCATE TABLE pull_requests, users, teams RESTART IDENTITY CASCADE",
+		"TRUNCATE TABLE pull_requests, users, teams, audit_events, reviewer_assignment_stats, idempotency_records, jobs RESTART IDENTITY CASCADE",
 	).Error
 
 	if err != nil {
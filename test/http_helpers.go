@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/controller/handlers"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
@@ -17,6 +18,11 @@ type apiTestServer struct {
 
 func newAPITestServer(t *testing.T) *apiTestServer {
 	t.Helper()
+	return newAPITestServerWithTimeout(t, 0)
+}
+
+func newAPITestServerWithTimeout(t *testing.T, requestTimeout time.Duration) *apiTestServer {
+	t.Helper()
 
 	gin.SetMode(gin.TestMode)
 
@@ -31,15 +37,23 @@ func newAPITestServer(t *testing.T) *apiTestServer {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 	statsRepo := repository.NewStatsRepository(db)
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	uow := repository.NewUnitOfWork(db)
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	userSvc := service.NewUserService(userRepo, prRepo, teamRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
-	statsSvc := service.NewStatsService(statsRepo)
+	teamSvc := service.NewTeamService(uow, teamRepo, userRepo)
+	userSvc := service.NewUserService(uow, userRepo, prRepo, teamRepo, availabilityRepo, auditRepo)
+	prSvc := service.NewPrService(uow, prRepo, userRepo, availabilityRepo, auditRepo)
+	statsSvc := service.NewStatsService(statsRepo, teamRepo)
+	availabilitySvc := service.NewAvailabilityService(uow, availabilityRepo, userRepo, teamRepo, prRepo, auditRepo, prSvc)
+	auditSvc := service.NewAuditService(auditRepo)
+	idempotencySvc := service.NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour, time.Minute)
+	consistencySvc := service.NewConsistencyService(prRepo, prSvc)
+	jobSvc := service.NewJobService(uow, repository.NewJobRepository(db), teamRepo, userSvc)
 
 	router := gin.New()
 	router.Use(gin.Recovery())
-	handlers.RegisterRoutes(router, teamSvc, userSvc, prSvc, statsSvc)
+	handlers.RegisterRoutes(router, requestTimeout, teamSvc, userSvc, prSvc, statsSvc, availabilitySvc, auditSvc, idempotencySvc, consistencySvc, jobSvc)
 
 	return &apiTestServer{router: router}
 }
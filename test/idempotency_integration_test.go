@@ -0,0 +1,63 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIdempotencyKey_ReplaysRetriedRequests(t *testing.T) {
+	server := newAPITestServer(t)
+
+	teamPayload := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Charlie","is_active":true}]}`
+	resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/team/add", teamPayload))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create team status = %d", resp.Code)
+	}
+
+	send := func(url, body, key string) (int, string, http.Header) {
+		req := newJSONRequest(t, http.MethodPost, url, body)
+		req.Header.Set("Idempotency-Key", key)
+		resp := server.doRequest(req)
+		return resp.Code, resp.Body.String(), resp.Header()
+	}
+
+	createPayload := `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`
+	status, body, created := send("/api/pullRequest/create", createPayload, "create-1")
+	if status != http.StatusCreated {
+		t.Fatalf("create PR status = %d", status)
+	}
+	retryStatus, retryBody, headers := send("/api/pullRequest/create", createPayload, "create-1")
+	if retryStatus != http.StatusCreated || retryBody != body {
+		t.Fatalf("expected replayed 201 with the original body, got %d %s", retryStatus, retryBody)
+	}
+	if headers.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed response to be marked")
+	}
+
+	otherPayload := `{"pull_request_id":"pr-2","pull_request_name":"Other","author_id":"u1"}`
+	req := newJSONRequest(t, http.MethodPost, "/api/pullRequest/create", otherPayload)
+	req.Header.Set("Idempotency-Key", "create-1")
+	assertErrorResponse(t, server.doRequest(req), http.StatusConflict, "IDEMPOTENCY_KEY_REUSED")
+
+	// От If-Match зависит результат изменения, поэтому тот же ключ с другой ожидаемой версией — другой запрос.
+	merge := func(ifMatch string) *http.Request {
+		req := newJSONRequest(t, http.MethodPost, "/api/pullRequest/merge", `{"pull_request_id":"pr-1"}`)
+		req.Header.Set("Idempotency-Key", "merge-1")
+		req.Header.Set("If-Match", ifMatch)
+		return req
+	}
+	if resp := server.doRequest(merge(created.Get("ETag"))); resp.Code != http.StatusOK {
+		t.Fatalf("merge PR status = %d", resp.Code)
+	}
+	assertErrorResponse(t, server.doRequest(merge(`"999"`)), http.StatusConflict, "IDEMPOTENCY_KEY_REUSED")
+
+	bulkPayload := `{"team_name":"backend","user_ids":["u2"]}`
+	status, body, _ = send("/api/users/bulkDeactivate", bulkPayload, "bulk-1")
+	if status != http.StatusOK {
+		t.Fatalf("bulk deactivate status = %d", status)
+	}
+	retryStatus, retryBody, _ = send("/api/users/bulkDeactivate", bulkPayload, "bulk-1")
+	if retryStatus != http.StatusOK || retryBody != body {
+		t.Fatalf("expected replayed bulk deactivation result, got %d %s", retryStatus, retryBody)
+	}
+}
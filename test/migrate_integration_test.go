@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	appdb "github.com/Leganyst/avitoTrainee/internal/db"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestMigrations_Postgres(t *testing.T) {
+	db := connectTestDB(t)
+	prepareDB(t, db)
+	resetMigrations(t, db)
+	// Остальные тесты ждут схему на месте.
+	t.Cleanup(func() { migrateTestDB(t, db) })
+
+	checkMigrations(t, db, func() *gorm.DB { return connectTestDB(t) })
+}
+
+func TestMigrations_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrations.db")
+	open := func() *gorm.DB {
+		db, err := appdb.Connect(&config.Config{Storage: config.StorageSQLite, SQLitePath: path})
+		if err != nil {
+			t.Fatalf("failed to open SQLite: %v", err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		return db
+	}
+
+	checkMigrations(t, open(), open)
+}
+
+// TestMigrations_AdoptAutoMigratedSchema проверяет, что базовая миграция принимает базу,
+// созданную AutoMigrate до появления версионных миграций, и не теряет данные.
+func TestMigrations_AdoptAutoMigratedSchema(t *testing.T) {
+	db, err := appdb.Connect(&config.Config{
+		Storage:    config.StorageSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "legacy.db"),
+	})
+	if err != nil {
+		t.Fatalf("failed to open SQLite: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&model.Team{}, &model.User{}, &model.PullRequest{}, &model.PRReviewer{}, &model.PRReviewerPeriod{},
+		&model.Absence{}, &model.TeamHoliday{}, &model.Delegation{}, &model.AuditEvent{},
+		&model.ReviewerAssignmentStat{}, &model.PRAssignmentStat{}, &model.IdempotencyRecord{},
+	); err != nil {
+		t.Fatalf("auto migrate failed: %v", err)
+	}
+	// merged_at появился уже в версионной миграции, у AutoMigrate-схемы его не было.
+	if err := db.Migrator().DropColumn(&model.PullRequest{}, "MergedAt"); err != nil {
+		t.Fatalf("drop merged_at: %v", err)
+	}
+	if err := db.Create(&model.Team{Name: "backend"}).Error; err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	applied, err := appdb.MigrateUp(context.Background(), db)
+	if err != nil {
+		t.Fatalf("migrate up over AutoMigrate schema: %v", err)
+	}
+	if len(applied) == 0 || applied[0].Name != "baseline" {
+		t.Fatalf("expected the baseline to be recorded first, got %+v", applied)
+	}
+	var teams int64
+	if err := db.Model(&model.Team{}).Count(&teams).Error; err != nil || teams != 1 {
+		t.Fatalf("expected existing team to survive, got %d, %v", teams, err)
+	}
+}
+
+// checkMigrations проходит up → status → down → up на пустой базе db; open открывает ещё одно подключение к ней же.
+func checkMigrations(t *testing.T, db *gorm.DB, open func() *gorm.DB) {
+	t.Helper()
+	ctx := context.Background()
+
+	// Экземпляры стартуют одновременно: каждая миграция применяется ровно один раз.
+	const instances = 4
+	conns := []*gorm.DB{db}
+	for len(conns) < instances {
+		conns = append(conns, open())
+	}
+	appliedBy := make([]int, instances)
+	errs := make([]error, instances)
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied, err := appdb.MigrateUp(ctx, conn)
+			appliedBy[i], errs[i] = len(applied), err
+		}()
+	}
+	wg.Wait()
+
+	statuses, err := appdb.MigrationStatuses(ctx, db)
+	if err != nil {
+		t.Fatalf("migration status: %v", err)
+	}
+	total := 0
+	for i := range conns {
+		if errs[i] != nil {
+			t.Fatalf("instance %d: migrate up: %v", i, errs[i])
+		}
+		total += appliedBy[i]
+	}
+	if len(statuses) == 0 || total != len(statuses) {
+		t.Fatalf("expected %d migrations applied once in total, got %d", len(statuses), total)
+	}
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			t.Fatalf("expected migration %d_%s to be applied", st.Version, st.Name)
+		}
+	}
+	if err := db.Create(&model.Team{Name: "backend"}).Error; err != nil {
+		t.Fatalf("expected migrated schema to accept writes: %v", err)
+	}
+
+	for range statuses {
+		if _, err := appdb.MigrateDown(ctx, db); err != nil {
+			t.Fatalf("migrate down: %v", err)
+		}
+	}
+	if _, err := appdb.MigrateDown(ctx, db); !errors.Is(err, appdb.ErrNoMigrations) {
+		t.Fatalf("expected ErrNoMigrations after rolling back everything, got %v", err)
+	}
+	if db.Migrator().HasTable("teams") {
+		t.Fatalf("expected baseline rollback to drop tables")
+	}
+	statuses, err = appdb.MigrationStatuses(ctx, db)
+	if err != nil {
+		t.Fatalf("migration status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.AppliedAt != nil {
+			t.Fatalf("expected migration %d_%s to be pending after rollback", st.Version, st.Name)
+		}
+	}
+
+	applied, err := appdb.MigrateUp(ctx, db)
+	if err != nil {
+		t.Fatalf("migrate up after rollback: %v", err)
+	}
+	if len(applied) != len(statuses) {
+		t.Fatalf("expected all %d migrations re-applied, got %d", len(statuses), len(applied))
+	}
+}
+
+// resetMigrations откатывает все миграции общей тестовой базы, чтобы проверка начиналась с пустой схемы.
+func resetMigrations(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for {
+		_, err := appdb.MigrateDown(context.Background(), db)
+		if errors.Is(err, appdb.ErrNoMigrations) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("reset migrations: %v", err)
+		}
+	}
+}
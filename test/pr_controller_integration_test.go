@@ -218,6 +218,70 @@ func TestPRController_Reassign_Errors(t *testing.T) {
 	})
 }
 
+func TestPRController_ManualReviewers(t *testing.T) {
+	server := newAPITestServer(t)
+
+	teamPayload := `{"team_name":"manual","max_reviewers":3,"members":[
+		{"user_id":"u1","username":"Alice","is_active":true},
+		{"user_id":"u2","username":"Bob","is_active":true},
+		{"user_id":"u3","username":"Eve","is_active":true},
+		{"user_id":"u4","username":"Oleg","is_active":true}
+	]}`
+	resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/team/add", teamPayload))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create team status %d", resp.Code)
+	}
+	resp = server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create PR status %d", resp.Code)
+	}
+	created := decodeBody[dto.CreatePRResponse](t, resp.Body)
+	if len(created.PR.AssignedReviewers) != 3 {
+		t.Fatalf("expected team limit of 3 reviewers, got %d", len(created.PR.AssignedReviewers))
+	}
+
+	t.Run("duplicate rejected", func(t *testing.T) {
+		resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reviewers/add", `{"pull_request_id":"pr-1","user_id":"u2"}`))
+		assertErrorResponse(t, resp, http.StatusConflict, "ALREADY_ASSIGNED")
+	})
+
+	t.Run("author rejected", func(t *testing.T) {
+		resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reviewers/add", `{"pull_request_id":"pr-1","user_id":"u1"}`))
+		assertErrorResponse(t, resp, http.StatusConflict, "REVIEWER_IS_AUTHOR")
+	})
+
+	t.Run("remove and add back", func(t *testing.T) {
+		resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reviewers/remove", `{"pull_request_id":"pr-1","user_id":"u2"}`))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("remove reviewer status %d", resp.Code)
+		}
+		removed := decodeBody[dto.RemoveReviewerResponse](t, resp.Body)
+		if len(removed.PR.AssignedReviewers) != 2 {
+			t.Fatalf("expected 2 reviewers after remove, got %d", len(removed.PR.AssignedReviewers))
+		}
+
+		resp = server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reviewers/add", `{"pull_request_id":"pr-1","user_id":"u2"}`))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("add reviewer status %d", resp.Code)
+		}
+	})
+
+	t.Run("targeted reassign", func(t *testing.T) {
+		resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reviewers/remove", `{"pull_request_id":"pr-1","user_id":"u4"}`))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("remove reviewer status %d", resp.Code)
+		}
+		resp = server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"u3","new_user_id":"u4"}`))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("targeted reassign status %d", resp.Code)
+		}
+		body := decodeBody[dto.ReassignResponse](t, resp.Body)
+		if body.ReplacedBy != "u4" {
+			t.Fatalf("replaced_by = %s, want u4", body.ReplacedBy)
+		}
+	})
+}
+
 // Нагрузочная проверка массовой деактивации и безопасных переназначений.
 // Средний объём данных: 10 команд по 10 активных ревьюверов (100 пользователей) и 30 открытых PR.
 // Требование: уложиться в 100 мс на операцию деактивации в этом объёме.
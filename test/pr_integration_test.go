@@ -1,8 +1,10 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Leganyst/avitoTrainee/internal/model"
 	"github.com/Leganyst/avitoTrainee/internal/repository"
@@ -17,8 +19,9 @@ func TestPRFlow_Create_Reassign_Merge(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	teamSvc := service.NewTeamService(repository.NewTeamRepository(db), userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), repository.NewTeamRepository(db), userRepo)
+	auditRepo := repository.NewAuditRepository(db)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), auditRepo)
 
 	members := []model.User{
 		{UserID: "u1", Username: "Alice", IsActive: true},
@@ -27,12 +30,12 @@ func TestPRFlow_Create_Reassign_Merge(t *testing.T) {
 		{UserID: "u4", Username: "Oleg", IsActive: true},
 	}
 
-	if _, err := teamSvc.CreateTeam("backend", members); err != nil {
+	if _, err := teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: members}); err != nil {
 		t.Fatalf("failed to create team: %v", err)
 	}
 
 	// act: create PR
-	pr, err := prSvc.CreatePR("pr-1", "Add search", "u1")
+	pr, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Add search", "u1")
 	if err != nil {
 		t.Fatalf("CreatePR returned error: %v", err)
 	}
@@ -52,7 +55,8 @@ func TestPRFlow_Create_Reassign_Merge(t *testing.T) {
 
 	// act: reassign одного ревьювера (кандидат - u4)
 	old := pr.AssignedReviewers[0].UserID
-	updated, replacedBy, err := prSvc.Reassign(pr.PRID, old)
+	beforeReassign := time.Now()
+	updated, replacedBy, err := prSvc.Reassign(context.Background(), service.Origin{}, pr.PRID, old, "")
 	if err != nil {
 		t.Fatalf("Reassign returned error: %v", err)
 	}
@@ -72,22 +76,51 @@ func TestPRFlow_Create_Reassign_Merge(t *testing.T) {
 		t.Fatalf("new reviewer not present in AssignedReviewers")
 	}
 
+	// assert: на момент до замены ревьювером числится old
+	past, err := prSvc.GetPR(context.Background(), pr.PRID, &beforeReassign)
+	if err != nil {
+		t.Fatalf("GetPR as of returned error: %v", err)
+	}
+	wasReviewer := false
+	for _, r := range past.AssignedReviewers {
+		wasReviewer = wasReviewer || r.UserID == old
+		if r.UserID == replacedBy {
+			t.Fatalf("replacement must not be a reviewer before reassign")
+		}
+	}
+	if !wasReviewer {
+		t.Fatalf("expected %s to be a reviewer as of %v", old, beforeReassign)
+	}
+
 	// act: merge (идемпотентность проверим повторным вызовом)
-	merged, err := prSvc.Merge(pr.PRID)
+	merged, err := prSvc.Merge(context.Background(), service.Origin{}, pr.PRID)
 	if err != nil {
 		t.Fatalf("Merge returned error: %v", err)
 	}
 	if merged.Status != "MERGED" {
 		t.Fatalf("expected MERGED, got %s", merged.Status)
 	}
-	if _, err := prSvc.Merge(pr.PRID); err != nil {
+	if _, err := prSvc.Merge(context.Background(), service.Origin{}, pr.PRID); err != nil {
 		t.Fatalf("second Merge should be idempotent, got %v", err)
 	}
 
 	// act: попытка reassign после MERGED
-	if _, _, err := prSvc.Reassign(pr.PRID, merged.AssignedReviewers[0].UserID); !errors.Is(err, serviceerrs.ErrPRMerged) {
+	if _, _, err := prSvc.Reassign(context.Background(), service.Origin{}, pr.PRID, merged.AssignedReviewers[0].UserID, ""); !errors.Is(err, serviceerrs.ErrPRMerged) {
 		t.Fatalf("expected ErrPRMerged after merge, got %v", err)
 	}
+
+	// assert: журнал содержит назначения, замену и один merge
+	events, err := auditRepo.Find(context.Background(), repository.AuditFilter{PRID: pr.PRID, Limit: 100})
+	if err != nil {
+		t.Fatalf("audit Find returned error: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	if counts[model.AuditAssigned] != len(pr.AssignedReviewers) || counts[model.AuditReplaced] != 1 || counts[model.AuditMerged] != 1 {
+		t.Fatalf("unexpected audit events: %+v", counts)
+	}
 }
 
 func TestPRFlow_Create_NoAuthor(t *testing.T) {
@@ -97,9 +130,9 @@ func TestPRFlow_Create_NoAuthor(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	prSvc := service.NewPrService(prRepo, userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
 
-	if _, err := prSvc.CreatePR("pr-x", "Feature", "missing"); !errors.Is(err, serviceerrs.ErrUserNotFound) {
+	if _, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-x", "Feature", "missing"); !errors.Is(err, serviceerrs.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
 }
@@ -111,15 +144,15 @@ func TestPRFlow_Create_Duplicate(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	teamSvc := service.NewTeamService(repository.NewTeamRepository(db), userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), repository.NewTeamRepository(db), userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
 
-	_, _ = teamSvc.CreateTeam("backend", []model.User{{UserID: "u1", Username: "Alice", IsActive: true}})
-	if _, err := prSvc.CreatePR("pr-1", "Feature", "u1"); err != nil {
+	_, _ = teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: []model.User{{UserID: "u1", Username: "Alice", IsActive: true}}})
+	if _, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Feature", "u1"); err != nil {
 		t.Fatalf("first CreatePR err: %v", err)
 	}
 	// повтор создания PR
-	if _, err := prSvc.CreatePR("pr-1", "Feature", "u1"); !errors.Is(err, serviceerrs.ErrPRExists) {
+	if _, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Feature", "u1"); !errors.Is(err, serviceerrs.ErrPRExists) {
 		t.Fatalf("expected ErrPRExists, got %v", err)
 	}
 }
@@ -132,19 +165,19 @@ func TestPRFlow_Reassign_NoCandidates(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), teamRepo, userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
 
 	// только один активный кроме автора -> кандидатов нет
-	_, _ = teamSvc.CreateTeam("backend", []model.User{
+	_, _ = teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: []model.User{
 		{UserID: "u1", Username: "Alice", IsActive: true},
 		{UserID: "u2", Username: "Bob", IsActive: true},
-	})
-	pr, err := prSvc.CreatePR("pr-1", "Feature", "u1")
+	}})
+	pr, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Feature", "u1")
 	if err != nil {
 		t.Fatalf("CreatePR err: %v", err)
 	}
-	if _, _, err := prSvc.Reassign(pr.PRID, pr.AssignedReviewers[0].UserID); !errors.Is(err, serviceerrs.ErrNoCandidates) {
+	if _, _, err := prSvc.Reassign(context.Background(), service.Origin{}, pr.PRID, pr.AssignedReviewers[0].UserID, ""); !errors.Is(err, serviceerrs.ErrNoCandidates) {
 		t.Fatalf("expected ErrNoCandidates, got %v", err)
 	}
 }
@@ -157,19 +190,19 @@ func TestPRFlow_Reassign_ReviewerMissing(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), teamRepo, userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
 
-	_, _ = teamSvc.CreateTeam("backend", []model.User{
+	_, _ = teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: []model.User{
 		{UserID: "u1", Username: "Alice", IsActive: true},
 		{UserID: "u2", Username: "Bob", IsActive: true},
 		{UserID: "u3", Username: "Eve", IsActive: true},
-	})
-	pr, err := prSvc.CreatePR("pr-1", "Feature", "u1")
+	}})
+	pr, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Feature", "u1")
 	if err != nil {
 		t.Fatalf("CreatePR err: %v", err)
 	}
-	if _, _, err := prSvc.Reassign(pr.PRID, "unknown"); !errors.Is(err, serviceerrs.ErrReviewerMissing) {
+	if _, _, err := prSvc.Reassign(context.Background(), service.Origin{}, pr.PRID, "unknown", ""); !errors.Is(err, serviceerrs.ErrReviewerMissing) {
 		t.Fatalf("expected ErrReviewerMissing, got %v", err)
 	}
 }
@@ -182,26 +215,26 @@ func TestPR_Reassign_To_Merged_PR(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	prRepo := repository.NewPRRepository(db)
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	prSvc := service.NewPrService(prRepo, userRepo)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), teamRepo, userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), prRepo, userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
 
-	_, _ = teamSvc.CreateTeam("backend", []model.User{
+	_, _ = teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: []model.User{
 		{UserID: "u1", Username: "Alice", IsActive: true},
 		{UserID: "u2", Username: "Bob", IsActive: true},
 		{UserID: "u3", Username: "Eve", IsActive: true},
-	})
+	}})
 
-	pr, err := prSvc.CreatePR("pr-1", "Feature", "u1")
+	pr, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Feature", "u1")
 	if err != nil {
 		t.Fatalf("CreatePR err: %v", err)
 	}
 
-	pr, err = prSvc.Merge(pr.PRID)
+	pr, err = prSvc.Merge(context.Background(), service.Origin{}, pr.PRID)
 	if err != nil {
 		t.Fatalf("Merge PR err: %v", err)
 	}
 
-	if _, _, err = prSvc.Reassign(pr.PRID, pr.AssignedReviewers[0].UserID); !errors.Is(err, serviceerrs.ErrPRMerged) {
+	if _, _, err = prSvc.Reassign(context.Background(), service.Origin{}, pr.PRID, pr.AssignedReviewers[0].UserID, ""); !errors.Is(err, serviceerrs.ErrPRMerged) {
 		t.Fatalf("This PR not reassigned viewers, err: %v", err)
 	}
 }
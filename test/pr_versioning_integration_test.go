@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/controller/dto"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+)
+
+func TestPRController_IfMatch(t *testing.T) {
+	server := newAPITestServer(t)
+
+	teamPayload := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Charlie","is_active":true},{"user_id":"u4","username":"Oleg","is_active":true}]}`
+	resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/team/add", teamPayload))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create team status = %d", resp.Code)
+	}
+
+	resp = server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create PR status = %d", resp.Code)
+	}
+	created := decodeBody[dto.CreatePRResponse](t, resp.Body)
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag on created PR")
+	}
+
+	reassignPayload := fmt.Sprintf(`{"pull_request_id":"pr-1","old_user_id":"%s"}`, created.PR.AssignedReviewers[0])
+	req := newJSONRequest(t, http.MethodPost, "/api/pullRequest/reassign", reassignPayload)
+	req.Header.Set("If-Match", etag)
+	resp = server.doRequest(req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("reassign with current ETag status = %d, want 200", resp.Code)
+	}
+	if next := resp.Header().Get("ETag"); next == "" || next == etag {
+		t.Fatalf("expected a new ETag after reassign, got %q", next)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/pullRequest/merge", `{"pull_request_id":"pr-1"}`)
+	req.Header.Set("If-Match", etag)
+	assertErrorResponse(t, server.doRequest(req), http.StatusPreconditionFailed, "PRECONDITION_FAILED")
+}
+
+func TestPRRepository_StaleVersionConflicts(t *testing.T) {
+	db := connectTestDB(t)
+	prepareDB(t, db)
+
+	ctx := context.Background()
+	teamRepo := repository.NewTeamRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	prRepo := repository.NewPRRepository(db)
+
+	team := &model.Team{Name: "backend"}
+	if err := teamRepo.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	users := make([]model.User, 0, 4)
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		u := model.User{UserID: id, Username: id, TeamID: team.ID, IsActive: true}
+		if err := userRepo.CreateOrUpdate(ctx, &u); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		users = append(users, u)
+	}
+	pr := &model.PullRequest{PRID: "pr-1", Name: "Add search", Status: "OPEN", AuthorID: users[0].ID}
+	if err := prRepo.CreatePR(ctx, pr); err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+	if err := prRepo.AddReviewers(ctx, pr, users[1:2]); err != nil {
+		t.Fatalf("add reviewers: %v", err)
+	}
+
+	// Два конкурентных запроса прочитали одну и ту же версию PR.
+	first, err := prRepo.GetPRByExternalID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("get PR: %v", err)
+	}
+	second := *first
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, candidate := range []struct {
+		pr   *model.PullRequest
+		user model.User
+	}{{first, users[2]}, {&second, users[3]}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = prRepo.ReplaceReviewer(ctx, candidate.pr, users[1].ID, candidate.user)
+		}()
+	}
+	wg.Wait()
+
+	conflicts := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, repoerrs.ErrConflict):
+			conflicts++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if conflicts != 1 {
+		t.Fatalf("expected exactly one writer to lose the race, got %d conflicts", conflicts)
+	}
+
+	got, err := prRepo.GetPRByExternalID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("get PR: %v", err)
+	}
+	if len(got.AssignedReviewers) != 1 {
+		t.Fatalf("expected a single reviewer after the race, got %d", len(got.AssignedReviewers))
+	}
+	if got.Version != pr.Version+1 {
+		t.Fatalf("expected version %d, got %d", pr.Version+1, got.Version)
+	}
+}
@@ -0,0 +1,1081 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/config"
+	appdb "github.com/Leganyst/avitoTrainee/internal/db"
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	repoerrs "github.com/Leganyst/avitoTrainee/internal/repository/errs"
+	"github.com/Leganyst/avitoTrainee/internal/repository/memory"
+	"gorm.io/gorm"
+)
+
+// repositoryBackend — набор репозиториев одного хранилища. Общий набор проверок прогоняется
+// на каждом хранилище, чтобы сервисы видели одинаковое поведение независимо от STORAGE.
+type repositoryBackend struct {
+	team         repository.TeamRepository
+	user         repository.UserRepository
+	pr           repository.PRRepository
+	stats        repository.StatsRepository
+	availability repository.AvailabilityRepository
+	audit        repository.AuditRepository
+	idempotency  repository.IdempotencyRepository
+	job          repository.JobRepository
+	uow          repository.UnitOfWork
+	// db — подключение SQL-хранилища для записи в обход репозиториев; у хранилища в памяти nil.
+	db *gorm.DB
+}
+
+func gormBackend(db *gorm.DB) *repositoryBackend {
+	return &repositoryBackend{
+		team:         repository.NewTeamRepository(db),
+		user:         repository.NewUserRepository(db),
+		pr:           repository.NewPRRepository(db),
+		stats:        repository.NewStatsRepository(db),
+		availability: repository.NewAvailabilityRepository(db),
+		audit:        repository.NewAuditRepository(db),
+		idempotency:  repository.NewIdempotencyRepository(db),
+		job:          repository.NewJobRepository(db),
+		uow:          repository.NewUnitOfWork(db),
+		db:           db,
+	}
+}
+
+func TestRepositoryConformance_Postgres(t *testing.T) {
+	db := connectTestDB(t)
+	runRepositoryConformance(t, func(t *testing.T) *repositoryBackend {
+		prepareDB(t, db)
+		return gormBackend(db)
+	})
+}
+
+func TestRepositoryConformance_SQLite(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) *repositoryBackend {
+		db, err := appdb.Connect(&config.Config{
+			Storage:    config.StorageSQLite,
+			SQLitePath: filepath.Join(t.TempDir(), "conformance.db"),
+		})
+		if err != nil {
+			t.Fatalf("failed to open SQLite: %v", err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		})
+		migrateTestDB(t, db)
+		return gormBackend(db)
+	})
+}
+
+func TestRepositoryConformance_Memory(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) *repositoryBackend {
+		store := memory.NewStore()
+		return &repositoryBackend{
+			team:         memory.NewTeamRepository(store),
+			user:         memory.NewUserRepository(store),
+			pr:           memory.NewPRRepository(store),
+			stats:        memory.NewStatsRepository(store),
+			availability: memory.NewAvailabilityRepository(store),
+			audit:        memory.NewAuditRepository(store),
+			idempotency:  memory.NewIdempotencyRepository(store),
+			job:          memory.NewJobRepository(store),
+			uow:          memory.NewUnitOfWork(store),
+		}
+	})
+}
+
+// runRepositoryConformance запускает проверки на пустом хранилище, которое open создаёт для каждой проверки.
+func runRepositoryConformance(t *testing.T, open func(t *testing.T) *repositoryBackend) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, b *repositoryBackend)
+	}{
+		{"Teams", conformTeams},
+		{"Users", conformUsers},
+		{"PullRequests", conformPullRequests},
+		{"ReviewerInvariants", conformReviewerInvariants},
+		{"Stats", conformStats},
+		{"Availability", conformAvailability},
+		{"Audit", conformAudit},
+		{"Idempotency", conformIdempotency},
+		{"Jobs", conformJobs},
+		{"UnitOfWork", conformUnitOfWork},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, open(t))
+		})
+	}
+}
+
+// seedTeam создаёт команду с активными участниками и возвращает участников с выданными ID.
+func seedTeam(t *testing.T, b *repositoryBackend, name string, userIDs ...string) (*model.Team, []model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	team := &model.Team{Name: name}
+	if err := b.team.CreateTeam(ctx, team); err != nil {
+		t.Fatalf("create team %s: %v", name, err)
+	}
+	users := make([]model.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		user := &model.User{UserID: id, Username: "user " + id, TeamID: team.ID, IsActive: true}
+		if err := b.user.CreateOrUpdate(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", id, err)
+		}
+		users = append(users, *user)
+	}
+	return team, users
+}
+
+// seedPR создаёт открытый PR автора author с ревьюверами reviewers и возвращает его в свежем состоянии.
+func seedPR(t *testing.T, b *repositoryBackend, prID string, author model.User, reviewers ...model.User) *model.PullRequest {
+	t.Helper()
+	ctx := context.Background()
+
+	pr := &model.PullRequest{PRID: prID, Name: "PR " + prID, Status: "OPEN", AuthorID: author.ID}
+	if err := b.pr.CreatePR(ctx, pr); err != nil {
+		t.Fatalf("create PR %s: %v", prID, err)
+	}
+	if err := b.pr.AddReviewers(ctx, pr, reviewers); err != nil {
+		t.Fatalf("add reviewers to %s: %v", prID, err)
+	}
+	return loadPR(t, b, prID)
+}
+
+func loadPR(t *testing.T, b *repositoryBackend, prID string) *model.PullRequest {
+	t.Helper()
+	pr, err := b.pr.GetPRByExternalID(context.Background(), prID)
+	if err != nil {
+		t.Fatalf("get PR %s: %v", prID, err)
+	}
+	return pr
+}
+
+func userIDsOf(users []model.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func conformTeams(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	team, _ := seedTeam(t, b, "backend", "u2", "u1")
+
+	if team.MaxReviewers != 2 || team.AssignmentMode != "random" {
+		t.Fatalf("expected default team settings, got max=%d mode=%q", team.MaxReviewers, team.AssignmentMode)
+	}
+	if err := b.team.CreateTeam(ctx, &model.Team{Name: "backend"}); !errors.Is(err, repoerrs.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for existing team, got %v", err)
+	}
+
+	loaded, err := b.team.GetTeamByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if got := userIDsOf(loaded.Users); !slices.Equal(got, []string{"u1", "u2"}) {
+		t.Fatalf("expected team members u1, u2, got %v", got)
+	}
+	if _, err := b.team.GetTeamByName(ctx, "missing"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing team, got %v", err)
+	}
+	for name, want := range map[string]bool{"backend": true, "missing": false} {
+		exists, err := b.team.TeamExists(ctx, name)
+		if err != nil {
+			t.Fatalf("team exists: %v", err)
+		}
+		if exists != want {
+			t.Fatalf("team %q: expected exists=%v, got %v", name, want, exists)
+		}
+	}
+
+	if err := b.team.SetMaxReviewers(ctx, team.ID, 3); err != nil {
+		t.Fatalf("set max reviewers: %v", err)
+	}
+	if loaded, err = b.team.GetTeamByName(ctx, "backend"); err != nil || loaded.MaxReviewers != 3 {
+		t.Fatalf("expected max reviewers 3, got %+v, %v", loaded, err)
+	}
+	if err := b.team.SetMaxReviewers(ctx, team.ID+100, 3); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing team, got %v", err)
+	}
+}
+
+func conformUsers(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	team, users := seedTeam(t, b, "backend", "u1", "u2", "u3")
+
+	if users[0].TimeZone != "UTC" || users[0].Seniority != "middle" {
+		t.Fatalf("expected default user settings, got tz=%q seniority=%q", users[0].TimeZone, users[0].Seniority)
+	}
+	renamed := &model.User{UserID: "u1", Username: "Alice", TeamID: team.ID, IsActive: true}
+	if err := b.user.CreateOrUpdate(ctx, renamed); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	if renamed.ID != users[0].ID {
+		t.Fatalf("expected update to keep ID %d, got %d", users[0].ID, renamed.ID)
+	}
+	loaded, err := b.user.GetByUserID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if loaded.Username != "Alice" || loaded.Team.Name != "backend" {
+		t.Fatalf("expected renamed user with team, got %q in %q", loaded.Username, loaded.Team.Name)
+	}
+	if _, err := b.user.GetByUserID(ctx, "missing"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing user, got %v", err)
+	}
+
+	if _, err := b.user.SetActive(ctx, "u2", false); err != nil {
+		t.Fatalf("set active: %v", err)
+	}
+	active, err := b.user.GetActiveUsersByTeam(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("get active users: %v", err)
+	}
+	if got := userIDsOf(active); !slices.Equal(got, []string{"u1", "u3"}) {
+		t.Fatalf("expected active u1, u3, got %v", got)
+	}
+
+	updated, err := b.user.SetWorkingHours(ctx, "u3", "Europe/Moscow", "10:00", "19:00")
+	if err != nil {
+		t.Fatalf("set working hours: %v", err)
+	}
+	if updated.TimeZone != "Europe/Moscow" || updated.WorkStart != "10:00" || updated.WorkEnd != "19:00" {
+		t.Fatalf("unexpected working hours %+v", updated)
+	}
+
+	deactivated, err := b.user.BulkDeactivate(ctx, team.ID, []string{"u1", "u3", "missing"})
+	if err != nil {
+		t.Fatalf("bulk deactivate: %v", err)
+	}
+	if got := userIDsOf(deactivated); !slices.Equal(got, []string{"u1", "u3"}) {
+		t.Fatalf("expected u1, u3 deactivated, got %v", got)
+	}
+	if _, err := b.user.BulkDeactivate(ctx, team.ID, []string{"missing"}); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when no user matches, got %v", err)
+	}
+	members, err := b.user.GetUsersByTeam(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("get team users: %v", err)
+	}
+	for _, u := range members {
+		if u.IsActive {
+			t.Fatalf("expected every member inactive, %s is active", u.UserID)
+		}
+	}
+}
+
+func conformPullRequests(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	_, users := seedTeam(t, b, "backend", "author", "r1", "r2", "r3")
+	author, r1, r2, r3 := users[0], users[1], users[2], users[3]
+
+	pr := seedPR(t, b, "pr-1", author, r1, r2)
+	if pr.Version != 2 {
+		t.Fatalf("expected version 2 after create and add reviewers, got %d", pr.Version)
+	}
+	if pr.Author.Team.Name != "backend" {
+		t.Fatalf("expected author with team preloaded, got %+v", pr.Author)
+	}
+	if got := userIDsOf(pr.AssignedReviewers); !slices.Equal(got, []string{"r1", "r2"}) {
+		t.Fatalf("expected reviewers r1, r2, got %v", got)
+	}
+	if err := b.pr.CreatePR(ctx, &model.PullRequest{PRID: "pr-1", Name: "again", Status: "OPEN", AuthorID: author.ID}); !errors.Is(err, repoerrs.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for existing PR, got %v", err)
+	}
+	if _, err := b.pr.GetPRByExternalID(ctx, "missing"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing PR, got %v", err)
+	}
+
+	stale := loadPR(t, b, "pr-1")
+	beforeReplace := time.Now()
+	if err := b.pr.ReplaceReviewer(ctx, pr, r1.ID, r3); err != nil {
+		t.Fatalf("replace reviewer: %v", err)
+	}
+	pr = loadPR(t, b, "pr-1")
+	if got := userIDsOf(pr.AssignedReviewers); !slices.Equal(got, []string{"r2", "r3"}) {
+		t.Fatalf("expected reviewers r2, r3 after replace, got %v", got)
+	}
+	stale.Name = "stale"
+	if err := b.pr.UpdatePR(ctx, stale); !errors.Is(err, repoerrs.ErrConflict) {
+		t.Fatalf("expected ErrConflict for stale version, got %v", err)
+	}
+
+	periods, err := b.pr.GetReviewerPeriodsAt(ctx, pr.ID, beforeReplace)
+	if err != nil {
+		t.Fatalf("reviewer periods: %v", err)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("expected two periods active before replace, got %+v", periods)
+	}
+	history, err := b.pr.GetPRsWhereReviewerAt(ctx, r1.ID, beforeReplace)
+	if err != nil {
+		t.Fatalf("PRs where reviewer at: %v", err)
+	}
+	if len(history) != 1 || history[0].PRID != "pr-1" {
+		t.Fatalf("expected r1 to have reviewed pr-1 before replace, got %+v", history)
+	}
+
+	first, err := b.pr.MarkReviewed(ctx, pr, r2.ID, time.Now())
+	if err != nil {
+		t.Fatalf("mark reviewed: %v", err)
+	}
+	again, err := b.pr.MarkReviewed(ctx, loadPR(t, b, "pr-1"), r2.ID, time.Now())
+	if err != nil {
+		t.Fatalf("mark reviewed again: %v", err)
+	}
+	if !first || again {
+		t.Fatalf("expected only the first review action to be recorded, got %v then %v", first, again)
+	}
+
+	pr = loadPR(t, b, "pr-1")
+	if err := b.pr.RemoveReviewer(ctx, pr, r1.ID); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for removing unassigned reviewer, got %v", err)
+	}
+	pr = loadPR(t, b, "pr-1")
+	if err := b.pr.RemoveReviewer(ctx, pr, r3.ID); err != nil {
+		t.Fatalf("remove reviewer: %v", err)
+	}
+
+	reviewing, err := b.pr.GetPRsWhereReviewer(ctx, r2.ID)
+	if err != nil {
+		t.Fatalf("PRs where reviewer: %v", err)
+	}
+	if len(reviewing) != 1 || reviewing[0].PRID != "pr-1" {
+		t.Fatalf("expected r2 to review pr-1, got %+v", reviewing)
+	}
+
+	open, err := b.pr.GetOpenPRs(ctx)
+	if err != nil {
+		t.Fatalf("open PRs: %v", err)
+	}
+	if len(open) != 1 || open[0].PRID != "pr-1" || open[0].Author.Team.Name != "backend" ||
+		!slices.Equal(userIDsOf(open[0].AssignedReviewers), []string{"r2"}) || len(open[0].ReviewerLinks) != 1 {
+		t.Fatalf("expected pr-1 with author team and reviewer r2, got %+v", open)
+	}
+
+	pr = loadPR(t, b, "pr-1")
+	pr.Status = "MERGED"
+	if err := b.pr.UpdatePR(ctx, pr); err != nil {
+		t.Fatalf("merge PR: %v", err)
+	}
+	if open, err = b.pr.GetOpenPRsByReviewerIDs(ctx, []uint{r2.ID}); err != nil {
+		t.Fatalf("open PRs by reviewers: %v", err)
+	}
+	if len(open) != 0 {
+		t.Fatalf("expected no open PRs after merge, got %+v", open)
+	}
+	if open, err = b.pr.GetOpenPRs(ctx); err != nil || len(open) != 0 {
+		t.Fatalf("expected no open PRs after merge, got %+v, %v", open, err)
+	}
+}
+
+func conformReviewerInvariants(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	other, outsiders := seedTeam(t, b, "frontend", "o1")
+	_, users := seedTeam(t, b, "backend", "author", "r1", "r2", "r3")
+	author, r1, r2, r3, o1 := users[0], users[1], users[2], users[3], outsiders[0]
+
+	seedPR(t, b, "pr-1", author, r1)
+	expectInvariant := func(want string, err error) {
+		t.Helper()
+		var invariantErr *repoerrs.ReviewerInvariantError
+		if !errors.As(err, &invariantErr) || invariantErr.Invariant != want || !errors.Is(err, repoerrs.ErrConstraint) {
+			t.Fatalf("expected reviewer invariant %q, got %v", want, err)
+		}
+	}
+
+	expectInvariant(repoerrs.InvariantReviewerIsAuthor, b.pr.AddReviewers(ctx, loadPR(t, b, "pr-1"), []model.User{author}))
+	expectInvariant(repoerrs.InvariantReviewerTeam, b.pr.AddReviewers(ctx, loadPR(t, b, "pr-1"), []model.User{o1}))
+	expectInvariant(repoerrs.InvariantReviewerTeam, b.pr.ReplaceReviewer(ctx, loadPR(t, b, "pr-1"), r1.ID, o1))
+	expectInvariant(repoerrs.InvariantReviewerIsAuthor, b.pr.ReplaceReviewers(ctx, loadPR(t, b, "pr-1"), []model.PRReviewer{{UserID: author.ID}}))
+	if err := b.pr.AddReviewers(ctx, loadPR(t, b, "pr-1"), []model.User{r2}); err != nil {
+		t.Fatalf("add reviewer within limit: %v", err)
+	}
+	expectInvariant(repoerrs.InvariantReviewerLimit, b.pr.AddReviewers(ctx, loadPR(t, b, "pr-1"), []model.User{r3}))
+	// Повторная запись уже назначенного ревьювера состав не меняет и лимит не нарушает.
+	if err := b.pr.AddReviewers(ctx, loadPR(t, b, "pr-1"), []model.User{r1}); err != nil {
+		t.Fatalf("re-adding assigned reviewer: %v", err)
+	}
+
+	pr := loadPR(t, b, "pr-1")
+	if got := userIDsOf(pr.AssignedReviewers); !slices.Equal(got, []string{"r1", "r2"}) {
+		t.Fatalf("expected rejected writes to leave r1, r2, got %v", got)
+	}
+	if pr.Version != 4 {
+		t.Fatalf("expected only successful writes to bump the version to 4, got %d", pr.Version)
+	}
+
+	// Ревьювер ушёл в другую команду, его период на PR уже записан. Команда ревьювера допустимой не становится:
+	// на PR назначаются только участники команды автора.
+	r2.TeamID = other.ID
+	if err := b.user.CreateOrUpdate(ctx, &r2); err != nil {
+		t.Fatalf("move r2 to another team: %v", err)
+	}
+	if periods, err := b.pr.GetReviewerPeriodsAt(ctx, pr.ID, time.Now()); err != nil || len(periods) != 2 {
+		t.Fatalf("expected reviewer periods of r1 and r2, got %+v, %v", periods, err)
+	}
+	expectInvariant(repoerrs.InvariantReviewerTeam, b.pr.ReplaceReviewer(ctx, loadPR(t, b, "pr-1"), r2.ID, o1))
+	if got := userIDsOf(loadPR(t, b, "pr-1").AssignedReviewers); !slices.Equal(got, []string{"r1", "r2"}) {
+		t.Fatalf("expected rejected replace to leave r1, r2, got %v", got)
+	}
+	// Замена ушедшего ревьювера участником команды автора допустима.
+	if err := b.pr.ReplaceReviewer(ctx, loadPR(t, b, "pr-1"), r2.ID, r3); err != nil {
+		t.Fatalf("replace reviewer who moved teams with the author's teammate: %v", err)
+	}
+	if got := userIDsOf(loadPR(t, b, "pr-1").AssignedReviewers); !slices.Equal(got, []string{"r1", "r3"}) {
+		t.Fatalf("expected reviewers r1, r3 after replace, got %v", got)
+	}
+
+	if b.db == nil {
+		return
+	}
+	// Прямая запись в обход репозитория тоже проверяется.
+	err := b.db.Exec("INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES (?, ?, ?)", pr.ID, author.ID, time.Now()).Error
+	if err == nil {
+		t.Fatalf("expected direct insert of the author as reviewer to fail")
+	}
+	err = b.db.Exec("UPDATE pr_reviewers SET user_id = ? WHERE pull_request_id = ? AND user_id = ?", o1.ID, pr.ID, r3.ID).Error
+	if err == nil || !strings.Contains(err.Error(), "reviewer_invariant:team") {
+		t.Fatalf("expected direct write of a reviewer from another team to fail, got %v", err)
+	}
+	if err := b.db.Exec("DELETE FROM pr_reviewers WHERE pull_request_id = ? AND user_id = ?", pr.ID, r3.ID).Error; err != nil {
+		t.Fatalf("delete r3: %v", err)
+	}
+	err = b.db.Exec("INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES (?, ?, ?)", pr.ID, o1.ID, time.Now()).Error
+	if err == nil || !strings.Contains(err.Error(), "reviewer_invariant:team") {
+		t.Fatalf("expected direct insert of a reviewer from another team to fail, got %v", err)
+	}
+}
+
+func conformStats(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	team, users := seedTeam(t, b, "backend", "author", "r1", "r2", "r3")
+	empty, _ := seedTeam(t, b, "empty")
+	author, r1, r2, r3 := users[0], users[1], users[2], users[3]
+
+	from := time.Now().Add(-time.Minute)
+	pr := seedPR(t, b, "pr-1", author, r1, r2)
+	if err := b.pr.ReplaceReviewer(ctx, pr, r1.ID, r3); err != nil {
+		t.Fatalf("replace reviewer: %v", err)
+	}
+	seedPR(t, b, "pr-2", author, r2)
+	reviewedAt := time.Now()
+	if _, err := b.pr.MarkReviewed(ctx, loadPR(t, b, "pr-1"), r2.ID, reviewedAt); err != nil {
+		t.Fatalf("mark reviewed: %v", err)
+	}
+	if _, err := b.pr.MarkReviewed(ctx, loadPR(t, b, "pr-1"), r3.ID, reviewedAt.Add(time.Second)); err != nil {
+		t.Fatalf("mark reviewed: %v", err)
+	}
+	to := time.Now().Add(time.Minute)
+
+	assertByUser := func(stage string) {
+		t.Helper()
+		byUser, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{})
+		if err != nil {
+			t.Fatalf("%s: assignments by user: %v", stage, err)
+		}
+		want := []repository.AssignmentStatByUser{
+			{UserID: "r2", Username: "user r2", Assignments: 2},
+			{UserID: "r3", Username: "user r3", Assignments: 1},
+		}
+		if !slices.Equal(byUser, want) {
+			t.Fatalf("%s: expected %+v, got %+v", stage, want, byUser)
+		}
+	}
+	assertByUser("counters")
+	if err := b.stats.RebuildAssignmentStats(ctx); err != nil {
+		t.Fatalf("rebuild stats: %v", err)
+	}
+	assertByUser("rebuilt")
+
+	// Статистика по пользователям считает дни создания PR, поэтому период задаётся целыми сутками UTC.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tomorrow := today.Add(24 * time.Hour)
+	byPeriod, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{From: &today, To: &tomorrow, Sort: repository.SortUserIDDesc})
+	if err != nil {
+		t.Fatalf("assignments by user in period: %v", err)
+	}
+	if len(byPeriod) != 2 || byPeriod[0].UserID != "r3" {
+		t.Fatalf("expected r3, r2 in period, got %+v", byPeriod)
+	}
+	if byPeriod, err = b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{To: &today}); err != nil || len(byPeriod) != 0 {
+		t.Fatalf("expected no assignments on PRs created before today, got %+v, %v", byPeriod, err)
+	}
+	byPR, err := b.stats.GetAssignmentsByPR(ctx, repository.AssignmentStatsFilter{TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("assignments by PR: %v", err)
+	}
+	for i := range byPR {
+		if byPR[i].CreatedAt.IsZero() {
+			t.Fatalf("expected PR creation time in %+v", byPR[i])
+		}
+		byPR[i].CreatedAt = time.Time{}
+	}
+	wantByPR := []repository.AssignmentStatByPR{{PRID: "pr-1", Name: "PR pr-1", Reviewers: 2}, {PRID: "pr-2", Name: "PR pr-2", Reviewers: 1}}
+	if !slices.Equal(byPR, wantByPR) {
+		t.Fatalf("expected %+v, got %+v", wantByPR, byPR)
+	}
+
+	// Постраничный обход по ключу последней строки повторяет полную выборку при любой сортировке,
+	// в том числе со смешанными направлениями ключей и сравнением времени.
+	for _, sort := range []string{repository.SortReviewersDesc, repository.SortReviewersAsc, repository.SortPRIDDesc, repository.SortCreatedAtDesc, repository.SortCreatedAtAsc} {
+		full, err := b.stats.GetAssignmentsByPR(ctx, repository.AssignmentStatsFilter{Sort: sort})
+		if err != nil {
+			t.Fatalf("%s: assignments by PR: %v", sort, err)
+		}
+		var paged []repository.AssignmentStatByPR
+		filter := repository.AssignmentStatsFilter{Sort: sort, Limit: 1}
+		for {
+			page, err := b.stats.GetAssignmentsByPR(ctx, filter)
+			if err != nil {
+				t.Fatalf("%s: assignments by PR page: %v", sort, err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			paged = append(paged, page...)
+			last := page[len(page)-1]
+			filter.After = &repository.StatsCursor{Count: last.Reviewers, CreatedAt: last.CreatedAt, ID: last.PRID}
+		}
+		if len(paged) != len(full) {
+			t.Fatalf("%s: expected pages to cover %+v, got %+v", sort, full, paged)
+		}
+		for i := range full {
+			if paged[i].PRID != full[i].PRID {
+				t.Fatalf("%s: expected pages to cover %+v, got %+v", sort, full, paged)
+			}
+		}
+	}
+	afterR2, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{
+		After: &repository.StatsCursor{Count: 2, ID: "r2"},
+	})
+	if err != nil || len(afterR2) != 1 || afterR2[0].UserID != "r3" {
+		t.Fatalf("expected only r3 after r2, got %+v, %v", afterR2, err)
+	}
+
+	churn, err := b.stats.GetPRChurn(ctx, repository.AssignmentStatsFilter{})
+	if err != nil {
+		t.Fatalf("PR churn: %v", err)
+	}
+	if len(churn) != 1 || churn[0].PRID != "pr-1" || churn[0].Removals != 1 || churn[0].DistinctReviewers != 3 {
+		t.Fatalf("expected one removal among three reviewers of pr-1, got %+v", churn)
+	}
+
+	samples, err := b.stats.GetReviewActionSamples(ctx, from, to)
+	if err != nil {
+		t.Fatalf("review action samples: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected two review actions, got %+v", samples)
+	}
+	for _, s := range samples {
+		if !s.FirstReviewedAt.Equal(samples[0].ReviewedAt) || s.PRCreatedAt.IsZero() || s.AssignedAt.IsZero() {
+			t.Fatalf("expected first review time of pr-1 in every sample, got %+v", samples)
+		}
+	}
+	reassignments, err := b.stats.GetReassignmentSamples(ctx, from, to)
+	if err != nil {
+		t.Fatalf("reassignment samples: %v", err)
+	}
+	if len(reassignments) != 1 || reassignments[0].ReviewerID != "r1" {
+		t.Fatalf("expected r1 replaced on pr-1, got %+v", reassignments)
+	}
+
+	open, err := b.stats.GetOpenReviewAssignments(ctx)
+	if err != nil {
+		t.Fatalf("open review assignments: %v", err)
+	}
+	if len(open) != 3 {
+		t.Fatalf("expected three open review assignments, got %+v", open)
+	}
+	load, err := b.stats.GetTeamLoad(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("team load: %v", err)
+	}
+	wantLoad := []repository.MemberLoad{
+		{UserID: "author", Username: "user author", IsActive: true},
+		{UserID: "r1", Username: "user r1", IsActive: true, TotalAssignments: 1},
+		{UserID: "r2", Username: "user r2", IsActive: true, OpenAssignments: 2, TotalAssignments: 2},
+		{UserID: "r3", Username: "user r3", IsActive: true, OpenAssignments: 1, TotalAssignments: 1},
+	}
+	if !slices.Equal(load, wantLoad) {
+		t.Fatalf("expected team load %+v, got %+v", wantLoad, load)
+	}
+	byTeam, err := b.stats.GetOpenPRsByTeam(ctx)
+	if err != nil {
+		t.Fatalf("open PRs by team: %v", err)
+	}
+	wantByTeam := []repository.TeamOpenPRs{{TeamName: "backend", OpenPRs: 2}, {TeamName: "empty"}}
+	if !slices.Equal(byTeam, wantByTeam) {
+		t.Fatalf("expected %+v, got %+v", wantByTeam, byTeam)
+	}
+
+	pr = loadPR(t, b, "pr-2")
+	pr.Status = "MERGED"
+	mergedAt := time.Now()
+	pr.MergedAt = &mergedAt
+	if err := b.pr.UpdatePR(ctx, pr); err != nil {
+		t.Fatalf("merge PR: %v", err)
+	}
+	merges, err := b.stats.GetMergeSamples(ctx, from, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("merge samples: %v", err)
+	}
+	if len(merges) != 1 || merges[0].PRID != "pr-2" || merges[0].TeamName != "backend" || merges[0].MergedAt.Before(merges[0].CreatedAt) {
+		t.Fatalf("expected merge of pr-2, got %+v", merges)
+	}
+	merged, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{Status: "MERGED"})
+	if err != nil {
+		t.Fatalf("merged assignments by user: %v", err)
+	}
+	if len(merged) != 1 || merged[0].UserID != "r2" || merged[0].Assignments != 1 {
+		t.Fatalf("expected one merged assignment of r2, got %+v", merged)
+	}
+
+	// Назначения на PR автора считаются за его нынешней командой.
+	author.TeamID = empty.ID
+	if err := b.user.CreateOrUpdate(ctx, &author); err != nil {
+		t.Fatalf("move author to another team: %v", err)
+	}
+	for teamName, want := range map[string]int{"backend": 0, "empty": 2} {
+		byTeam, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{TeamName: teamName})
+		if err != nil {
+			t.Fatalf("assignments by user of %s: %v", teamName, err)
+		}
+		if len(byTeam) != want {
+			t.Fatalf("expected %d reviewers on PRs of %s after the author moved, got %+v", want, teamName, byTeam)
+		}
+	}
+
+	if b.db == nil {
+		return
+	}
+	// Удаление в обход репозиториев, в том числе каскадное, тоже вычитается из счётчиков.
+	assertCounters := func(stage string, wantByUser []repository.AssignmentStatByUser, wantByPR []repository.AssignmentStatByPR) {
+		t.Helper()
+		for _, rebuild := range []bool{false, true} {
+			if rebuild {
+				if err := b.stats.RebuildAssignmentStats(ctx); err != nil {
+					t.Fatalf("%s: rebuild stats: %v", stage, err)
+				}
+			}
+			byUser, err := b.stats.GetAssignmentsByUser(ctx, repository.AssignmentStatsFilter{})
+			if err != nil {
+				t.Fatalf("%s: assignments by user: %v", stage, err)
+			}
+			byPR, err := b.stats.GetAssignmentsByPR(ctx, repository.AssignmentStatsFilter{})
+			if err != nil {
+				t.Fatalf("%s: assignments by PR: %v", stage, err)
+			}
+			for i := range byPR {
+				byPR[i].CreatedAt = time.Time{}
+			}
+			if !slices.Equal(byUser, wantByUser) || !slices.Equal(byPR, wantByPR) {
+				t.Fatalf("%s (rebuilt %t): expected %+v and %+v, got %+v and %+v", stage, rebuild, wantByUser, wantByPR, byUser, byPR)
+			}
+		}
+	}
+	if err := b.db.Exec("DELETE FROM users WHERE id = ?", r3.ID).Error; err != nil {
+		t.Fatalf("delete reviewer: %v", err)
+	}
+	assertCounters("reviewer deleted",
+		[]repository.AssignmentStatByUser{{UserID: "r2", Username: "user r2", Assignments: 2}},
+		[]repository.AssignmentStatByPR{{PRID: "pr-1", Name: "PR pr-1", Reviewers: 1}, {PRID: "pr-2", Name: "PR pr-2", Reviewers: 1}})
+	if err := b.db.Exec("DELETE FROM pull_requests WHERE pr_id = ?", "pr-2").Error; err != nil {
+		t.Fatalf("delete PR: %v", err)
+	}
+	assertCounters("PR deleted",
+		[]repository.AssignmentStatByUser{{UserID: "r2", Username: "user r2", Assignments: 1}},
+		[]repository.AssignmentStatByPR{{PRID: "pr-1", Name: "PR pr-1", Reviewers: 1}})
+	if err := b.db.Exec("DELETE FROM users WHERE id = ?", author.ID).Error; err != nil {
+		t.Fatalf("delete author: %v", err)
+	}
+	assertCounters("author deleted", nil, nil)
+}
+
+func conformAvailability(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	team, users := seedTeam(t, b, "backend", "u1", "u2", "u3")
+	now := time.Now()
+
+	absence := &model.Absence{UserID: users[0].ID, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	if err := b.availability.CreateAbsence(ctx, absence); err != nil {
+		t.Fatalf("create absence: %v", err)
+	}
+	future := &model.Absence{UserID: users[1].ID, StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	if err := b.availability.CreateAbsence(ctx, future); err != nil {
+		t.Fatalf("create absence: %v", err)
+	}
+	absent, err := b.availability.GetAbsentUserIDs(ctx, team.ID, now)
+	if err != nil {
+		t.Fatalf("absent users: %v", err)
+	}
+	if !slices.Equal(absent, []uint{users[0].ID}) {
+		t.Fatalf("expected only u1 absent, got %v", absent)
+	}
+
+	pending, err := b.availability.GetPendingAbsences(ctx, now)
+	if err != nil {
+		t.Fatalf("pending absences: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != absence.ID || pending[0].User.UserID != "u1" {
+		t.Fatalf("expected pending absence of u1, got %+v", pending)
+	}
+	if err := b.availability.MarkAbsenceReassigned(ctx, absence.ID, now); err != nil {
+		t.Fatalf("mark absence reassigned: %v", err)
+	}
+	if pending, err = b.availability.GetPendingAbsences(ctx, now); err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending absences after reassignment, got %+v, %v", pending, err)
+	}
+	if overlaps, err := b.availability.HasOverlappingAbsence(ctx, users[1].ID, now.Add(90*time.Minute), now.Add(3*time.Hour)); err != nil || !overlaps {
+		t.Fatalf("expected overlap with future absence, got %v, %v", overlaps, err)
+	}
+	if overlaps, err := b.availability.HasOverlappingAbsence(ctx, users[1].ID, now.Add(2*time.Hour), now.Add(3*time.Hour)); err != nil || overlaps {
+		t.Fatalf("expected adjacent period not to overlap, got %v, %v", overlaps, err)
+	}
+	found, err := b.availability.GetAbsence(ctx, future.ID)
+	if err != nil || found.User.UserID != "u2" || found.User.Team.Name != "backend" {
+		t.Fatalf("expected absence of u2 with team, got %+v, %v", found, err)
+	}
+	if err := b.availability.DeleteAbsence(ctx, future.ID); err != nil {
+		t.Fatalf("delete absence: %v", err)
+	}
+	if err := b.availability.DeleteAbsence(ctx, future.ID); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for deleted absence, got %v", err)
+	}
+	if _, err := b.availability.GetAbsence(ctx, future.ID); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for deleted absence lookup, got %v", err)
+	}
+
+	holiday := &model.TeamHoliday{TeamID: team.ID, StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour)}
+	if err := b.availability.CreateHoliday(ctx, holiday); err != nil {
+		t.Fatalf("create holiday: %v", err)
+	}
+	absent, err = b.availability.GetAbsentUserIDs(ctx, team.ID, now.Add(36*time.Hour))
+	if err != nil {
+		t.Fatalf("absent users on holiday: %v", err)
+	}
+	if len(absent) != 3 {
+		t.Fatalf("expected the whole team absent on holiday, got %v", absent)
+	}
+
+	delegation := &model.Delegation{UserID: users[1].ID, DelegateID: users[2].ID, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	if err := b.availability.CreateDelegation(ctx, delegation); err != nil {
+		t.Fatalf("create delegation: %v", err)
+	}
+	overlaps, err := b.availability.HasOverlappingDelegation(ctx, users[1].ID, now, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("overlapping delegation: %v", err)
+	}
+	if !overlaps {
+		t.Fatalf("expected overlapping delegation")
+	}
+	active, err := b.availability.GetActiveDelegations(ctx, team.ID, now)
+	if err != nil {
+		t.Fatalf("active delegations: %v", err)
+	}
+	if len(active) != 1 || active[0].Delegate.UserID != "u3" {
+		t.Fatalf("expected delegation to u3, got %+v", active)
+	}
+	if err := b.availability.DeleteDelegation(ctx, delegation.ID); err != nil {
+		t.Fatalf("delete delegation: %v", err)
+	}
+	if active, err = b.availability.GetActiveDelegations(ctx, team.ID, now); err != nil || len(active) != 0 {
+		t.Fatalf("expected no delegations after delete, got %+v, %v", active, err)
+	}
+}
+
+func conformAudit(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+	events := []model.AuditEvent{
+		{Type: model.AuditAssigned, Reason: "create", Actor: "api", PRID: "pr-1", UserID: "r1", TeamName: "backend", OccurredAt: base},
+		{Type: model.AuditReplaced, Reason: "reassign", Actor: "api", PRID: "pr-1", UserID: "r2", PreviousUserID: "r1", TeamName: "backend", OccurredAt: base.Add(time.Minute)},
+		{Type: model.AuditUnassigned, Reason: "deactivated", Actor: "api", PRID: "pr-2", UserID: "r1", TeamName: "backend", OccurredAt: base.Add(2 * time.Minute)},
+		{Type: model.AuditReplaced, Reason: "reassign", Actor: "api", PRID: "pr-3", UserID: "r3", PreviousUserID: "r1", TeamName: "frontend", OccurredAt: base.Add(3 * time.Minute)},
+	}
+	if err := b.audit.Append(ctx, events); err != nil {
+		t.Fatalf("append audit: %v", err)
+	}
+
+	found, err := b.audit.Find(ctx, repository.AuditFilter{UserID: "r1", TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("find audit: %v", err)
+	}
+	if len(found) != 3 || found[0].Type != model.AuditUnassigned || found[2].Type != model.AuditAssigned {
+		t.Fatalf("expected three backend events of r1 newest first, got %+v", found)
+	}
+	from := base.Add(30 * time.Second)
+	limited, err := b.audit.Find(ctx, repository.AuditFilter{From: &from, Limit: 2})
+	if err != nil {
+		t.Fatalf("find audit with limit: %v", err)
+	}
+	if len(limited) != 2 || limited[0].PRID != "pr-3" || limited[1].PRID != "pr-2" {
+		t.Fatalf("expected two newest events, got %+v", limited)
+	}
+
+	replacements, err := b.stats.GetReplacementsByUser(ctx, "backend", nil, nil)
+	if err != nil {
+		t.Fatalf("replacements by user: %v", err)
+	}
+	want := []repository.ReplacementStat{
+		{UserID: "r1", Reason: "deactivated", Count: 1},
+		{UserID: "r1", Reason: "reassign", Count: 1},
+	}
+	if !slices.Equal(replacements, want) {
+		t.Fatalf("expected %+v, got %+v", want, replacements)
+	}
+}
+
+func conformIdempotency(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	now := time.Now()
+
+	record := &model.IdempotencyRecord{Key: "k1", Route: "POST /pr", Fingerprint: "f1", ExpiresAt: now.Add(time.Hour)}
+	if err := b.idempotency.Create(ctx, record); err != nil {
+		t.Fatalf("reserve key: %v", err)
+	}
+	duplicate := &model.IdempotencyRecord{Key: "k1", Route: "POST /pr", Fingerprint: "f2", ExpiresAt: now.Add(time.Hour)}
+	if err := b.idempotency.Create(ctx, duplicate); !errors.Is(err, repoerrs.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for reserved key, got %v", err)
+	}
+
+	record.StatusCode = 201
+	record.Body = []byte(`{"ok":true}`)
+	if err := b.idempotency.Save(ctx, record); err != nil {
+		t.Fatalf("save record: %v", err)
+	}
+	loaded, err := b.idempotency.Get(ctx, "k1", "POST /pr")
+	if err != nil {
+		t.Fatalf("get record: %v", err)
+	}
+	if loaded.StatusCode != 201 || string(loaded.Body) != `{"ok":true}` || loaded.Fingerprint != "f1" {
+		t.Fatalf("unexpected stored record %+v", loaded)
+	}
+	if _, err := b.idempotency.Get(ctx, "k1", "POST /team"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another route, got %v", err)
+	}
+
+	expired := &model.IdempotencyRecord{Key: "k2", Route: "POST /pr", Fingerprint: "f", ExpiresAt: now.Add(-time.Minute)}
+	if err := b.idempotency.Create(ctx, expired); err != nil {
+		t.Fatalf("reserve expired key: %v", err)
+	}
+	removed, err := b.idempotency.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected one expired record removed, got %d", removed)
+	}
+	if err := b.idempotency.Delete(ctx, "k1", "POST /pr"); err != nil {
+		t.Fatalf("delete record: %v", err)
+	}
+	if _, err := b.idempotency.Get(ctx, "k1", "POST /pr"); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func conformJobs(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newJob := func(keys ...string) *model.Job {
+		job := &model.Job{Kind: model.JobKindBulkDeactivate, Status: model.JobQueued, Actor: "admin", Payload: `{"team_name":"backend"}`, Total: len(keys)}
+		for i, key := range keys {
+			job.Items = append(job.Items, model.JobItem{Seq: i + 1, Key: key, Status: model.JobItemPending})
+		}
+		if err := b.job.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		return job
+	}
+	first := newJob("u1", "u2")
+	second := newJob("u3")
+
+	claimed, err := b.job.ClaimNext(ctx, "w1", now, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("claim job: %v", err)
+	}
+	if claimed.ID != first.ID || claimed.Status != model.JobRunning || claimed.StartedAt == nil || len(claimed.Items) != 2 || claimed.Items[1].Key != "u2" ||
+		claimed.LockedBy != "w1" || claimed.LeaseUntil == nil || claimed.Attempts != 0 {
+		t.Fatalf("expected oldest job running under w1 with its items, got %+v", claimed)
+	}
+
+	item := claimed.Items[0]
+	doneAt := now.Add(time.Second)
+	item.Status, item.Result, item.FinishedAt = model.JobItemSucceeded, `{"reassigned":1}`, &doneAt
+	// Писать результаты может только обработчик, который держит задачу.
+	if err := b.job.SaveItem(ctx, "w2", doneAt, &item); !errors.Is(err, repoerrs.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost for another worker's item, got %v", err)
+	}
+	if err := b.job.SaveItem(ctx, "w1", doneAt, &item); err != nil {
+		t.Fatalf("save item: %v", err)
+	}
+	claimed.Processed, claimed.HeartbeatAt = 1, &doneAt
+	if err := b.job.SaveProgress(ctx, "w2", doneAt, claimed); !errors.Is(err, repoerrs.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost for another worker's progress, got %v", err)
+	}
+	if err := b.job.SaveProgress(ctx, "w1", doneAt, claimed); err != nil {
+		t.Fatalf("save progress: %v", err)
+	}
+
+	// Отмена выполняемой задачи только ставит флаг, задача из очереди отменяется сразу вместе с элементами.
+	if canceled, err := b.job.RequestCancel(ctx, first.ID, now); err != nil || !canceled.CancelRequested || canceled.Status != model.JobRunning {
+		t.Fatalf("expected cancel flag on running job, got %+v, %v", canceled, err)
+	}
+	if requested, err := b.job.CancelRequested(ctx, first.ID); err != nil || !requested {
+		t.Fatalf("expected cancel requested, got %t, %v", requested, err)
+	}
+	if canceled, err := b.job.RequestCancel(ctx, second.ID, now); err != nil || canceled.Status != model.JobCanceled || canceled.FinishedAt == nil {
+		t.Fatalf("expected queued job canceled, got %+v, %v", canceled, err)
+	}
+	loaded, err := b.job.Get(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if loaded.Items[0].Status != model.JobItemCanceled {
+		t.Fatalf("expected items of canceled queued job canceled, got %+v", loaded.Items)
+	}
+
+	loaded, err = b.job.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if loaded.Processed != 1 || !loaded.CancelRequested || loaded.Actor != "admin" ||
+		loaded.Items[0].Status != model.JobItemSucceeded || loaded.Items[0].Result != `{"reassigned":1}` || loaded.Items[0].Key != "u1" ||
+		loaded.Items[1].Status != model.JobItemPending {
+		t.Fatalf("unexpected stored job %+v", loaded)
+	}
+
+	// Живую задачу второй раз не забрать, а задачу с истёкшей арендой подхватывает другой обработчик
+	// и засчитывает прерванную попытку.
+	if _, err := b.job.ClaimNext(ctx, "w2", now, now.Add(time.Minute)); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected nothing to claim, got %v", err)
+	}
+	later := now.Add(time.Hour)
+	reclaimed, err := b.job.ClaimNext(ctx, "w2", later, later.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("reclaim stale job: %v", err)
+	}
+	if reclaimed.ID != first.ID || reclaimed.Processed != 1 || !reclaimed.StartedAt.Equal(*claimed.StartedAt) ||
+		reclaimed.LockedBy != "w2" || reclaimed.Attempts != 1 {
+		t.Fatalf("expected stale job reclaimed by w2 with its progress, got %+v", reclaimed)
+	}
+	// Прежний обработчик задачу потерял, даже если его аренда по его часам ещё не истекла.
+	if err := b.job.SaveProgress(ctx, "w1", doneAt, claimed); !errors.Is(err, repoerrs.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost after reclaim, got %v", err)
+	}
+
+	if _, err := b.job.Get(ctx, 999); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing job, got %v", err)
+	}
+	if _, err := b.job.RequestCancel(ctx, 999, now); !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when canceling missing job, got %v", err)
+	}
+}
+
+func conformUnitOfWork(t *testing.T, b *repositoryBackend) {
+	ctx := context.Background()
+	failure := errors.New("boom")
+
+	err := b.uow.Do(ctx, func(ctx context.Context) error {
+		if err := b.team.CreateTeam(ctx, &model.Team{Name: "kept"}); err != nil {
+			return err
+		}
+		// Ошибка вложенной единицы работы откатывает только её изменения.
+		nestedErr := b.uow.Do(ctx, func(ctx context.Context) error {
+			if err := b.team.CreateTeam(ctx, &model.Team{Name: "nested"}); err != nil {
+				return err
+			}
+			return b.team.CreateTeam(ctx, &model.Team{Name: "kept"})
+		})
+		if !errors.Is(nestedErr, repoerrs.ErrDuplicate) {
+			t.Errorf("expected duplicate team error in nested unit of work, got %v", nestedErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = b.uow.Do(ctx, func(ctx context.Context) error {
+		if err := b.team.CreateTeam(ctx, &model.Team{Name: "rolled-back"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	// Откат вложенной единицы работы не портит точку отката внешней, даже если до вложенной внешняя таблицу не меняла.
+	err = b.uow.Do(ctx, func(ctx context.Context) error {
+		nestedErr := b.uow.Do(ctx, func(ctx context.Context) error {
+			if err := b.team.CreateTeam(ctx, &model.Team{Name: "inner"}); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(nestedErr, failure) {
+			t.Errorf("expected fn error in nested unit of work, got %v", nestedErr)
+		}
+		if err := b.team.CreateTeam(ctx, &model.Team{Name: "outer"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	for name, want := range map[string]bool{"kept": true, "nested": false, "rolled-back": false, "inner": false, "outer": false} {
+		exists, err := b.team.TeamExists(ctx, name)
+		if err != nil {
+			t.Fatalf("team exists: %v", err)
+		}
+		if exists != want {
+			t.Fatalf("team %q: expected exists=%v, got %v", name, want, exists)
+		}
+	}
+
+	// Изменения строк на месте (ревьюверы, периоды назначения, версия PR) тоже откатываются.
+	_, users := seedTeam(t, b, "uow", "author", "r1", "r2")
+	before := seedPR(t, b, "pr-uow", users[0], users[1])
+	err = b.uow.Do(ctx, func(ctx context.Context) error {
+		pr, err := b.pr.GetPRByExternalID(ctx, "pr-uow")
+		if err != nil {
+			return err
+		}
+		if _, err := b.pr.MarkReviewed(ctx, pr, users[1].ID, time.Now()); err != nil {
+			return err
+		}
+		if err := b.pr.ReplaceReviewer(ctx, pr, users[1].ID, users[2]); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	after := loadPR(t, b, "pr-uow")
+	if after.Version != before.Version || !slices.Equal(userIDsOf(after.AssignedReviewers), []string{"r1"}) {
+		t.Fatalf("expected pr-uow unchanged at version %d with r1, got version %d with %v",
+			before.Version, after.Version, userIDsOf(after.AssignedReviewers))
+	}
+	if first, err := b.pr.MarkReviewed(ctx, after, users[1].ID, time.Now()); err != nil || !first {
+		t.Fatalf("expected rolled back review to be recorded again, got %v, %v", first, err)
+	}
+}
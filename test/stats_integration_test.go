@@ -0,0 +1,74 @@
+package test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+	"github.com/Leganyst/avitoTrainee/internal/service"
+)
+
+func TestStats_CountersMatchLiveQuery(t *testing.T) {
+	db := connectTestDB(t)
+	prepareDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	teamSvc := service.NewTeamService(repository.NewUnitOfWork(db), repository.NewTeamRepository(db), userRepo)
+	prSvc := service.NewPrService(repository.NewUnitOfWork(db), repository.NewPRRepository(db), userRepo, repository.NewAvailabilityRepository(db), repository.NewAuditRepository(db))
+
+	members := []model.User{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Bob", IsActive: true},
+		{UserID: "u3", Username: "Charlie", IsActive: true},
+		{UserID: "u4", Username: "Oleg", IsActive: true},
+	}
+	if _, err := teamSvc.CreateTeam(context.Background(), model.Team{Name: "backend", Users: members}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+
+	pr1, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-1", "Add search", "u1")
+	if err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if _, err := prSvc.CreatePR(context.Background(), service.Origin{}, "pr-2", "Fix search", "u2"); err != nil {
+		t.Fatalf("CreatePR returned error: %v", err)
+	}
+	if _, _, err := prSvc.Reassign(context.Background(), service.Origin{}, "pr-1", pr1.AssignedReviewers[0].UserID, ""); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if _, err := prSvc.Merge(context.Background(), service.Origin{}, "pr-2"); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+
+	// Все PR созданы сегодня, поэтому фильтр по дню создания не должен ничего отбросить.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, status := range []string{"", "OPEN", "MERGED"} {
+		counters := assignmentsByUser(t, statsRepo, repository.AssignmentStatsFilter{Status: status, Sort: repository.SortUserIDAsc})
+		byDay := assignmentsByUser(t, statsRepo, repository.AssignmentStatsFilter{Status: status, Sort: repository.SortUserIDAsc, From: &today})
+		if !reflect.DeepEqual(counters, byDay) {
+			t.Fatalf("status %q: counters %+v differ from day bucket %+v", status, counters, byDay)
+		}
+	}
+
+	before := assignmentsByUser(t, statsRepo, repository.AssignmentStatsFilter{Sort: repository.SortUserIDAsc})
+	if err := statsRepo.RebuildAssignmentStats(context.Background()); err != nil {
+		t.Fatalf("RebuildAssignmentStats returned error: %v", err)
+	}
+	after := assignmentsByUser(t, statsRepo, repository.AssignmentStatsFilter{Sort: repository.SortUserIDAsc})
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("rebuilt counters %+v differ from incremental %+v", after, before)
+	}
+}
+
+func assignmentsByUser(t *testing.T, repo *repository.GormStatsRepository, filter repository.AssignmentStatsFilter) []repository.AssignmentStatByUser {
+	t.Helper()
+	stats, err := repo.GetAssignmentsByUser(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetAssignmentsByUser returned error: %v", err)
+	}
+	return stats
+}
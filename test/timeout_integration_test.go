@@ -0,0 +1,26 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRequestDeadline_ReturnsTimeout(t *testing.T) {
+	server := newAPITestServerWithTimeout(t, time.Nanosecond)
+
+	resp := server.doRequest(newJSONRequest(t, http.MethodPost, "/api/pullRequest/merge", `{"pull_request_id":"pr-1"}`))
+	assertErrorResponse(t, resp, http.StatusGatewayTimeout, "TIMEOUT")
+}
+
+func TestRequestCanceledByClient_ReturnsCanceled(t *testing.T) {
+	server := newAPITestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := newJSONRequest(t, http.MethodPost, "/api/pullRequest/merge", `{"pull_request_id":"pr-1"}`).WithContext(ctx)
+
+	resp := server.doRequest(req)
+	assertErrorResponse(t, resp, 499, "CANCELED")
+}
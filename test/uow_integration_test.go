@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Leganyst/avitoTrainee/internal/model"
+	"github.com/Leganyst/avitoTrainee/internal/repository"
+)
+
+func TestUnitOfWork_RollbackDiscardsAllRepositories(t *testing.T) {
+	db := connectTestDB(t)
+	prepareDB(t, db)
+
+	ctx := context.Background()
+	teamRepo := repository.NewTeamRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	uow := repository.NewUnitOfWork(db)
+
+	failure := errors.New("boom")
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		team := &model.Team{Name: "backend"}
+		if err := teamRepo.CreateTeam(ctx, team); err != nil {
+			return err
+		}
+		if err := userRepo.CreateOrUpdate(ctx, &model.User{UserID: "u1", Username: "Alice", TeamID: team.ID, IsActive: true}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	exists, err := teamRepo.TeamExists(ctx, "backend")
+	if err != nil {
+		t.Fatalf("team exists: %v", err)
+	}
+	if exists {
+		t.Fatalf("expected team creation to be rolled back")
+	}
+	if _, err := userRepo.GetByUserID(ctx, "u1"); err == nil {
+		t.Fatalf("expected user creation to be rolled back")
+	}
+}
+
+func TestUnitOfWork_NestedFailureKeepsOuterChanges(t *testing.T) {
+	db := connectTestDB(t)
+	prepareDB(t, db)
+
+	ctx := context.Background()
+	teamRepo := repository.NewTeamRepository(db)
+	uow := repository.NewUnitOfWork(db)
+
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		if err := teamRepo.CreateTeam(ctx, &model.Team{Name: "backend"}); err != nil {
+			return err
+		}
+		// Повторная вставка падает на уникальном индексе; точка сохранения не даёт ей оборвать внешнюю транзакцию.
+		nestedErr := uow.Do(ctx, func(ctx context.Context) error {
+			return teamRepo.CreateTeam(ctx, &model.Team{Name: "backend"})
+		})
+		if nestedErr == nil {
+			t.Errorf("expected duplicate team error in nested unit of work")
+		}
+		return teamRepo.CreateTeam(ctx, &model.Team{Name: "frontend"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"backend", "frontend"} {
+		exists, err := teamRepo.TeamExists(ctx, name)
+		if err != nil {
+			t.Fatalf("team exists: %v", err)
+		}
+		if !exists {
+			t.Fatalf("expected team %s to be committed", name)
+		}
+	}
+}